@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+
+	"meemo/internal/infrastructure/logger"
+	"meemo/internal/interactor"
+	fileusecase "meemo/internal/usecase/file"
+
+	"go.uber.org/zap"
+)
+
+// runFsck выполняет команду "meemo fsck": сверяет базу с бакетом и печатает отчет в JSON.
+// Код выхода 1 означает, что остались неисправленные расхождения.
+func runFsck(ctx context.Context, i interactor.Interactor, args []string, log logger.Logger) int {
+	flags := flag.NewFlagSet("fsck", flag.ExitOnError)
+	repair := flags.Bool("repair", false, "repair found issues instead of only reporting them")
+	gracePeriod := flags.Duration("grace-period", fileusecase.DefaultCheckGracePeriod, "objects younger than this are not reported as orphaned")
+	pendingOlderThan := flags.Duration("pending-older-than", fileusecase.DefaultCheckPendingOlderThan, "pending files older than this are reported as stale")
+	maxIssues := flags.Int("max-issues", fileusecase.DefaultCheckMaxIssues, "maximum number of issues included in the report")
+	_ = flags.Parse(args)
+
+	report, err := i.NewFileUseCase().CheckStorage(ctx, &fileusecase.CheckStorageDtoIn{
+		Repair:           *repair,
+		GracePeriod:      *gracePeriod,
+		PendingOlderThan: *pendingOlderThan,
+		MaxIssues:        *maxIssues,
+	})
+	if err != nil {
+		log.Error("storage check failed", zap.Error(err))
+		return 2
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Error("failed to write storage check report", zap.Error(err))
+		return 2
+	}
+	if report.Unresolved() > 0 {
+		return 1
+	}
+	return 0
+}
@@ -11,14 +11,22 @@ import (
 
 	"meemo/config"
 	_ "meemo/docs"
+	"meemo/internal/infrastructure/antivirus"
+	"meemo/internal/infrastructure/crypto"
+	"meemo/internal/infrastructure/imaging"
 	"meemo/internal/infrastructure/logger"
 	"meemo/internal/infrastructure/storage/pg"
 	"meemo/internal/infrastructure/storage/s3"
+	s3file "meemo/internal/infrastructure/storage/s3/file"
+	"meemo/internal/infrastructure/storage/sqlite"
 	"meemo/internal/interactor"
 	"meemo/internal/presenter/http/router"
+	fileusecase "meemo/internal/usecase/file"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"go.uber.org/zap"
 	"gopkg.in/yaml.v3"
 )
 
@@ -52,6 +60,7 @@ import (
 // @name Authorization
 // @description Bearer {access_token}
 
+// Без аргументов запускается сервер; "meemo fsck [-repair]" проверяет согласованность хранилища.
 var configPathFlag = flag.String("config", ".config.yaml", "path to config file")
 
 func main() {
@@ -67,18 +76,120 @@ func main() {
 
 	ctx := context.Background()
 
-	S3, err := s3.NewS3(ctx, &cfg.S3)
-	if err != nil {
-		log.Fatal("failed to connect to S3")
+	var objects s3file.S3Client
+	switch cfg.Storage.Backend {
+	case "", config.StorageBackendS3:
+		S3, err := s3.NewS3(ctx, &cfg.S3)
+		if err != nil {
+			log.Fatal("failed to connect to S3")
+		}
+		objects = s3file.NewS3Client(S3, cfg.S3BucketName, log)
+	case config.StorageBackendFilesystem:
+		objects, err = s3file.NewFSClient(s3file.FSOptions{
+			Root:       cfg.Storage.Filesystem.Root,
+			ShardDepth: cfg.Storage.Filesystem.ShardDepth,
+			Fsync:      cfg.Storage.Filesystem.Fsync,
+		}, log)
+		if err != nil {
+			log.Fatal("failed to open filesystem storage", zap.Error(err))
+		}
+	default:
+		log.Fatal("invalid storage backend", zap.String("backend", cfg.Storage.Backend))
 	}
-	PG, err := pg.NewPGConnection(&cfg.Postgres)
-	if err != nil {
-		log.Fatal("failed to connect to PostgreSQL")
+	var db *sqlx.DB
+	switch cfg.Metadata.Backend {
+	case "", config.MetadataBackendPostgres:
+		db, err = pg.NewPGConnection(&cfg.Postgres)
+		if err != nil {
+			log.Fatal("failed to connect to PostgreSQL")
+		}
+	case config.MetadataBackendSQLite:
+		db, err = sqlite.NewSQLiteConnection(&cfg.Metadata.SQLite)
+		if err != nil {
+			log.Fatal("failed to open SQLite database", zap.Error(err))
+		}
+	default:
+		log.Fatal("invalid metadata backend", zap.String("backend", cfg.Metadata.Backend))
+	}
+
+	for mimeType, encoding := range cfg.Files.Compression.MimeTypes {
+		if err := s3file.ValidateEncoding(encoding); err != nil {
+			log.Fatal("invalid compression config", zap.String("mimeType", mimeType), zap.Error(err))
+		}
+	}
+	switch cfg.Files.MimeDetection {
+	case "", fileusecase.MimeDetectionOff, fileusecase.MimeDetectionCorrect, fileusecase.MimeDetectionVerify:
+	default:
+		log.Fatal("invalid mime detection mode", zap.String("mode", cfg.Files.MimeDetection))
+	}
+	typeRules := []config.TypeRulesConfig{cfg.Files.TypePolicy.TypeRulesConfig}
+	for _, planRules := range cfg.Files.TypePolicy.Plans {
+		typeRules = append(typeRules, planRules)
+	}
+	for _, rules := range typeRules {
+		for _, rule := range append(rules.Allow, rules.Deny...) {
+			if err := fileusecase.ValidateTypeRule(rule); err != nil {
+				log.Fatal("invalid file type policy", zap.Error(err))
+			}
+		}
+	}
+	if thumbnails := cfg.Files.Thumbnails; thumbnails.Enabled {
+		if len(thumbnails.Sizes) == 0 || len(thumbnails.Formats) == 0 {
+			log.Fatal("thumbnail sizes and formats must not be empty")
+		}
+		for _, size := range thumbnails.Sizes {
+			if size <= 0 {
+				log.Fatal("invalid thumbnail size", zap.Int("size", size))
+			}
+		}
+		for _, format := range thumbnails.Formats {
+			if err := imaging.ValidateFormat(format); err != nil {
+				log.Fatal("invalid thumbnail format", zap.Error(err))
+			}
+		}
+	}
+
+	var keys crypto.KeyProvider
+	if cfg.Encryption.Keyfile != "" {
+		keys, err = crypto.NewLocalKeyProvider(cfg.Encryption.Keyfile, cfg.Encryption.CurrentKey)
+		if err != nil {
+			log.Fatal("failed to load encryption keys", zap.Error(err))
+		}
+	} else if cfg.Encryption.Enabled {
+		log.Fatal("encryption is enabled but no keyfile is configured")
+	}
+
+	var scanner antivirus.Scanner
+	if cfg.Antivirus.Enabled {
+		scanner, err = antivirus.NewClamdScanner(antivirus.ClamdOptions{
+			Address:   cfg.Antivirus.Address,
+			Timeout:   cfg.Antivirus.Timeout,
+			ChunkSize: cfg.Antivirus.ChunkSize,
+		})
+		if err != nil {
+			log.Fatal("invalid antivirus config", zap.Error(err))
+		}
+		// clamd может подняться позже приложения, поэтому недоступность при старте не фатальна.
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		if err := scanner.Ping(pingCtx); err != nil {
+			log.Warn("clamd is unavailable", zap.String("address", cfg.Antivirus.Address), zap.Error(err))
+		}
+		cancel()
+	}
+
+	i := interactor.NewInteractor(db, objects, keys, scanner, cfg, log)
+
+	if flag.Arg(0) == "fsck" {
+		code := runFsck(ctx, i, flag.Args()[1:], log)
+		_ = log.Sync()
+		os.Exit(code)
 	}
 
-	i := interactor.NewInteractor(PG, S3, cfg.S3BucketName, log, cfg.RegistrationEnabled)
 	h := i.NewAppHandler()
 
+	jobs := i.NewScheduler()
+	jobs.Start(ctx)
+
 	e := setupEcho()
 	router.NewRouter(e, h)
 
@@ -100,6 +211,7 @@ func main() {
 	if err := e.Shutdown(shutdownCtx); err != nil {
 		log.Fatal("server shutdown failed")
 	}
+	jobs.Stop()
 
 	log.Info("server stopped")
 }
@@ -115,10 +227,10 @@ func setupEcho() *echo.Echo {
 
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
-		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, "X-User-ID", "X-User-Email", echo.HeaderXCSRFToken},
+		AllowMethods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions},
+		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, "X-User-ID", "X-User-Email", echo.HeaderXCSRFToken, "Digest", "Content-SHA256", "X-Share-Password"},
 		AllowCredentials: true,
-		ExposeHeaders:    []string{echo.HeaderXCSRFToken},
+		ExposeHeaders:    []string{echo.HeaderXCSRFToken, "Digest", "X-Checksum-SHA256", "X-Checksum-CRC32C"},
 	}))
 
 	e.Use(middleware.CSRFWithConfig(middleware.CSRFConfig{
@@ -148,6 +260,11 @@ func setupEcho() *echo.Echo {
 				return true
 			}
 
+			// Публичные ссылки открываются анонимно: у посетителя нет ни сессии, ни CSRF-cookie.
+			if path == "/s/:token" {
+				return true
+			}
+
 			if path == "/ping" || len(path) >= 8 && path[:8] == "/swagger" {
 				return true
 			}
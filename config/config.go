@@ -3,9 +3,11 @@ package config
 import (
 	"os"
 	"strconv"
+	"time"
 
 	"meemo/internal/infrastructure/storage/pg"
 	"meemo/internal/infrastructure/storage/s3"
+	"meemo/internal/infrastructure/storage/sqlite"
 )
 
 type Config struct {
@@ -14,10 +16,174 @@ type Config struct {
 	LogLevel string `yaml:"log_level"`
 
 	RegistrationEnabled bool `yaml:"registration_enabled"`
+	// AdminEmails — адреса администраторов: им доступны запросы /api/v1/admin и уведомления о карантине.
+	AdminEmails []string `yaml:"admin_emails"`
 
-	Postgres     pg.PGConfig `yaml:"postgres"`
-	S3           s3.Config   `yaml:"s3"`
-	S3BucketName string      `yaml:"s3_bucket_name"`
+	Postgres     pg.PGConfig    `yaml:"postgres"`
+	Metadata     MetadataConfig `yaml:"metadata"`
+	Storage      StorageConfig  `yaml:"storage"`
+	S3           s3.Config      `yaml:"s3"`
+	S3BucketName string         `yaml:"s3_bucket_name"`
+
+	Files      FilesConfig      `yaml:"files"`
+	Encryption EncryptionConfig `yaml:"encryption"`
+	Antivirus  AntivirusConfig  `yaml:"antivirus"`
+	Jobs       JobsConfig       `yaml:"jobs"`
+}
+
+const (
+	MetadataBackendPostgres = "postgres"
+	MetadataBackendSQLite   = "sqlite"
+)
+
+// MetadataConfig выбирает хранилище метаданных: Postgres (по умолчанию) или встроенный файл SQLite
+// для одного узла и локальной разработки.
+type MetadataConfig struct {
+	Backend string              `yaml:"backend"`
+	SQLite  sqlite.SQLiteConfig `yaml:"sqlite"`
+}
+
+const (
+	StorageBackendS3         = "s3"
+	StorageBackendFilesystem = "filesystem"
+)
+
+// StorageConfig выбирает хранилище содержимого файлов: S3 (по умолчанию) или каталог на локальном диске.
+type StorageConfig struct {
+	Backend    string           `yaml:"backend"`
+	Filesystem FilesystemConfig `yaml:"filesystem"`
+}
+
+type FilesystemConfig struct {
+	Root       string `yaml:"root"`
+	ShardDepth int    `yaml:"shard_depth"`
+	Fsync      bool   `yaml:"fsync"`
+}
+
+type FilesConfig struct {
+	Deduplication     bool              `yaml:"deduplication"`
+	DeleteConcurrency int               `yaml:"delete_concurrency"`
+	MaxFileSize       int64             `yaml:"max_file_size"`
+	Compression       CompressionConfig `yaml:"compression"`
+	MimeDetection     string            `yaml:"mime_detection"`
+	TypePolicy        TypePolicyConfig  `yaml:"type_policy"`
+	Thumbnails        ThumbnailsConfig  `yaml:"thumbnails"`
+}
+
+// ThumbnailsConfig задает миниатюры изображений. Первые элементы sizes и formats используются,
+// когда размер или формат не указаны в запросе.
+type ThumbnailsConfig struct {
+	Enabled         bool     `yaml:"enabled"`
+	Sizes           []int    `yaml:"sizes"`
+	Formats         []string `yaml:"formats"`
+	JPEGQuality     int      `yaml:"jpeg_quality"`
+	MaxSourceBytes  int64    `yaml:"max_source_bytes"`
+	MaxSourcePixels int64    `yaml:"max_source_pixels"`
+}
+
+// TypeRulesConfig — списки разрешенных и запрещенных типов файлов. Элемент списка — MIME-тип,
+// шаблон вида "image/*" или расширение с точкой. Пустой allow разрешает все, что не запрещено.
+type TypeRulesConfig struct {
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+}
+
+// TypePolicyConfig задает типы файлов, которые можно загружать: общие правила и правила
+// тарифных планов по имени плана пользователя. Файл должен пройти и те, и другие.
+type TypePolicyConfig struct {
+	TypeRulesConfig `yaml:",inline"`
+	Plans           map[string]TypeRulesConfig `yaml:"plans"`
+}
+
+// CompressionConfig задает сжатие содержимого при записи в S3. mime_types сопоставляет MIME-тип
+// или шаблон вида "text/*" с кодеком zstd или gzip.
+type CompressionConfig struct {
+	Enabled   bool              `yaml:"enabled"`
+	MinSize   int64             `yaml:"min_size"`
+	MimeTypes map[string]string `yaml:"mime_types"`
+}
+
+// EncryptionConfig задает шифрование содержимого файлов. Пока задан keyfile, зашифрованные объекты
+// читаются даже при выключенном enabled. Если current_key пуст, текущим считается последний ключ файла.
+type EncryptionConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	Keyfile    string `yaml:"keyfile"`
+	CurrentKey string `yaml:"current_key"`
+	ChunkSize  int    `yaml:"chunk_size"`
+}
+
+// AntivirusConfig задает проверку загрузок через clamd. Зараженные файлы попадают в карантин,
+// о чем сообщается владельцу и admin_emails: в журнал и, если задан webhook_url, POST-запросом.
+// При fail_open загрузка принимается без проверки, когда clamd недоступен.
+type AntivirusConfig struct {
+	Enabled        bool          `yaml:"enabled"`
+	Address        string        `yaml:"address"`
+	Timeout        time.Duration `yaml:"timeout"`
+	ChunkSize      int           `yaml:"chunk_size"`
+	FailOpen       bool          `yaml:"fail_open"`
+	WebhookURL     string        `yaml:"webhook_url"`
+	WebhookTimeout time.Duration `yaml:"webhook_timeout"`
+}
+
+type JobsConfig struct {
+	ChecksumScrubber ChecksumScrubberConfig `yaml:"checksum_scrubber"`
+	BlobCollector    BlobCollectorConfig    `yaml:"blob_collector"`
+	TrashPurger      TrashPurgerConfig      `yaml:"trash_purger"`
+	KeyRewrapper     KeyRewrapperConfig     `yaml:"key_rewrapper"`
+	Thumbnailer      ThumbnailerConfig      `yaml:"thumbnailer"`
+	FileExpirer      FileExpirerConfig      `yaml:"file_expirer"`
+	ObjectOutbox     ObjectOutboxConfig     `yaml:"object_outbox"`
+}
+
+type ChecksumScrubberConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	Interval      time.Duration `yaml:"interval"`
+	BatchSize     int           `yaml:"batch_size"`
+	ReverifyAfter time.Duration `yaml:"reverify_after"`
+}
+
+type BlobCollectorConfig struct {
+	Enabled     bool          `yaml:"enabled"`
+	Interval    time.Duration `yaml:"interval"`
+	BatchSize   int           `yaml:"batch_size"`
+	GracePeriod time.Duration `yaml:"grace_period"`
+}
+
+type TrashPurgerConfig struct {
+	Enabled   bool          `yaml:"enabled"`
+	Interval  time.Duration `yaml:"interval"`
+	BatchSize int           `yaml:"batch_size"`
+	Retention time.Duration `yaml:"retention"`
+}
+
+type KeyRewrapperConfig struct {
+	Enabled   bool          `yaml:"enabled"`
+	Interval  time.Duration `yaml:"interval"`
+	BatchSize int           `yaml:"batch_size"`
+}
+
+type ThumbnailerConfig struct {
+	Enabled   bool          `yaml:"enabled"`
+	Interval  time.Duration `yaml:"interval"`
+	BatchSize int           `yaml:"batch_size"`
+}
+
+type FileExpirerConfig struct {
+	Enabled   bool          `yaml:"enabled"`
+	Interval  time.Duration `yaml:"interval"`
+	BatchSize int           `yaml:"batch_size"`
+}
+
+// ObjectOutboxConfig задает повторы операций с S3, которые не удалось выполнить сразу. Задержка
+// перед повтором начинается с retry_delay и удваивается; после max_attempts попыток операция
+// остается в object_outbox с заполненным dead_at.
+type ObjectOutboxConfig struct {
+	Enabled     bool          `yaml:"enabled"`
+	Interval    time.Duration `yaml:"interval"`
+	BatchSize   int           `yaml:"batch_size"`
+	MaxAttempts int           `yaml:"max_attempts"`
+	RetryDelay  time.Duration `yaml:"retry_delay"`
+	Lease       time.Duration `yaml:"lease"`
 }
 
 func (c *Config) LoadSecretsFromEnv() {
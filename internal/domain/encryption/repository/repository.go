@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+)
+
+type ObjectKeyRepository interface {
+	// Save сохраняет ключ данных объекта, заменяя прежний, если объект перезаписан.
+	Save(ctx context.Context, key *entity.ObjectKey) (*entity.ObjectKey, error)
+	Get(ctx context.Context, s3Key string) (*entity.ObjectKey, error)
+	// Copy переносит ключ данных srcKey на dstKey. Если у srcKey ключа нет, ключ dstKey удаляется.
+	Copy(ctx context.Context, srcKey, dstKey string) error
+	Delete(ctx context.Context, s3Key string) error
+	// ListWrappedWithOther возвращает ключи, обернутые не мастер-ключом keyID, кроме отмеченных MarkRewrapFailed.
+	ListWrappedWithOther(ctx context.Context, keyID string, limit int) ([]*entity.ObjectKey, error)
+	// Rewrap заменяет обернутый ключ, если он все еще обернут мастер-ключом oldKeyID.
+	Rewrap(ctx context.Context, s3Key, oldKeyID, newKeyID string, wrappedKey []byte) (bool, error)
+	// MarkRewrapFailed отмечает ключ, который не удалось развернуть мастер-ключом keyID. Отметка
+	// снимается, когда объект перезаписывается.
+	MarkRewrapFailed(ctx context.Context, s3Key, keyID string) error
+}
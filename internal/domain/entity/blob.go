@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+type Blob struct {
+	SHA256            string    `json:"sha256"`
+	S3Key             string    `json:"s3_key"`
+	SizeInBytes       int64     `json:"size_in_bytes"`
+	CRC32C            string    `json:"crc32c"`
+	RefCount          int64     `json:"ref_count"`
+	ContentEncoding   string    `json:"content_encoding"`
+	StoredSizeInBytes int64     `json:"stored_size_in_bytes"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
@@ -5,18 +5,37 @@ import (
 	"time"
 )
 
+// DeletionExpired — причина удаления в журнале file_deletions: истек срок хранения файла.
+const DeletionExpired = "expired"
+
 type File struct {
-	ID           int64     `json:"id"`
-	UserID       int64     `json:"user_id"`
-	OriginalName string    `json:"original_name"`
-	MimeType     string    `json:"mime_type"`
-	SizeInBytes  int64     `json:"size_in_bytes"`
-	S3Bucket     string    `json:"s3_bucket"`
-	S3Key        string    `json:"s3_key"`
-	Status       int       `json:"status"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	IsPublic     bool      `json:"is_public"`
-	R            io.Reader `json:"-"`
-	W            io.Writer `json:"-"`
+	ID                 int64             `json:"id"`
+	UserID             int64             `json:"user_id"`
+	OriginalName       string            `json:"original_name"`
+	MimeType           string            `json:"mime_type"`
+	SizeInBytes        int64             `json:"size_in_bytes"`
+	S3Bucket           string            `json:"s3_bucket"`
+	S3Key              string            `json:"s3_key"`
+	Status             int               `json:"status"`
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+	IsPublic           bool              `json:"is_public"`
+	ChecksumSHA256     string            `json:"checksum_sha256"`
+	ChecksumCRC32C     string            `json:"checksum_crc32c"`
+	ChecksumVerifiedAt *time.Time        `json:"checksum_verified_at"`
+	ChecksumFailed     bool              `json:"checksum_failed"`
+	BlobSHA256         string            `json:"blob_sha256"`
+	ContentEncoding    string            `json:"content_encoding"`
+	StoredSizeInBytes  int64             `json:"stored_size_in_bytes"`
+	ScanStatus         string            `json:"scan_status"`
+	ScanSignature      string            `json:"scan_signature"`
+	ScannedAt          *time.Time        `json:"scanned_at"`
+	FolderID           *int64            `json:"folder_id"`
+	CurrentVersionID   *int64            `json:"current_version_id"`
+	DeletedAt          *time.Time        `json:"deleted_at"`
+	ExpiresAt          *time.Time        `json:"expires_at"`
+	Tags               []string          `json:"tags"`
+	Metadata           map[string]string `json:"metadata"`
+	R                  io.Reader         `json:"-"`
+	W                  io.Writer         `json:"-"`
 }
@@ -0,0 +1,6 @@
+package entity
+
+type FileSearchResult struct {
+	File *File   `json:"file"`
+	Rank float64 `json:"rank"`
+}
@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+type SharePermission string
+
+const (
+	PermissionViewer SharePermission = "viewer"
+	PermissionEditor SharePermission = "editor"
+)
+
+// Allows сообщает, покрывает ли право p право required: редактор может все, что может читатель.
+func (p SharePermission) Allows(required SharePermission) bool {
+	if p == PermissionEditor {
+		return true
+	}
+	return p == required
+}
+
+type FileShare struct {
+	ID         int64           `json:"id"`
+	FileID     int64           `json:"file_id"`
+	UserID     int64           `json:"user_id"`
+	UserEmail  string          `json:"user_email"`
+	Permission SharePermission `json:"permission"`
+	CreatedBy  *int64          `json:"created_by"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// SharedFile — файл другого пользователя, доступный текущему по ACL.
+type SharedFile struct {
+	File       *File
+	OwnerEmail string
+	Permission SharePermission
+}
@@ -5,4 +5,14 @@ const (
 	Loading
 	Loaded
 	Removed
+	// Quarantined — текущая версия заражена: файл нельзя скачать или поделиться им.
+	Quarantined
+)
+
+// Результаты антивирусной проверки. Пустое значение — содержимое не проверялось.
+const (
+	ScanClean    = "clean"
+	ScanInfected = "infected"
+	// ScanError — проверка не удалась, но содержимое принято, потому что включен fail_open.
+	ScanError = "error"
 )
@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+type FileVersion struct {
+	ID                int64      `json:"id"`
+	FileID            int64      `json:"file_id"`
+	VersionNumber     int        `json:"version_number"`
+	S3Key             string     `json:"s3_key"`
+	SizeInBytes       int64      `json:"size_in_bytes"`
+	MimeType          string     `json:"mime_type"`
+	ChecksumSHA256    string     `json:"checksum_sha256"`
+	ChecksumCRC32C    string     `json:"checksum_crc32c"`
+	BlobSHA256        string     `json:"blob_sha256"`
+	ContentEncoding   string     `json:"content_encoding"`
+	StoredSizeInBytes int64      `json:"stored_size_in_bytes"`
+	ScanStatus        string     `json:"scan_status"`
+	ScanSignature     string     `json:"scan_signature"`
+	ScannedAt         *time.Time `json:"scanned_at"`
+	CreatedBy         *int64     `json:"created_by"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+type Folder struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	ParentID  *int64    `json:"parent_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type FolderSize struct {
+	SizeInBytes int64 `json:"size_in_bytes"`
+	FileCount   int64 `json:"file_count"`
+	FolderCount int64 `json:"folder_count"`
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// ObjectKey — ключ данных зашифрованного объекта S3, обернутый мастер-ключом KeyID.
+type ObjectKey struct {
+	S3Key         string    `json:"s3_key"`
+	KeyID         string    `json:"key_id"`
+	WrappedKey    []byte    `json:"-"`
+	ChunkSize     int       `json:"chunk_size"`
+	PlaintextSize int64     `json:"plaintext_size"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
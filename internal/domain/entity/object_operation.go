@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// Операции с объектами S3 из очереди object_outbox.
+const (
+	ObjectOperationDelete = "delete"
+	ObjectOperationCopy   = "copy"
+)
+
+// ObjectOperation — изменение в S3, записанное в одной транзакции с изменением метаданных.
+// Операция применяется после фиксации и повторяется, пока не удастся; после исчерпания попыток
+// она остается в таблице с заполненным DeadAt. SourceKey задан только у копирования в S3Key.
+type ObjectOperation struct {
+	ID          int64      `json:"id"`
+	Operation   string     `json:"operation"`
+	S3Key       string     `json:"s3_key"`
+	SourceKey   string     `json:"source_key"`
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"last_error"`
+	AvailableAt time.Time  `json:"available_at"`
+	DeadAt      *time.Time `json:"dead_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// ShareLink — ссылка на файл для анонимного скачивания. Сам токен не хранится, только его SHA-256.
+type ShareLink struct {
+	ID            int64      `json:"id"`
+	FileID        int64      `json:"file_id"`
+	UserID        int64      `json:"user_id"`
+	TokenHash     string     `json:"-"`
+	PasswordHash  string     `json:"-"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	MaxDownloads  *int       `json:"max_downloads"`
+	DownloadCount int        `json:"download_count"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
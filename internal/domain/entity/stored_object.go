@@ -0,0 +1,18 @@
+package entity
+
+// Виды объектов S3, на которые ссылаются строки базы.
+const (
+	StoredObjectFile      = "file"
+	StoredObjectVersion   = "version"
+	StoredObjectBlob      = "blob"
+	StoredObjectThumbnail = "thumbnail"
+)
+
+// StoredObject — объект S3, который по данным базы должен существовать. SizeInBytes — размер
+// сохраненного объекта (после сжатия). FileID не задан у блобов: на них ссылаются версии разных файлов.
+type StoredObject struct {
+	S3Key       string `json:"s3_key"`
+	Kind        string `json:"kind"`
+	SizeInBytes int64  `json:"size_in_bytes"`
+	FileID      *int64 `json:"file_id"`
+}
@@ -0,0 +1,6 @@
+package entity
+
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
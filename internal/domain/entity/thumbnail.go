@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+const (
+	ThumbnailReady = iota + 1
+	// ThumbnailFailed — исходное изображение не удалось обработать, повторно миниатюра не создается.
+	ThumbnailFailed
+)
+
+// Thumbnail — уменьшенная копия изображения версии VersionID файла FileID, вписанная в квадрат Size×Size.
+type Thumbnail struct {
+	FileID      int64     `json:"file_id"`
+	VersionID   int64     `json:"version_id"`
+	Size        int       `json:"size"`
+	Format      string    `json:"format"`
+	Status      int       `json:"status"`
+	S3Key       string    `json:"s3_key"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	SizeInBytes int64     `json:"size_in_bytes"`
+	CreatedAt   time.Time `json:"created_at"`
+}
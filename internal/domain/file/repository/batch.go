@@ -0,0 +1,33 @@
+package repository
+
+import "meemo/internal/domain/entity"
+
+type BatchAction string
+
+const (
+	BatchTrash         BatchAction = "trash"
+	BatchPurge         BatchAction = "purge"
+	BatchSetVisibility BatchAction = "visibility"
+	BatchMove          BatchAction = "move"
+	BatchAddTags       BatchAction = "add_tags"
+	BatchRemoveTags    BatchAction = "remove_tags"
+)
+
+// BatchOperation — одна операция пакетного изменения. Файл задается по ID или,
+// если FileID пуст, по имени в корневой папке пользователя.
+type BatchOperation struct {
+	Action   BatchAction
+	FileID   int64
+	Name     string
+	IsPublic bool
+	FolderID *int64
+	Tags     []string
+}
+
+// BatchResult — итог одной операции. Для BatchPurge в Versions возвращаются удаленные версии,
+// чтобы вызывающий мог освободить их содержимое.
+type BatchResult struct {
+	File     *entity.File
+	Versions []*entity.FileVersion
+	Err      error
+}
@@ -0,0 +1,10 @@
+package repository
+
+import "errors"
+
+var (
+	// ErrUserNotFound возвращается, когда получатель файла не найден.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrQuotaExceeded возвращается, когда файл не помещается в квоту получателя.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+)
@@ -3,16 +3,101 @@ package repository
 import (
 	"context"
 	"meemo/internal/domain/entity"
+	"time"
 )
 
 type FileRepository interface {
+	Create(ctx context.Context, file *entity.File) (*entity.File, error)
 	Save(ctx context.Context, userID int64, originalName, mimeType, s3Bucket, s3Key string, sizeInBytes int64, isPublic bool) (*entity.File, error)
 	Delete(ctx context.Context, userEmail, originalName string) (*entity.File, error)
 	Get(ctx context.Context, fileID int64) (*entity.File, error)
 	GetByOriginalNameAndUserEmail(ctx context.Context, userEmail, originalName string) (*entity.File, error)
+	GetByName(ctx context.Context, userID int64, folderID *int64, originalName string) (*entity.File, error)
 	Rename(ctx context.Context, userEmail, originalName, newName string) (*entity.File, error)
+	RenameByID(ctx context.Context, fileID int64, newName string) (*entity.File, error)
 	ChangeVisibility(ctx context.Context, userEmail, originalName string, isPublic bool) (*entity.File, error)
 	SetStatus(ctx context.Context, userEmail, originalName string, status int) (*entity.File, error)
 	List(ctx context.Context, userEmail string) ([]*entity.File, error)
+	ListSharedWithUser(ctx context.Context, userID int64) ([]*entity.SharedFile, error)
+	ListPage(ctx context.Context, userID int64, opts ListOptions) ([]*entity.File, error)
+	Search(ctx context.Context, userID int64, opts SearchOptions) ([]*entity.FileSearchResult, error)
+	SetSearchText(ctx context.Context, fileID int64, text string) error
+	AddTags(ctx context.Context, userID, fileID int64, tags []string) (*entity.File, error)
+	RemoveTags(ctx context.Context, userID, fileID int64, tags []string) (*entity.File, error)
+	ReplaceTags(ctx context.Context, userID, fileID int64, tags []string) (*entity.File, error)
+	ListTags(ctx context.Context, userID int64) ([]*entity.TagCount, error)
+	UpdateMetadata(ctx context.Context, userID, fileID int64, set map[string]string, unset []string, replace bool) (*entity.File, error)
 	GetTotalUsedSpace(ctx context.Context, userEmail string) (int64, error)
+	GetUserPlan(ctx context.Context, userID int64) (string, error)
+	GetUserEmail(ctx context.Context, userID int64) (string, error)
+	ListForScrub(ctx context.Context, verifiedBefore time.Time, limit int) ([]*entity.File, error)
+	MarkChecksumVerified(ctx context.Context, fileID int64, failed bool) error
+	ListForThumbnails(ctx context.Context, mimeTypes []string, expected, limit int) ([]*entity.File, error)
+	GetBlob(ctx context.Context, sha256 string) (*entity.Blob, error)
+	ListUnreferencedBlobs(ctx context.Context, updatedBefore time.Time, limit int) ([]*entity.Blob, error)
+	DeleteUnreferencedBlob(ctx context.Context, sha256 string) (bool, error)
+	DeleteByID(ctx context.Context, userID, fileID int64) (*entity.File, error)
+	ListByFolder(ctx context.Context, userID int64, folderID *int64) ([]*entity.File, error)
+	ListInFolderTree(ctx context.Context, userID, folderID int64) ([]*entity.File, error)
+	MoveToFolder(ctx context.Context, userID, fileID int64, folderID *int64) (*entity.File, error)
+	AddVersion(ctx context.Context, version *entity.FileVersion, blob *entity.Blob) (*entity.File, error)
+	ListVersions(ctx context.Context, fileID int64) ([]*entity.FileVersion, error)
+	GetVersion(ctx context.Context, fileID int64, versionNumber int) (*entity.FileVersion, error)
+	RestoreVersion(ctx context.Context, fileID int64, versionNumber int) (*entity.File, error)
+	PruneVersions(ctx context.Context, fileID int64, keep int, createdBefore time.Time) ([]*entity.FileVersion, error)
+	Trash(ctx context.Context, userID, fileID int64) (*entity.File, error)
+	RestoreFromTrash(ctx context.Context, userID, fileID int64) (*entity.File, error)
+	ListTrash(ctx context.Context, userID int64) ([]*entity.File, error)
+	ListExpiredTrash(ctx context.Context, deletedBefore time.Time, limit int) ([]*entity.File, error)
+	SetExpiry(ctx context.Context, userID, fileID int64, expiresAt *time.Time) (*entity.File, error)
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]*entity.File, error)
+	DeleteExpired(ctx context.Context, fileID int64, now time.Time, reason string) (*entity.File, error)
+	ListStoredObjects(ctx context.Context) ([]*entity.StoredObject, error)
+	ListStalePending(ctx context.Context, updatedBefore time.Time) ([]*entity.File, error)
+	ApplyBatch(ctx context.Context, userID int64, ops []BatchOperation) ([]BatchResult, error)
+	TransferOwnership(ctx context.Context, fileID, fromUserID int64, toEmail string, maxBytes int64) (*entity.File, error)
+	EnqueueObjectOperations(ctx context.Context, ops []*entity.ObjectOperation, availableAt time.Time) ([]*entity.ObjectOperation, error)
+	ClaimObjectOperations(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*entity.ObjectOperation, error)
+	DeleteObjectOperations(ctx context.Context, ids []int64) error
+	RetryObjectOperation(ctx context.Context, id int64, lastError string, retryAt time.Time) error
+	DeadLetterObjectOperation(ctx context.Context, id int64, lastError string) error
+	// InTx выполняет fn в одной транзакции: изменения, сделанные через переданный в fn репозиторий,
+	// фиксируются вместе или откатываются, если fn вернула ошибку.
+	InTx(ctx context.Context, fn func(repo FileRepository) error) error
+}
+
+type SortField string
+
+const (
+	SortByName    SortField = "name"
+	SortBySize    SortField = "size"
+	SortByCreated SortField = "created"
+	SortByUpdated SortField = "updated"
+)
+
+// ListOptions описывает страницу списка файлов. Страницы листаются по ключу:
+// AfterValue и AfterID — значение поля сортировки и ID последнего файла предыдущей страницы.
+type ListOptions struct {
+	SortBy        SortField
+	Descending    bool
+	Limit         int
+	AfterValue    *string
+	AfterID       int64
+	MimePrefix    string
+	Status        *int
+	IsPublic      *bool
+	MinSize       *int64
+	MaxSize       *int64
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// Tags отбирает файлы, у которых есть все перечисленные теги.
+	Tags          []string
+	MetadataKey   string
+	MetadataValue *string
+}
+
+type SearchOptions struct {
+	Query         string
+	IncludePublic bool
+	Limit         int
 }
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const DefaultMimeType = "application/octet-stream"
+
+// SniffLength — сколько первых байт содержимого нужно для определения типа.
+const SniffLength = 512
+
+// knownTypes сопоставляет расширения с MIME-типами. Таблица своя, а не mime.TypeByExtension:
+// системный mime.types отличается от хоста к хосту. Первое расширение типа считается основным.
+var knownTypes = []struct {
+	ext      string
+	mimeType string
+}{
+	{".txt", "text/plain"},
+	{".log", "text/plain"},
+	{".md", "text/markdown"},
+	{".csv", "text/csv"},
+	{".tsv", "text/tab-separated-values"},
+	{".html", "text/html"},
+	{".htm", "text/html"},
+	{".css", "text/css"},
+	{".js", "text/javascript"},
+	{".mjs", "text/javascript"},
+	{".json", "application/json"},
+	{".ndjson", "application/x-ndjson"},
+	{".xml", "application/xml"},
+	{".yaml", "application/yaml"},
+	{".yml", "application/yaml"},
+	{".pdf", "application/pdf"},
+	{".rtf", "application/rtf"},
+	{".doc", "application/msword"},
+	{".docx", "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+	{".xls", "application/vnd.ms-excel"},
+	{".xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+	{".ppt", "application/vnd.ms-powerpoint"},
+	{".pptx", "application/vnd.openxmlformats-officedocument.presentationml.presentation"},
+	{".odt", "application/vnd.oasis.opendocument.text"},
+	{".ods", "application/vnd.oasis.opendocument.spreadsheet"},
+	{".odp", "application/vnd.oasis.opendocument.presentation"},
+	{".epub", "application/epub+zip"},
+	{".zip", "application/zip"},
+	{".jar", "application/java-archive"},
+	{".apk", "application/vnd.android.package-archive"},
+	{".tar", "application/x-tar"},
+	{".gz", "application/gzip"},
+	{".tgz", "application/gzip"},
+	{".bz2", "application/x-bzip2"},
+	{".xz", "application/x-xz"},
+	{".zst", "application/zstd"},
+	{".7z", "application/x-7z-compressed"},
+	{".rar", "application/vnd.rar"},
+	{".png", "image/png"},
+	{".jpg", "image/jpeg"},
+	{".jpeg", "image/jpeg"},
+	{".gif", "image/gif"},
+	{".webp", "image/webp"},
+	{".bmp", "image/bmp"},
+	{".ico", "image/x-icon"},
+	{".svg", "image/svg+xml"},
+	{".tif", "image/tiff"},
+	{".tiff", "image/tiff"},
+	{".heic", "image/heic"},
+	{".avif", "image/avif"},
+	{".mp3", "audio/mpeg"},
+	{".wav", "audio/wav"},
+	{".ogg", "audio/ogg"},
+	{".flac", "audio/flac"},
+	{".m4a", "audio/mp4"},
+	{".mp4", "video/mp4"},
+	{".webm", "video/webm"},
+	{".mov", "video/quicktime"},
+	{".avi", "video/x-msvideo"},
+	{".mkv", "video/x-matroska"},
+	{".woff", "font/woff"},
+	{".woff2", "font/woff2"},
+	{".ttf", "font/ttf"},
+	{".otf", "font/otf"},
+	{".wasm", "application/wasm"},
+	{".exe", MimeTypePE},
+	{".dll", MimeTypePE},
+	{".msi", "application/x-msi"},
+	{".sh", "application/x-sh"},
+	{".bat", "application/x-bat"},
+}
+
+var (
+	typesByExtension = make(map[string]string, len(knownTypes))
+	extensionsByType = make(map[string]string, len(knownTypes))
+)
+
+func init() {
+	for _, known := range knownTypes {
+		typesByExtension[known.ext] = known.mimeType
+		if _, ok := extensionsByType[known.mimeType]; !ok {
+			extensionsByType[known.mimeType] = known.ext
+		}
+	}
+	// Синонимы, которые встречаются у клиентов и в результатах http.DetectContentType.
+	for alias, mimeType := range map[string]string{
+		"text/xml":                 "application/xml",
+		"application/javascript":   "text/javascript",
+		"application/x-javascript": "text/javascript",
+		"application/x-gzip":       "application/gzip",
+		"audio/wave":               "audio/wav",
+		"audio/x-wav":              "audio/wav",
+		"image/jpg":                "image/jpeg",
+	} {
+		extensionsByType[alias] = extensionsByType[mimeType]
+	}
+}
+
+// MediaType возвращает MIME-тип без параметров в нижнем регистре или пустую строку для некорректного типа.
+func MediaType(mimeType string) string {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+// MimeTypeByExtension возвращает MIME-тип по расширению имени файла или пустую строку.
+func MimeTypeByExtension(name string) string {
+	return typesByExtension[strings.ToLower(path.Ext(name))]
+}
+
+// ExtensionByMimeType возвращает основное расширение MIME-типа с точкой или пустую строку.
+func ExtensionByMimeType(mimeType string) string {
+	return extensionsByType[MediaType(mimeType)]
+}
+
+// Типы исполняемых файлов, которых нет среди сигнатур http.DetectContentType.
+const (
+	MimeTypePE    = "application/vnd.microsoft.portable-executable"
+	MimeTypeELF   = "application/x-executable"
+	MimeTypeMachO = "application/x-mach-binary"
+)
+
+var machOMagics = [][]byte{
+	{0xFE, 0xED, 0xFA, 0xCE}, {0xFE, 0xED, 0xFA, 0xCF},
+	{0xCE, 0xFA, 0xED, 0xFE}, {0xCF, 0xFA, 0xED, 0xFE},
+}
+
+// detectExecutable распознает исполняемые файлы Windows, Linux и macOS. У PE проверяется
+// не только «MZ», но и заголовок «PE», на который указывает поле e_lfanew: с «MZ» может начинаться и текст.
+func detectExecutable(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte("\x7fELF")):
+		return MimeTypeELF
+	case bytes.HasPrefix(head, []byte("MZ")) && len(head) >= 0x40:
+		// e_lfanew — смещение заголовка PE, записано по смещению 0x3C.
+		offset := int64(binary.LittleEndian.Uint32(head[0x3C:]))
+		if offset+4 <= int64(len(head)) && bytes.Equal(head[offset:offset+4], []byte("PE\x00\x00")) {
+			return MimeTypePE
+		}
+	}
+	for _, magic := range machOMagics {
+		if bytes.HasPrefix(head, magic) {
+			return MimeTypeMachO
+		}
+	}
+	return ""
+}
+
+// DetectMimeType определяет тип содержимого по первым байтам head. Сигнатуры различают
+// не все форматы: текст, ZIP-контейнеры офисных документов и XML уточняются по расширению name.
+// Исполняемый файл определяется по сигнатуре независимо от расширения, а пустое содержимое
+// ничего не говорит о типе, и он берется из расширения.
+func DetectMimeType(head []byte, name string) string {
+	if executable := detectExecutable(head); executable != "" {
+		return executable
+	}
+	sniffed := DefaultMimeType
+	if len(head) > 0 {
+		sniffed = MediaType(http.DetectContentType(head))
+	}
+	// http.DetectContentType возвращает устаревшие синонимы.
+	switch sniffed {
+	case "text/xml":
+		sniffed = "application/xml"
+	case "application/x-gzip":
+		sniffed = "application/gzip"
+	}
+	byExtension := MimeTypeByExtension(name)
+
+	switch {
+	case byExtension == "":
+		return sniffed
+	case sniffed == DefaultMimeType:
+		return byExtension
+	case sniffed == "text/plain":
+		if IsTextMimeType(byExtension) {
+			return byExtension
+		}
+	case sniffed == "application/zip":
+		if strings.HasSuffix(byExtension, "+zip") || strings.HasPrefix(byExtension, "application/vnd.") || byExtension == "application/java-archive" {
+			return byExtension
+		}
+	case sniffed == "application/xml":
+		if strings.HasSuffix(byExtension, "+xml") {
+			return byExtension
+		}
+	}
+	return sniffed
+}
+
+// SameMimeType сравнивает MIME-типы без учета параметров и синонимов.
+func SameMimeType(a, b string) bool {
+	a, b = MediaType(a), MediaType(b)
+	if a == b {
+		return true
+	}
+	extA, extB := extensionsByType[a], extensionsByType[b]
+	return extA != "" && typesByExtension[extA] == typesByExtension[extB]
+}
+
+// IsTextMimeType сообщает, что содержимое типа mimeType — текст.
+func IsTextMimeType(mimeType string) bool {
+	mimeType = MediaType(mimeType)
+	switch {
+	case strings.HasPrefix(mimeType, "text/"),
+		strings.HasSuffix(mimeType, "+xml"),
+		mimeType == "application/json",
+		mimeType == "application/x-ndjson",
+		mimeType == "application/xml",
+		mimeType == "application/yaml",
+		mimeType == "application/x-sh",
+		mimeType == "application/x-bat":
+		return true
+	}
+	return false
+}
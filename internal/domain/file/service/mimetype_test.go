@@ -0,0 +1,86 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// peHead собирает начало исполняемого файла Windows: заглушку MS-DOS и заголовок PE по смещению 0x80.
+func peHead() []byte {
+	head := make([]byte, SniffLength)
+	copy(head, "MZ")
+	binary.LittleEndian.PutUint32(head[0x3C:], 0x80)
+	copy(head[0x80:], "PE\x00\x00")
+	return head
+}
+
+func TestDetectMimeType(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	jpeg := []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00")
+	zip := []byte("PK\x03\x04\x14\x00\x00\x00\x08\x00")
+	// «MZ» без заголовка PE — просто текст.
+	mzText := bytes.Repeat([]byte("MZ is not a magic number here. "), 4)
+	// e_lfanew указывает за пределы прочитанных байтов.
+	truncatedPE := peHead()[:0x60]
+
+	tests := []struct {
+		name     string
+		head     []byte
+		fileName string
+		expected string
+	}{
+		{"png", png, "image.png", "image/png"},
+		{"png with wrong extension", png, "image.jpg", "image/png"},
+		{"jpeg", jpeg, "photo.jpg", "image/jpeg"},
+		{"executable renamed to jpg", peHead(), "photo.jpg", MimeTypePE},
+		{"executable", peHead(), "setup.exe", MimeTypePE},
+		{"executable without extension", peHead(), "setup", MimeTypePE},
+		{"elf renamed to png", []byte("\x7fELF\x02\x01\x01\x00"), "image.png", MimeTypeELF},
+		{"mach-o renamed to txt", []byte("\xcf\xfa\xed\xfe\x07\x00\x00\x01"), "notes.txt", MimeTypeMachO},
+		{"text starting with MZ", mzText, "notes.txt", "text/plain"},
+		{"truncated pe", truncatedPE, "photo.jpg", "image/jpeg"},
+		{"empty with extension", nil, "photo.jpg", "image/jpeg"},
+		{"empty text file", []byte{}, "notes.txt", "text/plain"},
+		{"empty without extension", nil, "README", DefaultMimeType},
+		{"text without extension", []byte("just some words\n"), "README", "text/plain"},
+		{"text as markdown", []byte("# Title\n\nbody\n"), "README.md", "text/markdown"},
+		{"text as csv", []byte("a,b\n1,2\n"), "table.CSV", "text/csv"},
+		{"text renamed to jpg", []byte("not an image\n"), "photo.jpg", "text/plain"},
+		{"unknown binary uses extension", []byte{0x00, 0x01, 0x02, 0x03}, "model.heic", "image/heic"},
+		{"unknown binary without extension", []byte{0x00, 0x01, 0x02, 0x03}, "blob", DefaultMimeType},
+		{"docx", zip, "report.docx", "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{"zip renamed to pdf", zip, "report.pdf", "application/zip"},
+		{"xml", []byte("<?xml version=\"1.0\"?><feed/>"), "feed.svg", "image/svg+xml"},
+		{"xml without extension", []byte("<?xml version=\"1.0\"?><feed/>"), "feed", "application/xml"},
+		{"gzip", []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00"), "backup.tgz", "application/gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectMimeType(tt.head, tt.fileName); got != tt.expected {
+				t.Errorf("DetectMimeType(%q) = %q, expected %q", tt.fileName, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSameMimeType(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"image/jpeg", "image/jpeg", true},
+		{"image/jpeg", "image/jpg", true},
+		{"text/plain; charset=utf-8", "TEXT/PLAIN", true},
+		{"text/xml", "application/xml", true},
+		{"application/x-gzip", "application/gzip", true},
+		{"image/jpeg", "image/png", false},
+		{"image/jpeg", MimeTypePE, false},
+		{"application/x-unknown", "application/x-other", false},
+	}
+	for _, tt := range tests {
+		if got := SameMimeType(tt.a, tt.b); got != tt.expected {
+			t.Errorf("SameMimeType(%q, %q) = %v, expected %v", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
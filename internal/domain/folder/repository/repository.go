@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+)
+
+type FolderRepository interface {
+	Create(ctx context.Context, userID int64, parentID *int64, name string) (*entity.Folder, error)
+	Get(ctx context.Context, userID, folderID int64) (*entity.Folder, error)
+	Rename(ctx context.Context, userID, folderID int64, name string) (*entity.Folder, error)
+	Move(ctx context.Context, userID, folderID int64, parentID *int64) (*entity.Folder, error)
+	ListChildren(ctx context.Context, userID int64, parentID *int64) ([]*entity.Folder, error)
+	ListTree(ctx context.Context, userID, folderID int64) ([]*entity.Folder, error)
+	Delete(ctx context.Context, userID, folderID int64) error
+	GetSize(ctx context.Context, userID, folderID int64) (*entity.FolderSize, error)
+}
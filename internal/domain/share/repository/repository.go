@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+)
+
+type ShareRepository interface {
+	Upsert(ctx context.Context, fileID int64, userEmail string, permission entity.SharePermission, createdBy int64) (*entity.FileShare, error)
+	ListByFile(ctx context.Context, fileID int64) ([]*entity.FileShare, error)
+	GetPermission(ctx context.Context, fileID, userID int64) (entity.SharePermission, error)
+	Delete(ctx context.Context, fileID, userID int64) error
+}
+
+type ShareLinkRepository interface {
+	CreateLink(ctx context.Context, link *entity.ShareLink) (*entity.ShareLink, error)
+	GetLinkByTokenHash(ctx context.Context, tokenHash string) (*entity.ShareLink, error)
+	ListLinks(ctx context.Context, userID int64, fileID *int64) ([]*entity.ShareLink, error)
+	RevokeLink(ctx context.Context, userID, linkID int64) (*entity.ShareLink, error)
+	ClaimDownload(ctx context.Context, linkID int64) (*entity.ShareLink, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+)
+
+type ThumbnailRepository interface {
+	// Save сохраняет миниатюру, заменяя прежнюю для той же версии, размера и формата.
+	Save(ctx context.Context, thumbnail *entity.Thumbnail) (*entity.Thumbnail, error)
+	Get(ctx context.Context, fileID, versionID int64, size int, format string) (*entity.Thumbnail, error)
+	ListByVersion(ctx context.Context, fileID, versionID int64) ([]*entity.Thumbnail, error)
+	// ListStale возвращает миниатюры удаленных файлов и версий, которые больше не текущие.
+	ListStale(ctx context.Context, limit int) ([]*entity.Thumbnail, error)
+	Delete(ctx context.Context, thumbnail *entity.Thumbnail) error
+	// DeleteByKey удаляет миниатюру, хранящуюся под ключом s3Key, чтобы ее создали заново.
+	DeleteByKey(ctx context.Context, s3Key string) error
+}
@@ -0,0 +1,199 @@
+package antivirus
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultClamdChunkSize = 64 * 1024
+	DefaultClamdTimeout   = 2 * time.Minute
+)
+
+// ClamdOptions задает подключение к clamd. Address — "tcp://host:port", "unix:///path/clamd.sock"
+// или просто "host:port".
+type ClamdOptions struct {
+	Address   string
+	Timeout   time.Duration
+	ChunkSize int
+}
+
+type clamdScanner struct {
+	network   string
+	address   string
+	timeout   time.Duration
+	chunkSize int
+}
+
+// NewClamdScanner создает клиента clamd, который передает содержимое командой INSTREAM.
+// Каждая проверка открывает отдельное соединение.
+func NewClamdScanner(opts ClamdOptions) (Scanner, error) {
+	network, address, err := ParseClamdAddress(opts.Address)
+	if err != nil {
+		return nil, err
+	}
+	s := &clamdScanner{
+		network:   network,
+		address:   address,
+		timeout:   opts.Timeout,
+		chunkSize: opts.ChunkSize,
+	}
+	if s.timeout <= 0 {
+		s.timeout = DefaultClamdTimeout
+	}
+	if s.chunkSize <= 0 {
+		s.chunkSize = DefaultClamdChunkSize
+	}
+	return s, nil
+}
+
+// ParseClamdAddress разбирает адрес clamd на сеть и адрес для net.Dial.
+func ParseClamdAddress(address string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		path := strings.TrimPrefix(address, "unix://")
+		if path == "" {
+			return "", "", fmt.Errorf("invalid clamd address %q", address)
+		}
+		return "unix", path, nil
+	case strings.HasPrefix(address, "tcp://"):
+		address = strings.TrimPrefix(address, "tcp://")
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return "", "", fmt.Errorf("invalid clamd address %q: %w", address, err)
+	}
+	return "tcp", address, nil
+}
+
+func (s *clamdScanner) dial(ctx context.Context) (net.Conn, func() bool, error) {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrScanFailed, err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+	// Отмена контекста прерывает чтение и запись.
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	return conn, stop, nil
+}
+
+func (s *clamdScanner) Ping(ctx context.Context) error {
+	conn, stop, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer stop()
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.Write([]byte("zPING\x00")); err != nil {
+		return fmt.Errorf("%w: %v", ErrScanFailed, err)
+	}
+	reply, err := readReply(conn)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("%w: unexpected reply %q", ErrScanFailed, reply)
+	}
+	return nil
+}
+
+func (s *clamdScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	conn, stop, err := s.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+	defer func() { _ = conn.Close() }()
+
+	writeErr := s.stream(conn, r)
+	if writeErr != nil && !errors.Is(writeErr, ErrScanFailed) {
+		// Не удалось прочитать проверяемое содержимое: clamd ждет продолжения потока, ответа не будет.
+		return nil, writeErr
+	}
+	// Превысив StreamMaxLength, clamd отвечает ошибкой и закрывает соединение, не дочитав поток,
+	// поэтому ответ читается и после неудачной записи.
+	reply, err := readReply(conn)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if writeErr != nil {
+			return nil, writeErr
+		}
+		return nil, err
+	}
+	result, err := parseReply(reply)
+	if err != nil {
+		return nil, err
+	}
+	if writeErr != nil && !result.Infected {
+		return nil, writeErr
+	}
+	return result, nil
+}
+
+// stream передает r порциями: 4 байта длины в сетевом порядке и данные, в конце — порция нулевой длины.
+func (s *clamdScanner) stream(conn net.Conn, r io.Reader) error {
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return fmt.Errorf("%w: %v", ErrScanFailed, err)
+	}
+
+	buf := make([]byte, 4+s.chunkSize)
+	for {
+		n, readErr := io.ReadFull(r, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf, uint32(n))
+			if _, err := conn.Write(buf[:4+n]); err != nil {
+				return fmt.Errorf("%w: %v", ErrScanFailed, err)
+			}
+		}
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return fmt.Errorf("%w: %v", ErrScanFailed, err)
+	}
+	return nil
+}
+
+// readReply читает ответ clamd, который в режиме команд с префиксом z завершается нулевым байтом.
+func readReply(conn net.Conn) (string, error) {
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && (!errors.Is(err, io.EOF) || reply == "") {
+		return "", fmt.Errorf("%w: %v", ErrScanFailed, err)
+	}
+	return strings.TrimSpace(strings.TrimSuffix(reply, "\x00")), nil
+}
+
+// parseReply разбирает ответы вида "stream: OK", "stream: Eicar-Signature FOUND" и "... ERROR".
+func parseReply(reply string) (*Result, error) {
+	switch {
+	case strings.HasSuffix(reply, " FOUND"):
+		signature := strings.TrimSuffix(reply, " FOUND")
+		if i := strings.Index(signature, ": "); i >= 0 {
+			signature = signature[i+2:]
+		}
+		return &Result{Infected: true, Signature: signature}, nil
+	case strings.HasSuffix(reply, " ERROR"):
+		return nil, fmt.Errorf("%w: %s", ErrScanFailed, strings.TrimSuffix(reply, " ERROR"))
+	case strings.HasSuffix(reply, ": OK"):
+		return &Result{}, nil
+	}
+	return nil, fmt.Errorf("%w: unexpected reply %q", ErrScanFailed, reply)
+}
@@ -0,0 +1,190 @@
+// Package clamdtest — поддельный clamd для тестов. Сервер понимает команды PING и INSTREAM
+// и находит в содержимом заданные сигнатуры простым поиском подстроки.
+package clamdtest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// EICAR — стандартная тестовая строка, которую антивирусы считают вирусом.
+const EICAR = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// EICARSignature — имя, под которым clamd сообщает о строке EICAR.
+const EICARSignature = "Eicar-Test-Signature"
+
+var errStreamTooLong = errors.New("stream is too long")
+
+type Server struct {
+	listener net.Listener
+	// MaxStreamSize повторяет StreamMaxLength из clamd.conf: поток длиннее отклоняется с ошибкой.
+	MaxStreamSize int64
+	// Fail заставляет сервер отвечать ошибкой на каждую проверку.
+	Fail atomic.Bool
+
+	mu         sync.Mutex
+	signatures map[string][]byte
+	scans      int
+
+	wg sync.WaitGroup
+}
+
+// NewServer запускает сервер на случайном порту localhost. Строка EICAR распознается сразу.
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		listener:   listener,
+		signatures: map[string][]byte{EICARSignature: []byte(EICAR)},
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Address возвращает адрес сервера в формате настройки antivirus.address.
+func (s *Server) Address() string {
+	return "tcp://" + s.listener.Addr().String()
+}
+
+// AddSignature добавляет сигнатуру: содержимое с pattern будет считаться зараженным name.
+func (s *Server) AddSignature(name string, pattern []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signatures[name] = pattern
+}
+
+// Scans возвращает число выполненных проверок.
+func (s *Server) Scans() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scans
+}
+
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() { _ = conn.Close() }()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	command, delimiter, err := readCommand(r)
+	if err != nil {
+		return
+	}
+
+	reply := func(text string) {
+		_, _ = conn.Write([]byte(text + string(delimiter)))
+	}
+
+	switch command {
+	case "PING":
+		reply("PONG")
+	case "INSTREAM":
+		content, err := s.readStream(r)
+		if errors.Is(err, errStreamTooLong) {
+			reply("INSTREAM size limit exceeded. ERROR")
+			return
+		}
+		if err != nil {
+			return
+		}
+		if s.Fail.Load() {
+			reply("Can't allocate memory ERROR")
+			return
+		}
+		if name := s.match(content); name != "" {
+			reply("stream: " + name + " FOUND")
+			return
+		}
+		reply("stream: OK")
+	default:
+		reply("UNKNOWN COMMAND")
+	}
+}
+
+// readCommand читает команду в форме "zCOMMAND\0", "nCOMMAND\n" или "COMMAND\n".
+func readCommand(r *bufio.Reader) (string, byte, error) {
+	prefix, err := r.Peek(1)
+	if err != nil {
+		return "", 0, err
+	}
+
+	delimiter := byte('\n')
+	switch prefix[0] {
+	case 'z':
+		delimiter = 0
+		_, _ = r.ReadByte()
+	case 'n':
+		_, _ = r.ReadByte()
+	}
+
+	line, err := r.ReadString(delimiter)
+	if err != nil {
+		return "", 0, err
+	}
+	return strings.TrimSpace(strings.TrimSuffix(line, string(delimiter))), delimiter, nil
+}
+
+func (s *Server) readStream(r *bufio.Reader) ([]byte, error) {
+	var content bytes.Buffer
+	header := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+		size := int64(binary.BigEndian.Uint32(header))
+		if size == 0 {
+			break
+		}
+		if s.MaxStreamSize > 0 && int64(content.Len())+size > s.MaxStreamSize {
+			return nil, errStreamTooLong
+		}
+		if _, err := io.CopyN(&content, r, size); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	s.scans++
+	s.mu.Unlock()
+	return content.Bytes(), nil
+}
+
+func (s *Server) match(content []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, pattern := range s.signatures {
+		if bytes.Contains(content, pattern) {
+			return name
+		}
+	}
+	return ""
+}
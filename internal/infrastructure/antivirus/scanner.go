@@ -0,0 +1,23 @@
+package antivirus
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrScanFailed = errors.New("antivirus scan failed")
+
+// Result — вердикт проверки. Signature заполняется только для зараженного содержимого.
+type Result struct {
+	Infected  bool
+	Signature string
+}
+
+// Scanner проверяет содержимое на вирусы. Scan может прочитать r не до конца, если вердикт
+// известен раньше, — вызывающий код сам дочитывает поток, если он нужен кому-то еще.
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (*Result, error)
+	// Ping проверяет, что сканер доступен.
+	Ping(ctx context.Context) error
+}
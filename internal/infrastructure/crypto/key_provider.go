@@ -0,0 +1,132 @@
+package crypto
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const DataKeySize = 32
+
+var (
+	ErrUnknownKey     = errors.New("unknown master key")
+	ErrInvalidKeyfile = errors.New("invalid keyfile")
+)
+
+// KeyProvider оборачивает ключи данных мастер-ключом. Ключ данных никогда не хранится открытым:
+// в базе лежит только результат WrapKey вместе с ID мастер-ключа.
+type KeyProvider interface {
+	// CurrentKeyID возвращает ID мастер-ключа, которым оборачиваются новые ключи данных.
+	CurrentKeyID() string
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// NewDataKey генерирует случайный ключ данных AES-256.
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, DataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+type localKeyProvider struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewLocalKeyProvider читает мастер-ключи из файла. Каждая строка файла имеет вид
+// "<key-id> <ключ AES-256 в base64>", пустые строки и строки с # пропускаются.
+// Старые ключи остаются в файле, пока задание перешифровки не переобернет ими все ключи данных.
+// Если currentKeyID пуст, текущим считается последний ключ файла.
+func NewLocalKeyProvider(path, currentKeyID string) (KeyProvider, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is from config, not user input
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	p := &localKeyProvider{keys: make(map[string]cipher.AEAD)}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w: line %d: expected \"<key-id> <base64 key>\"", ErrInvalidKeyfile, line)
+		}
+		if _, ok := p.keys[fields[0]]; ok {
+			return nil, fmt.Errorf("%w: line %d: duplicate key id %q", ErrInvalidKeyfile, line, fields[0])
+		}
+		masterKey, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil || len(masterKey) != DataKeySize {
+			return nil, fmt.Errorf("%w: line %d: key must be %d bytes in base64", ErrInvalidKeyfile, line, DataKeySize)
+		}
+		aead, err := newAEAD(masterKey)
+		if err != nil {
+			return nil, err
+		}
+		p.keys[fields[0]] = aead
+		p.current = fields[0]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if currentKeyID != "" {
+		p.current = currentKeyID
+	}
+	if _, ok := p.keys[p.current]; !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, p.current)
+	}
+	return p, nil
+}
+
+func (p *localKeyProvider) CurrentKeyID() string {
+	return p.current
+}
+
+// WrapKey шифрует ключ данных текущим мастер-ключом. ID мастер-ключа входит в AAD,
+// чтобы обернутый ключ нельзя было выдать за обернутый другим ключом.
+func (p *localKeyProvider) WrapKey(_ context.Context, dataKey []byte) (string, []byte, error) {
+	aead := p.keys[p.current]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	return p.current, aead.Seal(nonce, nonce, dataKey, []byte(p.current)), nil
+}
+
+func (p *localKeyProvider) UnwrapKey(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, ErrAuthentication
+	}
+	dataKey, err := aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], []byte(keyID))
+	if err != nil {
+		return nil, ErrAuthentication
+	}
+	return dataKey, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,219 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Содержимое шифруется блоками по chunkSize байт открытого текста. Каждый блок — отдельное
+// сообщение AES-256-GCM с nonce из номера блока и признаком последнего блока в AAD: блоки нельзя
+// переставить или отбросить хвост незаметно, а любой блок расшифровывается независимо от остальных,
+// что позволяет читать произвольный диапазон объекта.
+const (
+	DefaultChunkSize = 64 * 1024
+	tagSize          = 16
+)
+
+var (
+	ErrAuthentication = errors.New("ciphertext authentication failed")
+	ErrSizeMismatch   = errors.New("content size does not match declared size")
+)
+
+// chunkCount возвращает число блоков. Пустой объект состоит из одного пустого блока,
+// чтобы и его целостность подтверждалась тегом.
+func chunkCount(plaintextSize int64, chunkSize int) int64 {
+	if plaintextSize == 0 {
+		return 1
+	}
+	return (plaintextSize + int64(chunkSize) - 1) / int64(chunkSize)
+}
+
+// CiphertextSize возвращает размер зашифрованного объекта.
+func CiphertextSize(plaintextSize int64, chunkSize int) int64 {
+	return plaintextSize + chunkCount(plaintextSize, chunkSize)*tagSize
+}
+
+// chunkSpan возвращает полуинтервал номеров блоков, покрывающих диапазон открытого текста.
+func chunkSpan(offset, length int64, chunkSize int) (int64, int64) {
+	first := offset / int64(chunkSize)
+	end := (offset + length + int64(chunkSize) - 1) / int64(chunkSize)
+	return first, max(end, first+1)
+}
+
+// CiphertextRange возвращает диапазон шифртекста, который нужно прочитать, чтобы расшифровать
+// length байт открытого текста начиная с offset.
+func CiphertextRange(offset, length, plaintextSize int64, chunkSize int) (int64, int64) {
+	first, end := chunkSpan(offset, length, chunkSize)
+	sealed := int64(chunkSize) + tagSize
+	ctOffset := first * sealed
+	ctEnd := min(end*sealed, CiphertextSize(plaintextSize, chunkSize))
+	return ctOffset, ctEnd - ctOffset
+}
+
+func chunkNonce(index int64) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[4:], uint64(index)) //nolint:gosec // G115: index is never negative
+	return nonce
+}
+
+func chunkAAD(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+type encryptingReader struct {
+	aead      cipher.AEAD
+	src       io.Reader
+	plain     []byte
+	sealed    []byte
+	pending   []byte
+	index     int64
+	total     int64
+	remaining int64
+	err       error
+}
+
+// NewEncryptingReader возвращает шифртекст ровно size байт из src. Если src короче или длиннее,
+// чтение завершается ошибкой ErrSizeMismatch.
+func NewEncryptingReader(dataKey []byte, src io.Reader, size int64, chunkSize int) (io.Reader, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptingReader{
+		aead:      aead,
+		src:       src,
+		plain:     make([]byte, chunkSize),
+		sealed:    make([]byte, 0, chunkSize+tagSize),
+		total:     chunkCount(size, chunkSize),
+		remaining: size,
+	}, nil
+}
+
+func (r *encryptingReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.index == r.total {
+			r.err = r.checkEOF()
+			continue
+		}
+
+		n := min(int64(len(r.plain)), r.remaining)
+		if _, err := io.ReadFull(r.src, r.plain[:n]); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				err = ErrSizeMismatch
+			}
+			r.err = err
+			continue
+		}
+		r.remaining -= n
+		r.pending = r.aead.Seal(r.sealed[:0], chunkNonce(r.index), r.plain[:n], chunkAAD(r.index == r.total-1))
+		r.index++
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func (r *encryptingReader) checkEOF() error {
+	var extra [1]byte
+	n, err := io.ReadFull(r.src, extra[:])
+	switch {
+	case n > 0:
+		return ErrSizeMismatch
+	case errors.Is(err, io.EOF):
+		return io.EOF
+	default:
+		return err
+	}
+}
+
+type decryptingWriter struct {
+	aead      cipher.AEAD
+	dst       io.Writer
+	sealed    []byte
+	filled    int
+	plain     []byte
+	index     int64
+	end       int64
+	total     int64
+	skip      int64
+	remaining int64
+}
+
+// NewDecryptingWriter возвращает writer, который принимает шифртекст, начиная с диапазона
+// CiphertextRange(offset, length, ...), и пишет в dst length байт открытого текста с позиции offset.
+// Последний неполный блок расшифровывается в Close, поэтому Close обязателен.
+func NewDecryptingWriter(dataKey []byte, dst io.Writer, plaintextSize int64, chunkSize int, offset, length int64) (io.WriteCloser, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	first, end := chunkSpan(offset, length, chunkSize)
+	return &decryptingWriter{
+		aead:      aead,
+		dst:       dst,
+		sealed:    make([]byte, chunkSize+tagSize),
+		plain:     make([]byte, 0, chunkSize),
+		index:     first,
+		end:       end,
+		total:     chunkCount(plaintextSize, chunkSize),
+		skip:      offset - first*int64(chunkSize),
+		remaining: length,
+	}, nil
+}
+
+func (w *decryptingWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		n := copy(w.sealed[w.filled:], p[written:])
+		w.filled += n
+		written += n
+		if w.filled == len(w.sealed) {
+			if err := w.flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (w *decryptingWriter) Close() error {
+	if w.filled > 0 {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+	if w.index != w.end {
+		return ErrSizeMismatch
+	}
+	return nil
+}
+
+func (w *decryptingWriter) flush() error {
+	if w.index >= w.end {
+		return ErrSizeMismatch
+	}
+
+	plain, err := w.aead.Open(w.plain[:0], chunkNonce(w.index), w.sealed[:w.filled], chunkAAD(w.index == w.total-1))
+	if err != nil {
+		return ErrAuthentication
+	}
+	w.index++
+	w.filled = 0
+
+	plain = plain[min(w.skip, int64(len(plain))):]
+	w.skip = 0
+	plain = plain[:min(w.remaining, int64(len(plain)))]
+	w.remaining -= int64(len(plain))
+
+	_, err = w.dst.Write(plain)
+	return err
+}
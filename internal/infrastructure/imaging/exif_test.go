@@ -0,0 +1,107 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// exifJPEG собирает начало JPEG с сегментом APP1, где в нулевом IFD записан тег Orientation.
+func exifJPEG(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], exifOrientationTag)
+	order.PutUint16(tiff[12:], 3) // SHORT
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(data[4:], uint16(len(segment)+2))
+	data = append(data, segment...)
+	return append(data, 0xFF, 0xDA, 0, 2)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	for orientation := 1; orientation <= 8; orientation++ {
+		for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+			if got := jpegOrientation(exifJPEG(order, uint16(orientation))); got != orientation {
+				t.Errorf("%v orientation %d: got %d", order, orientation, got)
+			}
+		}
+	}
+
+	valid := exifJPEG(binary.BigEndian, 6)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"not jpeg", []byte("\x89PNG\r\n\x1a\n")},
+		{"no exif", []byte{0xFF, 0xD8, 0xFF, 0xDA, 0, 2}},
+		{"out of range", exifJPEG(binary.LittleEndian, 9)},
+		{"zero", exifJPEG(binary.LittleEndian, 0)},
+		{"truncated", valid[:len(valid)-12]},
+		{"bad byte order", append(append([]byte{}, valid[:12]...), append([]byte("XX"), valid[14:]...)...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != 1 {
+				t.Errorf("Expected orientation 1, got %d", got)
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// Исходник 3×2 с пикселями A B C / D E F, номер пикселя записан в красный канал.
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	for i := 0; i < 6; i++ {
+		src.SetNRGBA(i%3, i/3, color.NRGBA{R: uint8('A' + i), A: 0xFF})
+	}
+
+	tests := []struct {
+		orientation int
+		expected    []string
+	}{
+		{1, []string{"ABC", "DEF"}},
+		{2, []string{"CBA", "FED"}},
+		{3, []string{"FED", "CBA"}},
+		{4, []string{"DEF", "ABC"}},
+		{5, []string{"AD", "BE", "CF"}},
+		{6, []string{"DA", "EB", "FC"}},
+		{7, []string{"FC", "EB", "DA"}},
+		{8, []string{"CF", "BE", "AD"}},
+		{0, []string{"ABC", "DEF"}},
+		{9, []string{"ABC", "DEF"}},
+	}
+	for _, tt := range tests {
+		out := orient(src, tt.orientation)
+		var rows []string
+		for y := 0; y < out.Rect.Dy(); y++ {
+			row := make([]byte, out.Rect.Dx())
+			for x := range row {
+				row[x] = out.NRGBAAt(x, y).R
+			}
+			rows = append(rows, string(row))
+		}
+		if len(rows) != len(tt.expected) {
+			t.Errorf("orient(%d) = %v, expected %v", tt.orientation, rows, tt.expected)
+			continue
+		}
+		for i := range rows {
+			if rows[i] != tt.expected[i] {
+				t.Errorf("orient(%d) = %v, expected %v", tt.orientation, rows, tt.expected)
+				break
+			}
+		}
+	}
+}
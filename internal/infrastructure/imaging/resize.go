@@ -0,0 +1,182 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// contribution — исходные пиксели, из которых складывается один пиксель результата, и их веса.
+type contribution struct {
+	start   int
+	weights []float32
+}
+
+// boxWeights усредняет по площади: пиксель результата покрывает scale исходных пикселей,
+// крайние учитываются частично. Используется только для уменьшения, поэтому scale >= 1.
+func boxWeights(srcLen, dstLen int) []contribution {
+	scale := float64(srcLen) / float64(dstLen)
+	out := make([]contribution, dstLen)
+	for i := range out {
+		lo := float64(i) * scale
+		hi := lo + scale
+		start := int(lo)
+		end := min(int(math.Ceil(hi)), srcLen)
+
+		weights := make([]float32, end-start)
+		for j := start; j < end; j++ {
+			weights[j-start] = float32((math.Min(hi, float64(j+1)) - math.Max(lo, float64(j))) / scale)
+		}
+		out[i] = contribution{start: start, weights: weights}
+	}
+	return out
+}
+
+// resize уменьшает изображение до width×height. Исходник читается построчно и не копируется целиком:
+// в памяти держится только результат в премультиплицированных float32, чтобы прозрачные пиксели
+// не окрашивали края.
+func resize(src image.Image, width, height int) *image.NRGBA {
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	columns := boxWeights(srcWidth, width)
+	rows := boxWeights(srcHeight, height)
+
+	// Для каждой исходной строки — результирующие строки, в которые она входит, и ее вес в них.
+	type rowShare struct {
+		dst    int
+		weight float32
+	}
+	shares := make([][]rowShare, srcHeight)
+	for dst, row := range rows {
+		for i, weight := range row.weights {
+			shares[row.start+i] = append(shares[row.start+i], rowShare{dst: dst, weight: weight})
+		}
+	}
+
+	acc := make([]float32, width*height*4)
+	srcRow := make([]float32, srcWidth*4)
+	dstRow := make([]float32, width*4)
+	for y := 0; y < srcHeight; y++ {
+		if len(shares[y]) == 0 {
+			continue
+		}
+		readRow(src, bounds.Min.Y+y, srcRow)
+
+		for x, column := range columns {
+			var r, g, b, a float32
+			for i, weight := range column.weights {
+				p := (column.start + i) * 4
+				r += srcRow[p] * weight
+				g += srcRow[p+1] * weight
+				b += srcRow[p+2] * weight
+				a += srcRow[p+3] * weight
+			}
+			dstRow[x*4], dstRow[x*4+1], dstRow[x*4+2], dstRow[x*4+3] = r, g, b, a
+		}
+
+		for _, share := range shares[y] {
+			line := acc[share.dst*width*4 : (share.dst+1)*width*4]
+			for i, v := range dstRow {
+				line[i] += v * share.weight
+			}
+		}
+	}
+
+	out := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(acc); i += 4 {
+		a := acc[i+3]
+		if a <= 0 {
+			continue
+		}
+		out.Pix[i] = clampUint8(acc[i] * 255 / a)
+		out.Pix[i+1] = clampUint8(acc[i+1] * 255 / a)
+		out.Pix[i+2] = clampUint8(acc[i+2] * 255 / a)
+		out.Pix[i+3] = clampUint8(a)
+	}
+	return out
+}
+
+// readRow пишет в dst строку y в виде премультиплицированных r, g, b, a от 0 до 255.
+// Для типов, которые возвращают стандартные декодеры, пиксели читаются напрямую.
+func readRow(src image.Image, y int, dst []float32) {
+	bounds := src.Bounds()
+	switch img := src.(type) {
+	case *image.YCbCr:
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b := color.YCbCrToRGB(img.Y[img.YOffset(x, y)], img.Cb[img.COffset(x, y)], img.Cr[img.COffset(x, y)])
+			p := (x - bounds.Min.X) * 4
+			dst[p], dst[p+1], dst[p+2], dst[p+3] = float32(r), float32(g), float32(b), 255
+		}
+	case *image.NRGBA:
+		pix := img.Pix[img.PixOffset(bounds.Min.X, y):]
+		for p := 0; p < bounds.Dx()*4; p += 4 {
+			a := float32(pix[p+3]) / 255
+			dst[p], dst[p+1], dst[p+2], dst[p+3] = float32(pix[p])*a, float32(pix[p+1])*a, float32(pix[p+2])*a, float32(pix[p+3])
+		}
+	case *image.RGBA:
+		pix := img.Pix[img.PixOffset(bounds.Min.X, y):]
+		for p := 0; p < bounds.Dx()*4; p++ {
+			dst[p] = float32(pix[p])
+		}
+	case *image.Gray:
+		pix := img.Pix[img.PixOffset(bounds.Min.X, y):]
+		for x := 0; x < bounds.Dx(); x++ {
+			v := float32(pix[x])
+			dst[x*4], dst[x*4+1], dst[x*4+2], dst[x*4+3] = v, v, v, 255
+		}
+	default:
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := src.At(x, y).RGBA()
+			p := (x - bounds.Min.X) * 4
+			dst[p], dst[p+1], dst[p+2], dst[p+3] = float32(r)/257, float32(g)/257, float32(b)/257, float32(a)/257
+		}
+	}
+}
+
+func clampUint8(v float32) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 255:
+		return 255
+	}
+	return uint8(v + 0.5)
+}
+
+// orient поворачивает и отражает изображение по значению EXIF-тега Orientation (1–8).
+func orient(img *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	out := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = width-1-x, y
+			case 3:
+				dx, dy = width-1-x, height-1-y
+			case 4:
+				dx, dy = x, height-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = height-1-y, x
+			case 7:
+				dx, dy = height-1-y, width-1-x
+			case 8:
+				dx, dy = y, width-1-x
+			}
+			copy(out.Pix[out.PixOffset(dx, dy):out.PixOffset(dx, dy)+4], img.Pix[img.PixOffset(x, y):img.PixOffset(x, y)+4])
+		}
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestThumbnail_Bounds(t *testing.T) {
+	tests := []struct {
+		name                 string
+		width, height, size  int
+		orientation          int
+		expectedW, expectedH int
+	}{
+		{"landscape", 100, 50, 64, 1, 64, 32},
+		{"portrait", 50, 100, 64, 1, 32, 64},
+		{"square", 300, 300, 64, 1, 64, 64},
+		{"exact fit", 64, 64, 64, 1, 64, 64},
+		{"small is not enlarged", 30, 20, 64, 1, 30, 20},
+		{"rounded height", 65, 3, 64, 1, 64, 3},
+		{"thin strip keeps one pixel", 1000, 1, 64, 1, 64, 1},
+		{"tall strip keeps one pixel", 1, 1000, 64, 1, 1, 64},
+		{"rotated by exif", 100, 50, 64, 6, 32, 64},
+		{"mirrored by exif", 100, 50, 64, 2, 64, 32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &Source{img: image.NewNRGBA(image.Rect(0, 0, tt.width, tt.height)), orientation: tt.orientation}
+			out := source.Thumbnail(tt.size)
+			if out.Rect.Dx() != tt.expectedW || out.Rect.Dy() != tt.expectedH {
+				t.Errorf("Expected %dx%d, got %dx%d", tt.expectedW, tt.expectedH, out.Rect.Dx(), out.Rect.Dy())
+			}
+		})
+	}
+}
+
+func TestResize_Pixels(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      image.Image
+		expected color.NRGBA
+	}{
+		{"solid", filledImage(7, 5, func(int, int) color.NRGBA {
+			return color.NRGBA{R: 0x20, G: 0x40, B: 0x80, A: 0xFF}
+		}), color.NRGBA{R: 0x20, G: 0x40, B: 0x80, A: 0xFF}},
+		{"black and white average", filledImage(2, 1, func(x, _ int) color.NRGBA {
+			return color.NRGBA{R: uint8(x * 0xFF), G: uint8(x * 0xFF), B: uint8(x * 0xFF), A: 0xFF}
+		}), color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xFF}},
+		// Прозрачные пиксели уменьшают непрозрачность, но не затемняют цвет.
+		{"transparent neighbours", filledImage(2, 2, func(x, y int) color.NRGBA {
+			if x == 0 && y == 0 {
+				return color.NRGBA{R: 0xFF, A: 0xFF}
+			}
+			return color.NRGBA{}
+		}), color.NRGBA{R: 0xFF, A: 0x40}},
+		{"gray", &image.Gray{Pix: []uint8{0x10, 0x30}, Stride: 2, Rect: image.Rect(0, 0, 2, 1)},
+			color.NRGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xFF}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := resize(tt.src, 1, 1)
+			if got := out.NRGBAAt(0, 0); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	// Уменьшение с нецелым шагом не теряет и не дублирует строки: однотонное изображение остается однотонным.
+	src := filledImage(10, 7, func(int, int) color.NRGBA { return color.NRGBA{R: 0x55, G: 0xAA, B: 0x11, A: 0xFF} })
+	out := resize(src, 3, 4)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 3; x++ {
+			if got := out.NRGBAAt(x, y); got != (color.NRGBA{R: 0x55, G: 0xAA, B: 0x11, A: 0xFF}) {
+				t.Fatalf("Pixel (%d, %d): expected solid colour, got %v", x, y, got)
+			}
+		}
+	}
+}
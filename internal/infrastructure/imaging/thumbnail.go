@@ -0,0 +1,128 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // регистрирует декодер GIF для image.Decode
+	"image/jpeg"
+	_ "image/png" // регистрирует декодер PNG для image.Decode
+	"io"
+)
+
+const (
+	FormatJPEG = "jpeg"
+	FormatWebP = "webp"
+
+	DefaultJPEGQuality = 80
+)
+
+var (
+	ErrImageTooLarge     = errors.New("image has too many pixels")
+	ErrUnsupportedFormat = errors.New("unsupported thumbnail format")
+)
+
+// placeholderColor — цвет заглушки, которая отдается, пока миниатюра не готова.
+var placeholderColor = color.NRGBA{R: 0xE5, G: 0xE7, B: 0xEB, A: 0xFF}
+
+// ContentType возвращает MIME-тип миниатюры в формате format.
+func ContentType(format string) string {
+	switch format {
+	case FormatJPEG:
+		return "image/jpeg"
+	case FormatWebP:
+		return "image/webp"
+	}
+	return ""
+}
+
+// ValidateFormat проверяет, что миниатюры можно кодировать в format.
+func ValidateFormat(format string) error {
+	if ContentType(format) == "" {
+		return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+	return nil
+}
+
+// Source — декодированное исходное изображение. Для GIF берется первый кадр.
+type Source struct {
+	img         image.Image
+	orientation int
+}
+
+// Load декодирует PNG, JPEG или GIF. Размер изображения проверяется по заголовку до декодирования,
+// чтобы маленький файл с огромными размерами не занял всю память. maxPixels <= 0 снимает ограничение.
+func Load(data []byte, maxPixels int64) (*Source, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if maxPixels > 0 && int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrImageTooLarge, cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	source := &Source{img: img, orientation: 1}
+	if format == "jpeg" {
+		source.orientation = jpegOrientation(data)
+	}
+	return source, nil
+}
+
+// Thumbnail уменьшает изображение так, чтобы оно вписалось в квадрат size×size с сохранением
+// пропорций, и поворачивает его по EXIF-ориентации. Маленькие изображения не увеличиваются.
+func (s *Source) Thumbnail(size int) *image.NRGBA {
+	bounds := s.img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > size || height > size {
+		if width >= height {
+			width, height = size, max(1, (height*size+width/2)/width)
+		} else {
+			width, height = max(1, (width*size+height/2)/height), size
+		}
+	}
+	return orient(resize(s.img, width, height), s.orientation)
+}
+
+// Placeholder возвращает однотонную заглушку size×size.
+func Placeholder(size int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = placeholderColor.R, placeholderColor.G, placeholderColor.B, placeholderColor.A
+	}
+	return img
+}
+
+// Encode кодирует миниатюру в JPEG с качеством quality или в WebP без потерь.
+// В JPEG нет прозрачности, поэтому прозрачные области заливаются белым.
+func Encode(w io.Writer, img *image.NRGBA, format string, quality int) error {
+	switch format {
+	case FormatJPEG:
+		if quality <= 0 {
+			quality = DefaultJPEGQuality
+		}
+		return jpeg.Encode(w, flatten(img), &jpeg.Options{Quality: quality})
+	case FormatWebP:
+		return encodeWebP(w, img)
+	}
+	return fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+// flatten накладывает изображение на белый фон.
+func flatten(img *image.NRGBA) *image.RGBA {
+	out := image.NewRGBA(img.Rect)
+	for i := 0; i < len(img.Pix); i += 4 {
+		a := uint32(img.Pix[i+3])
+		for c := 0; c < 3; c++ {
+			out.Pix[i+c] = uint8((uint32(img.Pix[i+c])*a + 255*(255-a) + 127) / 255)
+		}
+		out.Pix[i+3] = 0xFF
+	}
+	return out
+}
@@ -0,0 +1,357 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"io"
+	"sort"
+)
+
+// Кодировщик WebP без потерь (VP8L). Поддерживается минимальное подмножество формата: «вычитание
+// зеленого», предсказание по левому пикселю и по одному префиксному коду на канал без обратных
+// ссылок и кэша цветов. Для миниатюр этого достаточно, а в стандартной библиотеке кодировщика WebP нет.
+const (
+	vp8lSignature  = 0x2f
+	vp8lMaxSize    = 1 << 14
+	vp8lMaxCodeLen = 15
+	// Длины кодов длин кодируются тремя битами.
+	vp8lMaxCodeLengthCodeLen = 7
+
+	vp8lTransformPredictor     = 0
+	vp8lTransformSubtractGreen = 2
+	// Один блок предсказания на 512×512 пикселей: режим у всего изображения общий.
+	vp8lPredictorSizeBits = 9
+	vp8lPredictorLeft     = 1
+
+	vp8lGreenAlphabet    = 256 + 24
+	vp8lColorAlphabet    = 256
+	vp8lDistanceAlphabet = 40
+)
+
+var vp8lCodeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+var errWebPTooLarge = errors.New("webp: image dimensions exceed 16384")
+
+func encodeWebP(w io.Writer, img *image.NRGBA) error {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	if width < 1 || height < 1 || width > vp8lMaxSize || height > vp8lMaxSize {
+		return errWebPTooLarge
+	}
+
+	// Пиксели в порядке каналов VP8L после вычитания зеленого из красного и синего.
+	pixels := make([][4]uint8, 0, width*height)
+	hasAlpha := false
+	for y := 0; y < height; y++ {
+		row := img.Pix[img.PixOffset(img.Rect.Min.X, img.Rect.Min.Y+y):]
+		for x := 0; x < width; x++ {
+			r, g, b, a := row[x*4], row[x*4+1], row[x*4+2], row[x*4+3]
+			pixels = append(pixels, [4]uint8{g, r - g, b - g, a})
+			hasAlpha = hasAlpha || a != 0xFF
+		}
+	}
+	residuals := predictLeft(pixels, width)
+
+	bw := &bitWriter{}
+	bw.write(vp8lSignature, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	bw.write(boolBit(hasAlpha), 1)
+	bw.write(0, 3) // версия
+
+	bw.write(1, 1)
+	bw.write(vp8lTransformSubtractGreen, 2)
+	bw.write(1, 1)
+	bw.write(vp8lTransformPredictor, 2)
+	bw.write(vp8lPredictorSizeBits-2, 3)
+	writePredictorModes(bw)
+	bw.write(0, 1) // других преобразований нет
+
+	bw.write(0, 1) // без кэша цветов
+	bw.write(0, 1) // одна группа префиксных кодов на все изображение
+
+	alphabets := [4]int{vp8lGreenAlphabet, vp8lColorAlphabet, vp8lColorAlphabet, vp8lColorAlphabet}
+	var codes [4]prefixCode
+	for channel, alphabet := range alphabets {
+		counts := make([]int, alphabet)
+		for _, p := range residuals {
+			counts[p[channel]]++
+		}
+		codes[channel] = newPrefixCode(counts, vp8lMaxCodeLen)
+		codes[channel].writeTo(bw)
+	}
+	// Код расстояний не используется: достаточно простого кода из одного символа.
+	newPrefixCode(make([]int, vp8lDistanceAlphabet), vp8lMaxCodeLen).writeTo(bw)
+
+	for _, p := range residuals {
+		for channel := range codes {
+			codes[channel].writeSymbol(bw, int(p[channel]))
+		}
+	}
+
+	data := bw.bytes()
+	chunkSize := len(data)
+	padding := chunkSize % 2
+
+	header := make([]byte, 20)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+8+chunkSize+padding)) //nolint:gosec // G115: размер ограничен размерами изображения
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(chunkSize)) //nolint:gosec // G115: размер ограничен размерами изображения
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if padding == 1 {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	return nil
+}
+
+// predictLeft заменяет пиксели разностями с предсказанием. Первый пиксель предсказывается
+// непрозрачным черным, остальные в первой строке — левым соседом, в первом столбце — верхним,
+// а все прочие — левым соседом по режиму vp8lPredictorLeft.
+func predictLeft(pixels [][4]uint8, width int) [][4]uint8 {
+	residuals := make([][4]uint8, len(pixels))
+	for i, p := range pixels {
+		var pred [4]uint8
+		switch {
+		case i == 0:
+			pred = [4]uint8{0, 0, 0, 0xFF}
+		case i%width == 0:
+			pred = pixels[i-width]
+		default:
+			pred = pixels[i-1]
+		}
+		for c := range p {
+			residuals[i][c] = p[c] - pred[c]
+		}
+	}
+	return residuals
+}
+
+// writePredictorModes записывает подызображение режимов предсказания: каждый пиксель задает режим
+// в зеленом канале. Все блоки используют один режим, поэтому коды состоят из одного символа
+// и сами пиксели не занимают ни бита.
+func writePredictorModes(bw *bitWriter) {
+	bw.write(0, 1) // без кэша цветов
+	modes := make([]int, vp8lGreenAlphabet)
+	modes[vp8lPredictorLeft] = 1
+	newPrefixCode(modes, vp8lMaxCodeLen).writeTo(bw)
+	for _, alphabet := range []int{vp8lColorAlphabet, vp8lColorAlphabet, vp8lColorAlphabet, vp8lDistanceAlphabet} {
+		newPrefixCode(make([]int, alphabet), vp8lMaxCodeLen).writeTo(bw)
+	}
+}
+
+// bitWriter пишет биты начиная с младшего, как требует VP8L.
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nbits uint
+}
+
+func (b *bitWriter) write(value uint32, nbits uint) {
+	b.acc |= uint64(value) << b.nbits
+	b.nbits += nbits
+	for b.nbits >= 8 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc >>= 8
+		b.nbits -= 8
+	}
+}
+
+func (b *bitWriter) bytes() []byte {
+	if b.nbits > 0 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc, b.nbits = 0, 0
+	}
+	return b.buf
+}
+
+func boolBit(v bool) uint32 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// prefixCode — канонический код Хаффмана. Символ единственного кода занимает ноль бит.
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint32
+	symbols []int
+}
+
+// newPrefixCode строит код с длинами не больше maxLen по частотам символов.
+// Символ с нулевой частотой не получает кода; если символов нет совсем, код состоит из символа 0.
+func newPrefixCode(counts []int, maxLen int) prefixCode {
+	code := prefixCode{lengths: huffmanLengths(counts, maxLen)}
+	for symbol, length := range code.lengths {
+		if length > 0 {
+			code.symbols = append(code.symbols, symbol)
+		}
+	}
+	if len(code.symbols) == 0 {
+		code.lengths[0] = 1
+		code.symbols = []int{0}
+	}
+	code.codes = canonicalCodes(code.lengths)
+	return code
+}
+
+func (c prefixCode) writeSymbol(bw *bitWriter, symbol int) {
+	if len(c.symbols) == 1 {
+		return
+	}
+	bw.write(c.codes[symbol], uint(c.lengths[symbol]))
+}
+
+// writeTo записывает описание кода. Один или два символа меньше 256 записываются простым кодом,
+// остальные — длинами кодов, сжатыми кодом длин.
+func (c prefixCode) writeTo(bw *bitWriter) {
+	if len(c.symbols) <= 2 && c.symbols[len(c.symbols)-1] < 256 {
+		bw.write(1, 1)
+		bw.write(uint32(len(c.symbols)-1), 1) //nolint:gosec // G115: не больше 1
+		first := c.symbols[0]
+		if first < 2 {
+			bw.write(0, 1)
+			bw.write(uint32(first), 1) //nolint:gosec // G115: символ меньше 2
+		} else {
+			bw.write(1, 1)
+			bw.write(uint32(first), 8) //nolint:gosec // G115: символ меньше 256
+		}
+		if len(c.symbols) == 2 {
+			bw.write(uint32(c.symbols[1]), 8) //nolint:gosec // G115: символ меньше 256
+		}
+		return
+	}
+
+	counts := make([]int, len(vp8lCodeLengthOrder))
+	for _, length := range c.lengths {
+		counts[length]++
+	}
+	lengthCode := newPrefixCode(counts, vp8lMaxCodeLengthCodeLen)
+
+	n := len(vp8lCodeLengthOrder)
+	for n > 4 && lengthCode.lengths[vp8lCodeLengthOrder[n-1]] == 0 {
+		n--
+	}
+	bw.write(0, 1)
+	bw.write(uint32(n-4), 4) //nolint:gosec // G115: от 0 до 15
+	for _, symbol := range vp8lCodeLengthOrder[:n] {
+		bw.write(uint32(lengthCode.lengths[symbol]), 3)
+	}
+	bw.write(0, 1) // длины записаны для всего алфавита
+	for _, length := range c.lengths {
+		lengthCode.writeSymbol(bw, int(length))
+	}
+}
+
+// huffmanLengths вычисляет длины кодов Хаффмана. Если дерево получается глубже maxLen,
+// редкие символы приравниваются к более частым, пока глубина не уложится в ограничение.
+func huffmanLengths(counts []int, maxLen int) []uint8 {
+	type node struct {
+		weight      int
+		left, right int
+	}
+
+	lengths := make([]uint8, len(counts))
+	var symbols []int
+	for symbol, count := range counts {
+		if count > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+	switch len(symbols) {
+	case 0:
+		return lengths
+	case 1:
+		lengths[symbols[0]] = 1
+		return lengths
+	}
+
+	for minWeight := 1; ; minWeight *= 2 {
+		// Листья идут первыми, их номера совпадают с индексами в symbols.
+		nodes := make([]node, 0, 2*len(symbols))
+		for _, symbol := range symbols {
+			nodes = append(nodes, node{weight: max(counts[symbol], minWeight), left: -1, right: -1})
+		}
+		leaves := make([]int, len(symbols))
+		for i := range leaves {
+			leaves[i] = i
+		}
+		sort.SliceStable(leaves, func(i, j int) bool { return nodes[leaves[i]].weight < nodes[leaves[j]].weight })
+
+		// Две очереди: отсортированные листья и внутренние узлы, которые создаются по возрастанию веса.
+		var merged []int
+		pop := func() int {
+			if len(merged) == 0 || (len(leaves) > 0 && nodes[leaves[0]].weight <= nodes[merged[0]].weight) {
+				next := leaves[0]
+				leaves = leaves[1:]
+				return next
+			}
+			next := merged[0]
+			merged = merged[1:]
+			return next
+		}
+		for len(leaves)+len(merged) > 1 {
+			left, right := pop(), pop()
+			nodes = append(nodes, node{weight: nodes[left].weight + nodes[right].weight, left: left, right: right})
+			merged = append(merged, len(nodes)-1)
+		}
+
+		depths := make([]int, len(nodes))
+		maxDepth := 0
+		for i := len(nodes) - 1; i >= len(symbols); i-- {
+			depths[nodes[i].left] = depths[i] + 1
+			depths[nodes[i].right] = depths[i] + 1
+		}
+		for i := range symbols {
+			maxDepth = max(maxDepth, depths[i])
+		}
+		if maxDepth > maxLen {
+			continue
+		}
+		for i, symbol := range symbols {
+			lengths[symbol] = uint8(depths[i]) //nolint:gosec // G115: не больше maxLen
+		}
+		return lengths
+	}
+}
+
+// canonicalCodes назначает канонические коды и разворачивает их биты: VP8L читает код
+// со старшего бита, а биты в поток пишутся с младшего.
+func canonicalCodes(lengths []uint8) []uint32 {
+	var count [vp8lMaxCodeLen + 1]uint32
+	for _, length := range lengths {
+		count[length]++
+	}
+	count[0] = 0
+
+	var next [vp8lMaxCodeLen + 1]uint32
+	code := uint32(0)
+	for length := 1; length <= vp8lMaxCodeLen; length++ {
+		code = (code + count[length-1]) << 1
+		next[length] = code
+	}
+
+	codes := make([]uint32, len(lengths))
+	for symbol, length := range lengths {
+		if length == 0 {
+			continue
+		}
+		c := next[length]
+		next[length]++
+
+		var reversed uint32
+		for i := uint8(0); i < length; i++ {
+			reversed = reversed<<1 | (c>>i)&1
+		}
+		codes[symbol] = reversed
+	}
+	return codes
+}
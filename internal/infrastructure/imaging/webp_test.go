@@ -0,0 +1,110 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+func filledImage(width, height int, fill func(x, y int) color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, fill(x, y))
+		}
+	}
+	return img
+}
+
+func TestEncodeWebP_RoundTrip(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	tests := []struct {
+		name string
+		img  *image.NRGBA
+	}{
+		{"solid", filledImage(16, 16, func(int, int) color.NRGBA {
+			return color.NRGBA{R: 0x12, G: 0x80, B: 0xF0, A: 0xFF}
+		})},
+		{"one pixel", filledImage(1, 1, func(int, int) color.NRGBA {
+			return color.NRGBA{R: 0xFF, G: 0x00, B: 0x7F, A: 0xFF}
+		})},
+		{"gradient odd width", filledImage(37, 23, func(x, y int) color.NRGBA {
+			return color.NRGBA{R: uint8(x * 7), G: uint8(y * 11), B: uint8(x + y), A: 0xFF}
+		})},
+		{"single column", filledImage(1, 9, func(_, y int) color.NRGBA {
+			return color.NRGBA{R: uint8(y * 30), G: 0x40, B: 0xFF - uint8(y*30), A: 0xFF}
+		})},
+		{"single row", filledImage(13, 1, func(x, _ int) color.NRGBA {
+			return color.NRGBA{R: uint8(x * 19), G: uint8(x * 3), B: 0x01, A: 0xFF}
+		})},
+		{"alpha", filledImage(9, 7, func(x, y int) color.NRGBA {
+			return color.NRGBA{R: 0xC0, G: uint8(x * 20), B: uint8(y * 30), A: uint8((x + y*9) * 4)}
+		})},
+		{"transparent", filledImage(5, 5, func(x, y int) color.NRGBA {
+			return color.NRGBA{R: uint8(x), G: uint8(y), B: 0x00, A: 0x00}
+		})},
+		// Шум задействует весь алфавит каждого канала и ограничение длины кода.
+		{"noise", filledImage(63, 41, func(int, int) color.NRGBA {
+			return color.NRGBA{R: uint8(random.Intn(256)), G: uint8(random.Intn(256)), B: uint8(random.Intn(256)), A: uint8(random.Intn(256))}
+		})},
+		// Изображение, вырезанное из большего, начинается не с начала Pix.
+		{"sub image", filledImage(20, 20, func(x, y int) color.NRGBA {
+			return color.NRGBA{R: uint8(x * y), G: uint8(x), B: uint8(y), A: 0xFF}
+		}).SubImage(image.Rect(3, 5, 14, 12)).(*image.NRGBA)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := encodeWebP(&buf, tt.img); err != nil {
+				t.Fatalf("Failed to encode: %v", err)
+			}
+
+			decoded, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("Failed to decode: %v", err)
+			}
+			bounds := tt.img.Rect
+			if decoded.Bounds().Dx() != bounds.Dx() || decoded.Bounds().Dy() != bounds.Dy() {
+				t.Fatalf("Expected %dx%d, got %v", bounds.Dx(), bounds.Dy(), decoded.Bounds())
+			}
+			got, ok := decoded.(*image.NRGBA)
+			if !ok {
+				t.Fatalf("Expected lossless image to decode as NRGBA, got %T", decoded)
+			}
+			for y := 0; y < bounds.Dy(); y++ {
+				for x := 0; x < bounds.Dx(); x++ {
+					expected := tt.img.NRGBAAt(bounds.Min.X+x, bounds.Min.Y+y)
+					if actual := got.NRGBAAt(x, y); actual != expected {
+						t.Fatalf("Pixel (%d, %d): expected %v, got %v", x, y, expected, actual)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeWebP_Dimensions(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		expected      error
+	}{
+		{"empty", 0, 0, errWebPTooLarge},
+		{"too wide", vp8lMaxSize + 1, 1, errWebPTooLarge},
+		{"too tall", 1, vp8lMaxSize + 1, errWebPTooLarge},
+		{"max width", vp8lMaxSize, 1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := encodeWebP(&bytes.Buffer{}, image.NewNRGBA(image.Rect(0, 0, tt.width, tt.height)))
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
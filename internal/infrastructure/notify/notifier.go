@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"meemo/internal/infrastructure/logger"
+
+	"go.uber.org/zap"
+)
+
+const EventFileQuarantined = "file.quarantined"
+
+// Notification — событие, о котором нужно сообщить получателям Recipients (адреса почты).
+type Notification struct {
+	Event      string            `json:"event"`
+	Recipients []string          `json:"recipients"`
+	Subject    string            `json:"subject"`
+	Data       map[string]string `json:"data"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, n *Notification) error
+}
+
+type logNotifier struct {
+	log logger.Logger
+}
+
+// NewLogNotifier пишет уведомления в журнал. Используется, когда доставка не настроена.
+func NewLogNotifier(log logger.Logger) Notifier {
+	return &logNotifier{log: log}
+}
+
+func (n *logNotifier) Notify(_ context.Context, notification *Notification) error {
+	n.log.Warn("notification",
+		zap.String("event", notification.Event),
+		zap.Strings("recipients", notification.Recipients),
+		zap.String("subject", notification.Subject),
+		zap.Any("data", notification.Data),
+	)
+	return nil
+}
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+	next   Notifier
+}
+
+// NewWebhookNotifier отправляет уведомления POST-запросом с JSON на url, например в сервис рассылки почты,
+// и передает их дальше в next.
+func NewWebhookNotifier(url string, timeout time.Duration, next Notifier) Notifier {
+	return &webhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+		next:   next,
+	}
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, notification *Notification) error {
+	if err := n.next.Notify(ctx, notification); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("notification webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"meemo/internal/infrastructure/logger"
+
+	"go.uber.org/zap"
+)
+
+type Task func(ctx context.Context) error
+
+type Scheduler interface {
+	Every(name string, interval time.Duration, task Task)
+	Start(ctx context.Context)
+	Stop()
+}
+
+type job struct {
+	name     string
+	interval time.Duration
+	task     Task
+}
+
+type scheduler struct {
+	jobs   []job
+	log    logger.Logger
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewScheduler(log logger.Logger) Scheduler {
+	return &scheduler{log: log}
+}
+
+func (s *scheduler) Every(name string, interval time.Duration, task Task) {
+	if interval <= 0 {
+		s.log.Warn("job is not scheduled: interval must be positive", zap.String("job", name))
+		return
+	}
+	s.jobs = append(s.jobs, job{name: name, interval: interval, task: task})
+}
+
+func (s *scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.run(ctx, j)
+	}
+}
+
+func (s *scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *scheduler) run(ctx context.Context, j job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	s.log.Info("job scheduled", zap.String("job", j.name), zap.Duration("interval", j.interval))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			started := time.Now()
+			if err := j.task(ctx); err != nil {
+				s.log.Error("job failed", zap.String("job", j.name), zap.Error(err))
+				continue
+			}
+			s.log.Debug("job finished", zap.String("job", j.name), zap.Duration("took", time.Since(started)))
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"meemo/internal/domain/entity"
+	"time"
+)
+
+type Blob struct {
+	SHA256            string    `db:"sha256"`
+	S3Key             string    `db:"s3_key"`
+	SizeInBytes       int64     `db:"size_in_bytes"`
+	CRC32C            string    `db:"crc32c"`
+	RefCount          int64     `db:"ref_count"`
+	ContentEncoding   string    `db:"content_encoding"`
+	StoredSizeInBytes int64     `db:"stored_size_in_bytes"`
+	CreatedAt         time.Time `db:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at"`
+}
+
+func (m *Blob) ModelToEntity() *entity.Blob {
+	return &entity.Blob{
+		SHA256:            m.SHA256,
+		S3Key:             m.S3Key,
+		SizeInBytes:       m.SizeInBytes,
+		CRC32C:            m.CRC32C,
+		RefCount:          m.RefCount,
+		ContentEncoding:   m.ContentEncoding,
+		StoredSizeInBytes: m.StoredSizeInBytes,
+		CreatedAt:         m.CreatedAt,
+		UpdatedAt:         m.UpdatedAt,
+	}
+}
@@ -1,38 +1,81 @@
 package model
 
 import (
+	"database/sql"
 	"errors"
 	"meemo/internal/domain/entity"
 	"time"
 )
 
 type File struct {
-	ID           int64     `db:"id"`
-	UserID       int64     `db:"user_id"`
-	OriginalName string    `db:"original_name"`
-	MimeType     string    `db:"mime_type"`
-	SizeInBytes  int64     `db:"size_in_bytes"`
-	S3Bucket     string    `db:"s3_bucket"`
-	S3Key        string    `db:"s3_key"`
-	Status       int       `db:"status"`
-	CreatedAt    time.Time `db:"created_at"`
-	UpdatedAt    time.Time `db:"updated_at"`
-	IsPublic     bool      `db:"is_public"`
+	ID                 int64          `db:"id"`
+	UserID             int64          `db:"user_id"`
+	OriginalName       string         `db:"original_name"`
+	MimeType           string         `db:"mime_type"`
+	SizeInBytes        int64          `db:"size_in_bytes"`
+	S3Bucket           string         `db:"s3_bucket"`
+	S3Key              string         `db:"s3_key"`
+	Status             int            `db:"status"`
+	CreatedAt          time.Time      `db:"created_at"`
+	UpdatedAt          time.Time      `db:"updated_at"`
+	IsPublic           bool           `db:"is_public"`
+	ChecksumSHA256     string         `db:"checksum_sha256"`
+	ChecksumCRC32C     string         `db:"checksum_crc32c"`
+	ChecksumVerifiedAt sql.NullTime   `db:"checksum_verified_at"`
+	ChecksumFailed     bool           `db:"checksum_failed"`
+	BlobSHA256         sql.NullString `db:"blob_sha256"`
+	ContentEncoding    string         `db:"content_encoding"`
+	StoredSizeInBytes  int64          `db:"stored_size_in_bytes"`
+	ScanStatus         string         `db:"scan_status"`
+	ScanSignature      string         `db:"scan_signature"`
+	ScannedAt          sql.NullTime   `db:"scanned_at"`
+	FolderID           sql.NullInt64  `db:"folder_id"`
+	CurrentVersionID   sql.NullInt64  `db:"current_version_id"`
+	DeletedAt          sql.NullTime   `db:"deleted_at"`
+	ExpiresAt          sql.NullTime   `db:"expires_at"`
+	Tags               StringList     `db:"tags"`
+	Metadata           StringMap      `db:"metadata"`
 }
 
 func (m *File) ModelToEntity() *entity.File {
+	var checksumVerifiedAt *time.Time
+	if m.ChecksumVerifiedAt.Valid {
+		checksumVerifiedAt = &m.ChecksumVerifiedAt.Time
+	}
+
+	var deletedAt *time.Time
+	if m.DeletedAt.Valid {
+		deletedAt = &m.DeletedAt.Time
+	}
+
 	return &entity.File{
-		ID:           m.ID,
-		UserID:       m.UserID,
-		OriginalName: m.OriginalName,
-		MimeType:     m.MimeType,
-		SizeInBytes:  m.SizeInBytes,
-		S3Bucket:     m.S3Bucket,
-		S3Key:        m.S3Key,
-		Status:       m.Status,
-		CreatedAt:    m.CreatedAt,
-		UpdatedAt:    m.UpdatedAt,
-		IsPublic:     m.IsPublic,
+		ID:                 m.ID,
+		UserID:             m.UserID,
+		OriginalName:       m.OriginalName,
+		MimeType:           m.MimeType,
+		SizeInBytes:        m.SizeInBytes,
+		S3Bucket:           m.S3Bucket,
+		S3Key:              m.S3Key,
+		Status:             m.Status,
+		CreatedAt:          m.CreatedAt,
+		UpdatedAt:          m.UpdatedAt,
+		IsPublic:           m.IsPublic,
+		ChecksumSHA256:     m.ChecksumSHA256,
+		ChecksumCRC32C:     m.ChecksumCRC32C,
+		ChecksumVerifiedAt: checksumVerifiedAt,
+		ChecksumFailed:     m.ChecksumFailed,
+		BlobSHA256:         m.BlobSHA256.String,
+		ContentEncoding:    m.ContentEncoding,
+		StoredSizeInBytes:  m.StoredSizeInBytes,
+		ScanStatus:         m.ScanStatus,
+		ScanSignature:      m.ScanSignature,
+		ScannedAt:          NullTimeToPtr(m.ScannedAt),
+		FolderID:           NullInt64ToPtr(m.FolderID),
+		CurrentVersionID:   NullInt64ToPtr(m.CurrentVersionID),
+		DeletedAt:          deletedAt,
+		ExpiresAt:          NullTimeToPtr(m.ExpiresAt),
+		Tags:               []string(m.Tags),
+		Metadata:           map[string]string(m.Metadata),
 	}
 }
 
@@ -51,5 +94,30 @@ func (m *File) EntityToModel(entity *entity.File) error {
 	m.CreatedAt = entity.CreatedAt
 	m.UpdatedAt = entity.UpdatedAt
 	m.IsPublic = entity.IsPublic
+	m.ChecksumSHA256 = entity.ChecksumSHA256
+	m.ChecksumCRC32C = entity.ChecksumCRC32C
+	m.ChecksumVerifiedAt = sql.NullTime{}
+	if entity.ChecksumVerifiedAt != nil {
+		m.ChecksumVerifiedAt = sql.NullTime{Time: *entity.ChecksumVerifiedAt, Valid: true}
+	}
+	m.ChecksumFailed = entity.ChecksumFailed
+	m.BlobSHA256 = sql.NullString{String: entity.BlobSHA256, Valid: entity.BlobSHA256 != ""}
+	m.ContentEncoding = entity.ContentEncoding
+	m.StoredSizeInBytes = entity.StoredSizeInBytes
+	m.ScanStatus = entity.ScanStatus
+	m.ScanSignature = entity.ScanSignature
+	m.ScannedAt = PtrToNullTime(entity.ScannedAt)
+	m.FolderID = PtrToNullInt64(entity.FolderID)
+	m.CurrentVersionID = PtrToNullInt64(entity.CurrentVersionID)
+	m.DeletedAt = sql.NullTime{}
+	if entity.DeletedAt != nil {
+		m.DeletedAt = sql.NullTime{Time: *entity.DeletedAt, Valid: true}
+	}
+	m.ExpiresAt = PtrToNullTime(entity.ExpiresAt)
+	m.Tags = StringList(entity.Tags)
+	if m.Tags == nil {
+		m.Tags = StringList{}
+	}
+	m.Metadata = StringMap(entity.Metadata)
 	return nil
 }
@@ -0,0 +1,15 @@
+package model
+
+import "meemo/internal/domain/entity"
+
+type FileSearchResult struct {
+	File
+	Rank float64 `db:"rank"`
+}
+
+func (m *FileSearchResult) ModelToEntity() *entity.FileSearchResult {
+	return &entity.FileSearchResult{
+		File: m.File.ModelToEntity(),
+		Rank: m.Rank,
+	}
+}
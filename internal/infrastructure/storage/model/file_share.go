@@ -0,0 +1,45 @@
+package model
+
+import (
+	"database/sql"
+	"meemo/internal/domain/entity"
+	"time"
+)
+
+type FileShare struct {
+	ID         int64         `db:"id"`
+	FileID     int64         `db:"file_id"`
+	UserID     int64         `db:"user_id"`
+	UserEmail  string        `db:"user_email"`
+	Permission string        `db:"permission"`
+	CreatedBy  sql.NullInt64 `db:"created_by"`
+	CreatedAt  time.Time     `db:"created_at"`
+	UpdatedAt  time.Time     `db:"updated_at"`
+}
+
+func (m *FileShare) ModelToEntity() *entity.FileShare {
+	return &entity.FileShare{
+		ID:         m.ID,
+		FileID:     m.FileID,
+		UserID:     m.UserID,
+		UserEmail:  m.UserEmail,
+		Permission: entity.SharePermission(m.Permission),
+		CreatedBy:  NullInt64ToPtr(m.CreatedBy),
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+}
+
+type SharedFile struct {
+	File
+	OwnerEmail string `db:"owner_email"`
+	Permission string `db:"permission"`
+}
+
+func (m *SharedFile) ModelToEntity() *entity.SharedFile {
+	return &entity.SharedFile{
+		File:       m.File.ModelToEntity(),
+		OwnerEmail: m.OwnerEmail,
+		Permission: entity.SharePermission(m.Permission),
+	}
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"database/sql"
+	"meemo/internal/domain/entity"
+	"time"
+)
+
+type FileVersion struct {
+	ID                int64          `db:"id"`
+	FileID            int64          `db:"file_id"`
+	VersionNumber     int            `db:"version_number"`
+	S3Key             string         `db:"s3_key"`
+	SizeInBytes       int64          `db:"size_in_bytes"`
+	MimeType          string         `db:"mime_type"`
+	ChecksumSHA256    string         `db:"checksum_sha256"`
+	ChecksumCRC32C    string         `db:"checksum_crc32c"`
+	BlobSHA256        sql.NullString `db:"blob_sha256"`
+	ContentEncoding   string         `db:"content_encoding"`
+	StoredSizeInBytes int64          `db:"stored_size_in_bytes"`
+	ScanStatus        string         `db:"scan_status"`
+	ScanSignature     string         `db:"scan_signature"`
+	ScannedAt         sql.NullTime   `db:"scanned_at"`
+	CreatedBy         sql.NullInt64  `db:"created_by"`
+	CreatedAt         time.Time      `db:"created_at"`
+}
+
+func (m *FileVersion) ModelToEntity() *entity.FileVersion {
+	return &entity.FileVersion{
+		ID:                m.ID,
+		FileID:            m.FileID,
+		VersionNumber:     m.VersionNumber,
+		S3Key:             m.S3Key,
+		SizeInBytes:       m.SizeInBytes,
+		MimeType:          m.MimeType,
+		ChecksumSHA256:    m.ChecksumSHA256,
+		ChecksumCRC32C:    m.ChecksumCRC32C,
+		BlobSHA256:        m.BlobSHA256.String,
+		ContentEncoding:   m.ContentEncoding,
+		StoredSizeInBytes: m.StoredSizeInBytes,
+		ScanStatus:        m.ScanStatus,
+		ScanSignature:     m.ScanSignature,
+		ScannedAt:         NullTimeToPtr(m.ScannedAt),
+		CreatedBy:         NullInt64ToPtr(m.CreatedBy),
+		CreatedAt:         m.CreatedAt,
+	}
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"database/sql"
+	"meemo/internal/domain/entity"
+	"time"
+)
+
+type Folder struct {
+	ID        int64         `db:"id"`
+	UserID    int64         `db:"user_id"`
+	ParentID  sql.NullInt64 `db:"parent_id"`
+	Name      string        `db:"name"`
+	CreatedAt time.Time     `db:"created_at"`
+	UpdatedAt time.Time     `db:"updated_at"`
+}
+
+type FolderSize struct {
+	SizeInBytes int64 `db:"size_in_bytes"`
+	FileCount   int64 `db:"file_count"`
+	FolderCount int64 `db:"folder_count"`
+}
+
+func (m *Folder) ModelToEntity() *entity.Folder {
+	return &entity.Folder{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		ParentID:  NullInt64ToPtr(m.ParentID),
+		Name:      m.Name,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+func (m *FolderSize) ModelToEntity() *entity.FolderSize {
+	return &entity.FolderSize{
+		SizeInBytes: m.SizeInBytes,
+		FileCount:   m.FileCount,
+		FolderCount: m.FolderCount,
+	}
+}
+
+func NullInt64ToPtr(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int64
+}
+
+func PtrToNullInt64(v *int64) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *v, Valid: true}
+}
+
+func NullTimeToPtr(v sql.NullTime) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Time
+}
+
+func PtrToNullTime(v *time.Time) sql.NullTime {
+	if v == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *v, Valid: true}
+}
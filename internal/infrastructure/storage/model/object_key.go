@@ -0,0 +1,43 @@
+package model
+
+import (
+	"errors"
+	"meemo/internal/domain/entity"
+	"time"
+)
+
+type ObjectKey struct {
+	S3Key         string    `db:"s3_key"`
+	KeyID         string    `db:"key_id"`
+	WrappedKey    []byte    `db:"wrapped_key"`
+	ChunkSize     int       `db:"chunk_size"`
+	PlaintextSize int64     `db:"plaintext_size"`
+	CreatedAt     time.Time `db:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
+
+func (m *ObjectKey) ModelToEntity() *entity.ObjectKey {
+	return &entity.ObjectKey{
+		S3Key:         m.S3Key,
+		KeyID:         m.KeyID,
+		WrappedKey:    m.WrappedKey,
+		ChunkSize:     m.ChunkSize,
+		PlaintextSize: m.PlaintextSize,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+}
+
+func (m *ObjectKey) EntityToModel(e *entity.ObjectKey) error {
+	if e == nil {
+		return errors.New("entity is nil")
+	}
+	m.S3Key = e.S3Key
+	m.KeyID = e.KeyID
+	m.WrappedKey = e.WrappedKey
+	m.ChunkSize = e.ChunkSize
+	m.PlaintextSize = e.PlaintextSize
+	m.CreatedAt = e.CreatedAt
+	m.UpdatedAt = e.UpdatedAt
+	return nil
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"database/sql"
+	"meemo/internal/domain/entity"
+	"time"
+)
+
+type ObjectOperation struct {
+	ID          int64        `db:"id"`
+	Operation   string       `db:"operation"`
+	S3Key       string       `db:"s3_key"`
+	SourceKey   string       `db:"source_key"`
+	Attempts    int          `db:"attempts"`
+	LastError   string       `db:"last_error"`
+	AvailableAt time.Time    `db:"available_at"`
+	DeadAt      sql.NullTime `db:"dead_at"`
+	CreatedAt   time.Time    `db:"created_at"`
+}
+
+func (m *ObjectOperation) ModelToEntity() *entity.ObjectOperation {
+	return &entity.ObjectOperation{
+		ID:          m.ID,
+		Operation:   m.Operation,
+		S3Key:       m.S3Key,
+		SourceKey:   m.SourceKey,
+		Attempts:    m.Attempts,
+		LastError:   m.LastError,
+		AvailableAt: m.AvailableAt,
+		DeadAt:      NullTimeToPtr(m.DeadAt),
+		CreatedAt:   m.CreatedAt,
+	}
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"database/sql"
+	"errors"
+	"meemo/internal/domain/entity"
+	"time"
+)
+
+type ShareLink struct {
+	ID            int64          `db:"id"`
+	FileID        int64          `db:"file_id"`
+	UserID        int64          `db:"user_id"`
+	TokenHash     string         `db:"token_hash"`
+	PasswordHash  sql.NullString `db:"password_hash"`
+	ExpiresAt     sql.NullTime   `db:"expires_at"`
+	MaxDownloads  sql.NullInt32  `db:"max_downloads"`
+	DownloadCount int            `db:"download_count"`
+	RevokedAt     sql.NullTime   `db:"revoked_at"`
+	CreatedAt     time.Time      `db:"created_at"`
+	UpdatedAt     time.Time      `db:"updated_at"`
+}
+
+func (m *ShareLink) ModelToEntity() *entity.ShareLink {
+	link := &entity.ShareLink{
+		ID:            m.ID,
+		FileID:        m.FileID,
+		UserID:        m.UserID,
+		TokenHash:     m.TokenHash,
+		PasswordHash:  m.PasswordHash.String,
+		DownloadCount: m.DownloadCount,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+	if m.ExpiresAt.Valid {
+		link.ExpiresAt = &m.ExpiresAt.Time
+	}
+	if m.MaxDownloads.Valid {
+		maxDownloads := int(m.MaxDownloads.Int32)
+		link.MaxDownloads = &maxDownloads
+	}
+	if m.RevokedAt.Valid {
+		link.RevokedAt = &m.RevokedAt.Time
+	}
+	return link
+}
+
+func (m *ShareLink) EntityToModel(entity *entity.ShareLink) error {
+	if entity == nil {
+		return errors.New("entity is nil")
+	}
+	m.ID = entity.ID
+	m.FileID = entity.FileID
+	m.UserID = entity.UserID
+	m.TokenHash = entity.TokenHash
+	m.PasswordHash = sql.NullString{String: entity.PasswordHash, Valid: entity.PasswordHash != ""}
+	m.ExpiresAt = sql.NullTime{}
+	if entity.ExpiresAt != nil {
+		m.ExpiresAt = sql.NullTime{Time: *entity.ExpiresAt, Valid: true}
+	}
+	m.MaxDownloads = sql.NullInt32{}
+	if entity.MaxDownloads != nil {
+		m.MaxDownloads = sql.NullInt32{Int32: int32(*entity.MaxDownloads), Valid: true}
+	}
+	m.DownloadCount = entity.DownloadCount
+	m.RevokedAt = sql.NullTime{}
+	if entity.RevokedAt != nil {
+		m.RevokedAt = sql.NullTime{Time: *entity.RevokedAt, Valid: true}
+	}
+	m.CreatedAt = entity.CreatedAt
+	m.UpdatedAt = entity.UpdatedAt
+	return nil
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"database/sql"
+	"meemo/internal/domain/entity"
+)
+
+type StoredObject struct {
+	S3Key       string        `db:"s3_key"`
+	Kind        string        `db:"kind"`
+	SizeInBytes int64         `db:"size_in_bytes"`
+	FileID      sql.NullInt64 `db:"file_id"`
+}
+
+func (m *StoredObject) ModelToEntity() *entity.StoredObject {
+	return &entity.StoredObject{
+		S3Key:       m.S3Key,
+		Kind:        m.Kind,
+		SizeInBytes: m.SizeInBytes,
+		FileID:      NullInt64ToPtr(m.FileID),
+	}
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"meemo/internal/domain/entity"
+
+	"github.com/lib/pq"
+)
+
+type TagCount struct {
+	Tag   string `db:"tag"`
+	Count int64  `db:"count"`
+}
+
+func (m *TagCount) ModelToEntity() *entity.TagCount {
+	return &entity.TagCount{
+		Tag:   m.Tag,
+		Count: m.Count,
+	}
+}
+
+// StringList хранит теги файла: массив TEXT[] в Postgres или JSON-массив в SQLite.
+type StringList []string
+
+func (l *StringList) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("unsupported tags type %T", src)
+	}
+	if len(raw) > 0 && raw[0] == '[' {
+		return json.Unmarshal(raw, (*[]string)(l))
+	}
+	return (*pq.StringArray)(l).Scan(raw)
+}
+
+// Value возвращает массив в формате Postgres; SQLite получает теги JSON-строкой из своего репозитория.
+func (l StringList) Value() (driver.Value, error) {
+	return pq.StringArray(l).Value()
+}
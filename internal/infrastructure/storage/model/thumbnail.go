@@ -0,0 +1,52 @@
+package model
+
+import (
+	"errors"
+	"meemo/internal/domain/entity"
+	"time"
+)
+
+type Thumbnail struct {
+	FileID      int64     `db:"file_id"`
+	VersionID   int64     `db:"version_id"`
+	Size        int       `db:"size"`
+	Format      string    `db:"format"`
+	Status      int       `db:"status"`
+	S3Key       string    `db:"s3_key"`
+	Width       int       `db:"width"`
+	Height      int       `db:"height"`
+	SizeInBytes int64     `db:"size_in_bytes"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func (m *Thumbnail) ModelToEntity() *entity.Thumbnail {
+	return &entity.Thumbnail{
+		FileID:      m.FileID,
+		VersionID:   m.VersionID,
+		Size:        m.Size,
+		Format:      m.Format,
+		Status:      m.Status,
+		S3Key:       m.S3Key,
+		Width:       m.Width,
+		Height:      m.Height,
+		SizeInBytes: m.SizeInBytes,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+func (m *Thumbnail) EntityToModel(e *entity.Thumbnail) error {
+	if e == nil {
+		return errors.New("entity is nil")
+	}
+	m.FileID = e.FileID
+	m.VersionID = e.VersionID
+	m.Size = e.Size
+	m.Format = e.Format
+	m.Status = e.Status
+	m.S3Key = e.S3Key
+	m.Width = e.Width
+	m.Height = e.Height
+	m.SizeInBytes = e.SizeInBytes
+	m.CreatedAt = e.CreatedAt
+	return nil
+}
@@ -0,0 +1,22 @@
+package encryption
+
+import (
+	"meemo/internal/domain/encryption/repository"
+	sqlstore "meemo/internal/infrastructure/storage/sqlstore/encryption"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var templates = sqlstore.Templates{
+	SaveObjectKey:                  SaveObjectKeyTemplate,
+	GetObjectKey:                   GetObjectKeyTemplate,
+	CopyObjectKey:                  CopyObjectKeyTemplate,
+	DeleteObjectKey:                DeleteObjectKeyTemplate,
+	ListObjectKeysWrappedWithOther: ListObjectKeysWrappedWithOtherTemplate,
+	RewrapObjectKey:                RewrapObjectKeyTemplate,
+	MarkObjectKeyRewrapFailed:      MarkObjectKeyRewrapFailedTemplate,
+}
+
+func NewObjectKeyRepository(conn *sqlx.DB) repository.ObjectKeyRepository {
+	return sqlstore.NewObjectKeyRepository(conn, templates)
+}
@@ -0,0 +1,66 @@
+package encryption
+
+const objectKeyColumns = `s3_key, key_id, wrapped_key, chunk_size, plaintext_size, created_at, updated_at`
+
+const (
+	SaveObjectKeyTemplate = `
+INSERT INTO object_keys (s3_key, key_id, wrapped_key, chunk_size, plaintext_size)
+VALUES (:s3_key, :key_id, :wrapped_key, :chunk_size, :plaintext_size)
+ON CONFLICT (s3_key) DO UPDATE
+    SET key_id           = EXCLUDED.key_id,
+        wrapped_key      = EXCLUDED.wrapped_key,
+        chunk_size       = EXCLUDED.chunk_size,
+        plaintext_size   = EXCLUDED.plaintext_size,
+        rewrap_failed_at = NULL,
+        created_at       = CURRENT_TIMESTAMP,
+        updated_at       = CURRENT_TIMESTAMP
+RETURNING ` + objectKeyColumns + `;`
+
+	GetObjectKeyTemplate = `
+SELECT ` + objectKeyColumns + `
+FROM object_keys
+WHERE s3_key = $1;`
+
+	// CopyObjectKeyTemplate переносит ключ данных $1 на $2: копия объекта S3 остается тем же шифртекстом.
+	CopyObjectKeyTemplate = `
+INSERT INTO object_keys (s3_key, key_id, wrapped_key, chunk_size, plaintext_size, rewrap_failed_at)
+SELECT $2, key_id, wrapped_key, chunk_size, plaintext_size, rewrap_failed_at
+FROM object_keys
+WHERE s3_key = $1
+ON CONFLICT (s3_key) DO UPDATE
+    SET key_id           = EXCLUDED.key_id,
+        wrapped_key      = EXCLUDED.wrapped_key,
+        chunk_size       = EXCLUDED.chunk_size,
+        plaintext_size   = EXCLUDED.plaintext_size,
+        rewrap_failed_at = EXCLUDED.rewrap_failed_at,
+        created_at       = CURRENT_TIMESTAMP,
+        updated_at       = CURRENT_TIMESTAMP;`
+
+	DeleteObjectKeyTemplate = `
+DELETE FROM object_keys
+WHERE s3_key = $1;`
+
+	// ListObjectKeysWrappedWithOtherTemplate пропускает ключи, которые не удалось развернуть:
+	// иначе они возвращались бы в каждой пачке и останавливали ротацию.
+	ListObjectKeysWrappedWithOtherTemplate = `
+SELECT ` + objectKeyColumns + `
+FROM object_keys
+WHERE key_id <> $1
+  AND rewrap_failed_at IS NULL
+ORDER BY s3_key
+LIMIT $2;`
+
+	RewrapObjectKeyTemplate = `
+UPDATE object_keys
+SET key_id      = $3,
+    wrapped_key = $4,
+    updated_at  = CURRENT_TIMESTAMP
+WHERE s3_key = $1
+  AND key_id = $2;`
+
+	MarkObjectKeyRewrapFailedTemplate = `
+UPDATE object_keys
+SET rewrap_failed_at = CURRENT_TIMESTAMP
+WHERE s3_key = $1
+  AND key_id = $2;`
+)
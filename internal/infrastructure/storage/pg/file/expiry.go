@@ -0,0 +1,23 @@
+package file
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+	"meemo/internal/infrastructure/storage/model"
+	"time"
+)
+
+// SetExpiry задает срок хранения файла; nil снимает его.
+func (fr *fileRepository) SetExpiry(ctx context.Context, userID, fileID int64, expiresAt *time.Time) (*entity.File, error) {
+	return fr.queryFile(ctx, SetFileExpiryTemplate, fileID, userID, model.PtrToNullTime(expiresAt))
+}
+
+func (fr *fileRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*entity.File, error) {
+	return fr.queryFiles(ctx, ListExpiredFilesTemplate, now, limit)
+}
+
+// DeleteExpired удаляет файл с истекшим сроком и записывает удаление с причиной reason.
+// Если срок за это время продлили или сняли, возвращается sql.ErrNoRows.
+func (fr *fileRepository) DeleteExpired(ctx context.Context, fileID int64, now time.Time, reason string) (*entity.File, error) {
+	return fr.queryFile(ctx, DeleteExpiredFileTemplate, fileID, now, reason)
+}
@@ -0,0 +1,38 @@
+package file
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+	"meemo/internal/infrastructure/storage/model"
+	"time"
+)
+
+// ListStoredObjects возвращает все объекты S3, на которые ссылаются файлы, версии, блобы и миниатюры.
+func (fr *fileRepository) ListStoredObjects(ctx context.Context) ([]*entity.StoredObject, error) {
+	rows, err := fr.conn.QueryxContext(ctx, ListStoredObjectsTemplate,
+		entity.StoredObjectFile, entity.StoredObjectVersion, entity.StoredObjectBlob, entity.StoredObjectThumbnail)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var objects []*entity.StoredObject
+	for rows.Next() {
+		objectModel := &model.StoredObject{}
+		if err := rows.StructScan(objectModel); err != nil {
+			return nil, err
+		}
+		objects = append(objects, objectModel.ModelToEntity())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+// ListStalePending возвращает файлы в статусе Pending без содержимого, не менявшиеся с updatedBefore.
+func (fr *fileRepository) ListStalePending(ctx context.Context, updatedBefore time.Time) ([]*entity.File, error) {
+	return fr.queryFiles(ctx, ListStalePendingTemplate, entity.Pending, updatedBefore)
+}
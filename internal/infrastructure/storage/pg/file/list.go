@@ -0,0 +1,107 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+type sortColumn struct {
+	column string
+	cast   string
+}
+
+var sortColumns = map[repository.SortField]sortColumn{
+	repository.SortByName:    {column: "f.original_name", cast: "text"},
+	repository.SortBySize:    {column: "f.size_in_bytes", cast: "bigint"},
+	repository.SortByCreated: {column: "f.created_at", cast: "timestamptz"},
+	repository.SortByUpdated: {column: "f.updated_at", cast: "timestamptz"},
+}
+
+func (fr *fileRepository) ListPage(ctx context.Context, userID int64, opts repository.ListOptions) ([]*entity.File, error) {
+	query, args, err := buildListPageQuery(userID, opts)
+	if err != nil {
+		return nil, err
+	}
+	return fr.queryFiles(ctx, query, args...)
+}
+
+// buildListPageQuery собирает запрос страницы списка. Имена колонок берутся только из sortColumns,
+// все пользовательские значения передаются параметрами.
+func buildListPageQuery(userID int64, opts repository.ListOptions) (string, []any, error) {
+	sort, ok := sortColumns[opts.SortBy]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown sort field %q", opts.SortBy)
+	}
+
+	args := []any{userID}
+	conditions := []string{"f.user_id = $1", "f.deleted_at IS NULL"}
+	addCondition := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if opts.MimePrefix != "" {
+		addCondition(`f.mime_type LIKE $%d ESCAPE '\'`, escapeLike(opts.MimePrefix)+"%")
+	}
+	if opts.Status != nil {
+		addCondition("f.status = $%d", *opts.Status)
+	}
+	if opts.IsPublic != nil {
+		addCondition("f.is_public = $%d", *opts.IsPublic)
+	}
+	if opts.MinSize != nil {
+		addCondition("f.size_in_bytes >= $%d", *opts.MinSize)
+	}
+	if opts.MaxSize != nil {
+		addCondition("f.size_in_bytes <= $%d", *opts.MaxSize)
+	}
+	if opts.CreatedAfter != nil {
+		addCondition("f.created_at >= $%d", *opts.CreatedAfter)
+	}
+	if opts.CreatedBefore != nil {
+		addCondition("f.created_at < $%d", *opts.CreatedBefore)
+	}
+
+	if len(opts.Tags) > 0 {
+		addCondition("f.tags @> $%d::text[]", pq.Array(opts.Tags))
+	}
+	if opts.MetadataKey != "" {
+		if opts.MetadataValue != nil {
+			raw, err := json.Marshal(map[string]string{opts.MetadataKey: *opts.MetadataValue})
+			if err != nil {
+				return "", nil, err
+			}
+			addCondition("f.metadata @> $%d::jsonb", string(raw))
+		} else {
+			addCondition("f.metadata ? $%d", opts.MetadataKey)
+		}
+	}
+
+	direction, comparison := "ASC", ">"
+	if opts.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if opts.AfterValue != nil {
+		args = append(args, *opts.AfterValue, opts.AfterID)
+		conditions = append(conditions, fmt.Sprintf("(%s, f.id) %s ($%d::%s, $%d)",
+			sort.column, comparison, len(args)-1, sort.cast, len(args)))
+	}
+
+	args = append(args, opts.Limit)
+	query := fmt.Sprintf(ListFilesPageTemplate,
+		strings.Join(conditions, "\n  AND "),
+		sort.column, direction, direction,
+		len(args))
+	return query, args, nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -3,35 +3,37 @@ package file
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"meemo/internal/domain/entity"
 	"meemo/internal/domain/file/repository"
 	"meemo/internal/infrastructure/storage/model"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type fileRepository struct {
-	conn *sqlx.DB
+	// conn — соединение или транзакция InTx, в которой выполняются запросы.
+	conn sqlx.ExtContext
+	db   *sqlx.DB
+	tx   *sqlx.Tx
 }
 
 func NewFileRepository(conn *sqlx.DB) repository.FileRepository {
 	return &fileRepository{
 		conn: conn,
+		db:   conn,
 	}
 }
 
-func (fr *fileRepository) Save(ctx context.Context, userID int64, originalName, mimeType, s3Bucket, s3Key string, sizeInBytes int64, isPublic bool) (*entity.File, error) {
-	fileModel := &model.File{
-		UserID:       userID,
-		OriginalName: originalName,
-		MimeType:     mimeType,
-		S3Bucket:     s3Bucket,
-		S3Key:        s3Key,
-		SizeInBytes:  sizeInBytes,
-		IsPublic:     isPublic,
+func (fr *fileRepository) Create(ctx context.Context, file *entity.File) (*entity.File, error) {
+	fileModel := &model.File{}
+	if err := fileModel.EntityToModel(file); err != nil {
+		return nil, err
 	}
 
-	rows, err := fr.conn.NamedQueryContext(ctx, SaveFileTemplate, fileModel)
+	rows, err := sqlx.NamedQueryContext(ctx, fr.conn, SaveFileTemplate, fileModel)
 	if err != nil {
 		return nil, err
 	}
@@ -46,6 +48,18 @@ func (fr *fileRepository) Save(ctx context.Context, userID int64, originalName,
 	return nil, sql.ErrNoRows
 }
 
+func (fr *fileRepository) Save(ctx context.Context, userID int64, originalName, mimeType, s3Bucket, s3Key string, sizeInBytes int64, isPublic bool) (*entity.File, error) {
+	return fr.Create(ctx, &entity.File{
+		UserID:       userID,
+		OriginalName: originalName,
+		MimeType:     mimeType,
+		S3Bucket:     s3Bucket,
+		S3Key:        s3Key,
+		SizeInBytes:  sizeInBytes,
+		IsPublic:     isPublic,
+	})
+}
+
 func (fr *fileRepository) Delete(ctx context.Context, userEmail, originalName string) (*entity.File, error) {
 	fileModel := &model.File{}
 
@@ -77,10 +91,20 @@ func (fr *fileRepository) GetByOriginalNameAndUserEmail(ctx context.Context, use
 	return fileModel.ModelToEntity(), nil
 }
 
+func (fr *fileRepository) GetByName(ctx context.Context, userID int64, folderID *int64, originalName string) (*entity.File, error) {
+	fileModel := &model.File{}
+
+	err := fr.conn.QueryRowxContext(ctx, GetFileByNameInFolderTemplate, userID, model.PtrToNullInt64(folderID), originalName).StructScan(fileModel)
+	if err != nil {
+		return nil, err
+	}
+	return fileModel.ModelToEntity(), nil
+}
+
 func (fr *fileRepository) ChangeVisibility(ctx context.Context, userEmail, originalName string, isPublic bool) (*entity.File, error) {
 	fileModel := &model.File{}
 
-	err := fr.conn.QueryRowxContext(ctx, ChangeVisibilityTemplate, isPublic, userEmail, originalName).Scan(&fileModel.ID, &fileModel.IsPublic, &fileModel.UpdatedAt)
+	err := fr.conn.QueryRowxContext(ctx, ChangeVisibilityTemplate, isPublic, userEmail, originalName, entity.Quarantined).Scan(&fileModel.ID, &fileModel.IsPublic, &fileModel.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +115,7 @@ func (fr *fileRepository) ChangeVisibility(ctx context.Context, userEmail, origi
 func (fr *fileRepository) SetStatus(ctx context.Context, userEmail, originalName string, status int) (*entity.File, error) {
 	fileModel := &model.File{}
 
-	err := fr.conn.QueryRowxContext(ctx, SetStatusTemplate, status, userEmail, originalName).Scan(&fileModel.ID, &fileModel.Status, &fileModel.UpdatedAt)
+	err := fr.conn.QueryRowxContext(ctx, SetStatusTemplate, status, userEmail, originalName, entity.Quarantined).Scan(&fileModel.ID, &fileModel.Status, &fileModel.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -109,6 +133,33 @@ func (fr *fileRepository) Rename(ctx context.Context, userEmail, originalName, n
 	return fileModel.ModelToEntity(), nil
 }
 
+func (fr *fileRepository) RenameByID(ctx context.Context, fileID int64, newName string) (*entity.File, error) {
+	return fr.queryFile(ctx, RenameFileByIDTemplate, newName, fileID)
+}
+
+func (fr *fileRepository) ListSharedWithUser(ctx context.Context, userID int64) ([]*entity.SharedFile, error) {
+	rows, err := fr.conn.QueryxContext(ctx, ListSharedWithUserTemplate, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var files []*entity.SharedFile
+	for rows.Next() {
+		sharedModel := &model.SharedFile{}
+		if err := rows.StructScan(sharedModel); err != nil {
+			return nil, err
+		}
+		files = append(files, sharedModel.ModelToEntity())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
 func (fr *fileRepository) List(ctx context.Context, userEmail string) ([]*entity.File, error) {
 	rows, err := fr.conn.QueryxContext(ctx, ListUserFilesTemplate, userEmail)
 	if err != nil {
@@ -140,3 +191,299 @@ func (fr *fileRepository) GetTotalUsedSpace(ctx context.Context, userEmail strin
 	}
 	return totalBytes, nil
 }
+
+func (fr *fileRepository) GetUserPlan(ctx context.Context, userID int64) (string, error) {
+	var plan string
+	err := fr.conn.QueryRowxContext(ctx, GetUserPlanTemplate, userID).Scan(&plan)
+	if err != nil {
+		return "", err
+	}
+	return plan, nil
+}
+
+func (fr *fileRepository) GetUserEmail(ctx context.Context, userID int64) (string, error) {
+	var email string
+	err := fr.conn.QueryRowxContext(ctx, GetUserEmailTemplate, userID).Scan(&email)
+	if err != nil {
+		return "", err
+	}
+	return email, nil
+}
+
+func (fr *fileRepository) ListForScrub(ctx context.Context, verifiedBefore time.Time, limit int) ([]*entity.File, error) {
+	return fr.queryFiles(ctx, ListFilesForScrubTemplate, verifiedBefore, limit)
+}
+
+func (fr *fileRepository) ListForThumbnails(ctx context.Context, mimeTypes []string, expected, limit int) ([]*entity.File, error) {
+	return fr.queryFiles(ctx, ListFilesForThumbnailsTemplate, entity.Loaded, pq.Array(mimeTypes), expected, limit)
+}
+
+func (fr *fileRepository) MarkChecksumVerified(ctx context.Context, fileID int64, failed bool) error {
+	result, err := fr.conn.ExecContext(ctx, MarkChecksumVerifiedTemplate, failed, fileID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (fr *fileRepository) GetBlob(ctx context.Context, sha256 string) (*entity.Blob, error) {
+	blobModel := &model.Blob{}
+
+	err := fr.conn.QueryRowxContext(ctx, GetBlobTemplate, sha256).StructScan(blobModel)
+	if err != nil {
+		return nil, err
+	}
+	return blobModel.ModelToEntity(), nil
+}
+
+// AddVersion сохраняет новую версию содержимого файла и делает ее текущей.
+// Если передан blob, ссылка на него захватывается (с созданием записи при необходимости),
+// иначе при заполненном version.BlobSHA256 блоб должен уже существовать.
+func (fr *fileRepository) AddVersion(ctx context.Context, version *entity.FileVersion, blob *entity.Blob) (*entity.File, error) {
+	tx, err := fr.beginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var lockedID int64
+	if err := tx.QueryRowxContext(ctx, LockFileTemplate, version.FileID).Scan(&lockedID); err != nil {
+		return nil, err
+	}
+
+	// Содержимое версии, ссылающейся на блоб, хранится так, как сохранен блоб.
+	s3Key, blobSHA256 := version.S3Key, version.BlobSHA256
+	encoding, storedSize := version.ContentEncoding, version.StoredSizeInBytes
+	if blob != nil {
+		blobModel := &model.Blob{}
+		err = tx.QueryRowxContext(ctx, AcquireBlobTemplate, blob.SHA256, blob.S3Key, blob.SizeInBytes, blob.CRC32C, blob.ContentEncoding, blob.StoredSizeInBytes).StructScan(blobModel)
+		if err != nil {
+			return nil, err
+		}
+		s3Key, blobSHA256 = blobModel.S3Key, blobModel.SHA256
+		encoding, storedSize = blobModel.ContentEncoding, blobModel.StoredSizeInBytes
+	} else if blobSHA256 != "" {
+		blobModel := &model.Blob{}
+		if err := tx.QueryRowxContext(ctx, ReferenceBlobTemplate, blobSHA256).StructScan(blobModel); err != nil {
+			return nil, err
+		}
+		s3Key = blobModel.S3Key
+		encoding, storedSize = blobModel.ContentEncoding, blobModel.StoredSizeInBytes
+	}
+
+	versionModel := &model.FileVersion{}
+	err = tx.QueryRowxContext(ctx, InsertFileVersionTemplate,
+		version.FileID,
+		s3Key,
+		version.SizeInBytes,
+		version.MimeType,
+		version.ChecksumSHA256,
+		version.ChecksumCRC32C,
+		sql.NullString{String: blobSHA256, Valid: blobSHA256 != ""},
+		model.PtrToNullInt64(version.CreatedBy),
+		encoding,
+		storedSize,
+		version.ScanStatus,
+		version.ScanSignature,
+		model.PtrToNullTime(version.ScannedAt),
+	).StructScan(versionModel)
+	if err != nil {
+		return nil, err
+	}
+
+	verifiedAt := sql.NullTime{Time: time.Now(), Valid: true}
+	fileModel := &model.File{}
+	err = tx.QueryRowxContext(ctx, SetCurrentVersionTemplate, version.FileID, versionModel.VersionNumber, verifiedAt, entity.Loaded, entity.Quarantined, entity.ScanInfected).StructScan(fileModel)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return fileModel.ModelToEntity(), nil
+}
+
+func (fr *fileRepository) ListVersions(ctx context.Context, fileID int64) ([]*entity.FileVersion, error) {
+	return fr.queryVersions(ctx, ListFileVersionsTemplate, fileID)
+}
+
+func (fr *fileRepository) GetVersion(ctx context.Context, fileID int64, versionNumber int) (*entity.FileVersion, error) {
+	versionModel := &model.FileVersion{}
+
+	err := fr.conn.QueryRowxContext(ctx, GetFileVersionTemplate, fileID, versionNumber).StructScan(versionModel)
+	if err != nil {
+		return nil, err
+	}
+	return versionModel.ModelToEntity(), nil
+}
+
+// RestoreVersion делает указанную версию текущей. Контрольная сумма восстановленной
+// версии будет перепроверена при следующем проходе проверки.
+func (fr *fileRepository) RestoreVersion(ctx context.Context, fileID int64, versionNumber int) (*entity.File, error) {
+	fileModel := &model.File{}
+
+	err := fr.conn.QueryRowxContext(ctx, SetCurrentVersionTemplate, fileID, versionNumber, sql.NullTime{}, entity.Loaded, entity.Quarantined, entity.ScanInfected).StructScan(fileModel)
+	if err != nil {
+		return nil, err
+	}
+	return fileModel.ModelToEntity(), nil
+}
+
+// PruneVersions удаляет нетекущие версии файла, не попавшие в keep последних и созданные до createdBefore.
+// Нулевые keep и createdBefore отключают соответствующее условие.
+func (fr *fileRepository) PruneVersions(ctx context.Context, fileID int64, keep int, createdBefore time.Time) ([]*entity.FileVersion, error) {
+	before := sql.NullTime{Time: createdBefore, Valid: !createdBefore.IsZero()}
+	return fr.queryVersions(ctx, PruneFileVersionsTemplate, fileID, keep, before)
+}
+
+func (fr *fileRepository) queryVersions(ctx context.Context, query string, args ...any) ([]*entity.FileVersion, error) {
+	return selectVersions(ctx, fr.conn, query, args...)
+}
+
+func selectVersions(ctx context.Context, q sqlx.QueryerContext, query string, args ...any) ([]*entity.FileVersion, error) {
+	rows, err := q.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var versions []*entity.FileVersion
+	for rows.Next() {
+		versionModel := &model.FileVersion{}
+		if err := rows.StructScan(versionModel); err != nil {
+			return nil, err
+		}
+		versions = append(versions, versionModel.ModelToEntity())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func (fr *fileRepository) ListUnreferencedBlobs(ctx context.Context, updatedBefore time.Time, limit int) ([]*entity.Blob, error) {
+	rows, err := fr.conn.QueryxContext(ctx, ListUnreferencedBlobsTemplate, updatedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var blobs []*entity.Blob
+	for rows.Next() {
+		blobModel := &model.Blob{}
+		if err := rows.StructScan(blobModel); err != nil {
+			return nil, err
+		}
+		blobs = append(blobs, blobModel.ModelToEntity())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return blobs, nil
+}
+
+func (fr *fileRepository) DeleteUnreferencedBlob(ctx context.Context, sha256 string) (bool, error) {
+	var deleted string
+	err := fr.conn.QueryRowxContext(ctx, DeleteUnreferencedBlobTemplate, sha256).Scan(&deleted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (fr *fileRepository) DeleteByID(ctx context.Context, userID, fileID int64) (*entity.File, error) {
+	fileModel := &model.File{}
+
+	err := fr.conn.QueryRowxContext(ctx, DeleteFileByIDTemplate, fileID, userID).StructScan(fileModel)
+	if err != nil {
+		return nil, err
+	}
+	return fileModel.ModelToEntity(), nil
+}
+
+func (fr *fileRepository) ListByFolder(ctx context.Context, userID int64, folderID *int64) ([]*entity.File, error) {
+	return fr.queryFiles(ctx, ListFolderFilesTemplate, userID, model.PtrToNullInt64(folderID))
+}
+
+func (fr *fileRepository) ListInFolderTree(ctx context.Context, userID, folderID int64) ([]*entity.File, error) {
+	return fr.queryFiles(ctx, ListFolderTreeFilesTemplate, userID, folderID)
+}
+
+func (fr *fileRepository) MoveToFolder(ctx context.Context, userID, fileID int64, folderID *int64) (*entity.File, error) {
+	fileModel := &model.File{}
+
+	err := fr.conn.QueryRowxContext(ctx, MoveFileTemplate, model.PtrToNullInt64(folderID), fileID, userID).StructScan(fileModel)
+	if err != nil {
+		return nil, err
+	}
+	return fileModel.ModelToEntity(), nil
+}
+
+func (fr *fileRepository) Trash(ctx context.Context, userID, fileID int64) (*entity.File, error) {
+	fileModel := &model.File{}
+
+	err := fr.conn.QueryRowxContext(ctx, TrashFileTemplate, fileID, userID, entity.Removed).StructScan(fileModel)
+	if err != nil {
+		return nil, err
+	}
+	return fileModel.ModelToEntity(), nil
+}
+
+// RestoreFromTrash возвращает файл из корзины. Файл с загруженной версией снова становится Loaded,
+// файл без содержимого — Pending.
+func (fr *fileRepository) RestoreFromTrash(ctx context.Context, userID, fileID int64) (*entity.File, error) {
+	fileModel := &model.File{}
+
+	err := fr.conn.QueryRowxContext(ctx, RestoreFileFromTrashTemplate, fileID, userID, entity.Pending, entity.Loaded, entity.Quarantined, entity.ScanInfected).StructScan(fileModel)
+	if err != nil {
+		return nil, err
+	}
+	return fileModel.ModelToEntity(), nil
+}
+
+func (fr *fileRepository) ListTrash(ctx context.Context, userID int64) ([]*entity.File, error) {
+	return fr.queryFiles(ctx, ListTrashTemplate, userID)
+}
+
+func (fr *fileRepository) ListExpiredTrash(ctx context.Context, deletedBefore time.Time, limit int) ([]*entity.File, error) {
+	return fr.queryFiles(ctx, ListExpiredTrashTemplate, deletedBefore, limit)
+}
+
+func (fr *fileRepository) queryFiles(ctx context.Context, query string, args ...any) ([]*entity.File, error) {
+	rows, err := fr.conn.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var files []*entity.File
+	for rows.Next() {
+		fileModel := &model.File{}
+		if err := rows.StructScan(fileModel); err != nil {
+			return nil, err
+		}
+		files = append(files, fileModel.ModelToEntity())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
@@ -0,0 +1,51 @@
+package file
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+	"meemo/internal/infrastructure/storage/model"
+	"strings"
+	"unicode"
+)
+
+func (fr *fileRepository) Search(ctx context.Context, userID int64, opts repository.SearchOptions) ([]*entity.FileSearchResult, error) {
+	rows, err := fr.conn.QueryxContext(ctx, SearchFilesTemplate, userID, prefixTSQuery(opts.Query), opts.Query, opts.IncludePublic, opts.Limit, entity.Quarantined)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []*entity.FileSearchResult
+	for rows.Next() {
+		resultModel := &model.FileSearchResult{}
+		if err := rows.StructScan(resultModel); err != nil {
+			return nil, err
+		}
+		results = append(results, resultModel.ModelToEntity())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (fr *fileRepository) SetSearchText(ctx context.Context, fileID int64, text string) error {
+	_, err := fr.conn.ExecContext(ctx, SetSearchTextTemplate, text, fileID)
+	return err
+}
+
+// prefixTSQuery превращает пользовательский запрос в tsquery, где каждое слово ищется как префикс.
+// В запрос попадают только буквы и цифры, поэтому синтаксис tsquery из ввода не протекает.
+func prefixTSQuery(query string) string {
+	terms := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
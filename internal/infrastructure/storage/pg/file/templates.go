@@ -1,32 +1,65 @@
 package file
 
+const fileColumns = `f.id, f.user_id, f.original_name, f.mime_type, f.size_in_bytes,
+       f.s3_bucket, f.s3_key, f.status, f.created_at, f.updated_at, f.is_public,
+       f.checksum_sha256, f.checksum_crc32c, f.checksum_verified_at, f.checksum_failed, f.blob_sha256,
+       f.content_encoding, f.stored_size_in_bytes,
+       f.scan_status, f.scan_signature, f.scanned_at,
+       f.folder_id, f.current_version_id, f.deleted_at, f.expires_at,
+       f.tags, f.metadata`
+
+const blobColumns = `sha256, s3_key, size_in_bytes, crc32c, ref_count, content_encoding, stored_size_in_bytes,
+       created_at, updated_at`
+
+const versionColumns = `v.id, v.file_id, v.version_number, v.s3_key, v.size_in_bytes, v.mime_type,
+       v.checksum_sha256, v.checksum_crc32c, v.blob_sha256, v.content_encoding, v.stored_size_in_bytes,
+       v.scan_status, v.scan_signature, v.scanned_at,
+       v.created_by, v.created_at`
+
+const objectOperationColumns = `id, operation, s3_key, source_key, attempts, last_error, available_at, dead_at, created_at`
+
+// releaseVersionBlobs освобождает ссылки на блобы у всех версий удаленных файлов из CTE deleted.
+const releaseVersionBlobs = `released AS (
+    UPDATE blobs b
+    SET ref_count = b.ref_count - r.refs, updated_at = CURRENT_TIMESTAMP
+    FROM (SELECT v.blob_sha256, COUNT(*) AS refs
+          FROM file_versions v
+          INNER JOIN deleted d ON v.file_id = d.id
+          WHERE v.blob_sha256 IS NOT NULL
+          GROUP BY v.blob_sha256) r
+    WHERE b.sha256 = r.blob_sha256
+)`
+
 const (
 	SaveFileTemplate = `
-INSERT INTO files (user_id, original_name, mime_type, size_in_bytes, s3_bucket, s3_key, status, created_at, updated_at, is_public)
-VALUES (:user_id, :original_name, :mime_type, :size_in_bytes, :s3_bucket, :s3_key, :status, :created_at, :updated_at, :is_public) 
+INSERT INTO files (user_id, original_name, mime_type, size_in_bytes, s3_bucket, s3_key, status, created_at, updated_at, is_public, folder_id, expires_at, tags, metadata)
+VALUES (:user_id, :original_name, :mime_type, :size_in_bytes, :s3_bucket, :s3_key, :status, :created_at, :updated_at, :is_public, :folder_id, :expires_at, :tags, :metadata)
 RETURNING id;`
 
 	DeleteFileTemplate = `
-DELETE FROM files f
-USING users u
-WHERE f.user_id = u.id 
-  AND u.email = $1 
-  AND f.original_name = $2
-RETURNING f.id;`
+WITH deleted AS (
+    DELETE FROM files f
+    USING users u
+    WHERE f.user_id = u.id
+      AND u.email = $1
+      AND f.original_name = $2
+      AND f.folder_id IS NULL
+      AND f.deleted_at IS NULL
+    RETURNING f.id
+), ` + releaseVersionBlobs + `
+SELECT id FROM deleted;`
 
 	GetFileTemplate = `
-SELECT f.id, f.user_id, f.original_name, f.mime_type, f.size_in_bytes, 
-       f.s3_bucket, f.s3_key, f.status, f.created_at, f.updated_at, f.is_public
+SELECT ` + fileColumns + `
 FROM files f
 INNER JOIN users u ON f.user_id = u.id
-WHERE f.id = $1`
+WHERE f.id = $1 AND f.deleted_at IS NULL`
 
 	GetFileByOriginalNameAndUserEmailTemplate = `
-SELECT f.id, f.user_id, f.original_name, f.mime_type, f.size_in_bytes, 
-       f.s3_bucket, f.s3_key, f.status, f.created_at, f.updated_at, f.is_public
+SELECT ` + fileColumns + `
 FROM files f
 INNER JOIN users u ON f.user_id = u.id
-WHERE u.email = $1 AND f.original_name = $2`
+WHERE u.email = $1 AND f.original_name = $2 AND f.folder_id IS NULL AND f.deleted_at IS NULL`
 
 	ChangeVisibilityTemplate = `
 UPDATE files f
@@ -35,8 +68,51 @@ FROM users u
 WHERE f.user_id = u.id 
   AND u.email = $2 
   AND f.original_name = $3
+  AND f.folder_id IS NULL
+  AND f.deleted_at IS NULL
+  AND (NOT $1 OR f.status <> $4)
 RETURNING f.id, f.is_public, f.updated_at;`
 
+	ChangeVisibilityByIDTemplate = `
+UPDATE files f
+SET is_public = $3, updated_at = CURRENT_TIMESTAMP
+WHERE f.id = $1 AND f.user_id = $2 AND f.deleted_at IS NULL AND (NOT $3 OR f.status <> $4)
+RETURNING ` + fileColumns + `;`
+
+	LockUserByEmailTemplate = `
+SELECT id FROM users WHERE email = $1 FOR UPDATE;`
+
+	// Занятое получателем место считается так же, как в GetTotalUsedSpaceTemplate;
+	// размер файла включает все его версии.
+	TransferUsageTemplate = `
+SELECT COALESCE((SELECT SUM(f.size_in_bytes) FROM files f WHERE f.user_id = $1), 0)
+     + COALESCE((SELECT SUM(v.size_in_bytes)
+                 FROM file_versions v
+                 INNER JOIN files f ON v.file_id = f.id
+                 WHERE f.user_id = $1
+                   AND v.id IS DISTINCT FROM f.current_version_id), 0) AS used_bytes,
+       (SELECT f.size_in_bytes + COALESCE((SELECT SUM(v.size_in_bytes)
+                                           FROM file_versions v
+                                           WHERE v.file_id = f.id
+                                             AND v.id IS DISTINCT FROM f.current_version_id), 0)
+        FROM files f
+        WHERE f.id = $2) AS file_bytes;`
+
+	// Файл переходит в корень получателя: папки прежнего владельца ему недоступны.
+	TransferFileTemplate = `
+UPDATE files f
+SET user_id = $3, folder_id = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE f.id = $1 AND f.user_id = $2 AND f.deleted_at IS NULL
+RETURNING ` + fileColumns + `;`
+
+	DeleteFileSharesTemplate = `
+DELETE FROM file_shares WHERE file_id = $1;`
+
+	RevokeFileShareLinksTemplate = `
+UPDATE share_links
+SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+WHERE file_id = $1;`
+
 	SetStatusTemplate = `
 UPDATE files f
 SET status = $1, updated_at = CURRENT_TIMESTAMP
@@ -44,6 +120,9 @@ FROM users u
 WHERE f.user_id = u.id 
   AND u.email = $2 
   AND f.original_name = $3
+  AND f.folder_id IS NULL
+  AND f.deleted_at IS NULL
+  AND f.status <> $4
 RETURNING f.id, f.status, f.updated_at;`
 
 	RenameFileTemplate = `
@@ -53,19 +132,377 @@ FROM users u
 WHERE f.user_id = u.id 
   AND u.email = $2 
   AND f.original_name = $3
+  AND f.folder_id IS NULL
+  AND f.deleted_at IS NULL
 RETURNING f.id, f.original_name, f.updated_at;`
 
+	RenameFileByIDTemplate = `
+UPDATE files f
+SET original_name = $1, updated_at = CURRENT_TIMESTAMP
+WHERE f.id = $2 AND f.deleted_at IS NULL
+RETURNING ` + fileColumns + `;`
+
+	ListSharedWithUserTemplate = `
+SELECT ` + fileColumns + `, o.email AS owner_email, s.permission
+FROM file_shares s
+INNER JOIN files f ON s.file_id = f.id
+INNER JOIN users o ON f.user_id = o.id
+WHERE s.user_id = $1 AND f.deleted_at IS NULL
+ORDER BY f.original_name, f.id;`
+
 	ListUserFilesTemplate = `
-SELECT f.id, f.user_id, f.original_name, f.mime_type, f.size_in_bytes, 
-       f.s3_bucket, f.s3_key, f.status, f.created_at, f.updated_at, f.is_public
+SELECT ` + fileColumns + `
 FROM files f
 INNER JOIN users u ON f.user_id = u.id
-WHERE u.email = $1
+WHERE u.email = $1 AND f.deleted_at IS NULL
 ORDER BY f.created_at DESC;`
 
+	// ListFilesPageTemplate дополняется условиями, колонкой и направлением сортировки в buildListPageQuery.
+	ListFilesPageTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE %s
+ORDER BY %s %s, f.id %s
+LIMIT $%d;`
+
+	// SearchFilesTemplate ищет по tsvector с префиксными лексемами ($2) и нечетко по имени через pg_trgm ($3).
+	// Чужие публичные файлы в карантине ($6) и с истекшим сроком не попадают в выдачу: их не отдает ни один запрос.
+	SearchFilesTemplate = `
+WITH query AS (
+    SELECT to_tsquery('simple', $2) AS ts, $3::text AS raw
+)
+SELECT ` + fileColumns + `,
+       ts_rank_cd(f.search_vector, q.ts) + word_similarity(q.raw, f.original_name) AS rank
+FROM files f, query q
+WHERE f.deleted_at IS NULL
+  AND (f.user_id = $1 OR ($4 AND f.is_public AND f.status <> $6
+                           AND (f.expires_at IS NULL OR f.expires_at > CURRENT_TIMESTAMP)))
+  AND (f.search_vector @@ q.ts OR q.raw <% f.original_name)
+ORDER BY rank DESC, f.id
+LIMIT $5;`
+
+	AddFileTagsTemplate = `
+UPDATE files f
+SET tags = ARRAY(SELECT DISTINCT t FROM unnest(f.tags || $3::text[]) t ORDER BY t), updated_at = CURRENT_TIMESTAMP
+WHERE f.id = $1 AND f.user_id = $2 AND f.deleted_at IS NULL
+RETURNING ` + fileColumns + `;`
+
+	RemoveFileTagsTemplate = `
+UPDATE files f
+SET tags = ARRAY(SELECT t FROM unnest(f.tags) t WHERE t <> ALL($3::text[]) ORDER BY t), updated_at = CURRENT_TIMESTAMP
+WHERE f.id = $1 AND f.user_id = $2 AND f.deleted_at IS NULL
+RETURNING ` + fileColumns + `;`
+
+	ReplaceFileTagsTemplate = `
+UPDATE files f
+SET tags = ARRAY(SELECT DISTINCT t FROM unnest($3::text[]) t ORDER BY t), updated_at = CURRENT_TIMESTAMP
+WHERE f.id = $1 AND f.user_id = $2 AND f.deleted_at IS NULL
+RETURNING ` + fileColumns + `;`
+
+	ListUserTagsTemplate = `
+SELECT t AS tag, COUNT(*) AS count
+FROM files f, unnest(f.tags) t
+WHERE f.user_id = $1 AND f.deleted_at IS NULL
+GROUP BY t
+ORDER BY count DESC, tag;`
+
+	// UpdateFileMetadataTemplate дописывает ключи из $3 и удаляет ключи из $4; при $5 прежние метаданные отбрасываются.
+	UpdateFileMetadataTemplate = `
+UPDATE files f
+SET metadata = (CASE WHEN $5 THEN '{}'::jsonb ELSE f.metadata END || $3::jsonb) - $4::text[],
+    updated_at = CURRENT_TIMESTAMP
+WHERE f.id = $1 AND f.user_id = $2 AND f.deleted_at IS NULL
+RETURNING ` + fileColumns + `;`
+
+	SetSearchTextTemplate = `
+UPDATE files
+SET search_text = $1
+WHERE id = $2;`
+
+	GetUserPlanTemplate = `
+SELECT plan
+FROM users
+WHERE id = $1;`
+
+	GetUserEmailTemplate = `
+SELECT email
+FROM users
+WHERE id = $1;`
+
 	GetTotalUsedSpaceTemplate = `
-SELECT COALESCE(SUM(f.size_in_bytes), 0)
+SELECT COALESCE((SELECT SUM(f.size_in_bytes)
+                 FROM files f
+                 INNER JOIN users u ON f.user_id = u.id
+                 WHERE u.email = $1), 0)
+     + COALESCE((SELECT SUM(v.size_in_bytes)
+                 FROM file_versions v
+                 INNER JOIN files f ON v.file_id = f.id
+                 INNER JOIN users u ON f.user_id = u.id
+                 WHERE u.email = $1
+                   AND v.id IS DISTINCT FROM f.current_version_id), 0);`
+
+	LockFileTemplate = `
+SELECT id FROM files WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;`
+
+	InsertFileVersionTemplate = `
+INSERT INTO file_versions AS v (file_id, version_number, s3_key, size_in_bytes, mime_type,
+                                checksum_sha256, checksum_crc32c, blob_sha256, created_by,
+                                content_encoding, stored_size_in_bytes, scan_status, scan_signature, scanned_at)
+SELECT f.id, COALESCE(MAX(pv.version_number), 0) + 1, $2::text, $3::bigint, COALESCE(NULLIF($4::text, ''), f.mime_type),
+       $5::text, $6::text, $7::text, $8::bigint, $9::text, $10::bigint, $11::text, $12::text, $13::timestamptz
 FROM files f
-INNER JOIN users u ON f.user_id = u.id
-WHERE u.email = $1;`
+LEFT JOIN file_versions pv ON pv.file_id = f.id
+WHERE f.id = $1
+GROUP BY f.id, f.mime_type
+RETURNING ` + versionColumns + `;`
+
+	SetCurrentVersionTemplate = `
+UPDATE files f
+SET current_version_id = v.id, s3_key = v.s3_key, size_in_bytes = v.size_in_bytes, mime_type = v.mime_type,
+    checksum_sha256 = v.checksum_sha256, checksum_crc32c = v.checksum_crc32c, blob_sha256 = v.blob_sha256,
+    content_encoding = v.content_encoding, stored_size_in_bytes = v.stored_size_in_bytes,
+    scan_status = v.scan_status, scan_signature = v.scan_signature, scanned_at = v.scanned_at,
+    checksum_verified_at = $3, checksum_failed = false, updated_at = CURRENT_TIMESTAMP,
+    status = CASE WHEN v.scan_status = $6 THEN $5 ELSE $4 END
+FROM file_versions v
+WHERE f.id = $1 AND v.file_id = f.id AND v.version_number = $2
+RETURNING ` + fileColumns + `;`
+
+	ListFileVersionsTemplate = `
+SELECT ` + versionColumns + `
+FROM file_versions v
+WHERE v.file_id = $1
+ORDER BY v.version_number DESC;`
+
+	GetFileVersionTemplate = `
+SELECT ` + versionColumns + `
+FROM file_versions v
+WHERE v.file_id = $1 AND v.version_number = $2;`
+
+	PruneFileVersionsTemplate = `
+WITH ranked AS (
+    SELECT pv.id, ROW_NUMBER() OVER (ORDER BY pv.version_number DESC) AS rn
+    FROM file_versions pv
+    WHERE pv.file_id = $1
+), deleted AS (
+    DELETE FROM file_versions v
+    USING ranked r, files f
+    WHERE v.id = r.id
+      AND f.id = v.file_id
+      AND v.id IS DISTINCT FROM f.current_version_id
+      AND ($2::int = 0 OR r.rn > $2::int)
+      AND ($3::timestamptz IS NULL OR v.created_at < $3::timestamptz)
+    RETURNING ` + versionColumns + `
+), released AS (
+    UPDATE blobs b
+    SET ref_count = b.ref_count - r.refs, updated_at = CURRENT_TIMESTAMP
+    FROM (SELECT blob_sha256, COUNT(*) AS refs
+          FROM deleted
+          WHERE blob_sha256 IS NOT NULL
+          GROUP BY blob_sha256) r
+    WHERE b.sha256 = r.blob_sha256
+)
+SELECT * FROM deleted ORDER BY version_number;`
+
+	GetFileByNameInFolderTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.user_id = $1 AND f.folder_id IS NOT DISTINCT FROM $2 AND f.original_name = $3 AND f.deleted_at IS NULL;`
+
+	ListFilesForScrubTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.checksum_sha256 <> ''
+  AND (f.checksum_verified_at IS NULL OR f.checksum_verified_at < $1)
+ORDER BY f.checksum_verified_at NULLS FIRST, f.id
+LIMIT $2;`
+
+	// ListFilesForThumbnailsTemplate выбирает загруженные изображения, у текущей версии которых
+	// миниатюр меньше $2: новые загрузки и файлы, для которых появились новые размеры.
+	ListFilesForThumbnailsTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.status = $1
+  AND f.deleted_at IS NULL
+  AND f.current_version_id IS NOT NULL
+  AND f.mime_type = ANY ($2)
+  AND (SELECT COUNT(*)
+       FROM thumbnails t
+       WHERE t.file_id = f.id
+         AND t.version_id = f.current_version_id) < $3
+ORDER BY f.updated_at DESC, f.id DESC
+LIMIT $4;`
+
+	MarkChecksumVerifiedTemplate = `
+UPDATE files
+SET checksum_verified_at = CURRENT_TIMESTAMP, checksum_failed = $1
+WHERE id = $2;`
+
+	GetBlobTemplate = `
+SELECT ` + blobColumns + `
+FROM blobs
+WHERE sha256 = $1;`
+
+	AcquireBlobTemplate = `
+INSERT INTO blobs (sha256, s3_key, size_in_bytes, crc32c, ref_count, content_encoding, stored_size_in_bytes)
+VALUES ($1, $2, $3, $4, 1, $5, $6)
+ON CONFLICT (sha256) DO UPDATE
+SET ref_count = blobs.ref_count + 1, updated_at = CURRENT_TIMESTAMP
+RETURNING ` + blobColumns + `;`
+
+	ReferenceBlobTemplate = `
+UPDATE blobs
+SET ref_count = ref_count + 1, updated_at = CURRENT_TIMESTAMP
+WHERE sha256 = $1
+RETURNING ` + blobColumns + `;`
+
+	ListUnreferencedBlobsTemplate = `
+SELECT ` + blobColumns + `
+FROM blobs
+WHERE ref_count = 0 AND updated_at < $1
+ORDER BY updated_at
+LIMIT $2;`
+
+	DeleteUnreferencedBlobTemplate = `
+DELETE FROM blobs
+WHERE sha256 = $1 AND ref_count = 0
+RETURNING sha256;`
+
+	DeleteFileByIDTemplate = `
+WITH deleted AS (
+    DELETE FROM files f
+    WHERE f.id = $1 AND f.user_id = $2
+    RETURNING ` + fileColumns + `
+), ` + releaseVersionBlobs + `
+SELECT * FROM deleted;`
+
+	ListFolderFilesTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.user_id = $1 AND f.folder_id IS NOT DISTINCT FROM $2 AND f.deleted_at IS NULL
+ORDER BY f.original_name;`
+
+	ListFolderTreeFilesTemplate = `
+WITH RECURSIVE tree AS (
+    SELECT id FROM folders WHERE id = $2 AND user_id = $1
+    UNION ALL
+    SELECT fo.id FROM folders fo INNER JOIN tree t ON fo.parent_id = t.id
+)
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.user_id = $1 AND f.folder_id IN (SELECT id FROM tree)
+ORDER BY f.id;`
+
+	MoveFileTemplate = `
+UPDATE files f
+SET folder_id = $1, updated_at = CURRENT_TIMESTAMP
+WHERE f.id = $2 AND f.user_id = $3 AND f.deleted_at IS NULL
+RETURNING ` + fileColumns + `;`
+
+	TrashFileTemplate = `
+UPDATE files f
+SET status = $3, deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE f.id = $1 AND f.user_id = $2 AND f.deleted_at IS NULL
+RETURNING ` + fileColumns + `;`
+
+	RestoreFileFromTrashTemplate = `
+UPDATE files f
+SET status = CASE WHEN f.current_version_id IS NULL THEN $3 WHEN f.scan_status = $6 THEN $5 ELSE $4 END,
+    deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE f.id = $1 AND f.user_id = $2 AND f.deleted_at IS NOT NULL
+RETURNING ` + fileColumns + `;`
+
+	ListTrashTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.user_id = $1 AND f.deleted_at IS NOT NULL
+ORDER BY f.deleted_at DESC;`
+
+	ListExpiredTrashTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.deleted_at < $1
+ORDER BY f.deleted_at
+LIMIT $2;`
+
+	SetFileExpiryTemplate = `
+UPDATE files f
+SET expires_at = $3, updated_at = CURRENT_TIMESTAMP
+WHERE f.id = $1 AND f.user_id = $2 AND f.deleted_at IS NULL
+RETURNING ` + fileColumns + `;`
+
+	// ListExpiredFilesTemplate отбирает и файлы в корзине: срок хранения действует и там.
+	ListExpiredFilesTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.expires_at <= $1
+ORDER BY f.expires_at
+LIMIT $2;`
+
+	// DeleteExpiredFileTemplate удаляет файл, только если срок не продлили после выборки,
+	// и записывает удаление в file_deletions.
+	DeleteExpiredFileTemplate = `
+WITH deleted AS (
+    DELETE FROM files f
+    WHERE f.id = $1 AND f.expires_at <= $2
+    RETURNING ` + fileColumns + `
+), ` + releaseVersionBlobs + `, recorded AS (
+    INSERT INTO file_deletions (file_id, user_id, original_name, size_in_bytes, reason, expires_at)
+    SELECT id, user_id, original_name, size_in_bytes, $3, expires_at FROM deleted
+)
+SELECT * FROM deleted;`
+
+	// ListStoredObjectsTemplate перечисляет все объекты S3, на которые ссылается база. Содержимое
+	// текущей версии помечается как file ($1), остальные версии — как version ($2).
+	// Версии с дедупликацией хранятся в блобах и отдельно не перечисляются.
+	ListStoredObjectsTemplate = `
+SELECT v.s3_key, CASE WHEN f.current_version_id = v.id THEN $1 ELSE $2 END AS kind,
+       v.stored_size_in_bytes AS size_in_bytes, v.file_id
+FROM file_versions v
+INNER JOIN files f ON v.file_id = f.id
+WHERE v.blob_sha256 IS NULL AND v.s3_key <> ''
+UNION ALL
+SELECT f.s3_key, $1, f.stored_size_in_bytes, f.id
+FROM files f
+WHERE f.current_version_id IS NULL AND f.blob_sha256 IS NULL AND f.s3_key <> ''
+UNION ALL
+SELECT b.s3_key, $3, b.stored_size_in_bytes, NULL
+FROM blobs b
+UNION ALL
+SELECT t.s3_key, $4, t.size_in_bytes, t.file_id
+FROM thumbnails t
+WHERE t.s3_key <> '';`
+
+	// ListStalePendingTemplate отбирает файлы, содержимое которых так и не загрузили.
+	ListStalePendingTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.status = $1 AND f.current_version_id IS NULL AND f.updated_at < $2 AND f.deleted_at IS NULL
+ORDER BY f.updated_at;`
+
+	EnqueueObjectOperationTemplate = `
+INSERT INTO object_outbox (operation, s3_key, source_key, available_at)
+VALUES ($1, $2, $3, $4)
+RETURNING ` + objectOperationColumns + `;`
+
+	// ClaimObjectOperationsTemplate выбирает готовые операции и откладывает их до $2, чтобы
+	// другой экземпляр не взял их, пока они выполняются.
+	ClaimObjectOperationsTemplate = `
+UPDATE object_outbox
+SET attempts = attempts + 1, available_at = $2
+WHERE id IN (SELECT id
+             FROM object_outbox
+             WHERE dead_at IS NULL AND available_at <= $1
+             ORDER BY available_at, id
+             LIMIT $3 FOR UPDATE SKIP LOCKED)
+RETURNING ` + objectOperationColumns + `;`
+
+	DeleteObjectOperationsTemplate = `
+DELETE FROM object_outbox WHERE id = ANY($1);`
+
+	RetryObjectOperationTemplate = `
+UPDATE object_outbox SET last_error = $2, available_at = $3 WHERE id = $1;`
+
+	DeadLetterObjectOperationTemplate = `
+UPDATE object_outbox SET last_error = $2, dead_at = CURRENT_TIMESTAMP WHERE id = $1;`
 )
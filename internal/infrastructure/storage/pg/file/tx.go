@@ -0,0 +1,24 @@
+package file
+
+import (
+	"context"
+	"meemo/internal/domain/file/repository"
+	"meemo/internal/infrastructure/storage/sqlstore"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// InTx выполняет fn в одной транзакции. Методы, которые сами открывают транзакцию, внутри нее
+// работают в точке сохранения.
+func (fr *fileRepository) InTx(ctx context.Context, fn func(repo repository.FileRepository) error) error {
+	if fr.tx != nil {
+		return fn(fr)
+	}
+	return sqlstore.InTx(ctx, fr.db, func(tx *sqlx.Tx) error {
+		return fn(&fileRepository{conn: tx, db: fr.db, tx: tx})
+	})
+}
+
+func (fr *fileRepository) beginTx(ctx context.Context) (*sqlstore.LocalTx, error) {
+	return sqlstore.BeginLocal(ctx, fr.db, fr.tx)
+}
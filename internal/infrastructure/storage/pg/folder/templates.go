@@ -0,0 +1,70 @@
+package folder
+
+const folderColumns = `id, user_id, parent_id, name, created_at, updated_at`
+
+const (
+	CreateFolderTemplate = `
+INSERT INTO folders (user_id, parent_id, name)
+VALUES ($1, $2, $3)
+RETURNING ` + folderColumns + `;`
+
+	GetFolderTemplate = `
+SELECT ` + folderColumns + `
+FROM folders
+WHERE id = $1 AND user_id = $2;`
+
+	RenameFolderTemplate = `
+UPDATE folders
+SET name = $1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $2 AND user_id = $3
+RETURNING ` + folderColumns + `;`
+
+	// Папку нельзя переместить внутрь самой себя или своих потомков.
+	MoveFolderTemplate = `
+UPDATE folders
+SET parent_id = $1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $2 AND user_id = $3
+  AND NOT EXISTS (
+    WITH RECURSIVE tree AS (
+        SELECT id FROM folders WHERE id = $2
+        UNION ALL
+        SELECT fo.id FROM folders fo INNER JOIN tree t ON fo.parent_id = t.id
+    )
+    SELECT 1 FROM tree WHERE id = $1
+  )
+RETURNING ` + folderColumns + `;`
+
+	ListChildFoldersTemplate = `
+SELECT ` + folderColumns + `
+FROM folders
+WHERE user_id = $1 AND parent_id IS NOT DISTINCT FROM $2
+ORDER BY name;`
+
+	// Папки поддерева возвращаются от корня вглубь: родитель всегда идет раньше потомков.
+	ListFolderTreeTemplate = `
+WITH RECURSIVE tree AS (
+    SELECT ` + folderColumns + `, 0 AS depth FROM folders WHERE id = $2 AND user_id = $1
+    UNION ALL
+    SELECT fo.id, fo.user_id, fo.parent_id, fo.name, fo.created_at, fo.updated_at, t.depth + 1
+    FROM folders fo INNER JOIN tree t ON fo.parent_id = t.id
+)
+SELECT ` + folderColumns + `
+FROM tree
+ORDER BY depth, name;`
+
+	DeleteFolderTemplate = `
+DELETE FROM folders
+WHERE id = $1 AND user_id = $2;`
+
+	GetFolderSizeTemplate = `
+WITH RECURSIVE tree AS (
+    SELECT id FROM folders WHERE id = $1 AND user_id = $2
+    UNION ALL
+    SELECT fo.id FROM folders fo INNER JOIN tree t ON fo.parent_id = t.id
+)
+SELECT COALESCE(SUM(f.size_in_bytes), 0) AS size_in_bytes,
+       COUNT(f.id)                        AS file_count,
+       (SELECT COUNT(*) - 1 FROM tree)    AS folder_count
+FROM files f
+WHERE f.folder_id IN (SELECT id FROM tree) AND f.deleted_at IS NULL;`
+)
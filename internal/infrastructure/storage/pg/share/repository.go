@@ -0,0 +1,28 @@
+package share
+
+import (
+	"meemo/internal/domain/share/repository"
+	sqlstore "meemo/internal/infrastructure/storage/sqlstore/share"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var templates = sqlstore.Templates{
+	UpsertShare:             UpsertShareTemplate,
+	ListFileShares:          ListFileSharesTemplate,
+	GetSharePermission:      GetSharePermissionTemplate,
+	DeleteShare:             DeleteShareTemplate,
+	CreateShareLink:         CreateShareLinkTemplate,
+	GetShareLinkByTokenHash: GetShareLinkByTokenHashTemplate,
+	ListShareLinks:          ListShareLinksTemplate,
+	RevokeShareLink:         RevokeShareLinkTemplate,
+	ClaimShareLinkDownload:  ClaimShareLinkDownloadTemplate,
+}
+
+func NewShareRepository(conn *sqlx.DB) repository.ShareRepository {
+	return sqlstore.NewShareRepository(conn, templates)
+}
+
+func NewShareLinkRepository(conn *sqlx.DB) repository.ShareLinkRepository {
+	return sqlstore.NewShareLinkRepository(conn, templates)
+}
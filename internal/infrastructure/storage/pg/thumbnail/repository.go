@@ -0,0 +1,21 @@
+package thumbnail
+
+import (
+	"meemo/internal/domain/thumbnail/repository"
+	sqlstore "meemo/internal/infrastructure/storage/sqlstore/thumbnail"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var templates = sqlstore.Templates{
+	SaveThumbnail:           SaveThumbnailTemplate,
+	GetThumbnail:            GetThumbnailTemplate,
+	ListThumbnailsByVersion: ListThumbnailsByVersionTemplate,
+	ListStaleThumbnails:     ListStaleThumbnailsTemplate,
+	DeleteThumbnail:         DeleteThumbnailTemplate,
+	DeleteThumbnailByKey:    DeleteThumbnailByKeyTemplate,
+}
+
+func NewThumbnailRepository(conn *sqlx.DB) repository.ThumbnailRepository {
+	return sqlstore.NewThumbnailRepository(conn, templates)
+}
@@ -0,0 +1,173 @@
+package file
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Кодеки сжатия совпадают с именами content-coding из HTTP, чтобы сжатое содержимое
+// можно было отдавать клиенту без перепаковки.
+const (
+	EncodingIdentity = ""
+	EncodingZstd     = "zstd"
+	EncodingGzip     = "gzip"
+)
+
+var (
+	ErrUnsupportedEncoding = errors.New("unsupported content encoding")
+	ErrContentSize         = errors.New("content size does not match declared size")
+	errDecodeStopped       = errors.New("decoding stopped")
+)
+
+// CompressionPolicy выбирает кодек для содержимого по его MIME-типу и размеру.
+type CompressionPolicy struct {
+	// MinSize — минимальный размер, начиная с которого содержимое сжимается.
+	MinSize int64
+	// MimeTypes сопоставляет MIME-тип или шаблон вида "text/*" с кодеком.
+	MimeTypes map[string]string
+}
+
+// Encoding возвращает кодек для содержимого или EncodingIdentity, если его не нужно сжимать.
+// Точное совпадение MIME-типа важнее шаблона.
+func (p CompressionPolicy) Encoding(mimeType string, sizeInBytes int64) string {
+	if len(p.MimeTypes) == 0 || sizeInBytes < p.MinSize {
+		return EncodingIdentity
+	}
+
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return EncodingIdentity
+	}
+	if encoding, ok := p.MimeTypes[mediaType]; ok {
+		return encoding
+	}
+	if major, _, ok := strings.Cut(mediaType, "/"); ok {
+		return p.MimeTypes[major+"/*"]
+	}
+	return EncodingIdentity
+}
+
+// ValidateEncoding проверяет, что кодек поддерживается.
+func ValidateEncoding(encoding string) error {
+	switch encoding {
+	case EncodingIdentity, EncodingZstd, EncodingGzip:
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrUnsupportedEncoding, encoding)
+}
+
+func newEncoder(encoding string, w io.Writer) (io.WriteCloser, error) {
+	switch encoding {
+	case EncodingZstd:
+		return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	case EncodingGzip:
+		return gzip.NewWriter(w), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedEncoding, encoding)
+}
+
+func newDecoder(encoding string, r io.Reader) (io.ReadCloser, error) {
+	switch encoding {
+	case EncodingZstd:
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	case EncodingGzip:
+		return gzip.NewReader(r)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedEncoding, encoding)
+}
+
+// PutObjectCompressed сжимает sizeInBytes байт из reader кодеком encoding и сохраняет объект под key.
+// Размер сжатого содержимого заранее неизвестен, а S3 его требует, поэтому оно собирается
+// во временном файле. Если сжатие не уменьшило содержимое, объект сохраняется несжатым.
+// Возвращает кодек, с которым сохранен объект, и размер объекта.
+func PutObjectCompressed(ctx context.Context, client S3Client, key string, reader io.Reader, sizeInBytes int64, encoding string) (string, int64, error) {
+	tmp, err := os.CreateTemp("", "meemo-compress-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	encoder, err := newEncoder(encoding, tmp)
+	if err != nil {
+		return "", 0, err
+	}
+	read, err := io.Copy(encoder, reader)
+	if err != nil {
+		return "", 0, err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", 0, err
+	}
+	if read != sizeInBytes {
+		return "", 0, fmt.Errorf("%w: read %d of %d bytes", ErrContentSize, read, sizeInBytes)
+	}
+
+	storedSize, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return "", 0, err
+	}
+	if storedSize >= sizeInBytes {
+		// Исходное содержимое уже прочитано, поэтому оно восстанавливается из временного файла.
+		decoder, err := newDecoder(encoding, tmp)
+		if err != nil {
+			return "", 0, err
+		}
+		defer func() { _ = decoder.Close() }()
+		if err := client.PutObject(ctx, key, decoder, sizeInBytes); err != nil {
+			return "", 0, err
+		}
+		return EncodingIdentity, sizeInBytes, nil
+	}
+	if err := client.PutObject(ctx, key, tmp, storedSize); err != nil {
+		return "", 0, err
+	}
+	return encoding, storedSize, nil
+}
+
+// GetObjectDecoded пишет в inWriter содержимое объекта, распакованное кодеком encoding.
+// Если inWriter вернет ошибку, чтение объекта прерывается и возвращается эта ошибка.
+func GetObjectDecoded(ctx context.Context, client S3Client, key, encoding string, inWriter io.Writer) error {
+	if encoding == EncodingIdentity {
+		return client.GetObject(ctx, key, inWriter)
+	}
+
+	pr, pw := io.Pipe()
+	fetched := make(chan error, 1)
+	go func() {
+		err := client.GetObject(ctx, key, pw)
+		_ = pw.CloseWithError(err)
+		fetched <- err
+	}()
+
+	decoder, err := newDecoder(encoding, pr)
+	if err == nil {
+		_, err = io.Copy(inWriter, decoder)
+		_ = decoder.Close()
+	}
+	// Если распаковка остановилась раньше конца объекта, чтение из S3 нужно прервать.
+	_ = pr.CloseWithError(errDecodeStopped)
+
+	fetchErr := <-fetched
+	if err != nil {
+		return err
+	}
+	return fetchErr
+}
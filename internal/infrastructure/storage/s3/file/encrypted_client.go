@@ -0,0 +1,214 @@
+package file
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+
+	"meemo/internal/domain/encryption/repository"
+	"meemo/internal/domain/entity"
+	"meemo/internal/infrastructure/crypto"
+	"meemo/internal/infrastructure/logger"
+
+	"go.uber.org/zap"
+)
+
+type EncryptionOptions struct {
+	// EncryptWrites включает шифрование новых объектов. Без него объекты пишутся открытым текстом,
+	// а ранее зашифрованные по-прежнему читаются.
+	EncryptWrites bool
+	ChunkSize     int
+}
+
+// encryptedS3Client шифрует содержимое объектов конвертным шифрованием: у каждого объекта свой
+// случайный ключ данных, который хранится в базе обернутым мастер-ключом. Объекты без ключа
+// в базе считаются записанными до включения шифрования и отдаются как есть.
+type encryptedS3Client struct {
+	inner      S3Client
+	keys       crypto.KeyProvider
+	objectKeys repository.ObjectKeyRepository
+	opts       EncryptionOptions
+	log        logger.Logger
+}
+
+func NewEncryptedS3Client(inner S3Client, keys crypto.KeyProvider, objectKeys repository.ObjectKeyRepository, opts EncryptionOptions, log logger.Logger) S3Client {
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = crypto.DefaultChunkSize
+	}
+	return &encryptedS3Client{
+		inner:      inner,
+		keys:       keys,
+		objectKeys: objectKeys,
+		opts:       opts,
+		log:        log,
+	}
+}
+
+func (c *encryptedS3Client) PutObject(ctx context.Context, key string, reader io.Reader, sizeInBytes int64) error {
+	if !c.opts.EncryptWrites {
+		if err := c.inner.PutObject(ctx, key, reader, sizeInBytes); err != nil {
+			return err
+		}
+		// Объект мог быть зашифрован раньше: его старый ключ больше не подходит.
+		return c.objectKeys.Delete(ctx, key)
+	}
+
+	dataKey, err := crypto.NewDataKey()
+	if err != nil {
+		return err
+	}
+	keyID, wrappedKey, err := c.keys.WrapKey(ctx, dataKey)
+	if err != nil {
+		c.log.Error("failed to wrap data key", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	ciphertext, err := crypto.NewEncryptingReader(dataKey, reader, sizeInBytes, c.opts.ChunkSize)
+	if err != nil {
+		return err
+	}
+
+	if err := c.inner.PutObject(ctx, key, ciphertext, crypto.CiphertextSize(sizeInBytes, c.opts.ChunkSize)); err != nil {
+		return err
+	}
+
+	_, err = c.objectKeys.Save(ctx, &entity.ObjectKey{
+		S3Key:         key,
+		KeyID:         keyID,
+		WrappedKey:    wrappedKey,
+		ChunkSize:     c.opts.ChunkSize,
+		PlaintextSize: sizeInBytes,
+	})
+	if err != nil {
+		c.log.Error("failed to save data key, deleting object", zap.String("key", key), zap.Error(err))
+		// Без ключа объект не расшифровать, поэтому он удаляется вместе с возможным старым ключом.
+		if delErr := c.inner.DeleteObject(ctx, key); delErr != nil {
+			c.log.Error("failed to delete object without data key", zap.String("key", key), zap.Error(delErr))
+		}
+		_ = c.objectKeys.Delete(ctx, key)
+		return err
+	}
+	return nil
+}
+
+func (c *encryptedS3Client) GetObject(ctx context.Context, key string, inWriter io.Writer) error {
+	objectKey, err := c.objectKeys.Get(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.inner.GetObject(ctx, key, inWriter)
+	}
+	if err != nil {
+		return err
+	}
+	return c.decrypt(ctx, objectKey, 0, objectKey.PlaintextSize, inWriter)
+}
+
+func (c *encryptedS3Client) GetObjectRange(ctx context.Context, key string, offset, length int64, inWriter io.Writer) error {
+	objectKey, err := c.objectKeys.Get(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.inner.GetObjectRange(ctx, key, offset, length, inWriter)
+	}
+	if err != nil {
+		return err
+	}
+	if length <= 0 {
+		return nil
+	}
+	if offset < 0 || offset+length > objectKey.PlaintextSize {
+		return fmt.Errorf("range %d-%d is outside of object %s", offset, offset+length-1, key)
+	}
+	return c.decrypt(ctx, objectKey, offset, length, inWriter)
+}
+
+// decrypt читает только блоки шифртекста, покрывающие запрошенный диапазон.
+func (c *encryptedS3Client) decrypt(ctx context.Context, objectKey *entity.ObjectKey, offset, length int64, inWriter io.Writer) error {
+	dataKey, err := c.keys.UnwrapKey(ctx, objectKey.KeyID, objectKey.WrappedKey)
+	if err != nil {
+		c.log.Error("failed to unwrap data key", zap.String("key", objectKey.S3Key), zap.String("keyID", objectKey.KeyID), zap.Error(err))
+		return err
+	}
+	plaintext, err := crypto.NewDecryptingWriter(dataKey, inWriter, objectKey.PlaintextSize, objectKey.ChunkSize, offset, length)
+	if err != nil {
+		return err
+	}
+
+	if offset == 0 && length == objectKey.PlaintextSize {
+		err = c.inner.GetObject(ctx, objectKey.S3Key, plaintext)
+	} else {
+		ctOffset, ctLength := crypto.CiphertextRange(offset, length, objectKey.PlaintextSize, objectKey.ChunkSize)
+		err = c.inner.GetObjectRange(ctx, objectKey.S3Key, ctOffset, ctLength, plaintext)
+	}
+	if err == nil {
+		err = plaintext.Close()
+	}
+	if errors.Is(err, crypto.ErrAuthentication) || errors.Is(err, crypto.ErrSizeMismatch) {
+		c.log.Error("failed to decrypt object", zap.String("key", objectKey.S3Key), zap.Error(err))
+	}
+	return err
+}
+
+func (c *encryptedS3Client) DeleteObject(ctx context.Context, key string) error {
+	if err := c.inner.DeleteObject(ctx, key); err != nil {
+		return err
+	}
+	return c.objectKeys.Delete(ctx, key)
+}
+
+// CopyObject копирует шифртекст как есть, поэтому копия использует тот же ключ данных.
+func (c *encryptedS3Client) CopyObject(ctx context.Context, srcKey, dstKey string) error {
+	if err := c.inner.CopyObject(ctx, srcKey, dstKey); err != nil {
+		return err
+	}
+	if err := c.objectKeys.Copy(ctx, srcKey, dstKey); err != nil {
+		c.log.Error("failed to copy data key", zap.String("srcKey", srcKey), zap.String("dstKey", dstKey), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ListObjects сообщает размер зашифрованных объектов до шифрования, если размер шифртекста
+// совпадает с ожидаемым. Иначе возвращается размер как есть, и расхождение видно вызывающему.
+// Ключ данных ищется для каждого объекта отдельным запросом.
+func (c *encryptedS3Client) ListObjects(ctx context.Context, prefix string, fn func(*ObjectInfo) error) error {
+	return c.inner.ListObjects(ctx, prefix, func(object *ObjectInfo) error {
+		objectKey, err := c.objectKeys.Get(ctx, object.Key)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if err == nil && object.SizeInBytes == crypto.CiphertextSize(objectKey.PlaintextSize, objectKey.ChunkSize) {
+			object.SizeInBytes = objectKey.PlaintextSize
+		}
+		return fn(object)
+	})
+}
+
+func (c *encryptedS3Client) SaveFile(ctx context.Context, fileID int64, fileReader io.Reader, sizeInBytes int64) error {
+	return c.PutObject(ctx, FileKey(fileID), fileReader, sizeInBytes)
+}
+
+func (c *encryptedS3Client) GetFileByID(ctx context.Context, fileID int64, inWriter io.Writer) error {
+	return c.GetObject(ctx, FileKey(fileID), inWriter)
+}
+
+func (c *encryptedS3Client) GetFileByOriginalName(ctx context.Context, userEmail, originalName string, inWriter io.Writer) error {
+	return c.GetObject(ctx, userEmail+originalName, inWriter)
+}
+
+func (c *encryptedS3Client) DeleteFile(ctx context.Context, fileID int64) error {
+	return c.DeleteObject(ctx, FileKey(fileID))
+}
+
+func (c *encryptedS3Client) RenameFile(ctx context.Context, userEmail, originalName, newName string) error {
+	if err := c.CopyObject(ctx, userEmail+originalName, userEmail+newName); err != nil {
+		return err
+	}
+	return c.DeleteObject(ctx, userEmail+originalName)
+}
+
+func (c *encryptedS3Client) CreateBucket(ctx context.Context, bucketName string) error {
+	return c.inner.CreateBucket(ctx, bucketName)
+}
+
+func (c *encryptedS3Client) DeleteBucket(ctx context.Context, bucketName string) error {
+	return c.inner.DeleteBucket(ctx, bucketName)
+}
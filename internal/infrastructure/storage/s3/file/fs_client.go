@@ -0,0 +1,443 @@
+package file
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"meemo/internal/infrastructure/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultFSShardDepth = 2
+	maxFSShardDepth     = 16
+	// Временные файлы старше этого срока остались от прерванных записей и удаляются при запуске.
+	fsStaleTempAge = 24 * time.Hour
+	fsTempDir      = ".tmp"
+	maxFSNameLen   = 255
+)
+
+var (
+	ErrObjectNotFound   = errors.New("object not found")
+	ErrObjectKeyTooLong = errors.New("object key is too long")
+	ErrInvalidRange     = errors.New("invalid object range")
+)
+
+// FSOptions задает хранилище объектов в локальном каталоге Root.
+type FSOptions struct {
+	Root string
+	// ShardDepth — число уровней подкаталогов, названных по два символа SHA-256 ключа,
+	// чтобы в одном каталоге не скапливались миллионы файлов.
+	ShardDepth int
+	// Fsync сбрасывает на диск содержимое объекта перед переименованием и каталог после него,
+	// чтобы записанный объект пережил сбой питания.
+	Fsync bool
+}
+
+// NewFSClient создает хранилище объектов на локальном диске с той же семантикой, что и S3ClientImpl.
+// Объект пишется во временный файл и переименовывается в постоянный путь, поэтому читатели
+// видят либо прежнее содержимое, либо новое целиком.
+func NewFSClient(opts FSOptions, log logger.Logger) (S3Client, error) {
+	if opts.Root == "" {
+		return nil, errors.New("filesystem storage root is not set")
+	}
+	if opts.ShardDepth <= 0 {
+		opts.ShardDepth = DefaultFSShardDepth
+	}
+	if opts.ShardDepth > maxFSShardDepth {
+		return nil, fmt.Errorf("filesystem shard depth must not exceed %d", maxFSShardDepth)
+	}
+	root, err := filepath.Abs(opts.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &fsClient{
+		root:       root,
+		tmpDir:     filepath.Join(root, fsTempDir),
+		shardDepth: opts.ShardDepth,
+		fsync:      opts.Fsync,
+		log:        log,
+	}
+	if err := os.MkdirAll(c.tmpDir, 0o750); err != nil {
+		return nil, err
+	}
+	c.removeStaleTemp()
+	return c, nil
+}
+
+type fsClient struct {
+	root       string
+	tmpDir     string
+	shardDepth int
+	fsync      bool
+	log        logger.Logger
+}
+
+// objectPath возвращает путь объекта: каталоги по префиксу SHA-256 ключа и экранированный ключ
+// в качестве имени файла, по которому ListObjects восстанавливает ключ.
+func (c *fsClient) objectPath(key string) (string, error) {
+	name := url.PathEscape(key)
+	// Имена, начинающиеся с точки, зарезервированы: так называются "." и ".." и каталог временных файлов.
+	if strings.HasPrefix(name, ".") {
+		name = "%2E" + name[1:]
+	}
+	if name == "" || len(name) > maxFSNameLen {
+		return "", fmt.Errorf("%w: %q", ErrObjectKeyTooLong, key)
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	hash := hex.EncodeToString(sum[:])
+	parts := make([]string, 0, c.shardDepth+2)
+	parts = append(parts, c.root)
+	for i := range c.shardDepth {
+		parts = append(parts, hash[2*i:2*i+2])
+	}
+	return filepath.Join(append(parts, name)...), nil
+}
+
+func objectKey(name string) (string, bool) {
+	if strings.HasPrefix(name, ".") {
+		return "", false
+	}
+	key, err := url.PathUnescape(name)
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// writeObject записывает объект во временный файл и атомарно переименовывает его в path.
+func (c *fsClient) writeObject(path string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(c.tmpDir, "object-*")
+	if err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if err := write(tmp); err != nil {
+		return err
+	}
+	if c.fsync {
+		if err := tmp.Sync(); err != nil {
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := c.makeDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	committed = true
+	return c.syncDir(filepath.Dir(path))
+}
+
+// makeDir создает каталог шарда. С включенным fsync на диск сбрасываются и родительские каталоги,
+// в которых появились новые записи.
+func (c *fsClient) makeDir(dir string) error {
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	for parent := filepath.Dir(dir); c.fsync && strings.HasPrefix(parent, c.root); parent = filepath.Dir(parent) {
+		if err := c.syncDir(parent); err != nil {
+			return err
+		}
+		if parent == c.root {
+			break
+		}
+	}
+	return nil
+}
+
+func (c *fsClient) syncDir(dir string) error {
+	if !c.fsync {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = d.Close() }()
+	return d.Sync()
+}
+
+func (c *fsClient) openObject(key string) (*os.File, error) {
+	path, err := c.objectPath(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+	return f, err
+}
+
+func (c *fsClient) removeStaleTemp() {
+	entries, err := os.ReadDir(c.tmpDir)
+	if err != nil {
+		c.log.Warn("failed to read filesystem storage temp directory", zap.String("dir", c.tmpDir), zap.Error(err))
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < fsStaleTempAge {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.tmpDir, entry.Name())); err != nil {
+			c.log.Warn("failed to remove stale temp file", zap.String("name", entry.Name()), zap.Error(err))
+		}
+	}
+}
+
+func (c *fsClient) PutObject(ctx context.Context, key string, reader io.Reader, sizeInBytes int64) error {
+	c.log.Debug("saving object to filesystem", zap.String("key", key), zap.Int64("sizeInBytes", sizeInBytes))
+
+	path, err := c.objectPath(key)
+	if err != nil {
+		return err
+	}
+	err = c.writeObject(path, func(w io.Writer) error {
+		// Как и S3 с ContentLength, записывается ровно sizeInBytes байт, а более короткий поток — ошибка.
+		n, err := io.CopyN(w, &contextReader{ctx: ctx, r: reader}, sizeInBytes)
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("object %s: read %d of %d bytes: %w", key, n, sizeInBytes, io.ErrUnexpectedEOF)
+		}
+		return err
+	})
+	if err != nil {
+		c.log.Error("failed to save object to filesystem", zap.String("key", key), zap.Error(err))
+		return err
+	}
+
+	c.log.Info("object uploaded successfully", zap.String("key", key))
+	return nil
+}
+
+func (c *fsClient) GetObject(ctx context.Context, key string, inWriter io.Writer) error {
+	f, err := c.openObject(key)
+	if err != nil {
+		c.log.Error("failed to get object from filesystem", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := io.Copy(inWriter, &contextReader{ctx: ctx, r: f}); err != nil {
+		c.log.Error("failed to copy object content", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (c *fsClient) GetObjectRange(ctx context.Context, key string, offset, length int64, inWriter io.Writer) error {
+	if length <= 0 {
+		return nil
+	}
+
+	f, err := c.openObject(key)
+	if err != nil {
+		c.log.Error("failed to get object range from filesystem", zap.String("key", key), zap.Int64("offset", offset), zap.Int64("length", length), zap.Error(err))
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	// S3 отвечает InvalidRange, если диапазон начинается за концом объекта.
+	if offset < 0 || offset >= info.Size() {
+		return fmt.Errorf("%w: offset %d, object %s is %d bytes", ErrInvalidRange, offset, key, info.Size())
+	}
+
+	if _, err := io.Copy(inWriter, &contextReader{ctx: ctx, r: io.NewSectionReader(f, offset, length)}); err != nil {
+		c.log.Error("failed to copy object range content", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DeleteObject, как и S3, не считает ошибкой удаление отсутствующего объекта.
+func (c *fsClient) DeleteObject(ctx context.Context, key string) error {
+	path, err := c.objectPath(key)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		c.log.Error("failed to delete object from filesystem", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	if err := c.syncDir(filepath.Dir(path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	c.log.Info("object deleted from filesystem", zap.String("key", key))
+	return nil
+}
+
+func (c *fsClient) CopyObject(ctx context.Context, srcKey, dstKey string) error {
+	dstPath, err := c.objectPath(dstKey)
+	if err != nil {
+		return err
+	}
+	src, err := c.openObject(srcKey)
+	if err != nil {
+		c.log.Error("failed to copy object in filesystem", zap.String("srcKey", srcKey), zap.String("dstKey", dstKey), zap.Error(err))
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	err = c.writeObject(dstPath, func(w io.Writer) error {
+		_, err := io.Copy(w, &contextReader{ctx: ctx, r: src})
+		return err
+	})
+	if err != nil {
+		c.log.Error("failed to copy object in filesystem", zap.String("srcKey", srcKey), zap.String("dstKey", dstKey), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ListObjects обходит все шарды: ключи разбросаны по каталогам хешем, поэтому префикс не сужает обход.
+// Объекты передаются в fn по мере обхода, в порядке путей: по шардам, внутри шарда — по имени файла.
+func (c *fsClient) ListObjects(ctx context.Context, prefix string, fn func(*ObjectInfo) error) error {
+	var fnErr error
+	err := filepath.WalkDir(c.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != c.root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		key, ok := objectKey(entry.Name())
+		if !ok || !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			// Объект удалили во время обхода.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fnErr = fn(&ObjectInfo{
+			Key:          key,
+			SizeInBytes:  info.Size(),
+			LastModified: info.ModTime(),
+		})
+		return fnErr
+	})
+	if err != nil && fnErr == nil {
+		c.log.Error("failed to list objects in filesystem", zap.String("prefix", prefix), zap.Error(err))
+	}
+	return err
+}
+
+func (c *fsClient) SaveFile(ctx context.Context, fileID int64, fileReader io.Reader, sizeInBytes int64) error {
+	return c.PutObject(ctx, FileKey(fileID), fileReader, sizeInBytes)
+}
+
+func (c *fsClient) GetFileByID(ctx context.Context, fileID int64, inWriter io.Writer) error {
+	return c.GetObject(ctx, FileKey(fileID), inWriter)
+}
+
+func (c *fsClient) GetFileByOriginalName(ctx context.Context, userEmail, originalName string, inWriter io.Writer) error {
+	return c.GetObject(ctx, userEmail+originalName, inWriter)
+}
+
+func (c *fsClient) DeleteFile(ctx context.Context, fileID int64) error {
+	return c.DeleteObject(ctx, FileKey(fileID))
+}
+
+func (c *fsClient) RenameFile(ctx context.Context, userEmail, originalName, newName string) error {
+	srcKey := userEmail + originalName
+	dstKey := userEmail + newName
+
+	srcPath, err := c.objectPath(srcKey)
+	if err != nil {
+		return err
+	}
+	dstPath, err := c.objectPath(dstKey)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := c.makeDir(filepath.Dir(dstPath)); err != nil {
+		return err
+	}
+	if err := os.Rename(srcPath, dstPath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = fmt.Errorf("%w: %s", ErrObjectNotFound, srcKey)
+		}
+		c.log.Error("failed to rename file in filesystem", zap.String("srcKey", srcKey), zap.String("dstKey", dstKey), zap.Error(err))
+		return err
+	}
+	if err := c.syncDir(filepath.Dir(dstPath)); err != nil {
+		return err
+	}
+	if err := c.syncDir(filepath.Dir(srcPath)); err != nil {
+		return err
+	}
+
+	c.log.Info("file renamed in filesystem", zap.String("srcKey", srcKey), zap.String("dstKey", dstKey))
+	return nil
+}
+
+// CreateBucket и DeleteBucket не поддерживаются: все объекты хранятся в одном каталоге Root.
+func (c *fsClient) CreateBucket(_ context.Context, bucketName string) error {
+	return fmt.Errorf("create bucket %q: %w", bucketName, errors.ErrUnsupported)
+}
+
+func (c *fsClient) DeleteBucket(_ context.Context, bucketName string) error {
+	return fmt.Errorf("delete bucket %q: %w", bucketName, errors.ErrUnsupported)
+}
+
+// contextReader прерывает чтение после отмены контекста, как это делает клиент S3.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
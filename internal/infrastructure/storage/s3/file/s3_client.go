@@ -2,8 +2,10 @@ package file
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"strconv"
+	"time"
 
 	"meemo/internal/infrastructure/logger"
 
@@ -14,6 +16,16 @@ import (
 )
 
 type S3Client interface {
+	PutObject(ctx context.Context, key string, reader io.Reader, sizeInBytes int64) error
+	GetObject(ctx context.Context, key string, inWriter io.Writer) error
+	// GetObjectRange пишет в inWriter length байт объекта начиная с offset.
+	GetObjectRange(ctx context.Context, key string, offset, length int64, inWriter io.Writer) error
+	DeleteObject(ctx context.Context, key string) error
+	CopyObject(ctx context.Context, srcKey, dstKey string) error
+	// ListObjects вызывает fn для каждого объекта с ключом, начинающимся с prefix, не собирая
+	// список целиком. Порядок стабилен между вызовами: S3 перечисляет объекты в порядке ключей,
+	// файловое хранилище — в порядке путей на диске. Ошибка fn прекращает обход и возвращается.
+	ListObjects(ctx context.Context, prefix string, fn func(*ObjectInfo) error) error
 	SaveFile(ctx context.Context, fileID int64, fileReader io.Reader, sizeInBytes int64) error
 	GetFileByID(ctx context.Context, fileID int64, inWriter io.Writer) error
 	GetFileByOriginalName(ctx context.Context, userEmail, originalName string, inWriter io.Writer) error
@@ -23,6 +35,39 @@ type S3Client interface {
 	DeleteBucket(ctx context.Context, bucketName string) error
 }
 
+type ObjectInfo struct {
+	Key string
+	// SizeInBytes — размер объекта в том виде, в каком его записали через клиент, например до шифрования.
+	SizeInBytes  int64
+	LastModified time.Time
+}
+
+// FileKey возвращает ключ объекта, под которым хранится содержимое файла без дедупликации.
+func FileKey(fileID int64) string {
+	return strconv.FormatInt(fileID, 10)
+}
+
+// BlobKey возвращает ключ объекта с содержимым, адресуемым по SHA-256.
+func BlobKey(sha256 string) string {
+	return "blobs/" + sha256
+}
+
+// UploadKey возвращает временный ключ, в который загружается содержимое до вычисления хеша.
+// token отличает параллельные загрузки новых версий одного файла.
+func UploadKey(fileID int64, token string) string {
+	return "uploads/" + strconv.FormatInt(fileID, 10) + "/" + token
+}
+
+// VersionKey возвращает ключ объекта с содержимым отдельной версии файла без дедупликации.
+func VersionKey(fileID int64, token string) string {
+	return "versions/" + strconv.FormatInt(fileID, 10) + "/" + token
+}
+
+// ThumbnailKey возвращает ключ миниатюры версии файла.
+func ThumbnailKey(fileID, versionID int64, size int, format string) string {
+	return "thumbnails/" + strconv.FormatInt(fileID, 10) + "/" + strconv.FormatInt(versionID, 10) + "/" + strconv.Itoa(size) + "." + format
+}
+
 func NewS3Client(client *s3.Client, bucketName string, log logger.Logger) S3Client {
 	return &S3ClientImpl{
 		BucketName: bucketName,
@@ -37,87 +82,157 @@ type S3ClientImpl struct {
 	log        logger.Logger
 }
 
-func (s3Client *S3ClientImpl) SaveFile(ctx context.Context, fileID int64, fileReader io.Reader, sizeInBytes int64) error {
-	s3Client.log.Debug("saving file to S3", zap.Int64("fileID", fileID), zap.Int64("sizeInBytes", sizeInBytes))
+func (s3Client *S3ClientImpl) PutObject(ctx context.Context, key string, reader io.Reader, sizeInBytes int64) error {
+	s3Client.log.Debug("saving object to S3", zap.String("key", key), zap.Int64("sizeInBytes", sizeInBytes))
 
-	key := strconv.FormatInt(fileID, 10)
 	contentType := "application/octet-stream"
 
 	_, err := s3Client.Client.PutObject(ctx, &s3.PutObjectInput{
 		Bucket:        aws.String(s3Client.BucketName),
 		Key:           aws.String(key),
-		Body:          fileReader,
+		Body:          reader,
 		ContentLength: aws.Int64(sizeInBytes),
 		ContentType:   aws.String(contentType),
 	})
 
 	if err != nil {
-		s3Client.log.Error("failed to upload file to S3", zap.Int64("fileID", fileID), zap.Error(err))
+		s3Client.log.Error("failed to upload object to S3", zap.String("key", key), zap.Error(err))
 		return err
 	}
 
-	s3Client.log.Info("file uploaded successfully", zap.String("key", key))
+	s3Client.log.Info("object uploaded successfully", zap.String("key", key))
 	return nil
 }
 
-func (s3Client *S3ClientImpl) GetFileByID(ctx context.Context, fileID int64, inWriter io.Writer) error {
-	key := strconv.FormatInt(fileID, 10)
-
+func (s3Client *S3ClientImpl) GetObject(ctx context.Context, key string, inWriter io.Writer) error {
 	result, err := s3Client.Client.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(s3Client.BucketName),
 		Key:    aws.String(key),
 	})
 	if err != nil {
-		s3Client.log.Error("failed to get file from S3", zap.Int64("fileID", fileID), zap.Error(err))
+		s3Client.log.Error("failed to get object from S3", zap.String("key", key), zap.Error(err))
 		return err
 	}
 	defer func() { _ = result.Body.Close() }()
 
 	_, err = io.Copy(inWriter, result.Body)
 	if err != nil {
-		s3Client.log.Error("failed to copy file content", zap.Int64("fileID", fileID), zap.Error(err))
+		s3Client.log.Error("failed to copy object content", zap.String("key", key), zap.Error(err))
 		return err
 	}
 	return nil
 }
 
-func (s3Client *S3ClientImpl) GetFileByOriginalName(ctx context.Context, userEmail, originalName string, inWriter io.Writer) error {
-	key := userEmail + originalName
+func (s3Client *S3ClientImpl) GetObjectRange(ctx context.Context, key string, offset, length int64, inWriter io.Writer) error {
+	if length <= 0 {
+		return nil
+	}
 
 	result, err := s3Client.Client.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(s3Client.BucketName),
 		Key:    aws.String(key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)),
 	})
 	if err != nil {
-		s3Client.log.Error("failed to get file by name from S3", zap.String("key", key), zap.Error(err))
+		s3Client.log.Error("failed to get object range from S3", zap.String("key", key), zap.Int64("offset", offset), zap.Int64("length", length), zap.Error(err))
 		return err
 	}
 	defer func() { _ = result.Body.Close() }()
 
 	_, err = io.Copy(inWriter, result.Body)
 	if err != nil {
-		s3Client.log.Error("failed to copy file content", zap.String("key", key), zap.Error(err))
+		s3Client.log.Error("failed to copy object range content", zap.String("key", key), zap.Error(err))
 		return err
 	}
 	return nil
 }
 
-func (s3Client *S3ClientImpl) DeleteFile(ctx context.Context, fileID int64) error {
-	key := strconv.FormatInt(fileID, 10)
-
+func (s3Client *S3ClientImpl) DeleteObject(ctx context.Context, key string) error {
 	_, err := s3Client.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(s3Client.BucketName),
 		Key:    aws.String(key),
 	})
 	if err != nil {
-		s3Client.log.Error("failed to delete file from S3", zap.Int64("fileID", fileID), zap.Error(err))
+		s3Client.log.Error("failed to delete object from S3", zap.String("key", key), zap.Error(err))
+		return err
+	}
+
+	s3Client.log.Info("object deleted from S3", zap.String("key", key))
+	return nil
+}
+
+func (s3Client *S3ClientImpl) CopyObject(ctx context.Context, srcKey, dstKey string) error {
+	copySource := s3Client.BucketName + "/" + srcKey
+	_, err := s3Client.Client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(s3Client.BucketName),
+		CopySource: aws.String(copySource),
+		Key:        aws.String(dstKey),
+	})
+	if err != nil {
+		s3Client.log.Error("failed to copy object in S3", zap.String("srcKey", srcKey), zap.String("dstKey", dstKey), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s3Client *S3ClientImpl) ListObjects(ctx context.Context, prefix string, fn func(*ObjectInfo) error) error {
+	paginator := s3.NewListObjectsV2Paginator(s3Client.Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s3Client.BucketName),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			s3Client.log.Error("failed to list objects in S3", zap.String("prefix", prefix), zap.Error(err))
+			return err
+		}
+		for _, object := range page.Contents {
+			err := fn(&ObjectInfo{
+				Key:          aws.ToString(object.Key),
+				SizeInBytes:  aws.ToInt64(object.Size),
+				LastModified: aws.ToTime(object.LastModified),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s3Client *S3ClientImpl) SaveFile(ctx context.Context, fileID int64, fileReader io.Reader, sizeInBytes int64) error {
+	return s3Client.PutObject(ctx, FileKey(fileID), fileReader, sizeInBytes)
+}
+
+func (s3Client *S3ClientImpl) GetFileByID(ctx context.Context, fileID int64, inWriter io.Writer) error {
+	return s3Client.GetObject(ctx, FileKey(fileID), inWriter)
+}
+
+func (s3Client *S3ClientImpl) GetFileByOriginalName(ctx context.Context, userEmail, originalName string, inWriter io.Writer) error {
+	key := userEmail + originalName
+
+	result, err := s3Client.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s3Client.BucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		s3Client.log.Error("failed to get file by name from S3", zap.String("key", key), zap.Error(err))
 		return err
 	}
+	defer func() { _ = result.Body.Close() }()
 
-	s3Client.log.Info("file deleted from S3", zap.Int64("fileID", fileID))
+	_, err = io.Copy(inWriter, result.Body)
+	if err != nil {
+		s3Client.log.Error("failed to copy file content", zap.String("key", key), zap.Error(err))
+		return err
+	}
 	return nil
 }
 
+func (s3Client *S3ClientImpl) DeleteFile(ctx context.Context, fileID int64) error {
+	return s3Client.DeleteObject(ctx, FileKey(fileID))
+}
+
 func (s3Client *S3ClientImpl) RenameFile(ctx context.Context, userEmail, originalName, newName string) error {
 	srcKey := userEmail + originalName
 	dstKey := userEmail + newName
@@ -0,0 +1,8 @@
+package sqlite
+
+import "time"
+
+type SQLiteConfig struct {
+	Path        string        `yaml:"path"`
+	BusyTimeout time.Duration `yaml:"busy_timeout"`
+}
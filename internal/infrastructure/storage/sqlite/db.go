@@ -0,0 +1,133 @@
+package sqlite
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"embed"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratesqlite "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	driverName         = "meemo_sqlite3"
+	defaultBusyTimeout = 5 * time.Second
+
+	// timeFormat — формат хранения времени: всегда UTC с фиксированной точностью, чтобы значения
+	// сравнивались как строки в том же порядке, что и моменты времени.
+	timeFormat = "2006-01-02 15:04:05.000000-07:00"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+func init() {
+	sql.Register(driverName, &sqliteDriver{SQLiteDriver: sqlite3.SQLiteDriver{ConnectHook: registerFunctions}})
+	sqlx.BindDriver(driverName, sqlx.QUESTION)
+}
+
+// NewSQLiteConnection открывает файл базы, создавая его при необходимости, и применяет миграции.
+// Транзакции начинаются с BEGIN IMMEDIATE: пишущие транзакции выполняются по очереди, что заменяет
+// блокировки строк Postgres.
+func NewSQLiteConnection(config *SQLiteConfig) (*sqlx.DB, error) {
+	if config.Path == "" {
+		return nil, errors.New("sqlite path is empty")
+	}
+	if err := os.MkdirAll(filepath.Dir(config.Path), 0o750); err != nil {
+		return nil, err
+	}
+
+	busyTimeout := config.BusyTimeout
+	if busyTimeout <= 0 {
+		busyTimeout = defaultBusyTimeout
+	}
+	params := url.Values{}
+	params.Set("_foreign_keys", "1")
+	params.Set("_journal_mode", "WAL")
+	params.Set("_busy_timeout", fmt.Sprint(busyTimeout.Milliseconds()))
+	params.Set("_txlock", "immediate")
+
+	conn, err := sqlx.Open(driverName, "file:"+config.Path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Ping(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	if err := Migrate(conn); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// Migrate применяет встроенные миграции схемы SQLite.
+func Migrate(conn *sqlx.DB) error {
+	source, err := iofs.New(migrations, "migrations")
+	if err != nil {
+		return err
+	}
+
+	database, err := migratesqlite.WithInstance(conn.DB, &migratesqlite.Config{})
+	if err != nil {
+		return fmt.Errorf("failed to create sqlite migration driver: %w", err)
+	}
+
+	// m.Close не вызывается: он закрыл бы и переданное соединение.
+	m, err := migrate.NewWithInstance("iofs", source, "sqlite3", database)
+	if err != nil {
+		return fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+	return nil
+}
+
+type sqliteDriver struct {
+	sqlite3.SQLiteDriver
+}
+
+func (d *sqliteDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.SQLiteDriver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConn{SQLiteConn: conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+// sqliteConn приводит параметры-время к timeFormat. Без этого драйвер записал бы время в поясе
+// значения, и сравнение строк в запросах перестало бы совпадать с порядком времени.
+type sqliteConn struct {
+	*sqlite3.SQLiteConn
+}
+
+func (c *sqliteConn) CheckNamedValue(nv *driver.NamedValue) error {
+	value, err := driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	if err != nil {
+		return err
+	}
+	if t, ok := value.(time.Time); ok {
+		value = formatTime(t)
+	}
+	nv.Value = value
+	return nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
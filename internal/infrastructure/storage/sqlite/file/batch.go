@@ -0,0 +1,95 @@
+package file
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+	"meemo/internal/infrastructure/storage/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ApplyBatch выполняет операции в одной транзакции. Каждая операция защищена точкой сохранения:
+// ошибка одной откатывает только ее, остальные фиксируются вместе при коммите.
+func (fr *fileRepository) ApplyBatch(ctx context.Context, userID int64, ops []repository.BatchOperation) ([]repository.BatchResult, error) {
+	tx, err := fr.beginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	results := make([]repository.BatchResult, len(ops))
+	for i, op := range ops {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT batch_item"); err != nil {
+			return nil, err
+		}
+
+		results[i] = applyBatchOperation(ctx, tx.Tx, userID, op)
+
+		release := "RELEASE SAVEPOINT batch_item"
+		if results[i].Err != nil {
+			release = "ROLLBACK TO SAVEPOINT batch_item"
+		}
+		if _, err := tx.ExecContext(ctx, release); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func applyBatchOperation(ctx context.Context, tx *sqlx.Tx, userID int64, op repository.BatchOperation) repository.BatchResult {
+	fileID := op.FileID
+	if fileID == 0 {
+		existing, err := queryTxFile(ctx, tx, GetFileByNameInFolderTemplate, userID, sql.NullInt64{}, op.Name)
+		if err != nil {
+			return repository.BatchResult{Err: err}
+		}
+		fileID = existing.ID
+	}
+
+	var (
+		updated  *entity.File
+		versions []*entity.FileVersion
+		err      error
+	)
+	switch op.Action {
+	case repository.BatchTrash:
+		updated, err = queryTxFile(ctx, tx, TrashFileTemplate, fileID, userID, entity.Removed)
+	case repository.BatchPurge:
+		// Версии удаляются каскадно вместе со строкой файла, поэтому их ключи читаются заранее.
+		versions, err = selectVersions(ctx, tx, ListFileVersionsTemplate, fileID)
+		if err == nil {
+			updated, err = deleteFile(ctx, tx, fileID, userID)
+		}
+	case repository.BatchSetVisibility:
+		updated, err = queryTxFile(ctx, tx, ChangeVisibilityByIDTemplate, fileID, userID, op.IsPublic, entity.Quarantined)
+	case repository.BatchMove:
+		updated, err = queryTxFile(ctx, tx, MoveFileTemplate, model.PtrToNullInt64(op.FolderID), fileID, userID)
+	case repository.BatchAddTags:
+		updated, err = queryTxFile(ctx, tx, AddFileTagsTemplate, fileID, userID, jsonArray(op.Tags))
+	case repository.BatchRemoveTags:
+		updated, err = queryTxFile(ctx, tx, RemoveFileTagsTemplate, fileID, userID, jsonArray(op.Tags))
+	default:
+		err = fmt.Errorf("unknown batch action %q", op.Action)
+	}
+	if err != nil {
+		return repository.BatchResult{Err: err}
+	}
+	return repository.BatchResult{File: updated, Versions: versions}
+}
+
+func queryTxFile(ctx context.Context, tx *sqlx.Tx, query string, args ...any) (*entity.File, error) {
+	fileModel := &model.File{}
+
+	err := tx.QueryRowxContext(ctx, query, args...).StructScan(fileModel)
+	if err != nil {
+		return nil, err
+	}
+	return fileModel.ModelToEntity(), nil
+}
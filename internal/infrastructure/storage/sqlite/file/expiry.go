@@ -0,0 +1,49 @@
+package file
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+	"meemo/internal/infrastructure/storage/model"
+	"time"
+)
+
+// SetExpiry задает срок хранения файла; nil снимает его.
+func (fr *fileRepository) SetExpiry(ctx context.Context, userID, fileID int64, expiresAt *time.Time) (*entity.File, error) {
+	return fr.queryFile(ctx, SetFileExpiryTemplate, fileID, userID, model.PtrToNullTime(expiresAt))
+}
+
+func (fr *fileRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*entity.File, error) {
+	return fr.queryFiles(ctx, ListExpiredFilesTemplate, now, limit)
+}
+
+// DeleteExpired удаляет файл с истекшим сроком и записывает удаление с причиной reason.
+// Если срок за это время продлили или сняли, возвращается sql.ErrNoRows.
+func (fr *fileRepository) DeleteExpired(ctx context.Context, fileID int64, now time.Time, reason string) (*entity.File, error) {
+	tx, err := fr.beginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var expiredID int64
+	if err := tx.QueryRowxContext(ctx, GetExpiredFileIDTemplate, fileID, now).Scan(&expiredID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, ReleaseFileBlobsTemplate, fileID); err != nil {
+		return nil, err
+	}
+	deleted, err := queryTxFile(ctx, tx.Tx, DeleteExpiredFileTemplate, fileID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, RecordFileDeletionTemplate,
+		deleted.ID, deleted.UserID, deleted.OriginalName, deleted.SizeInBytes, reason, model.PtrToNullTime(deleted.ExpiresAt))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
@@ -0,0 +1,118 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sortColumn — колонка сортировки и разбор значения курсора: время и размер сравниваются
+// в формате хранения, а не как строки курсора.
+type sortColumn struct {
+	column string
+	parse  func(value string) (any, error)
+}
+
+var sortColumns = map[repository.SortField]sortColumn{
+	repository.SortByName:    {column: "f.original_name", parse: parseText},
+	repository.SortBySize:    {column: "f.size_in_bytes", parse: parseInt},
+	repository.SortByCreated: {column: "f.created_at", parse: parseTime},
+	repository.SortByUpdated: {column: "f.updated_at", parse: parseTime},
+}
+
+func (fr *fileRepository) ListPage(ctx context.Context, userID int64, opts repository.ListOptions) ([]*entity.File, error) {
+	query, args, err := buildListPageQuery(userID, opts)
+	if err != nil {
+		return nil, err
+	}
+	return fr.queryFiles(ctx, query, args...)
+}
+
+// buildListPageQuery собирает запрос страницы списка. Имена колонок берутся только из sortColumns,
+// все пользовательские значения передаются параметрами.
+func buildListPageQuery(userID int64, opts repository.ListOptions) (string, []any, error) {
+	sort, ok := sortColumns[opts.SortBy]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown sort field %q", opts.SortBy)
+	}
+
+	args := []any{userID}
+	conditions := []string{"f.user_id = ?1", "f.deleted_at IS NULL"}
+	addCondition := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, strings.ReplaceAll(format, "?N", fmt.Sprintf("?%d", len(args))))
+	}
+
+	if opts.MimePrefix != "" {
+		addCondition("substr(f.mime_type, 1, length(?N)) = ?N", opts.MimePrefix)
+	}
+	if opts.Status != nil {
+		addCondition("f.status = ?N", *opts.Status)
+	}
+	if opts.IsPublic != nil {
+		addCondition("f.is_public = ?N", *opts.IsPublic)
+	}
+	if opts.MinSize != nil {
+		addCondition("f.size_in_bytes >= ?N", *opts.MinSize)
+	}
+	if opts.MaxSize != nil {
+		addCondition("f.size_in_bytes <= ?N", *opts.MaxSize)
+	}
+	if opts.CreatedAfter != nil {
+		addCondition("f.created_at >= ?N", *opts.CreatedAfter)
+	}
+	if opts.CreatedBefore != nil {
+		addCondition("f.created_at < ?N", *opts.CreatedBefore)
+	}
+
+	for _, tag := range opts.Tags {
+		addCondition("EXISTS (SELECT 1 FROM json_each(f.tags) WHERE value = ?N)", tag)
+	}
+	if opts.MetadataKey != "" {
+		if opts.MetadataValue != nil {
+			args = append(args, opts.MetadataKey, *opts.MetadataValue)
+			conditions = append(conditions, fmt.Sprintf(
+				"EXISTS (SELECT 1 FROM json_each(f.metadata) WHERE key = ?%d AND value = ?%d)", len(args)-1, len(args)))
+		} else {
+			addCondition("EXISTS (SELECT 1 FROM json_each(f.metadata) WHERE key = ?N)", opts.MetadataKey)
+		}
+	}
+
+	direction, comparison := "ASC", ">"
+	if opts.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if opts.AfterValue != nil {
+		after, err := sort.parse(*opts.AfterValue)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid cursor value: %w", err)
+		}
+		args = append(args, after, opts.AfterID)
+		conditions = append(conditions, fmt.Sprintf("(%s, f.id) %s (?%d, ?%d)",
+			sort.column, comparison, len(args)-1, len(args)))
+	}
+
+	args = append(args, opts.Limit)
+	query := fmt.Sprintf(ListFilesPageTemplate,
+		strings.Join(conditions, "\n  AND "),
+		sort.column, direction, direction,
+		len(args))
+	return query, args, nil
+}
+
+func parseText(value string) (any, error) {
+	return value, nil
+}
+
+func parseInt(value string) (any, error) {
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func parseTime(value string) (any, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
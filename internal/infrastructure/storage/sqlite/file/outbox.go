@@ -0,0 +1,62 @@
+package file
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+	"meemo/internal/infrastructure/storage/model"
+	"time"
+)
+
+// EnqueueObjectOperations записывает операции с S3 в очередь. Worker возьмет их не раньше availableAt.
+func (fr *fileRepository) EnqueueObjectOperations(ctx context.Context, ops []*entity.ObjectOperation, availableAt time.Time) ([]*entity.ObjectOperation, error) {
+	enqueued := make([]*entity.ObjectOperation, 0, len(ops))
+	for _, op := range ops {
+		opModel := &model.ObjectOperation{}
+		err := fr.conn.QueryRowxContext(ctx, EnqueueObjectOperationTemplate, op.Operation, op.S3Key, op.SourceKey, availableAt).StructScan(opModel)
+		if err != nil {
+			return nil, err
+		}
+		enqueued = append(enqueued, opModel.ModelToEntity())
+	}
+	return enqueued, nil
+}
+
+// ClaimObjectOperations выбирает до limit операций, готовых к выполнению в now, и откладывает их до leaseUntil.
+func (fr *fileRepository) ClaimObjectOperations(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*entity.ObjectOperation, error) {
+	rows, err := fr.conn.QueryxContext(ctx, ClaimObjectOperationsTemplate, now, leaseUntil, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ops []*entity.ObjectOperation
+	for rows.Next() {
+		opModel := &model.ObjectOperation{}
+		if err := rows.StructScan(opModel); err != nil {
+			return nil, err
+		}
+		ops = append(ops, opModel.ModelToEntity())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ops, nil
+}
+
+// DeleteObjectOperations удаляет из очереди выполненные или отмененные операции.
+func (fr *fileRepository) DeleteObjectOperations(ctx context.Context, ids []int64) error {
+	_, err := fr.conn.ExecContext(ctx, DeleteObjectOperationsTemplate, jsonArray(ids))
+	return err
+}
+
+func (fr *fileRepository) RetryObjectOperation(ctx context.Context, id int64, lastError string, retryAt time.Time) error {
+	_, err := fr.conn.ExecContext(ctx, RetryObjectOperationTemplate, id, lastError, retryAt)
+	return err
+}
+
+func (fr *fileRepository) DeadLetterObjectOperation(ctx context.Context, id int64, lastError string) error {
+	_, err := fr.conn.ExecContext(ctx, DeadLetterObjectOperationTemplate, id, lastError)
+	return err
+}
@@ -0,0 +1,58 @@
+package file
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+	"meemo/internal/infrastructure/storage/model"
+)
+
+func (fr *fileRepository) AddTags(ctx context.Context, userID, fileID int64, tags []string) (*entity.File, error) {
+	return fr.queryFile(ctx, AddFileTagsTemplate, fileID, userID, jsonArray(tags))
+}
+
+func (fr *fileRepository) RemoveTags(ctx context.Context, userID, fileID int64, tags []string) (*entity.File, error) {
+	return fr.queryFile(ctx, RemoveFileTagsTemplate, fileID, userID, jsonArray(tags))
+}
+
+func (fr *fileRepository) ReplaceTags(ctx context.Context, userID, fileID int64, tags []string) (*entity.File, error) {
+	return fr.queryFile(ctx, ReplaceFileTagsTemplate, fileID, userID, jsonArray(tags))
+}
+
+func (fr *fileRepository) ListTags(ctx context.Context, userID int64) ([]*entity.TagCount, error) {
+	rows, err := fr.conn.QueryxContext(ctx, ListUserTagsTemplate, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var tags []*entity.TagCount
+	for rows.Next() {
+		tagModel := &model.TagCount{}
+		if err := rows.StructScan(tagModel); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tagModel.ModelToEntity())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// UpdateMetadata дописывает ключи set и удаляет ключи unset. При replace прежние метаданные
+// полностью заменяются на set.
+func (fr *fileRepository) UpdateMetadata(ctx context.Context, userID, fileID int64, set map[string]string, unset []string, replace bool) (*entity.File, error) {
+	return fr.queryFile(ctx, UpdateFileMetadataTemplate, fileID, userID, jsonObject(set), jsonArray(unset), replace)
+}
+
+func (fr *fileRepository) queryFile(ctx context.Context, query string, args ...any) (*entity.File, error) {
+	fileModel := &model.File{}
+
+	err := fr.conn.QueryRowxContext(ctx, query, args...).StructScan(fileModel)
+	if err != nil {
+		return nil, err
+	}
+	return fileModel.ModelToEntity(), nil
+}
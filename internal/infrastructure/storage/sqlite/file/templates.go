@@ -0,0 +1,520 @@
+package file
+
+const fileColumns = `f.id, f.user_id, f.original_name, f.mime_type, f.size_in_bytes,
+       f.s3_bucket, f.s3_key, f.status, f.created_at, f.updated_at, f.is_public,
+       f.checksum_sha256, f.checksum_crc32c, f.checksum_verified_at, f.checksum_failed, f.blob_sha256,
+       f.content_encoding, f.stored_size_in_bytes,
+       f.scan_status, f.scan_signature, f.scanned_at,
+       f.folder_id, f.current_version_id, f.deleted_at, f.expires_at,
+       f.tags, f.metadata`
+
+// returningFileColumns — те же колонки для RETURNING: SQLite не разрешает в нем псевдоним таблицы.
+const returningFileColumns = `id, user_id, original_name, mime_type, size_in_bytes,
+       s3_bucket, s3_key, status, created_at, updated_at, is_public,
+       checksum_sha256, checksum_crc32c, checksum_verified_at, checksum_failed, blob_sha256,
+       content_encoding, stored_size_in_bytes,
+       scan_status, scan_signature, scanned_at,
+       folder_id, current_version_id, deleted_at, expires_at,
+       tags, metadata`
+
+const blobColumns = `sha256, s3_key, size_in_bytes, crc32c, ref_count, content_encoding, stored_size_in_bytes,
+       created_at, updated_at`
+
+const versionColumns = `v.id, v.file_id, v.version_number, v.s3_key, v.size_in_bytes, v.mime_type,
+       v.checksum_sha256, v.checksum_crc32c, v.blob_sha256, v.content_encoding, v.stored_size_in_bytes,
+       v.scan_status, v.scan_signature, v.scanned_at,
+       v.created_by, v.created_at`
+
+const returningVersionColumns = `id, file_id, version_number, s3_key, size_in_bytes, mime_type,
+       checksum_sha256, checksum_crc32c, blob_sha256, content_encoding, stored_size_in_bytes,
+       scan_status, scan_signature, scanned_at,
+       created_by, created_at`
+
+const objectOperationColumns = `id, operation, s3_key, source_key, attempts, last_error, available_at, dead_at, created_at`
+
+const (
+	SaveFileTemplate = `
+INSERT INTO files (user_id, original_name, mime_type, size_in_bytes, s3_bucket, s3_key, status, created_at, updated_at, is_public, folder_id, expires_at, tags, metadata)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11, ?12, ?13, ?14)
+RETURNING id;`
+
+	// ReleaseFileBlobsTemplate освобождает ссылки на блобы у всех версий файла ?1. Выполняется
+	// перед удалением строки файла, пока версии еще не удалены каскадно.
+	ReleaseFileBlobsTemplate = `
+UPDATE blobs
+SET ref_count = ref_count - (SELECT COUNT(*)
+                             FROM file_versions v
+                             WHERE v.file_id = ?1 AND v.blob_sha256 = blobs.sha256),
+    updated_at = now()
+WHERE sha256 IN (SELECT v.blob_sha256 FROM file_versions v WHERE v.file_id = ?1);`
+
+	GetFileIDByOriginalNameAndUserEmailTemplate = `
+SELECT f.id
+FROM files f
+INNER JOIN users u ON f.user_id = u.id
+WHERE u.email = ?1 AND f.original_name = ?2 AND f.folder_id IS NULL AND f.deleted_at IS NULL;`
+
+	DeleteFileTemplate = `
+DELETE FROM files
+WHERE id = ?1;`
+
+	GetFileTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+INNER JOIN users u ON f.user_id = u.id
+WHERE f.id = ?1 AND f.deleted_at IS NULL`
+
+	GetFileByOriginalNameAndUserEmailTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+INNER JOIN users u ON f.user_id = u.id
+WHERE u.email = ?1 AND f.original_name = ?2 AND f.folder_id IS NULL AND f.deleted_at IS NULL`
+
+	ChangeVisibilityTemplate = `
+UPDATE files
+SET is_public = ?1, updated_at = now()
+WHERE user_id = (SELECT id FROM users WHERE email = ?2)
+  AND original_name = ?3
+  AND folder_id IS NULL
+  AND deleted_at IS NULL
+  AND (NOT ?1 OR status <> ?4)
+RETURNING id, is_public, updated_at;`
+
+	ChangeVisibilityByIDTemplate = `
+UPDATE files
+SET is_public = ?3, updated_at = now()
+WHERE id = ?1 AND user_id = ?2 AND deleted_at IS NULL AND (NOT ?3 OR status <> ?4)
+RETURNING ` + returningFileColumns + `;`
+
+	// Отдельной блокировки строки не нужно: транзакция SQLite сразу берет блокировку записи.
+	GetUserIDByEmailTemplate = `
+SELECT id FROM users WHERE email = ?1;`
+
+	// Занятое получателем место считается так же, как в GetTotalUsedSpaceTemplate;
+	// размер файла включает все его версии.
+	TransferUsageTemplate = `
+SELECT COALESCE((SELECT SUM(f.size_in_bytes) FROM files f WHERE f.user_id = ?1), 0)
+     + COALESCE((SELECT SUM(v.size_in_bytes)
+                 FROM file_versions v
+                 INNER JOIN files f ON v.file_id = f.id
+                 WHERE f.user_id = ?1
+                   AND v.id IS NOT f.current_version_id), 0) AS used_bytes,
+       (SELECT f.size_in_bytes + COALESCE((SELECT SUM(v.size_in_bytes)
+                                           FROM file_versions v
+                                           WHERE v.file_id = f.id
+                                             AND v.id IS NOT f.current_version_id), 0)
+        FROM files f
+        WHERE f.id = ?2) AS file_bytes;`
+
+	// Файл переходит в корень получателя: папки прежнего владельца ему недоступны.
+	TransferFileTemplate = `
+UPDATE files
+SET user_id = ?3, folder_id = NULL, updated_at = now()
+WHERE id = ?1 AND user_id = ?2 AND deleted_at IS NULL
+RETURNING ` + returningFileColumns + `;`
+
+	DeleteFileSharesTemplate = `
+DELETE FROM file_shares WHERE file_id = ?1;`
+
+	RevokeFileShareLinksTemplate = `
+UPDATE share_links
+SET revoked_at = COALESCE(revoked_at, now()), updated_at = now()
+WHERE file_id = ?1;`
+
+	SetStatusTemplate = `
+UPDATE files
+SET status = ?1, updated_at = now()
+WHERE user_id = (SELECT id FROM users WHERE email = ?2)
+  AND original_name = ?3
+  AND folder_id IS NULL
+  AND deleted_at IS NULL
+  AND status <> ?4
+RETURNING id, status, updated_at;`
+
+	RenameFileTemplate = `
+UPDATE files
+SET original_name = ?1, updated_at = now()
+WHERE user_id = (SELECT id FROM users WHERE email = ?2)
+  AND original_name = ?3
+  AND folder_id IS NULL
+  AND deleted_at IS NULL
+RETURNING id, original_name, updated_at;`
+
+	RenameFileByIDTemplate = `
+UPDATE files
+SET original_name = ?1, updated_at = now()
+WHERE id = ?2 AND deleted_at IS NULL
+RETURNING ` + returningFileColumns + `;`
+
+	ListSharedWithUserTemplate = `
+SELECT ` + fileColumns + `, o.email AS owner_email, s.permission
+FROM file_shares s
+INNER JOIN files f ON s.file_id = f.id
+INNER JOIN users o ON f.user_id = o.id
+WHERE s.user_id = ?1 AND f.deleted_at IS NULL
+ORDER BY f.original_name, f.id;`
+
+	ListUserFilesTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+INNER JOIN users u ON f.user_id = u.id
+WHERE u.email = ?1 AND f.deleted_at IS NULL
+ORDER BY f.created_at DESC;`
+
+	// ListFilesPageTemplate дополняется условиями, колонкой и направлением сортировки в buildListPageQuery.
+	ListFilesPageTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE %s
+ORDER BY %s %s, f.id %s
+LIMIT ?%d;`
+
+	// SearchFilesTemplate ищет по префиксам слов имени, тегов и текста и нечетко по имени через
+	// word_similarity с порогом pg_trgm по умолчанию. Функции регистрирует пакет sqlite.
+	// Чужие публичные файлы в карантине (?5) и с истекшим сроком не попадают в выдачу.
+	SearchFilesTemplate = `
+SELECT ` + fileColumns + `,
+       search_rank(?2, f.original_name, f.tags, f.search_text) + word_similarity(?2, f.original_name) AS rank
+FROM files f
+WHERE f.deleted_at IS NULL
+  AND (f.user_id = ?1 OR (?3 AND f.is_public AND f.status <> ?5
+                           AND (f.expires_at IS NULL OR f.expires_at > now())))
+  AND (search_match(?2, f.original_name, f.tags, f.search_text) OR word_similarity(?2, f.original_name) >= 0.6)
+ORDER BY rank DESC, f.id
+LIMIT ?4;`
+
+	AddFileTagsTemplate = `
+UPDATE files
+SET tags = (SELECT json_group_array(t)
+            FROM (SELECT value AS t FROM json_each(files.tags)
+                  UNION
+                  SELECT value FROM json_each(?3)
+                  ORDER BY t)),
+    updated_at = now()
+WHERE id = ?1 AND user_id = ?2 AND deleted_at IS NULL
+RETURNING ` + returningFileColumns + `;`
+
+	RemoveFileTagsTemplate = `
+UPDATE files
+SET tags = (SELECT json_group_array(t)
+            FROM (SELECT value AS t
+                  FROM json_each(files.tags)
+                  WHERE value NOT IN (SELECT value FROM json_each(?3))
+                  ORDER BY t)),
+    updated_at = now()
+WHERE id = ?1 AND user_id = ?2 AND deleted_at IS NULL
+RETURNING ` + returningFileColumns + `;`
+
+	ReplaceFileTagsTemplate = `
+UPDATE files
+SET tags = (SELECT json_group_array(t)
+            FROM (SELECT DISTINCT value AS t FROM json_each(?3) ORDER BY t)),
+    updated_at = now()
+WHERE id = ?1 AND user_id = ?2 AND deleted_at IS NULL
+RETURNING ` + returningFileColumns + `;`
+
+	ListUserTagsTemplate = `
+SELECT t.value AS tag, COUNT(*) AS count
+FROM files f, json_each(f.tags) t
+WHERE f.user_id = ?1 AND f.deleted_at IS NULL
+GROUP BY t.value
+ORDER BY count DESC, tag;`
+
+	// UpdateFileMetadataTemplate дописывает ключи из ?3 и удаляет ключи из ?4; при ?5 прежние метаданные отбрасываются.
+	UpdateFileMetadataTemplate = `
+UPDATE files
+SET metadata = (SELECT json_group_object(key, value)
+                FROM json_each(json_patch(CASE WHEN ?5 THEN '{}' ELSE files.metadata END, ?3))
+                WHERE key NOT IN (SELECT value FROM json_each(?4))),
+    updated_at = now()
+WHERE id = ?1 AND user_id = ?2 AND deleted_at IS NULL
+RETURNING ` + returningFileColumns + `;`
+
+	SetSearchTextTemplate = `
+UPDATE files
+SET search_text = ?1
+WHERE id = ?2;`
+
+	GetUserPlanTemplate = `
+SELECT plan
+FROM users
+WHERE id = ?1;`
+
+	GetUserEmailTemplate = `
+SELECT email
+FROM users
+WHERE id = ?1;`
+
+	GetTotalUsedSpaceTemplate = `
+SELECT COALESCE((SELECT SUM(f.size_in_bytes)
+                 FROM files f
+                 INNER JOIN users u ON f.user_id = u.id
+                 WHERE u.email = ?1), 0)
+     + COALESCE((SELECT SUM(v.size_in_bytes)
+                 FROM file_versions v
+                 INNER JOIN files f ON v.file_id = f.id
+                 INNER JOIN users u ON f.user_id = u.id
+                 WHERE u.email = ?1
+                   AND v.id IS NOT f.current_version_id), 0);`
+
+	GetLiveFileIDTemplate = `
+SELECT id FROM files WHERE id = ?1 AND deleted_at IS NULL;`
+
+	InsertFileVersionTemplate = `
+INSERT INTO file_versions (file_id, version_number, s3_key, size_in_bytes, mime_type,
+                           checksum_sha256, checksum_crc32c, blob_sha256, created_by,
+                           content_encoding, stored_size_in_bytes, scan_status, scan_signature, scanned_at)
+SELECT f.id, COALESCE((SELECT MAX(pv.version_number) FROM file_versions pv WHERE pv.file_id = f.id), 0) + 1,
+       ?2, ?3, COALESCE(NULLIF(?4, ''), f.mime_type), ?5, ?6, ?7, ?8, ?9, ?10, ?11, ?12, ?13
+FROM files f
+WHERE f.id = ?1
+RETURNING ` + returningVersionColumns + `;`
+
+	SetCurrentVersionTemplate = `
+UPDATE files
+SET current_version_id = v.id, s3_key = v.s3_key, size_in_bytes = v.size_in_bytes, mime_type = v.mime_type,
+    checksum_sha256 = v.checksum_sha256, checksum_crc32c = v.checksum_crc32c, blob_sha256 = v.blob_sha256,
+    content_encoding = v.content_encoding, stored_size_in_bytes = v.stored_size_in_bytes,
+    scan_status = v.scan_status, scan_signature = v.scan_signature, scanned_at = v.scanned_at,
+    checksum_verified_at = ?3, checksum_failed = 0, updated_at = now(),
+    status = CASE WHEN v.scan_status = ?6 THEN ?5 ELSE ?4 END
+FROM file_versions v
+WHERE files.id = ?1 AND v.file_id = files.id AND v.version_number = ?2
+RETURNING ` + returningFileColumns + `;`
+
+	ListFileVersionsTemplate = `
+SELECT ` + versionColumns + `
+FROM file_versions v
+WHERE v.file_id = ?1
+ORDER BY v.version_number DESC;`
+
+	GetFileVersionTemplate = `
+SELECT ` + versionColumns + `
+FROM file_versions v
+WHERE v.file_id = ?1 AND v.version_number = ?2;`
+
+	// ListPrunableVersionsTemplate отбирает нетекущие версии файла за пределами ?2 последних,
+	// созданные до ?3; нулевой ?2 и NULL в ?3 отключают условие.
+	ListPrunableVersionsTemplate = `
+WITH ranked AS (
+    SELECT pv.id, ROW_NUMBER() OVER (ORDER BY pv.version_number DESC) AS rn
+    FROM file_versions pv
+    WHERE pv.file_id = ?1
+)
+SELECT ` + versionColumns + `
+FROM file_versions v
+INNER JOIN ranked r ON v.id = r.id
+INNER JOIN files f ON f.id = v.file_id
+WHERE v.id IS NOT f.current_version_id
+  AND (?2 = 0 OR r.rn > ?2)
+  AND (?3 IS NULL OR v.created_at < ?3)
+ORDER BY v.version_number;`
+
+	DeleteFileVersionTemplate = `
+DELETE FROM file_versions WHERE id = ?1;`
+
+	ReleaseBlobTemplate = `
+UPDATE blobs
+SET ref_count = ref_count - 1, updated_at = now()
+WHERE sha256 = ?1;`
+
+	GetFileByNameInFolderTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.user_id = ?1 AND f.folder_id IS ?2 AND f.original_name = ?3 AND f.deleted_at IS NULL;`
+
+	ListFilesForScrubTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.checksum_sha256 <> ''
+  AND (f.checksum_verified_at IS NULL OR f.checksum_verified_at < ?1)
+ORDER BY f.checksum_verified_at NULLS FIRST, f.id
+LIMIT ?2;`
+
+	// ListFilesForThumbnailsTemplate выбирает загруженные изображения, у текущей версии которых
+	// миниатюр меньше ?3: новые загрузки и файлы, для которых появились новые размеры.
+	// Типы ?2 передаются JSON-массивом.
+	ListFilesForThumbnailsTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.status = ?1
+  AND f.deleted_at IS NULL
+  AND f.current_version_id IS NOT NULL
+  AND f.mime_type IN (SELECT value FROM json_each(?2))
+  AND (SELECT COUNT(*)
+       FROM thumbnails t
+       WHERE t.file_id = f.id
+         AND t.version_id = f.current_version_id) < ?3
+ORDER BY f.updated_at DESC, f.id DESC
+LIMIT ?4;`
+
+	MarkChecksumVerifiedTemplate = `
+UPDATE files
+SET checksum_verified_at = now(), checksum_failed = ?1
+WHERE id = ?2;`
+
+	GetBlobTemplate = `
+SELECT ` + blobColumns + `
+FROM blobs
+WHERE sha256 = ?1;`
+
+	AcquireBlobTemplate = `
+INSERT INTO blobs (sha256, s3_key, size_in_bytes, crc32c, ref_count, content_encoding, stored_size_in_bytes)
+VALUES (?1, ?2, ?3, ?4, 1, ?5, ?6)
+ON CONFLICT (sha256) DO UPDATE
+SET ref_count = blobs.ref_count + 1, updated_at = now()
+RETURNING ` + blobColumns + `;`
+
+	ReferenceBlobTemplate = `
+UPDATE blobs
+SET ref_count = ref_count + 1, updated_at = now()
+WHERE sha256 = ?1
+RETURNING ` + blobColumns + `;`
+
+	ListUnreferencedBlobsTemplate = `
+SELECT ` + blobColumns + `
+FROM blobs
+WHERE ref_count = 0 AND updated_at < ?1
+ORDER BY updated_at
+LIMIT ?2;`
+
+	DeleteUnreferencedBlobTemplate = `
+DELETE FROM blobs
+WHERE sha256 = ?1 AND ref_count = 0
+RETURNING sha256;`
+
+	DeleteFileByIDTemplate = `
+DELETE FROM files
+WHERE id = ?1 AND user_id = ?2
+RETURNING ` + returningFileColumns + `;`
+
+	ListFolderFilesTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.user_id = ?1 AND f.folder_id IS ?2 AND f.deleted_at IS NULL
+ORDER BY f.original_name;`
+
+	ListFolderTreeFilesTemplate = `
+WITH RECURSIVE tree AS (
+    SELECT id FROM folders WHERE id = ?2 AND user_id = ?1
+    UNION ALL
+    SELECT fo.id FROM folders fo INNER JOIN tree t ON fo.parent_id = t.id
+)
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.user_id = ?1 AND f.folder_id IN (SELECT id FROM tree)
+ORDER BY f.id;`
+
+	MoveFileTemplate = `
+UPDATE files
+SET folder_id = ?1, updated_at = now()
+WHERE id = ?2 AND user_id = ?3 AND deleted_at IS NULL
+RETURNING ` + returningFileColumns + `;`
+
+	TrashFileTemplate = `
+UPDATE files
+SET status = ?3, deleted_at = now(), updated_at = now()
+WHERE id = ?1 AND user_id = ?2 AND deleted_at IS NULL
+RETURNING ` + returningFileColumns + `;`
+
+	RestoreFileFromTrashTemplate = `
+UPDATE files
+SET status = CASE WHEN current_version_id IS NULL THEN ?3 WHEN scan_status = ?6 THEN ?5 ELSE ?4 END,
+    deleted_at = NULL, updated_at = now()
+WHERE id = ?1 AND user_id = ?2 AND deleted_at IS NOT NULL
+RETURNING ` + returningFileColumns + `;`
+
+	ListTrashTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.user_id = ?1 AND f.deleted_at IS NOT NULL
+ORDER BY f.deleted_at DESC;`
+
+	ListExpiredTrashTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.deleted_at < ?1
+ORDER BY f.deleted_at
+LIMIT ?2;`
+
+	SetFileExpiryTemplate = `
+UPDATE files
+SET expires_at = ?3, updated_at = now()
+WHERE id = ?1 AND user_id = ?2 AND deleted_at IS NULL
+RETURNING ` + returningFileColumns + `;`
+
+	// ListExpiredFilesTemplate отбирает и файлы в корзине: срок хранения действует и там.
+	ListExpiredFilesTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.expires_at <= ?1
+ORDER BY f.expires_at
+LIMIT ?2;`
+
+	GetExpiredFileIDTemplate = `
+SELECT id FROM files WHERE id = ?1 AND expires_at <= ?2;`
+
+	DeleteExpiredFileTemplate = `
+DELETE FROM files
+WHERE id = ?1
+RETURNING ` + returningFileColumns + `;`
+
+	RecordFileDeletionTemplate = `
+INSERT INTO file_deletions (file_id, user_id, original_name, size_in_bytes, reason, expires_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6);`
+
+	// ListStoredObjectsTemplate перечисляет все объекты хранилища, на которые ссылается база. Содержимое
+	// текущей версии помечается как file (?1), остальные версии — как version (?2).
+	// Версии с дедупликацией хранятся в блобах и отдельно не перечисляются.
+	ListStoredObjectsTemplate = `
+SELECT v.s3_key, CASE WHEN f.current_version_id = v.id THEN ?1 ELSE ?2 END AS kind,
+       v.stored_size_in_bytes AS size_in_bytes, v.file_id
+FROM file_versions v
+INNER JOIN files f ON v.file_id = f.id
+WHERE v.blob_sha256 IS NULL AND v.s3_key <> ''
+UNION ALL
+SELECT f.s3_key, ?1, f.stored_size_in_bytes, f.id
+FROM files f
+WHERE f.current_version_id IS NULL AND f.blob_sha256 IS NULL AND f.s3_key <> ''
+UNION ALL
+SELECT b.s3_key, ?3, b.stored_size_in_bytes, NULL
+FROM blobs b
+UNION ALL
+SELECT t.s3_key, ?4, t.size_in_bytes, t.file_id
+FROM thumbnails t
+WHERE t.s3_key <> '';`
+
+	// ListStalePendingTemplate отбирает файлы, содержимое которых так и не загрузили.
+	ListStalePendingTemplate = `
+SELECT ` + fileColumns + `
+FROM files f
+WHERE f.status = ?1 AND f.current_version_id IS NULL AND f.updated_at < ?2 AND f.deleted_at IS NULL
+ORDER BY f.updated_at;`
+
+	EnqueueObjectOperationTemplate = `
+INSERT INTO object_outbox (operation, s3_key, source_key, available_at)
+VALUES (?1, ?2, ?3, ?4)
+RETURNING ` + objectOperationColumns + `;`
+
+	// ClaimObjectOperationsTemplate выбирает готовые операции и откладывает их до ?2, чтобы
+	// другой экземпляр не взял их, пока они выполняются. Выборка и обновление идут одним
+	// запросом под блокировкой записи SQLite, поэтому SKIP LOCKED не нужен.
+	ClaimObjectOperationsTemplate = `
+UPDATE object_outbox
+SET attempts = attempts + 1, available_at = ?2
+WHERE id IN (SELECT id
+             FROM object_outbox
+             WHERE dead_at IS NULL AND available_at <= ?1
+             ORDER BY available_at, id
+             LIMIT ?3)
+RETURNING ` + objectOperationColumns + `;`
+
+	DeleteObjectOperationsTemplate = `
+DELETE FROM object_outbox WHERE id IN (SELECT value FROM json_each(?1));`
+
+	RetryObjectOperationTemplate = `
+UPDATE object_outbox SET last_error = ?2, available_at = ?3 WHERE id = ?1;`
+
+	DeadLetterObjectOperationTemplate = `
+UPDATE object_outbox SET last_error = ?2, dead_at = now() WHERE id = ?1;`
+)
@@ -0,0 +1,57 @@
+package file
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+)
+
+// TransferOwnership передает файл вместе с версиями пользователю toEmail, если файл помещается
+// в его квоту maxBytes. Транзакция SQLite с начала держит блокировку записи, поэтому параллельные
+// передачи одному пользователю не превысят квоту. Доступы и ссылки прежнего владельца отзываются.
+func (fr *fileRepository) TransferOwnership(ctx context.Context, fileID, fromUserID int64, toEmail string, maxBytes int64) (*entity.File, error) {
+	tx, err := fr.beginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var toUserID int64
+	if err := tx.QueryRowxContext(ctx, GetUserIDByEmailTemplate, toEmail).Scan(&toUserID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	var lockedID int64
+	if err := tx.QueryRowxContext(ctx, GetLiveFileIDTemplate, fileID).Scan(&lockedID); err != nil {
+		return nil, err
+	}
+
+	var usedBytes, fileBytes int64
+	if err := tx.QueryRowxContext(ctx, TransferUsageTemplate, toUserID, fileID).Scan(&usedBytes, &fileBytes); err != nil {
+		return nil, err
+	}
+	if usedBytes+fileBytes > maxBytes {
+		return nil, repository.ErrQuotaExceeded
+	}
+
+	transferred, err := queryTxFile(ctx, tx.Tx, TransferFileTemplate, fileID, fromUserID, toUserID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, DeleteFileSharesTemplate, fileID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, RevokeFileShareLinksTemplate, fileID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return transferred, nil
+}
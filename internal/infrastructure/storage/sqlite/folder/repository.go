@@ -0,0 +1,23 @@
+package folder
+
+import (
+	"meemo/internal/domain/folder/repository"
+	sqlstore "meemo/internal/infrastructure/storage/sqlstore/folder"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var templates = sqlstore.Templates{
+	CreateFolder:     CreateFolderTemplate,
+	GetFolder:        GetFolderTemplate,
+	RenameFolder:     RenameFolderTemplate,
+	MoveFolder:       MoveFolderTemplate,
+	ListChildFolders: ListChildFoldersTemplate,
+	ListFolderTree:   ListFolderTreeTemplate,
+	DeleteFolder:     DeleteFolderTemplate,
+	GetFolderSize:    GetFolderSizeTemplate,
+}
+
+func NewFolderRepository(conn *sqlx.DB) repository.FolderRepository {
+	return sqlstore.NewFolderRepository(conn, templates)
+}
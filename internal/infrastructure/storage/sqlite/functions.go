@@ -0,0 +1,169 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Веса полей при ранжировании поиска — как веса A, B и C у ts_rank_cd в Postgres.
+const (
+	nameWeight = 1.0
+	tagsWeight = 0.4
+	textWeight = 0.2
+)
+
+// registerFunctions добавляет в соединение функции, на которые опираются схема и запросы:
+// now() в формате хранения времени, REGEXP и поиск по файлам.
+func registerFunctions(conn *sqlite3.SQLiteConn) error {
+	functions := []struct {
+		name string
+		impl any
+		pure bool
+	}{
+		{name: "now", impl: now, pure: false},
+		{name: "regexp", impl: regexpMatch, pure: true},
+		{name: "search_match", impl: searchMatch, pure: true},
+		{name: "search_rank", impl: searchRank, pure: true},
+		{name: "word_similarity", impl: wordSimilarity, pure: true},
+	}
+	for _, fn := range functions {
+		if err := conn.RegisterFunc(fn.name, fn.impl, fn.pure); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func now() string {
+	return formatTime(time.Now())
+}
+
+var regexpCache sync.Map
+
+// regexpMatch реализует оператор "value REGEXP pattern".
+func regexpMatch(pattern, value string) (bool, error) {
+	if re, ok := regexpCache.Load(pattern); ok {
+		return re.(*regexp.Regexp).MatchString(value), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	regexpCache.Store(pattern, re)
+	return re.MatchString(value), nil
+}
+
+// searchMatch проверяет, что каждое слово запроса — префикс какого-нибудь слова имени, тегов
+// (JSON-массив) или извлеченного текста файла.
+func searchMatch(query, name, tags, text string) bool {
+	terms := searchWords(query)
+	if len(terms) == 0 {
+		return false
+	}
+	fields := searchFields(name, tags, text)
+	for _, term := range terms {
+		if termWeight(term, fields) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// searchRank — средний по словам запроса вес лучшего поля, в котором слово нашлось.
+func searchRank(query, name, tags, text string) float64 {
+	terms := searchWords(query)
+	if len(terms) == 0 {
+		return 0
+	}
+	fields := searchFields(name, tags, text)
+	var rank float64
+	for _, term := range terms {
+		rank += termWeight(term, fields)
+	}
+	return rank / float64(len(terms))
+}
+
+type searchField struct {
+	words  []string
+	weight float64
+}
+
+func searchFields(name, tags, text string) []searchField {
+	var tagList []string
+	_ = json.Unmarshal([]byte(tags), &tagList)
+	return []searchField{
+		{words: searchWords(name), weight: nameWeight},
+		{words: searchWords(strings.Join(tagList, " ")), weight: tagsWeight},
+		{words: searchWords(text), weight: textWeight},
+	}
+}
+
+func termWeight(term string, fields []searchField) float64 {
+	for _, field := range fields {
+		for _, word := range field.words {
+			if strings.HasPrefix(word, term) {
+				return field.weight
+			}
+		}
+	}
+	return 0
+}
+
+// searchWords делит строку на слова из букв и цифр в нижнем регистре, как имена файлов
+// индексируются в Postgres: "quarterly_report-2024.pdf" дает quarterly, report, 2024 и pdf.
+func searchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// wordSimilarity повторяет word_similarity из pg_trgm: наибольшее сходство множества триграмм
+// запроса с непрерывным отрезком упорядоченных триграмм текста.
+func wordSimilarity(query, text string) float64 {
+	queryTrigrams := make(map[string]bool)
+	for _, trigram := range trigrams(query) {
+		queryTrigrams[trigram] = true
+	}
+	if len(queryTrigrams) == 0 {
+		return 0
+	}
+
+	textTrigrams := trigrams(text)
+	var best float64
+	for start := range textTrigrams {
+		extent := make(map[string]bool)
+		common := 0
+		for _, trigram := range textTrigrams[start:] {
+			if !extent[trigram] {
+				extent[trigram] = true
+				if queryTrigrams[trigram] {
+					common++
+				}
+			}
+			similarity := float64(common) / float64(len(queryTrigrams)+len(extent)-common)
+			if similarity > best {
+				best = similarity
+			}
+		}
+	}
+	return best
+}
+
+// trigrams возвращает триграммы слов строки по порядку. Как и в pg_trgm, слово дополняется
+// двумя пробелами в начале и одним в конце.
+func trigrams(s string) []string {
+	var result []string
+	for _, word := range searchWords(s) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			result = append(result, string(padded[i:i+3]))
+		}
+	}
+	return result
+}
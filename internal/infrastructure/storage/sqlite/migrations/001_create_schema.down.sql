@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS object_outbox;
+DROP TABLE IF EXISTS file_deletions;
+DROP TABLE IF EXISTS thumbnails;
+DROP TABLE IF EXISTS object_keys;
+DROP TABLE IF EXISTS share_links;
+DROP TABLE IF EXISTS file_shares;
+DROP TABLE IF EXISTS file_versions;
+DROP TABLE IF EXISTS files;
+DROP TABLE IF EXISTS folders;
+DROP TABLE IF EXISTS blobs;
+DROP TABLE IF EXISTS users;
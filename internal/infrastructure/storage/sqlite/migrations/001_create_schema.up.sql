@@ -0,0 +1,220 @@
+-- Схема SQLite соответствует миграциям Postgres 001–018. Время хранится текстом в UTC (см. timeFormat),
+-- now() и REGEXP регистрируются приложением при открытии соединения. Теги и метаданные файла — JSON.
+CREATE TABLE IF NOT EXISTS users
+(
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    first_name    TEXT      NOT NULL,
+    last_name     TEXT      NOT NULL,
+    email         TEXT      NOT NULL UNIQUE,
+    password_salt TEXT      NOT NULL,
+    plan          TEXT      NOT NULL DEFAULT '',
+    created_at    TIMESTAMP DEFAULT (now()),
+    updated_at    TIMESTAMP DEFAULT (now()),
+
+    CONSTRAINT chk_email_not_empty CHECK (email <> ''),
+    CONSTRAINT chk_email_format CHECK (email REGEXP '^[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}$')
+);
+
+CREATE TABLE IF NOT EXISTS blobs
+(
+    sha256               TEXT PRIMARY KEY,
+    s3_key               TEXT      NOT NULL,
+    size_in_bytes        INTEGER   NOT NULL,
+    crc32c               TEXT      NOT NULL DEFAULT '',
+    ref_count            INTEGER   NOT NULL DEFAULT 0,
+    content_encoding     TEXT      NOT NULL DEFAULT '',
+    stored_size_in_bytes INTEGER   NOT NULL DEFAULT 0,
+    created_at           TIMESTAMP DEFAULT (now()),
+    updated_at           TIMESTAMP DEFAULT (now()),
+
+    CONSTRAINT chk_blobs_ref_count CHECK (ref_count >= 0)
+);
+
+CREATE INDEX idx_blobs_unreferenced ON blobs (updated_at) WHERE ref_count = 0;
+
+CREATE TABLE IF NOT EXISTS folders
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    parent_id  INTEGER REFERENCES folders (id) ON DELETE CASCADE,
+    name       TEXT      NOT NULL,
+    created_at TIMESTAMP DEFAULT (now()),
+    updated_at TIMESTAMP DEFAULT (now()),
+
+    CONSTRAINT chk_folders_name CHECK (name <> '' AND instr(name, '/') = 0)
+);
+
+CREATE UNIQUE INDEX idx_folders_unique_name ON folders (user_id, COALESCE(parent_id, 0), name);
+CREATE INDEX idx_folders_parent_id ON folders (parent_id);
+
+CREATE TABLE IF NOT EXISTS files
+(
+    id                   INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id              INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    original_name        TEXT      NOT NULL,
+    mime_type            TEXT      NOT NULL,
+    size_in_bytes        INTEGER   NOT NULL,
+    s3_bucket            TEXT      NOT NULL,
+    s3_key               TEXT      NOT NULL,
+    status               INTEGER   NOT NULL DEFAULT 0,
+    created_at           TIMESTAMP DEFAULT (now()),
+    updated_at           TIMESTAMP DEFAULT (now()),
+    is_public            BOOLEAN   NOT NULL DEFAULT 0,
+    checksum_sha256      TEXT      NOT NULL DEFAULT '',
+    checksum_crc32c      TEXT      NOT NULL DEFAULT '',
+    checksum_verified_at TIMESTAMP,
+    checksum_failed      BOOLEAN   NOT NULL DEFAULT 0,
+    blob_sha256          TEXT REFERENCES blobs (sha256),
+    folder_id            INTEGER REFERENCES folders (id),
+    current_version_id   INTEGER REFERENCES file_versions (id) ON DELETE SET NULL,
+    deleted_at           TIMESTAMP,
+    search_text          TEXT      NOT NULL DEFAULT '',
+    tags                 TEXT      NOT NULL DEFAULT '[]',
+    metadata             TEXT      NOT NULL DEFAULT '{}',
+    content_encoding     TEXT      NOT NULL DEFAULT '',
+    stored_size_in_bytes INTEGER   NOT NULL DEFAULT 0,
+    scan_status          TEXT      NOT NULL DEFAULT '',
+    scan_signature       TEXT      NOT NULL DEFAULT '',
+    scanned_at           TIMESTAMP,
+    expires_at           TIMESTAMP
+);
+
+CREATE INDEX idx_files_user_id ON files (user_id);
+CREATE INDEX idx_files_status ON files (status);
+CREATE INDEX idx_files_checksum_verified_at ON files (checksum_verified_at);
+CREATE INDEX idx_files_blob_sha256 ON files (blob_sha256);
+CREATE INDEX idx_files_folder_id ON files (folder_id);
+CREATE INDEX idx_files_deleted_at ON files (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_files_expires_at ON files (expires_at) WHERE expires_at IS NOT NULL;
+CREATE UNIQUE INDEX idx_files_unique_name ON files (user_id, COALESCE(folder_id, 0), original_name)
+    WHERE deleted_at IS NULL;
+CREATE INDEX idx_files_list_name ON files (user_id, original_name, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_files_list_size ON files (user_id, size_in_bytes, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_files_list_created ON files (user_id, created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_files_list_updated ON files (user_id, updated_at, id) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS file_versions
+(
+    id                   INTEGER PRIMARY KEY AUTOINCREMENT,
+    file_id              INTEGER   NOT NULL REFERENCES files (id) ON DELETE CASCADE,
+    version_number       INTEGER   NOT NULL,
+    s3_key               TEXT      NOT NULL,
+    size_in_bytes        INTEGER   NOT NULL,
+    mime_type            TEXT      NOT NULL,
+    checksum_sha256      TEXT      NOT NULL DEFAULT '',
+    checksum_crc32c      TEXT      NOT NULL DEFAULT '',
+    blob_sha256          TEXT REFERENCES blobs (sha256),
+    created_by           INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    content_encoding     TEXT      NOT NULL DEFAULT '',
+    stored_size_in_bytes INTEGER   NOT NULL DEFAULT 0,
+    scan_status          TEXT      NOT NULL DEFAULT '',
+    scan_signature       TEXT      NOT NULL DEFAULT '',
+    scanned_at           TIMESTAMP,
+    created_at           TIMESTAMP DEFAULT (now()),
+
+    CONSTRAINT unique_file_version UNIQUE (file_id, version_number)
+);
+
+CREATE INDEX idx_file_versions_blob_sha256 ON file_versions (blob_sha256);
+
+CREATE TABLE IF NOT EXISTS file_shares
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    file_id    INTEGER   NOT NULL REFERENCES files (id) ON DELETE CASCADE,
+    user_id    INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    permission TEXT      NOT NULL,
+    created_by INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT (now()),
+    updated_at TIMESTAMP DEFAULT (now()),
+
+    CONSTRAINT unique_file_share UNIQUE (file_id, user_id),
+    CONSTRAINT chk_file_shares_permission CHECK (permission IN ('viewer', 'editor'))
+);
+
+CREATE INDEX idx_file_shares_user_id ON file_shares (user_id);
+
+CREATE TABLE IF NOT EXISTS share_links
+(
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    file_id        INTEGER   NOT NULL REFERENCES files (id) ON DELETE CASCADE,
+    user_id        INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash     TEXT      NOT NULL UNIQUE,
+    password_hash  TEXT,
+    expires_at     TIMESTAMP,
+    max_downloads  INTEGER,
+    download_count INTEGER   NOT NULL DEFAULT 0,
+    revoked_at     TIMESTAMP,
+    created_at     TIMESTAMP DEFAULT (now()),
+    updated_at     TIMESTAMP DEFAULT (now()),
+
+    CONSTRAINT chk_share_links_max_downloads CHECK (max_downloads IS NULL OR max_downloads > 0)
+);
+
+CREATE INDEX idx_share_links_user_id ON share_links (user_id, created_at);
+CREATE INDEX idx_share_links_file_id ON share_links (file_id);
+
+-- Ключи данных зашифрованных объектов. Объект без строки в этой таблице хранится открытым текстом.
+CREATE TABLE IF NOT EXISTS object_keys
+(
+    s3_key         TEXT PRIMARY KEY,
+    key_id         TEXT      NOT NULL,
+    wrapped_key    BLOB      NOT NULL,
+    chunk_size     INTEGER   NOT NULL,
+    plaintext_size INTEGER   NOT NULL,
+    created_at     TIMESTAMP DEFAULT (now()),
+    updated_at     TIMESTAMP DEFAULT (now()),
+
+    CONSTRAINT chk_object_keys_chunk_size CHECK (chunk_size > 0),
+    CONSTRAINT chk_object_keys_plaintext_size CHECK (plaintext_size >= 0)
+);
+
+CREATE INDEX idx_object_keys_key_id ON object_keys (key_id);
+
+-- Миниатюры версии version_id. Внешнего ключа на files нет: строки остаются после удаления файла,
+-- пока фоновая задача не удалит их объекты.
+CREATE TABLE IF NOT EXISTS thumbnails
+(
+    file_id       INTEGER   NOT NULL,
+    version_id    INTEGER   NOT NULL,
+    size          INTEGER   NOT NULL,
+    format        TEXT      NOT NULL,
+    status        INTEGER   NOT NULL,
+    s3_key        TEXT      NOT NULL DEFAULT '',
+    width         INTEGER   NOT NULL DEFAULT 0,
+    height        INTEGER   NOT NULL DEFAULT 0,
+    size_in_bytes INTEGER   NOT NULL DEFAULT 0,
+    created_at    TIMESTAMP DEFAULT (now()),
+
+    PRIMARY KEY (file_id, version_id, size, format),
+    CONSTRAINT chk_thumbnails_size CHECK (size > 0)
+);
+
+-- Журнал удалений по истечении срока. Внешних ключей нет: записи переживают и файл, и пользователя.
+CREATE TABLE IF NOT EXISTS file_deletions
+(
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    file_id       INTEGER   NOT NULL,
+    user_id       INTEGER   NOT NULL,
+    original_name TEXT      NOT NULL,
+    size_in_bytes INTEGER   NOT NULL,
+    reason        TEXT      NOT NULL,
+    expires_at    TIMESTAMP,
+    deleted_at    TIMESTAMP DEFAULT (now())
+);
+
+CREATE INDEX idx_file_deletions_user_id ON file_deletions (user_id, deleted_at);
+
+CREATE TABLE IF NOT EXISTS object_outbox
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    operation    TEXT      NOT NULL,
+    s3_key       TEXT      NOT NULL,
+    source_key   TEXT      NOT NULL DEFAULT '',
+    attempts     INTEGER   NOT NULL DEFAULT 0,
+    last_error   TEXT      NOT NULL DEFAULT '',
+    available_at TIMESTAMP NOT NULL DEFAULT (now()),
+    dead_at      TIMESTAMP,
+    created_at   TIMESTAMP NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX idx_object_outbox_available_at ON object_outbox (available_at, id) WHERE dead_at IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE object_keys DROP COLUMN rewrap_failed_at;
@@ -0,0 +1,2 @@
+-- Соответствует миграции Postgres 019.
+ALTER TABLE object_keys ADD COLUMN rewrap_failed_at TIMESTAMP;
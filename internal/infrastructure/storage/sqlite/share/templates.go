@@ -0,0 +1,71 @@
+package share
+
+const shareColumns = `s.id, s.file_id, s.user_id, u.email AS user_email, s.permission, s.created_by, s.created_at, s.updated_at`
+
+const (
+	// UpsertShareTemplate выдает доступ пользователю с email ?2 или меняет уже выданное право.
+	// Владельцу файла доступ не выдается: он и так имеет полные права.
+	UpsertShareTemplate = `
+INSERT INTO file_shares (file_id, user_id, permission, created_by)
+SELECT f.id, u.id, ?3, ?4
+FROM files f, users u
+WHERE f.id = ?1 AND u.email = ?2 AND u.id <> f.user_id
+ON CONFLICT (file_id, user_id) DO UPDATE
+    SET permission = excluded.permission, updated_at = now()
+RETURNING id, file_id, user_id, (SELECT email FROM users WHERE users.id = file_shares.user_id) AS user_email,
+          permission, created_by, created_at, updated_at;`
+
+	ListFileSharesTemplate = `
+SELECT ` + shareColumns + `
+FROM file_shares s
+INNER JOIN users u ON s.user_id = u.id
+WHERE s.file_id = ?1
+ORDER BY u.email;`
+
+	GetSharePermissionTemplate = `
+SELECT permission
+FROM file_shares
+WHERE file_id = ?1 AND user_id = ?2;`
+
+	DeleteShareTemplate = `
+DELETE FROM file_shares
+WHERE file_id = ?1 AND user_id = ?2;`
+)
+
+const linkColumns = `id, file_id, user_id, token_hash, password_hash, expires_at, max_downloads,
+       download_count, revoked_at, created_at, updated_at`
+
+const (
+	CreateShareLinkTemplate = `
+INSERT INTO share_links (file_id, user_id, token_hash, password_hash, expires_at, max_downloads)
+VALUES (:file_id, :user_id, :token_hash, :password_hash, :expires_at, :max_downloads)
+RETURNING ` + linkColumns + `;`
+
+	GetShareLinkByTokenHashTemplate = `
+SELECT ` + linkColumns + `
+FROM share_links
+WHERE token_hash = ?1;`
+
+	ListShareLinksTemplate = `
+SELECT ` + linkColumns + `
+FROM share_links
+WHERE user_id = ?1 AND (?2 IS NULL OR file_id = ?2)
+ORDER BY created_at DESC, id DESC;`
+
+	RevokeShareLinkTemplate = `
+UPDATE share_links
+SET revoked_at = COALESCE(revoked_at, now()), updated_at = now()
+WHERE id = ?1 AND user_id = ?2
+RETURNING ` + linkColumns + `;`
+
+	// ClaimShareLinkDownloadTemplate атомарно засчитывает скачивание, пока ссылка действует,
+	// поэтому параллельные запросы не превысят max_downloads.
+	ClaimShareLinkDownloadTemplate = `
+UPDATE share_links
+SET download_count = download_count + 1, updated_at = now()
+WHERE id = ?1
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > now())
+  AND (max_downloads IS NULL OR download_count < max_downloads)
+RETURNING ` + linkColumns + `;`
+)
@@ -0,0 +1,57 @@
+package thumbnail
+
+const thumbnailColumns = `t.file_id, t.version_id, t.size, t.format, t.status, t.s3_key, t.width, t.height,
+       t.size_in_bytes, t.created_at`
+
+// returningThumbnailColumns — те же колонки для RETURNING: SQLite не разрешает в нем псевдоним таблицы.
+const returningThumbnailColumns = `file_id, version_id, size, format, status, s3_key, width, height,
+       size_in_bytes, created_at`
+
+const (
+	SaveThumbnailTemplate = `
+INSERT INTO thumbnails (file_id, version_id, size, format, status, s3_key, width, height, size_in_bytes)
+VALUES (:file_id, :version_id, :size, :format, :status, :s3_key, :width, :height, :size_in_bytes)
+ON CONFLICT (file_id, version_id, size, format) DO UPDATE
+    SET status        = EXCLUDED.status,
+        s3_key        = EXCLUDED.s3_key,
+        width         = EXCLUDED.width,
+        height        = EXCLUDED.height,
+        size_in_bytes = EXCLUDED.size_in_bytes,
+        created_at    = now()
+RETURNING ` + returningThumbnailColumns + `;`
+
+	GetThumbnailTemplate = `
+SELECT ` + thumbnailColumns + `
+FROM thumbnails t
+WHERE t.file_id = ?1
+  AND t.version_id = ?2
+  AND t.size = ?3
+  AND t.format = ?4;`
+
+	ListThumbnailsByVersionTemplate = `
+SELECT ` + thumbnailColumns + `
+FROM thumbnails t
+WHERE t.file_id = ?1
+  AND t.version_id = ?2
+ORDER BY t.size, t.format;`
+
+	ListStaleThumbnailsTemplate = `
+SELECT ` + thumbnailColumns + `
+FROM thumbnails t
+LEFT JOIN files f ON f.id = t.file_id
+WHERE f.id IS NULL
+   OR f.current_version_id IS NOT t.version_id
+ORDER BY t.created_at
+LIMIT ?1;`
+
+	DeleteThumbnailTemplate = `
+DELETE FROM thumbnails
+WHERE file_id = ?1
+  AND version_id = ?2
+  AND size = ?3
+  AND format = ?4;`
+
+	DeleteThumbnailByKeyTemplate = `
+DELETE FROM thumbnails
+WHERE s3_key = ?1;`
+)
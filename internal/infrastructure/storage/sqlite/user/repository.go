@@ -0,0 +1,21 @@
+package user
+
+import (
+	"meemo/internal/domain/user/repository"
+	sqlstore "meemo/internal/infrastructure/storage/sqlstore/user"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var templates = sqlstore.Templates{
+	CreateUser:      CreateUserTemplate,
+	GetUserByEmail:  GetUserByEmailTemplate,
+	UpdateUser:      UpdateUserTemplate,
+	DeleteUser:      DeleteUserTemplate,
+	UpdateUserEmail: UpdateUserEmailTemplate,
+	CheckPassword:   CheckPasswordQuery,
+}
+
+func NewUserRepository(conn *sqlx.DB) repository.UserRepository {
+	return sqlstore.NewUserRepository(conn, templates)
+}
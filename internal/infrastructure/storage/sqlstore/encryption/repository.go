@@ -0,0 +1,116 @@
+package encryption
+
+import (
+	"context"
+	"database/sql"
+	"meemo/internal/domain/encryption/repository"
+	"meemo/internal/domain/entity"
+	"meemo/internal/infrastructure/storage/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type objectKeyRepository struct {
+	conn      *sqlx.DB
+	templates Templates
+}
+
+func NewObjectKeyRepository(conn *sqlx.DB, templates Templates) repository.ObjectKeyRepository {
+	return &objectKeyRepository{
+		conn:      conn,
+		templates: templates,
+	}
+}
+
+func (r *objectKeyRepository) Save(ctx context.Context, key *entity.ObjectKey) (*entity.ObjectKey, error) {
+	keyModel := &model.ObjectKey{}
+	if err := keyModel.EntityToModel(key); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.NamedQueryContext(ctx, r.templates.SaveObjectKey, keyModel)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if rows.Next() {
+		if err := rows.StructScan(keyModel); err != nil {
+			return nil, err
+		}
+		return keyModel.ModelToEntity(), nil
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *objectKeyRepository) Get(ctx context.Context, s3Key string) (*entity.ObjectKey, error) {
+	keyModel := &model.ObjectKey{}
+
+	err := r.conn.QueryRowxContext(ctx, r.templates.GetObjectKey, s3Key).StructScan(keyModel)
+	if err != nil {
+		return nil, err
+	}
+	return keyModel.ModelToEntity(), nil
+}
+
+func (r *objectKeyRepository) Copy(ctx context.Context, srcKey, dstKey string) error {
+	result, err := r.conn.ExecContext(ctx, r.templates.CopyObjectKey, srcKey, dstKey)
+	if err != nil {
+		return err
+	}
+	copied, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if copied == 0 {
+		return r.Delete(ctx, dstKey)
+	}
+	return nil
+}
+
+func (r *objectKeyRepository) Delete(ctx context.Context, s3Key string) error {
+	_, err := r.conn.ExecContext(ctx, r.templates.DeleteObjectKey, s3Key)
+	return err
+}
+
+func (r *objectKeyRepository) ListWrappedWithOther(ctx context.Context, keyID string, limit int) ([]*entity.ObjectKey, error) {
+	rows, err := r.conn.QueryxContext(ctx, r.templates.ListObjectKeysWrappedWithOther, keyID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var keys []*entity.ObjectKey
+	for rows.Next() {
+		keyModel := &model.ObjectKey{}
+		if err := rows.StructScan(keyModel); err != nil {
+			return nil, err
+		}
+		keys = append(keys, keyModel.ModelToEntity())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *objectKeyRepository) Rewrap(ctx context.Context, s3Key, oldKeyID, newKeyID string, wrappedKey []byte) (bool, error) {
+	result, err := r.conn.ExecContext(ctx, r.templates.RewrapObjectKey, s3Key, oldKeyID, newKeyID, wrappedKey)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return updated > 0, nil
+}
+
+func (r *objectKeyRepository) MarkRewrapFailed(ctx context.Context, s3Key, keyID string) error {
+	_, err := r.conn.ExecContext(ctx, r.templates.MarkObjectKeyRewrapFailed, s3Key, keyID)
+	return err
+}
@@ -0,0 +1,12 @@
+package encryption
+
+// Templates — запросы к object_keys; PostgreSQL и SQLite отличаются только текстом SQL.
+type Templates struct {
+	SaveObjectKey                  string
+	GetObjectKey                   string
+	CopyObjectKey                  string
+	DeleteObjectKey                string
+	ListObjectKeysWrappedWithOther string
+	RewrapObjectKey                string
+	MarkObjectKeyRewrapFailed      string
+}
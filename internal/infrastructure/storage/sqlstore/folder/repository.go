@@ -0,0 +1,120 @@
+package folder
+
+import (
+	"context"
+	"database/sql"
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/folder/repository"
+	"meemo/internal/infrastructure/storage/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type folderRepository struct {
+	conn      *sqlx.DB
+	templates Templates
+}
+
+func NewFolderRepository(conn *sqlx.DB, templates Templates) repository.FolderRepository {
+	return &folderRepository{
+		conn:      conn,
+		templates: templates,
+	}
+}
+
+func (r *folderRepository) Create(ctx context.Context, userID int64, parentID *int64, name string) (*entity.Folder, error) {
+	folderModel := &model.Folder{}
+
+	err := r.conn.QueryRowxContext(ctx, r.templates.CreateFolder, userID, model.PtrToNullInt64(parentID), name).StructScan(folderModel)
+	if err != nil {
+		return nil, err
+	}
+	return folderModel.ModelToEntity(), nil
+}
+
+func (r *folderRepository) Get(ctx context.Context, userID, folderID int64) (*entity.Folder, error) {
+	folderModel := &model.Folder{}
+
+	err := r.conn.QueryRowxContext(ctx, r.templates.GetFolder, folderID, userID).StructScan(folderModel)
+	if err != nil {
+		return nil, err
+	}
+	return folderModel.ModelToEntity(), nil
+}
+
+func (r *folderRepository) Rename(ctx context.Context, userID, folderID int64, name string) (*entity.Folder, error) {
+	folderModel := &model.Folder{}
+
+	err := r.conn.QueryRowxContext(ctx, r.templates.RenameFolder, name, folderID, userID).StructScan(folderModel)
+	if err != nil {
+		return nil, err
+	}
+	return folderModel.ModelToEntity(), nil
+}
+
+func (r *folderRepository) Move(ctx context.Context, userID, folderID int64, parentID *int64) (*entity.Folder, error) {
+	folderModel := &model.Folder{}
+
+	err := r.conn.QueryRowxContext(ctx, r.templates.MoveFolder, model.PtrToNullInt64(parentID), folderID, userID).StructScan(folderModel)
+	if err != nil {
+		return nil, err
+	}
+	return folderModel.ModelToEntity(), nil
+}
+
+func (r *folderRepository) ListChildren(ctx context.Context, userID int64, parentID *int64) ([]*entity.Folder, error) {
+	return r.queryFolders(ctx, r.templates.ListChildFolders, userID, model.PtrToNullInt64(parentID))
+}
+
+func (r *folderRepository) ListTree(ctx context.Context, userID, folderID int64) ([]*entity.Folder, error) {
+	return r.queryFolders(ctx, r.templates.ListFolderTree, userID, folderID)
+}
+
+func (r *folderRepository) queryFolders(ctx context.Context, query string, args ...any) ([]*entity.Folder, error) {
+	rows, err := r.conn.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var folders []*entity.Folder
+	for rows.Next() {
+		folderModel := &model.Folder{}
+		if err := rows.StructScan(folderModel); err != nil {
+			return nil, err
+		}
+		folders = append(folders, folderModel.ModelToEntity())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return folders, nil
+}
+
+func (r *folderRepository) Delete(ctx context.Context, userID, folderID int64) error {
+	result, err := r.conn.ExecContext(ctx, r.templates.DeleteFolder, folderID, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *folderRepository) GetSize(ctx context.Context, userID, folderID int64) (*entity.FolderSize, error) {
+	sizeModel := &model.FolderSize{}
+
+	err := r.conn.QueryRowxContext(ctx, r.templates.GetFolderSize, folderID, userID).StructScan(sizeModel)
+	if err != nil {
+		return nil, err
+	}
+	return sizeModel.ModelToEntity(), nil
+}
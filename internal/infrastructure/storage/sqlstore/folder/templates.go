@@ -0,0 +1,13 @@
+package folder
+
+// Templates — запросы репозитория папок на диалекте конкретной базы.
+type Templates struct {
+	CreateFolder     string
+	GetFolder        string
+	RenameFolder     string
+	MoveFolder       string
+	ListChildFolders string
+	ListFolderTree   string
+	DeleteFolder     string
+	GetFolderSize    string
+}
@@ -0,0 +1,92 @@
+package share
+
+import (
+	"context"
+	"database/sql"
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/share/repository"
+	"meemo/internal/infrastructure/storage/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type shareLinkRepository struct {
+	conn      *sqlx.DB
+	templates Templates
+}
+
+func NewShareLinkRepository(conn *sqlx.DB, templates Templates) repository.ShareLinkRepository {
+	return &shareLinkRepository{
+		conn:      conn,
+		templates: templates,
+	}
+}
+
+func (r *shareLinkRepository) CreateLink(ctx context.Context, link *entity.ShareLink) (*entity.ShareLink, error) {
+	linkModel := &model.ShareLink{}
+	if err := linkModel.EntityToModel(link); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.NamedQueryContext(ctx, r.templates.CreateShareLink, linkModel)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if rows.Next() {
+		if err := rows.StructScan(linkModel); err != nil {
+			return nil, err
+		}
+		return linkModel.ModelToEntity(), nil
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *shareLinkRepository) GetLinkByTokenHash(ctx context.Context, tokenHash string) (*entity.ShareLink, error) {
+	return r.queryLink(ctx, r.templates.GetShareLinkByTokenHash, tokenHash)
+}
+
+func (r *shareLinkRepository) ListLinks(ctx context.Context, userID int64, fileID *int64) ([]*entity.ShareLink, error) {
+	rows, err := r.conn.QueryxContext(ctx, r.templates.ListShareLinks, userID, model.PtrToNullInt64(fileID))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var links []*entity.ShareLink
+	for rows.Next() {
+		linkModel := &model.ShareLink{}
+		if err := rows.StructScan(linkModel); err != nil {
+			return nil, err
+		}
+		links = append(links, linkModel.ModelToEntity())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+func (r *shareLinkRepository) RevokeLink(ctx context.Context, userID, linkID int64) (*entity.ShareLink, error) {
+	return r.queryLink(ctx, r.templates.RevokeShareLink, linkID, userID)
+}
+
+func (r *shareLinkRepository) ClaimDownload(ctx context.Context, linkID int64) (*entity.ShareLink, error) {
+	return r.queryLink(ctx, r.templates.ClaimShareLinkDownload, linkID)
+}
+
+func (r *shareLinkRepository) queryLink(ctx context.Context, query string, args ...any) (*entity.ShareLink, error) {
+	linkModel := &model.ShareLink{}
+
+	err := r.conn.QueryRowxContext(ctx, query, args...).StructScan(linkModel)
+	if err != nil {
+		return nil, err
+	}
+	return linkModel.ModelToEntity(), nil
+}
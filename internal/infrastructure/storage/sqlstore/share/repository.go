@@ -0,0 +1,80 @@
+package share
+
+import (
+	"context"
+	"database/sql"
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/share/repository"
+	"meemo/internal/infrastructure/storage/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type shareRepository struct {
+	conn      *sqlx.DB
+	templates Templates
+}
+
+func NewShareRepository(conn *sqlx.DB, templates Templates) repository.ShareRepository {
+	return &shareRepository{
+		conn:      conn,
+		templates: templates,
+	}
+}
+
+func (r *shareRepository) Upsert(ctx context.Context, fileID int64, userEmail string, permission entity.SharePermission, createdBy int64) (*entity.FileShare, error) {
+	shareModel := &model.FileShare{}
+
+	err := r.conn.QueryRowxContext(ctx, r.templates.UpsertShare, fileID, userEmail, string(permission), createdBy).StructScan(shareModel)
+	if err != nil {
+		return nil, err
+	}
+	return shareModel.ModelToEntity(), nil
+}
+
+func (r *shareRepository) ListByFile(ctx context.Context, fileID int64) ([]*entity.FileShare, error) {
+	rows, err := r.conn.QueryxContext(ctx, r.templates.ListFileShares, fileID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var shares []*entity.FileShare
+	for rows.Next() {
+		shareModel := &model.FileShare{}
+		if err := rows.StructScan(shareModel); err != nil {
+			return nil, err
+		}
+		shares = append(shares, shareModel.ModelToEntity())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+func (r *shareRepository) GetPermission(ctx context.Context, fileID, userID int64) (entity.SharePermission, error) {
+	var permission string
+	if err := r.conn.QueryRowxContext(ctx, r.templates.GetSharePermission, fileID, userID).Scan(&permission); err != nil {
+		return "", err
+	}
+	return entity.SharePermission(permission), nil
+}
+
+func (r *shareRepository) Delete(ctx context.Context, fileID, userID int64) error {
+	result, err := r.conn.ExecContext(ctx, r.templates.DeleteShare, fileID, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package share
+
+// Templates — запросы доступов и публичных ссылок на диалекте конкретной базы.
+type Templates struct {
+	UpsertShare             string
+	ListFileShares          string
+	GetSharePermission      string
+	DeleteShare             string
+	CreateShareLink         string
+	GetShareLinkByTokenHash string
+	ListShareLinks          string
+	RevokeShareLink         string
+	ClaimShareLinkDownload  string
+}
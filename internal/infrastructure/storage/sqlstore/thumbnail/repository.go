@@ -0,0 +1,97 @@
+package thumbnail
+
+import (
+	"context"
+	"database/sql"
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/thumbnail/repository"
+	"meemo/internal/infrastructure/storage/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type thumbnailRepository struct {
+	conn      *sqlx.DB
+	templates Templates
+}
+
+func NewThumbnailRepository(conn *sqlx.DB, templates Templates) repository.ThumbnailRepository {
+	return &thumbnailRepository{
+		conn:      conn,
+		templates: templates,
+	}
+}
+
+func (r *thumbnailRepository) Save(ctx context.Context, thumbnail *entity.Thumbnail) (*entity.Thumbnail, error) {
+	thumbnailModel := &model.Thumbnail{}
+	if err := thumbnailModel.EntityToModel(thumbnail); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.NamedQueryContext(ctx, r.templates.SaveThumbnail, thumbnailModel)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if rows.Next() {
+		if err := rows.StructScan(thumbnailModel); err != nil {
+			return nil, err
+		}
+		return thumbnailModel.ModelToEntity(), nil
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *thumbnailRepository) Get(ctx context.Context, fileID, versionID int64, size int, format string) (*entity.Thumbnail, error) {
+	thumbnailModel := &model.Thumbnail{}
+
+	err := r.conn.QueryRowxContext(ctx, r.templates.GetThumbnail, fileID, versionID, size, format).StructScan(thumbnailModel)
+	if err != nil {
+		return nil, err
+	}
+	return thumbnailModel.ModelToEntity(), nil
+}
+
+func (r *thumbnailRepository) ListByVersion(ctx context.Context, fileID, versionID int64) ([]*entity.Thumbnail, error) {
+	return r.queryThumbnails(ctx, r.templates.ListThumbnailsByVersion, fileID, versionID)
+}
+
+func (r *thumbnailRepository) ListStale(ctx context.Context, limit int) ([]*entity.Thumbnail, error) {
+	return r.queryThumbnails(ctx, r.templates.ListStaleThumbnails, limit)
+}
+
+func (r *thumbnailRepository) Delete(ctx context.Context, thumbnail *entity.Thumbnail) error {
+	_, err := r.conn.ExecContext(ctx, r.templates.DeleteThumbnail, thumbnail.FileID, thumbnail.VersionID, thumbnail.Size, thumbnail.Format)
+	return err
+}
+
+func (r *thumbnailRepository) DeleteByKey(ctx context.Context, s3Key string) error {
+	_, err := r.conn.ExecContext(ctx, r.templates.DeleteThumbnailByKey, s3Key)
+	return err
+}
+
+func (r *thumbnailRepository) queryThumbnails(ctx context.Context, query string, args ...any) ([]*entity.Thumbnail, error) {
+	rows, err := r.conn.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var thumbnails []*entity.Thumbnail
+	for rows.Next() {
+		thumbnailModel := &model.Thumbnail{}
+		if err := rows.StructScan(thumbnailModel); err != nil {
+			return nil, err
+		}
+		thumbnails = append(thumbnails, thumbnailModel.ModelToEntity())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return thumbnails, nil
+}
@@ -0,0 +1,11 @@
+package thumbnail
+
+// Templates — запросы репозитория миниатюр на диалекте конкретной базы.
+type Templates struct {
+	SaveThumbnail           string
+	GetThumbnail            string
+	ListThumbnailsByVersion string
+	ListStaleThumbnails     string
+	DeleteThumbnail         string
+	DeleteThumbnailByKey    string
+}
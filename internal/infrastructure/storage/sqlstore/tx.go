@@ -0,0 +1,70 @@
+// Package sqlstore содержит репозитории и транзакции, общие для PostgreSQL и SQLite.
+// Отличия диалектов живут в шаблонах запросов пакетов pg и sqlite.
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// InTx выполняет fn в новой транзакции db и фиксирует ее, если fn не вернула ошибку.
+func InTx(ctx context.Context, db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// LocalTx — транзакция одного метода репозитория. Внутри внешней транзакции это точка сохранения,
+// а фиксирует изменения владелец внешней транзакции.
+type LocalTx struct {
+	*sqlx.Tx
+	ctx       context.Context
+	savepoint bool
+	done      bool
+}
+
+// BeginLocal открывает транзакцию db или точку сохранения в outer, если она не nil.
+func BeginLocal(ctx context.Context, db *sqlx.DB, outer *sqlx.Tx) (*LocalTx, error) {
+	if outer != nil {
+		if _, err := outer.ExecContext(ctx, "SAVEPOINT local_tx"); err != nil {
+			return nil, err
+		}
+		return &LocalTx{Tx: outer, ctx: ctx, savepoint: true}, nil
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalTx{Tx: tx, ctx: ctx}, nil
+}
+
+func (t *LocalTx) Commit() error {
+	t.done = true
+	if t.savepoint {
+		_, err := t.Tx.ExecContext(t.ctx, "RELEASE SAVEPOINT local_tx")
+		return err
+	}
+	return t.Tx.Commit()
+}
+
+// Rollback откатывает незафиксированные изменения; после Commit ничего не делает.
+func (t *LocalTx) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	if t.savepoint {
+		_, err := t.Tx.ExecContext(t.ctx, "ROLLBACK TO SAVEPOINT local_tx")
+		return err
+	}
+	return t.Tx.Rollback()
+}
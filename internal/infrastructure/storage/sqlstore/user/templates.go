@@ -0,0 +1,11 @@
+package user
+
+// Templates — запросы репозитория пользователей на диалекте конкретной базы.
+type Templates struct {
+	CreateUser      string
+	GetUserByEmail  string
+	UpdateUser      string
+	DeleteUser      string
+	UpdateUserEmail string
+	CheckPassword   string
+}
@@ -0,0 +1,20 @@
+package interactor
+
+import (
+	"meemo/config"
+	"meemo/internal/domain/encryption/repository"
+	storage "meemo/internal/infrastructure/storage/pg/encryption"
+	sqlitestorage "meemo/internal/infrastructure/storage/sqlite/encryption"
+	usecase "meemo/internal/usecase/encryption"
+)
+
+func (i *interactor) NewObjectKeyRepository() repository.ObjectKeyRepository {
+	if i.metadata == config.MetadataBackendSQLite {
+		return sqlitestorage.NewObjectKeyRepository(i.conn)
+	}
+	return storage.NewObjectKeyRepository(i.conn)
+}
+
+func (i *interactor) NewEncryptionUseCase() usecase.Usecase {
+	return usecase.NewEncryptionUsecase(i.NewObjectKeyRepository(), i.keys, i.log)
+}
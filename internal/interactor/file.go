@@ -1,30 +1,135 @@
 package interactor
 
 import (
+	"meemo/config"
 	"meemo/internal/domain/file/repository"
 	"meemo/internal/domain/file/service"
+	folderrepository "meemo/internal/domain/folder/repository"
+	sharerepository "meemo/internal/domain/share/repository"
+	thumbnailrepository "meemo/internal/domain/thumbnail/repository"
+	"meemo/internal/infrastructure/notify"
 	storage "meemo/internal/infrastructure/storage/pg/file"
+	folderstorage "meemo/internal/infrastructure/storage/pg/folder"
+	sharestorage "meemo/internal/infrastructure/storage/pg/share"
+	thumbnailstorage "meemo/internal/infrastructure/storage/pg/thumbnail"
 	"meemo/internal/infrastructure/storage/s3/file"
+	sqlitestorage "meemo/internal/infrastructure/storage/sqlite/file"
+	sqlitefolderstorage "meemo/internal/infrastructure/storage/sqlite/folder"
+	sqlitesharestorage "meemo/internal/infrastructure/storage/sqlite/share"
+	sqlitethumbnailstorage "meemo/internal/infrastructure/storage/sqlite/thumbnail"
 	handler "meemo/internal/presenter/http/handler/file"
 	usecase "meemo/internal/usecase/file"
 )
 
 func (i *interactor) NewFileRepository() repository.FileRepository {
+	if i.metadata == config.MetadataBackendSQLite {
+		return sqlitestorage.NewFileRepository(i.conn)
+	}
 	return storage.NewFileRepository(i.conn)
 }
 
+func (i *interactor) NewFolderRepository() folderrepository.FolderRepository {
+	if i.metadata == config.MetadataBackendSQLite {
+		return sqlitefolderstorage.NewFolderRepository(i.conn)
+	}
+	return folderstorage.NewFolderRepository(i.conn)
+}
+
+func (i *interactor) NewShareRepository() sharerepository.ShareRepository {
+	if i.metadata == config.MetadataBackendSQLite {
+		return sqlitesharestorage.NewShareRepository(i.conn)
+	}
+	return sharestorage.NewShareRepository(i.conn)
+}
+
+func (i *interactor) NewShareLinkRepository() sharerepository.ShareLinkRepository {
+	if i.metadata == config.MetadataBackendSQLite {
+		return sqlitesharestorage.NewShareLinkRepository(i.conn)
+	}
+	return sharestorage.NewShareLinkRepository(i.conn)
+}
+
+func (i *interactor) NewThumbnailRepository() thumbnailrepository.ThumbnailRepository {
+	if i.metadata == config.MetadataBackendSQLite {
+		return sqlitethumbnailstorage.NewThumbnailRepository(i.conn)
+	}
+	return thumbnailstorage.NewThumbnailRepository(i.conn)
+}
+
 func (i *interactor) NewFileService() service.FileService {
 	return service.NewFileService()
 }
 
 func (i *interactor) NewS3Storage() file.S3Client {
-	return file.NewS3Client(i.s3client, i.s3bucket, i.log)
+	client := i.objects
+	if i.keys == nil {
+		return client
+	}
+	opts := file.EncryptionOptions{
+		EncryptWrites: i.encryption.Enabled,
+		ChunkSize:     i.encryption.ChunkSize,
+	}
+	return file.NewEncryptedS3Client(client, i.keys, i.NewObjectKeyRepository(), opts, i.log)
+}
+
+func (i *interactor) NewNotifier() notify.Notifier {
+	notifier := notify.NewLogNotifier(i.log)
+	if i.antivirus.WebhookURL == "" {
+		return notifier
+	}
+	return notify.NewWebhookNotifier(i.antivirus.WebhookURL, i.antivirus.WebhookTimeout, notifier)
 }
 
 func (i *interactor) NewFileUseCase() usecase.Usecase {
-	return usecase.NewFileUsecase(i.NewFileRepository(), i.NewFileService(), i.NewS3Storage(), i.log)
+	opts := usecase.Options{
+		Deduplication:     i.files.Deduplication,
+		DeleteConcurrency: i.files.DeleteConcurrency,
+		MaxFileSize:       i.files.MaxFileSize,
+		MimeDetection:     i.files.MimeDetection,
+		TypePolicy: usecase.TypePolicy{
+			TypeRules: typeRules(i.files.TypePolicy.TypeRulesConfig),
+		},
+		Thumbnails: usecase.ThumbnailOptions{
+			Enabled:         i.files.Thumbnails.Enabled,
+			Sizes:           i.files.Thumbnails.Sizes,
+			Formats:         i.files.Thumbnails.Formats,
+			JPEGQuality:     i.files.Thumbnails.JPEGQuality,
+			MaxSourceBytes:  i.files.Thumbnails.MaxSourceBytes,
+			MaxSourcePixels: i.files.Thumbnails.MaxSourcePixels,
+		},
+		Outbox: usecase.OutboxOptions{
+			MaxAttempts: i.jobs.ObjectOutbox.MaxAttempts,
+			RetryDelay:  i.jobs.ObjectOutbox.RetryDelay,
+			Lease:       i.jobs.ObjectOutbox.Lease,
+		},
+	}
+	if i.scanner != nil {
+		opts.Antivirus = usecase.AntivirusOptions{
+			Scanner:     i.scanner,
+			FailOpen:    i.antivirus.FailOpen,
+			Notifier:    i.NewNotifier(),
+			AdminEmails: i.adminEmails,
+		}
+	}
+	if plans := i.files.TypePolicy.Plans; len(plans) > 0 {
+		opts.TypePolicy.Plans = make(map[string]usecase.TypeRules, len(plans))
+		for plan, rules := range plans {
+			opts.TypePolicy.Plans[plan] = typeRules(rules)
+		}
+	}
+	if compression := i.files.Compression; compression.Enabled {
+		opts.Compression = file.CompressionPolicy{
+			MinSize:   compression.MinSize,
+			MimeTypes: compression.MimeTypes,
+		}
+	}
+	return usecase.NewFileUsecase(i.NewFileRepository(), i.NewFolderRepository(), i.NewShareRepository(), i.NewShareLinkRepository(), i.NewThumbnailRepository(), i.NewFileService(), i.NewS3Storage(), i.log, opts)
 }
 
 func (i *interactor) NewFileHandler() handler.FileHandler {
-	return handler.NewFileHandler(i.NewFileUseCase(), i.NewJWTTokenService(), i.log)
+	return handler.NewFileHandler(i.NewFileUseCase(), i.NewJWTTokenService(), i.adminEmails, i.log)
+}
+
+func typeRules(rules config.TypeRulesConfig) usecase.TypeRules {
+	return usecase.TypeRules{Allow: rules.Allow, Deny: rules.Deny}
 }
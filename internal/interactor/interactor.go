@@ -1,28 +1,59 @@
 package interactor
 
 import (
+	"meemo/config"
+	"meemo/internal/infrastructure/antivirus"
+	"meemo/internal/infrastructure/crypto"
 	"meemo/internal/infrastructure/logger"
+	"meemo/internal/infrastructure/scheduler"
+	"meemo/internal/infrastructure/storage/s3/file"
 	handler "meemo/internal/presenter/http/handler"
 	filehandler "meemo/internal/presenter/http/handler/file"
 	userhandler "meemo/internal/presenter/http/handler/user"
+	usecase "meemo/internal/usecase/file"
 
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/jmoiron/sqlx"
 )
 
 type Interactor interface {
 	NewAppHandler() handler.AppHandler
+	NewScheduler() scheduler.Scheduler
+	NewFileUseCase() usecase.Usecase
 }
 type interactor struct {
 	conn                *sqlx.DB
-	s3client            *s3.Client
-	s3bucket            string
+	metadata            string
+	objects             file.S3Client
 	log                 logger.Logger
 	registrationEnabled bool
+	adminEmails         []string
+	files               config.FilesConfig
+	encryption          config.EncryptionConfig
+	keys                crypto.KeyProvider
+	scanner             antivirus.Scanner
+	antivirus           config.AntivirusConfig
+	jobs                config.JobsConfig
 }
 
-func NewInteractor(conn *sqlx.DB, s3client *s3.Client, bucket string, log logger.Logger, registrationEnabled bool) Interactor {
-	return &interactor{conn: conn, s3client: s3client, s3bucket: bucket, log: log, registrationEnabled: registrationEnabled}
+// conn — соединение с хранилищем метаданных, выбранным в cfg.Metadata: от него зависит реализация репозиториев.
+// objects — хранилище содержимого (S3 или локальный диск) без шифрования: его добавляет NewS3Storage.
+// keys может быть nil: тогда содержимое файлов не шифруется и не расшифровывается.
+// scanner может быть nil: тогда загрузки не проверяются антивирусом.
+func NewInteractor(conn *sqlx.DB, objects file.S3Client, keys crypto.KeyProvider, scanner antivirus.Scanner, cfg *config.Config, log logger.Logger) Interactor {
+	return &interactor{
+		conn:                conn,
+		metadata:            cfg.Metadata.Backend,
+		objects:             objects,
+		log:                 log,
+		registrationEnabled: cfg.RegistrationEnabled,
+		adminEmails:         cfg.AdminEmails,
+		files:               cfg.Files,
+		encryption:          cfg.Encryption,
+		keys:                keys,
+		scanner:             scanner,
+		antivirus:           cfg.Antivirus,
+		jobs:                cfg.Jobs,
+	}
 }
 
 type appHandler struct {
@@ -0,0 +1,83 @@
+package interactor
+
+import (
+	"context"
+
+	"meemo/internal/infrastructure/scheduler"
+	encryptionusecase "meemo/internal/usecase/encryption"
+	usecase "meemo/internal/usecase/file"
+)
+
+func (i *interactor) NewScheduler() scheduler.Scheduler {
+	s := scheduler.NewScheduler(i.log)
+	fileUseCase := i.NewFileUseCase()
+
+	if scrubber := i.jobs.ChecksumScrubber; scrubber.Enabled {
+		s.Every("checksum-scrubber", scrubber.Interval, func(ctx context.Context) error {
+			_, err := fileUseCase.ScrubChecksums(ctx, &usecase.ScrubChecksumsDtoIn{
+				BatchSize:     scrubber.BatchSize,
+				ReverifyAfter: scrubber.ReverifyAfter,
+			})
+			return err
+		})
+	}
+
+	if collector := i.jobs.BlobCollector; collector.Enabled {
+		s.Every("blob-collector", collector.Interval, func(ctx context.Context) error {
+			_, err := fileUseCase.CollectBlobs(ctx, &usecase.CollectBlobsDtoIn{
+				BatchSize:   collector.BatchSize,
+				GracePeriod: collector.GracePeriod,
+			})
+			return err
+		})
+	}
+
+	if purger := i.jobs.TrashPurger; purger.Enabled {
+		s.Every("trash-purger", purger.Interval, func(ctx context.Context) error {
+			_, err := fileUseCase.PurgeTrash(ctx, &usecase.PurgeTrashDtoIn{
+				BatchSize: purger.BatchSize,
+				Retention: purger.Retention,
+			})
+			return err
+		})
+	}
+
+	if rewrapper := i.jobs.KeyRewrapper; rewrapper.Enabled && i.keys != nil {
+		encryptionUseCase := i.NewEncryptionUseCase()
+		s.Every("key-rewrapper", rewrapper.Interval, func(ctx context.Context) error {
+			_, err := encryptionUseCase.RewrapKeys(ctx, &encryptionusecase.RewrapKeysDtoIn{
+				BatchSize: rewrapper.BatchSize,
+			})
+			return err
+		})
+	}
+
+	if thumbnailer := i.jobs.Thumbnailer; thumbnailer.Enabled && i.files.Thumbnails.Enabled {
+		s.Every("thumbnailer", thumbnailer.Interval, func(ctx context.Context) error {
+			_, err := fileUseCase.GenerateThumbnails(ctx, &usecase.GenerateThumbnailsDtoIn{
+				BatchSize: thumbnailer.BatchSize,
+			})
+			return err
+		})
+	}
+
+	if expirer := i.jobs.FileExpirer; expirer.Enabled {
+		s.Every("file-expirer", expirer.Interval, func(ctx context.Context) error {
+			_, err := fileUseCase.ExpireFiles(ctx, &usecase.ExpireFilesDtoIn{
+				BatchSize: expirer.BatchSize,
+			})
+			return err
+		})
+	}
+
+	if outbox := i.jobs.ObjectOutbox; outbox.Enabled {
+		s.Every("object-outbox", outbox.Interval, func(ctx context.Context) error {
+			_, err := fileUseCase.ProcessObjectOutbox(ctx, &usecase.ProcessObjectOutboxDtoIn{
+				BatchSize: outbox.BatchSize,
+			})
+			return err
+		})
+	}
+
+	return s
+}
@@ -3,15 +3,20 @@ package interactor
 import (
 	"time"
 
+	"meemo/config"
 	tokenservice "meemo/internal/domain/token/service"
 	"meemo/internal/domain/user/repository"
 	userservice "meemo/internal/domain/user/service"
 	storage "meemo/internal/infrastructure/storage/pg/user"
+	sqlitestorage "meemo/internal/infrastructure/storage/sqlite/user"
 	handler "meemo/internal/presenter/http/handler/user"
 	usecase "meemo/internal/usecase/user"
 )
 
 func (i *interactor) NewUserRepository() repository.UserRepository {
+	if i.metadata == config.MetadataBackendSQLite {
+		return sqlitestorage.NewUserRepository(i.conn)
+	}
 	return storage.NewUserRepository(i.conn)
 }
 
@@ -0,0 +1,73 @@
+package file
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// DownloadArchive скачивает несколько файлов или папку одним архивом
+// @Summary Скачать архив
+// @Description Собирает ZIP (format=zip, по умолчанию) или tar.gz (format=tar.gz) из выбранных файлов и/или папки
+// @Description на лету, не сохраняя его на диск. Повторяющиеся имена получают суффикс " (N)"
+// @Tags files
+// @Accept json
+// @Produce application/zip
+// @Produce application/gzip
+// @Param request body ArchiveRequest true "Файлы и папка"
+// @Success 200 {file} binary
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/archive [post]
+func (h *fileHandler) DownloadArchive(c echo.Context) error {
+	var req ArchiveRequest
+	if err := c.Bind(&req); err != nil {
+		h.log.Warn("invalid JSON in DownloadArchive", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+	}
+
+	archive, err := h.fileUsecase.PrepareArchive(c.Request().Context(), &fileusecase.PrepareArchiveDtoIn{
+		UserID:   getUserID(c),
+		FileIDs:  req.FileIDs,
+		FolderID: req.FolderID,
+		Format:   req.Format,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, fileusecase.ErrInvalidArchive):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrFileNotFound), errors.Is(err, fileusecase.ErrFolderNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrAccessDenied), errors.Is(err, fileusecase.ErrFileQuarantined):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrFileNotReady):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrFileExpired):
+			return c.JSON(http.StatusGone, map[string]string{"error": err.Error()})
+		}
+		h.log.Error("failed to prepare archive", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to prepare archive"})
+	}
+
+	c.Response().Header().Set("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(archive.FileName))
+	c.Response().Header().Set("Content-Type", archive.ContentType)
+	c.Response().WriteHeader(http.StatusOK)
+
+	// Заголовки уже отправлены. Соединение обрывается, чтобы клиент не принял
+	// недописанный архив за целый.
+	if err := h.fileUsecase.WriteArchive(c.Request().Context(), archive, c.Response().Writer); err != nil {
+		h.log.Error("failed to stream archive", zap.String("fileName", archive.FileName), zap.Error(err))
+		panic(http.ErrAbortHandler)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package file
+
+import (
+	"errors"
+	"net/http"
+
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// BatchFiles применяет набор операций к файлам
+// @Summary Пакетные операции с файлами
+// @Description Выполняет до 1000 операций (delete, visibility, move, tag, untag) над файлами, заданными по file_id
+// @Description или по имени в корневой папке. Операции выполняются в одной транзакции, ошибка одной не отменяет остальные.
+// @Description delete перемещает файл в корзину, с permanent=true удаляет его безвозвратно
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param request body BatchFilesRequest true "Операции"
+// @Success 200 {object} BatchFilesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/batch [post]
+func (h *fileHandler) BatchFiles(c echo.Context) error {
+	var req BatchFilesRequest
+	if err := c.Bind(&req); err != nil {
+		h.log.Warn("invalid JSON in BatchFiles", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+	}
+
+	resp, err := h.fileUsecase.BatchFiles(c.Request().Context(), &fileusecase.BatchFilesDtoIn{
+		UserID:     getUserID(c),
+		Operations: req.Operations,
+	})
+	if err != nil {
+		if errors.Is(err, fileusecase.ErrInvalidBatch) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		h.log.Error("failed to apply batch", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to apply batch"})
+	}
+
+	out := BatchFilesResponse{
+		Results:   make([]BatchItemResponse, 0, len(resp.Results)),
+		Succeeded: resp.Succeeded,
+		Failed:    resp.Failed,
+	}
+	for _, item := range resp.Results {
+		out.Results = append(out.Results, BatchItemResponse{
+			Index:  item.Index,
+			Action: item.Action,
+			OK:     item.Err == nil,
+			Error:  h.batchItemError(item.Err),
+			File:   item.File,
+		})
+	}
+	return c.JSON(http.StatusOK, out)
+}
+
+func (h *fileHandler) batchItemError(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, fileusecase.ErrInvalidBatchOperation),
+		errors.Is(err, fileusecase.ErrInvalidTags),
+		errors.Is(err, fileusecase.ErrFileNotFound),
+		errors.Is(err, fileusecase.ErrFolderNotFound):
+		return err.Error()
+	case isUniqueViolation(err):
+		return "name already exists in the destination folder"
+	}
+
+	h.log.Error("batch operation failed", zap.Error(err))
+	return "internal error"
+}
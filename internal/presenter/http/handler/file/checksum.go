@@ -0,0 +1,62 @@
+package file
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderDigest         = "Digest"
+	HeaderContentSHA256  = "Content-SHA256"
+	HeaderChecksumSHA256 = "X-Checksum-SHA256"
+	HeaderChecksumCRC32C = "X-Checksum-CRC32C"
+)
+
+var errInvalidChecksumHeader = errors.New("invalid checksum header")
+
+// expectedSHA256 возвращает ожидаемый клиентом SHA-256 содержимого в hex.
+// Поддерживаются заголовки Content-SHA256 (hex) и Digest: SHA-256=<base64> (RFC 3230).
+func expectedSHA256(c echo.Context) (string, error) {
+	if v := strings.TrimSpace(c.Request().Header.Get(HeaderContentSHA256)); v != "" {
+		sum, err := hex.DecodeString(v)
+		if err != nil || len(sum) != 32 {
+			return "", errInvalidChecksumHeader
+		}
+		return hex.EncodeToString(sum), nil
+	}
+
+	digest := c.Request().Header.Get(HeaderDigest)
+	if digest == "" {
+		return "", nil
+	}
+
+	for _, part := range strings.Split(digest, ",") {
+		alg, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || !strings.EqualFold(alg, "SHA-256") {
+			continue
+		}
+		sum, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(sum) != 32 {
+			return "", errInvalidChecksumHeader
+		}
+		return hex.EncodeToString(sum), nil
+	}
+
+	return "", nil
+}
+
+func setChecksumHeaders(c echo.Context, sha256Hex, crc32cHex string) {
+	if sha256Hex != "" {
+		if sum, err := hex.DecodeString(sha256Hex); err == nil {
+			c.Response().Header().Set(HeaderDigest, "SHA-256="+base64.StdEncoding.EncodeToString(sum))
+		}
+		c.Response().Header().Set(HeaderChecksumSHA256, sha256Hex)
+	}
+	if crc32cHex != "" {
+		c.Response().Header().Set(HeaderChecksumCRC32C, crc32cHex)
+	}
+}
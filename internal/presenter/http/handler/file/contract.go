@@ -1,10 +1,20 @@
 package file
 
+import (
+	"time"
+
+	fileusecase "meemo/internal/usecase/file"
+)
+
 type SaveFileMetadata struct {
-	OriginalName string `json:"original_name"`
-	IsPublic     bool   `json:"is_public"`
-	MimeType     string `json:"mime_type"`
-	SizeInBytes  int64  `json:"size_in_bytes"`
+	OriginalName string            `json:"original_name"`
+	IsPublic     bool              `json:"is_public"`
+	MimeType     string            `json:"mime_type"`
+	SizeInBytes  int64             `json:"size_in_bytes"`
+	FolderID     *int64            `json:"folder_id"`
+	Tags         []string          `json:"tags"`
+	Metadata     map[string]string `json:"metadata"`
+	ExpiresAt    *time.Time        `json:"expires_at"`
 }
 
 type RenameFileRequest struct {
@@ -21,3 +31,93 @@ type SetStatusRequest struct {
 	OriginalName string `json:"original_name"`
 	Status       int    `json:"status"`
 }
+
+type InstantUploadRequest struct {
+	OriginalName string `json:"original_name"`
+	IsPublic     bool   `json:"is_public"`
+	MimeType     string `json:"mime_type"`
+	SizeInBytes  int64  `json:"size_in_bytes"`
+	SHA256       string `json:"sha256"`
+}
+
+type CreateFolderRequest struct {
+	Name     string `json:"name"`
+	ParentID *int64 `json:"parent_id"`
+}
+
+type RenameFolderRequest struct {
+	Name string `json:"name"`
+}
+
+type MoveFolderRequest struct {
+	ParentID *int64 `json:"parent_id"`
+}
+
+type MoveFileRequest struct {
+	FolderID *int64 `json:"folder_id"`
+}
+
+type FileTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+type ReplaceFileMetadataRequest struct {
+	Metadata map[string]string `json:"metadata"`
+}
+
+type PatchFileMetadataRequest struct {
+	Set   map[string]string `json:"set"`
+	Unset []string          `json:"unset"`
+}
+
+type SetFileExpiryRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type ShareFileRequest struct {
+	Email      string `json:"email"`
+	Permission string `json:"permission"`
+}
+
+type RenameFileByIDRequest struct {
+	NewName string `json:"new_name"`
+}
+
+type CreateShareLinkRequest struct {
+	ExpiresAt    *time.Time `json:"expires_at"`
+	Password     string     `json:"password"`
+	MaxDownloads *int       `json:"max_downloads"`
+}
+
+type BatchFilesRequest struct {
+	Operations []fileusecase.BatchOperationDto `json:"operations"`
+}
+
+type BatchItemResponse struct {
+	Index  int                          `json:"index"`
+	Action string                       `json:"action"`
+	OK     bool                         `json:"ok"`
+	Error  string                       `json:"error,omitempty"`
+	File   *fileusecase.FileListItemDto `json:"file,omitempty"`
+}
+
+type BatchFilesResponse struct {
+	Results   []BatchItemResponse `json:"results"`
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+}
+
+type ArchiveRequest struct {
+	FileIDs  []int64 `json:"file_ids"`
+	FolderID *int64  `json:"folder_id"`
+	Format   string  `json:"format"`
+}
+
+type CopyFileRequest struct {
+	NewName  string `json:"new_name"`
+	FolderID *int64 `json:"folder_id"`
+}
+
+type TransferFileRequest struct {
+	Email string `json:"email"`
+}
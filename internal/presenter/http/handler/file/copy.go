@@ -0,0 +1,126 @@
+package file
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// CopyFile копирует файл
+// @Summary Копировать файл
+// @Description Создает копию текущей версии файла на стороне сервера. Без new_name копия называется "<имя> (copy).<расширение>"
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param name path string true "Имя файла с расширением"
+// @Param request body CopyFileRequest false "Имя и папка копии"
+// @Success 201 {object} fileusecase.FileListItemDto
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 415 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/{name}/copy [post]
+func (h *fileHandler) CopyFile(c echo.Context) error {
+	originalName, err := url.PathUnescape(c.Param("name"))
+	if err != nil || originalName == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "file name is required"})
+	}
+
+	var req CopyFileRequest
+	if err := c.Bind(&req); err != nil {
+		h.log.Warn("invalid JSON in CopyFile", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+	}
+
+	resp, err := h.fileUsecase.CopyFile(c.Request().Context(), &fileusecase.CopyFileDtoIn{
+		UserID:       getUserID(c),
+		UserEmail:    getUserEmail(c),
+		OriginalName: originalName,
+		NewName:      req.NewName,
+		FolderID:     req.FolderID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, fileusecase.ErrFileNotFound), errors.Is(err, fileusecase.ErrFolderNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrFileNotReady):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrFileQuarantined):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrFileExpired):
+			return c.JSON(http.StatusGone, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrInsufficientStorage):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "insufficient storage space"})
+		case errors.Is(err, fileusecase.ErrTypeNotAllowed):
+			return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"error": err.Error()})
+		case isUniqueViolation(err):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "file with this name already exists"})
+		}
+		h.log.Error("failed to copy file", zap.String("originalName", originalName), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to copy file"})
+	}
+
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// TransferFile передает файл другому пользователю
+// @Summary Передать владение файлом
+// @Description Делает пользователя с указанным email владельцем файла. Файл переносится в корень получателя
+// @Description и должен поместиться в его квоту вместе со всеми версиями. Доступы и ссылки прежнего владельца отзываются
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param id path int true "ID файла"
+// @Param request body TransferFileRequest true "Email получателя"
+// @Success 200 {object} fileusecase.TransferFileDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id}/transfer [post]
+func (h *fileHandler) TransferFile(c echo.Context) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+
+	var req TransferFileRequest
+	if err := c.Bind(&req); err != nil {
+		h.log.Warn("invalid JSON in TransferFile", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+	}
+	if req.Email == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "email is required"})
+	}
+
+	resp, err := h.fileUsecase.TransferFile(c.Request().Context(), &fileusecase.TransferFileDtoIn{
+		UserID:    getUserID(c),
+		UserEmail: getUserEmail(c),
+		FileID:    fileID,
+		Email:     req.Email,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, fileusecase.ErrFileNotFound), errors.Is(err, fileusecase.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrTransferToOwner), errors.Is(err, fileusecase.ErrReceiverQuotaExceeded):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case isUniqueViolation(err):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "receiver already has a file with this name"})
+		}
+		h.log.Error("failed to transfer file", zap.Int64("fileID", fileID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to transfer file"})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
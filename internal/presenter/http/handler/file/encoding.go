@@ -0,0 +1,56 @@
+package file
+
+import (
+	"strconv"
+	"strings"
+
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+)
+
+// acceptsEncoding сообщает, разрешает ли заголовок Accept-Encoding кодек coding.
+// Кодек с q=0 запрещен, даже если разрешен шаблон "*".
+func acceptsEncoding(header, coding string) bool {
+	accepted := false
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.TrimSpace(name)
+		if !strings.EqualFold(name, coding) && name != "*" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if strings.EqualFold(name, coding) {
+			return q > 0
+		}
+		accepted = q > 0
+	}
+	return accepted
+}
+
+// keepStoredEncoding решает, можно ли отдать сжатое содержимое как есть, и выставляет заголовки ответа.
+// Частичные ответы всегда отдаются распакованными: диапазон задается в байтах исходного содержимого.
+func keepStoredEncoding(c echo.Context, encoding string, storedSize int64, rng *fileusecase.ByteRange) bool {
+	if encoding == "" {
+		return false
+	}
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+	if rng != nil || !acceptsEncoding(c.Request().Header.Get(echo.HeaderAcceptEncoding), encoding) {
+		return false
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentEncoding, encoding)
+	header.Set(echo.HeaderContentLength, strconv.FormatInt(storedSize, 10))
+	// Digest описывает тело ответа, а оно теперь сжато. X-Checksum-* по-прежнему относятся к исходному содержимому.
+	header.Del(HeaderDigest)
+	return true
+}
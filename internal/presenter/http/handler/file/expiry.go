@@ -0,0 +1,55 @@
+package file
+
+import (
+	"errors"
+	"net/http"
+
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// SetFileExpiry задает срок хранения файла
+// @Summary Изменить срок хранения
+// @Description Задает момент, после которого файл будет удален вместе с версиями. expires_at = null делает файл бессрочным
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param id path int true "ID файла"
+// @Param request body SetFileExpiryRequest true "Срок хранения"
+// @Success 200 {object} fileusecase.FileExpiryDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id}/expiry [put]
+func (h *fileHandler) SetFileExpiry(c echo.Context) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+
+	var req SetFileExpiryRequest
+	if err := c.Bind(&req); err != nil {
+		h.log.Warn("invalid JSON in SetFileExpiry", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+	}
+
+	resp, err := h.fileUsecase.SetFileExpiry(c.Request().Context(), &fileusecase.SetFileExpiryDtoIn{
+		UserID:    getUserID(c),
+		FileID:    fileID,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, fileusecase.ErrFileNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "file not found"})
+		case errors.Is(err, fileusecase.ErrInvalidExpiry):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		h.log.Error("failed to set file expiry", zap.Int64("fileID", fileID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to set file expiry"})
+	}
+	return c.JSON(http.StatusOK, resp)
+}
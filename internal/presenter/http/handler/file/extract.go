@@ -0,0 +1,51 @@
+package file
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+func (h *fileHandler) extractArchive(c echo.Context, fileID int64, src io.ReaderAt, size int64, expectedSum string) error {
+	resp, err := h.fileUsecase.ExtractArchive(c.Request().Context(), &fileusecase.ExtractArchiveDtoIn{
+		Email:          getUserEmail(c),
+		UserID:         getUserID(c),
+		ID:             fileID,
+		SizeInBytes:    size,
+		ExpectedSHA256: expectedSum,
+	}, src)
+	if err != nil {
+		switch {
+		case errors.Is(err, fileusecase.ErrChecksumMismatch):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "checksum mismatch"})
+		case errors.Is(err, fileusecase.ErrSizeMismatch):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrFileTooLarge):
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrFileNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "file not found"})
+		case errors.Is(err, fileusecase.ErrExtractTarget):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrUnsupportedArchive), errors.Is(err, fileusecase.ErrTypeNotAllowed), errors.Is(err, fileusecase.ErrMimeTypeMismatch):
+			return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrUnsafeArchive):
+			h.log.Warn("unsafe archive rejected", zap.Int64("fileID", fileID), zap.Error(err))
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrInsufficientStorage):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "insufficient storage space"})
+		case errors.Is(err, fileusecase.ErrScanUnavailable):
+			h.log.Error("antivirus scan unavailable", zap.Int64("fileID", fileID), zap.Error(err))
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		}
+		h.log.Error("failed to extract archive", zap.Int64("fileID", fileID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to extract archive"})
+	}
+
+	h.log.Info("archive extracted", zap.Int64("fileID", fileID), zap.Int("files", len(resp.Files)))
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,309 @@
+package file
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// CreateFolder создает папку
+// @Summary Создать папку
+// @Description Создает папку в корне или внутри родительской папки
+// @Tags folders
+// @Accept json
+// @Produce json
+// @Param request body CreateFolderRequest true "Имя папки и ID родителя"
+// @Success 201 {object} fileusecase.FolderDto
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /folders [post]
+func (h *fileHandler) CreateFolder(c echo.Context) error {
+	var req CreateFolderRequest
+	if err := c.Bind(&req); err != nil {
+		h.log.Warn("invalid JSON in CreateFolder", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+	}
+
+	dto := &fileusecase.CreateFolderDtoIn{
+		UserID:   getUserID(c),
+		ParentID: req.ParentID,
+		Name:     req.Name,
+	}
+
+	resp, err := h.fileUsecase.CreateFolder(c.Request().Context(), dto)
+	if err != nil {
+		return h.folderError(c, err, "failed to create folder")
+	}
+
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// ListRootFolder получает содержимое корневой папки
+// @Summary Получить содержимое корня
+// @Description Возвращает папки и файлы, лежащие в корне хранилища пользователя
+// @Tags folders
+// @Produce json
+// @Success 200 {object} fileusecase.ListFolderChildrenDtoOut
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /folders [get]
+func (h *fileHandler) ListRootFolder(c echo.Context) error {
+	dto := &fileusecase.ListFolderChildrenDtoIn{
+		UserID: getUserID(c),
+	}
+
+	resp, err := h.fileUsecase.ListFolderChildren(c.Request().Context(), dto)
+	if err != nil {
+		return h.folderError(c, err, "failed to list folder")
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ListFolderChildren получает содержимое папки
+// @Summary Получить содержимое папки
+// @Description Возвращает дочерние папки и файлы папки
+// @Tags folders
+// @Produce json
+// @Param id path int true "ID папки"
+// @Success 200 {object} fileusecase.ListFolderChildrenDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /folders/{id}/children [get]
+func (h *fileHandler) ListFolderChildren(c echo.Context) error {
+	folderID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid folder ID"})
+	}
+
+	dto := &fileusecase.ListFolderChildrenDtoIn{
+		UserID:   getUserID(c),
+		FolderID: &folderID,
+	}
+
+	resp, err := h.fileUsecase.ListFolderChildren(c.Request().Context(), dto)
+	if err != nil {
+		return h.folderError(c, err, "failed to list folder")
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// RenameFolder переименовывает папку
+// @Summary Переименовать папку
+// @Description Изменяет имя папки
+// @Tags folders
+// @Accept json
+// @Produce json
+// @Param id path int true "ID папки"
+// @Param request body RenameFolderRequest true "Новое имя папки"
+// @Success 200 {object} fileusecase.FolderDto
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /folders/{id}/rename [put]
+func (h *fileHandler) RenameFolder(c echo.Context) error {
+	folderID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid folder ID"})
+	}
+
+	var req RenameFolderRequest
+	if err := c.Bind(&req); err != nil {
+		h.log.Warn("invalid JSON in RenameFolder", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+	}
+
+	dto := &fileusecase.RenameFolderDtoIn{
+		UserID:   getUserID(c),
+		FolderID: folderID,
+		Name:     req.Name,
+	}
+
+	resp, err := h.fileUsecase.RenameFolder(c.Request().Context(), dto)
+	if err != nil {
+		return h.folderError(c, err, "failed to rename folder")
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// MoveFolder перемещает папку
+// @Summary Переместить папку
+// @Description Перемещает папку в другую папку или в корень (parent_id = null)
+// @Tags folders
+// @Accept json
+// @Produce json
+// @Param id path int true "ID папки"
+// @Param request body MoveFolderRequest true "ID новой родительской папки"
+// @Success 200 {object} fileusecase.FolderDto
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /folders/{id}/move [put]
+func (h *fileHandler) MoveFolder(c echo.Context) error {
+	folderID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid folder ID"})
+	}
+
+	var req MoveFolderRequest
+	if err := c.Bind(&req); err != nil {
+		h.log.Warn("invalid JSON in MoveFolder", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+	}
+
+	dto := &fileusecase.MoveFolderDtoIn{
+		UserID:   getUserID(c),
+		FolderID: folderID,
+		ParentID: req.ParentID,
+	}
+
+	resp, err := h.fileUsecase.MoveFolder(c.Request().Context(), dto)
+	if err != nil {
+		return h.folderError(c, err, "failed to move folder")
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// DeleteFolder удаляет папку
+// @Summary Удалить папку
+// @Description Рекурсивно удаляет папку вместе со всеми вложенными папками и файлами
+// @Tags folders
+// @Produce json
+// @Param id path int true "ID папки"
+// @Success 200 {object} fileusecase.DeleteFolderDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /folders/{id} [delete]
+func (h *fileHandler) DeleteFolder(c echo.Context) error {
+	folderID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid folder ID"})
+	}
+
+	dto := &fileusecase.DeleteFolderDtoIn{
+		UserID:   getUserID(c),
+		FolderID: folderID,
+	}
+
+	resp, err := h.fileUsecase.DeleteFolder(c.Request().Context(), dto)
+	if err != nil {
+		return h.folderError(c, err, "failed to delete folder")
+	}
+
+	h.log.Info("folder deleted", zap.Int64("folderID", resp.ID), zap.Int("deletedFiles", resp.DeletedFiles))
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetFolderSize считает размер папки
+// @Summary Получить размер папки
+// @Description Рекурсивно считает суммарный размер и количество файлов и папок
+// @Tags folders
+// @Produce json
+// @Param id path int true "ID папки"
+// @Success 200 {object} fileusecase.GetFolderSizeDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /folders/{id}/size [get]
+func (h *fileHandler) GetFolderSize(c echo.Context) error {
+	folderID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid folder ID"})
+	}
+
+	dto := &fileusecase.GetFolderSizeDtoIn{
+		UserID:   getUserID(c),
+		FolderID: folderID,
+	}
+
+	resp, err := h.fileUsecase.GetFolderSize(c.Request().Context(), dto)
+	if err != nil {
+		return h.folderError(c, err, "failed to get folder size")
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// MoveFile перемещает файл в папку
+// @Summary Переместить файл
+// @Description Перемещает файл в папку или в корень (folder_id = null)
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param id path int true "ID файла"
+// @Param request body MoveFileRequest true "ID папки назначения"
+// @Success 200 {object} fileusecase.MoveFileDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id}/move [put]
+func (h *fileHandler) MoveFile(c echo.Context) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+
+	var req MoveFileRequest
+	if err := c.Bind(&req); err != nil {
+		h.log.Warn("invalid JSON in MoveFile", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+	}
+
+	dto := &fileusecase.MoveFileDtoIn{
+		UserID:   getUserID(c),
+		FileID:   fileID,
+		FolderID: req.FolderID,
+	}
+
+	resp, err := h.fileUsecase.MoveFile(c.Request().Context(), dto)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "file not found"})
+		}
+		return h.folderError(c, err, "failed to move file")
+	}
+
+	h.log.Info("file moved", zap.Int64("fileID", resp.ID))
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (h *fileHandler) folderError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, fileusecase.ErrFolderNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "folder not found"})
+	case errors.Is(err, fileusecase.ErrInvalidFolderName):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid folder name"})
+	case errors.Is(err, fileusecase.ErrInvalidFolderMove):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case isUniqueViolation(err):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "name already exists in the destination folder"})
+	}
+
+	h.log.Error(message, zap.Error(err))
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": message})
+}
+
+func isUniqueViolation(err error) bool {
+	return strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "unique")
+}
+
+func parseIDParam(c echo.Context, name string) (int64, error) {
+	return strconv.ParseInt(c.Param(name), 10, 64)
+}
@@ -0,0 +1,70 @@
+package file
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// CheckStorage сверяет базу с бакетом
+// @Summary Проверить согласованность хранилища
+// @Description Ищет объекты S3 без строк в базе, пропавшие объекты, объекты с неверным размером и файлы,
+// @Description застрявшие в статусе Pending. С repair=true расхождения исправляются, иначе только попадают в отчет.
+// @Description Доступно администраторам из admin_emails
+// @Tags admin
+// @Produce json
+// @Param repair query bool false "Исправить расхождения"
+// @Param grace_period query string false "Объекты моложе этого срока не считаются лишними (например, 24h)"
+// @Param pending_older_than query string false "Файлы Pending старше этого срока считаются застрявшими (например, 24h)"
+// @Param max_issues query int false "Сколько расхождений включить в отчет"
+// @Success 200 {object} fileusecase.CheckStorageDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /admin/fsck [post]
+func (h *fileHandler) CheckStorage(c echo.Context) error {
+	req := &fileusecase.CheckStorageDtoIn{}
+
+	repair, err := optionalQuery(c, "repair", strconv.ParseBool)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if repair != nil {
+		req.Repair = *repair
+	}
+	gracePeriod, err := optionalQuery(c, "grace_period", time.ParseDuration)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if gracePeriod != nil {
+		req.GracePeriod = *gracePeriod
+	}
+	pendingOlderThan, err := optionalQuery(c, "pending_older_than", time.ParseDuration)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if pendingOlderThan != nil {
+		req.PendingOlderThan = *pendingOlderThan
+	}
+	maxIssues, err := optionalQuery(c, "max_issues", strconv.Atoi)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if maxIssues != nil {
+		req.MaxIssues = *maxIssues
+	}
+
+	resp, err := h.fileUsecase.CheckStorage(c.Request().Context(), req)
+	if err != nil {
+		h.log.Error("failed to check storage", zap.Bool("repair", req.Repair), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to check storage"})
+	}
+	return c.JSON(http.StatusOK, resp)
+}
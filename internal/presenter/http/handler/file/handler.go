@@ -1,10 +1,15 @@
 package file
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"net/http"
 	"net/url"
+	"path"
+	"strings"
 
+	fileservice "meemo/internal/domain/file/service"
 	tokenservice "meemo/internal/domain/token/service"
 	"meemo/internal/infrastructure/logger"
 	fileusecase "meemo/internal/usecase/file"
@@ -25,32 +30,80 @@ type FileHandler interface {
 	ChangeVisibility(c echo.Context) error
 	SetStatus(c echo.Context) error
 	GetStorageInfo(c echo.Context) error
+	InstantUpload(c echo.Context) error
+	CreateFolder(c echo.Context) error
+	ListRootFolder(c echo.Context) error
+	ListFolderChildren(c echo.Context) error
+	RenameFolder(c echo.Context) error
+	MoveFolder(c echo.Context) error
+	DeleteFolder(c echo.Context) error
+	GetFolderSize(c echo.Context) error
+	MoveFile(c echo.Context) error
+	ListFileVersions(c echo.Context) error
+	GetFileVersion(c echo.Context) error
+	RestoreFileVersion(c echo.Context) error
+	PruneFileVersions(c echo.Context) error
+	ListTrash(c echo.Context) error
+	RestoreFromTrash(c echo.Context) error
+	EmptyTrash(c echo.Context) error
+	SearchFiles(c echo.Context) error
+	ShareFile(c echo.Context) error
+	ListFileShares(c echo.Context) error
+	RevokeFileShare(c echo.Context) error
+	ListSharedWithMe(c echo.Context) error
+	RenameFileByID(c echo.Context) error
+	DeleteFileByID(c echo.Context) error
+	CreateShareLink(c echo.Context) error
+	ListShareLinks(c echo.Context) error
+	RevokeShareLink(c echo.Context) error
+	OpenShareLink(c echo.Context) error
+	BatchFiles(c echo.Context) error
+	DownloadArchive(c echo.Context) error
+	CopyFile(c echo.Context) error
+	TransferFile(c echo.Context) error
+	AddFileTags(c echo.Context) error
+	RemoveFileTags(c echo.Context) error
+	ReplaceFileTags(c echo.Context) error
+	ListTags(c echo.Context) error
+	ReplaceFileMetadata(c echo.Context) error
+	PatchFileMetadata(c echo.Context) error
+	GetThumbnail(c echo.Context) error
+	SetFileExpiry(c echo.Context) error
+	CheckStorage(c echo.Context) error
 	FileMiddleware() echo.MiddlewareFunc
+	AdminMiddleware() echo.MiddlewareFunc
 }
 
 type fileHandler struct {
 	fileUsecase fileusecase.Usecase
 	jwtService  tokenservice.TokenService
+	adminEmails []string
 	log         logger.Logger
 }
 
-func NewFileHandler(usecase fileusecase.Usecase, jwtService tokenservice.TokenService, log logger.Logger) FileHandler {
+func NewFileHandler(usecase fileusecase.Usecase, jwtService tokenservice.TokenService, adminEmails []string, log logger.Logger) FileHandler {
 	return &fileHandler{
 		fileUsecase: usecase,
 		jwtService:  jwtService,
+		adminEmails: adminEmails,
 		log:         log,
 	}
 }
 
 // SaveFileMetadata создает метаданные файла
 // @Summary Создать метаданные файла
-// @Description Создает метаданные для нового файла
+// @Description Создает метаданные для нового файла. Если файл с таким именем уже есть в папке,
+// @Description возвращает его, и следующая загрузка содержимого создаст новую версию.
+// @Description Если задан expires_at, файл будет удален после этого момента
 // @Tags files
 // @Accept json
 // @Produce json
 // @Param file body SaveFileMetadata true "Метаданные файла"
+// @Success 200 {object} fileusecase.SaveFileMetadataDtoOut
 // @Success 201 {object} fileusecase.SaveFileMetadataDtoOut
 // @Failure 400 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 415 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Security BearerAuth
 // @Router /files/metadata [post]
@@ -76,6 +129,10 @@ func (h *fileHandler) SaveFileMetadata(c echo.Context) error {
 		SizeInBytes:  req.SizeInBytes,
 		OriginalName: req.OriginalName,
 		IsPublic:     req.IsPublic,
+		FolderID:     req.FolderID,
+		Tags:         req.Tags,
+		Metadata:     req.Metadata,
+		ExpiresAt:    req.ExpiresAt,
 	}
 
 	resp, err := h.fileUsecase.SaveFileMetadata(c.Request().Context(), &dto)
@@ -84,25 +141,52 @@ func (h *fileHandler) SaveFileMetadata(c echo.Context) error {
 			h.log.Warn("insufficient storage space", zap.Int64("userID", userID), zap.Int64("sizeInBytes", req.SizeInBytes))
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": "insufficient storage space"})
 		}
+		if errors.Is(err, fileusecase.ErrFolderNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "folder not found"})
+		}
+		if errors.Is(err, fileusecase.ErrInvalidTags) || errors.Is(err, fileusecase.ErrInvalidMetadata) || errors.Is(err, fileusecase.ErrInvalidExpiry) || errors.Is(err, fileusecase.ErrInvalidSize) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, fileusecase.ErrFileTooLarge) {
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, fileusecase.ErrTypeNotAllowed) {
+			h.log.Warn("file type rejected", zap.Int64("userID", userID), zap.String("originalName", req.OriginalName), zap.Error(err))
+			return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"error": err.Error()})
+		}
 		h.log.Error("failed to create file metadata", zap.Int64("userID", userID), zap.String("originalName", req.OriginalName), zap.Error(err))
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to create file metadata"})
 	}
 
+	if resp.NewVersion {
+		h.log.Info("file metadata reused for new version", zap.Int64("fileID", resp.ID), zap.String("originalName", resp.OriginalName))
+		return c.JSON(http.StatusOK, resp)
+	}
+
 	h.log.Info("file metadata created", zap.Int64("fileID", resp.ID), zap.String("originalName", resp.OriginalName))
 	return c.JSON(http.StatusCreated, resp)
 }
 
 // SaveFileContent загружает содержимое файла
 // @Summary Загрузить содержимое файла
-// @Description Загружает содержимое файла по его ID
+// @Description Загружает содержимое файла по его ID. Каждая загрузка создает новую версию файла.
+// @Description С extract=true загруженный ZIP, tar или tar.gz распаковывается в отдельные файлы рядом с файлом {id},
+// @Description а сам файл {id} (новый и пустой) удаляется; в этом случае ответ — fileusecase.ExtractArchiveDtoOut.
+// @Description Первое содержимое файла должно совпадать по размеру с size_in_bytes из метаданных, новая версия должна помещаться в квоту
 // @Tags files
 // @Accept multipart/form-data
 // @Produce json
 // @Param id path int true "ID файла"
 // @Param file formData file true "Содержимое файла"
+// @Param extract query bool false "Распаковать архив"
+// @Param Content-SHA256 header string false "Ожидаемый SHA-256 содержимого (hex)"
+// @Param Digest header string false "Ожидаемый дайджест содержимого, например SHA-256=<base64>"
 // @Success 200 {object} fileusecase.SaveFileContentDtoOut
 // @Failure 400 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 415 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Failure 503 {object} map[string]string
 // @Security BearerAuth
 // @Router /files/{id}/content [post]
 func (h *fileHandler) SaveFileContent(c echo.Context) error {
@@ -111,6 +195,12 @@ func (h *fileHandler) SaveFileContent(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "file ID is required"})
 	}
 
+	expectedSum, err := expectedSHA256(c)
+	if err != nil {
+		h.log.Warn("invalid checksum header in SaveFileContent", zap.String("fileID", fileID), zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid checksum header"})
+	}
+
 	file, err := c.FormFile("file")
 	if err != nil {
 		h.log.Warn("file is required in SaveFileContent", zap.String("fileID", fileID), zap.Error(err))
@@ -125,13 +215,47 @@ func (h *fileHandler) SaveFileContent(c echo.Context) error {
 	// TODO: Добавить обработку ошибки
 	defer func() { _ = src.Close() }()
 
+	if c.QueryParam("extract") == "true" {
+		return h.extractArchive(c, mustParseInt64(fileID), src, file.Size, expectedSum)
+	}
+
 	req := &fileusecase.SaveFileContentDtoIn{
-		ID:          mustParseInt64(fileID),
-		SizeInBytes: file.Size,
+		UserID:         getUserID(c),
+		ID:             mustParseInt64(fileID),
+		SizeInBytes:    file.Size,
+		ExpectedSHA256: expectedSum,
 	}
 
 	resp, err := h.fileUsecase.SaveFileContent(c.Request().Context(), req, src)
 	if err != nil {
+		if errors.Is(err, fileusecase.ErrChecksumMismatch) {
+			h.log.Warn("checksum mismatch on upload", zap.Int64("fileID", req.ID))
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "checksum mismatch"})
+		}
+		if errors.Is(err, fileusecase.ErrSizeMismatch) {
+			h.log.Warn("size mismatch on upload", zap.Int64("fileID", req.ID), zap.Int64("sizeInBytes", req.SizeInBytes))
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, fileusecase.ErrInsufficientStorage) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "insufficient storage space"})
+		}
+		if errors.Is(err, fileusecase.ErrFileTooLarge) {
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, fileusecase.ErrFileNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "file not found"})
+		}
+		if errors.Is(err, fileusecase.ErrAccessDenied) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, fileusecase.ErrTypeNotAllowed) || errors.Is(err, fileusecase.ErrMimeTypeMismatch) {
+			h.log.Warn("file content type rejected", zap.Int64("fileID", req.ID), zap.Error(err))
+			return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, fileusecase.ErrScanUnavailable) {
+			h.log.Error("antivirus scan unavailable", zap.Int64("fileID", req.ID), zap.Error(err))
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+		}
 		h.log.Error("failed to upload file content", zap.Int64("fileID", req.ID), zap.Error(err))
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to upload file content"})
 	}
@@ -146,9 +270,14 @@ func (h *fileHandler) SaveFileContent(c echo.Context) error {
 // @Tags files
 // @Produce application/octet-stream
 // @Param name path string true "Имя файла с расширением"
+// @Param Range header string false "Диапазон байт, например bytes=0-1023"
 // @Success 200 {file} file
+// @Success 206 {file} file
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 416 {object} map[string]string
 // @Security BearerAuth
 // @Router /files/{name} [get]
 func (h *fileHandler) GetFile(c echo.Context) error {
@@ -165,6 +294,12 @@ func (h *fileHandler) GetFile(c echo.Context) error {
 
 	metadata, err := h.fileUsecase.GetFileMetadataByName(c.Request().Context(), req)
 	if err != nil {
+		if errors.Is(err, fileusecase.ErrFileQuarantined) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, fileusecase.ErrFileExpired) {
+			return c.JSON(http.StatusGone, map[string]string{"error": err.Error()})
+		}
 		h.log.Warn("file not found", zap.String("originalName", originalName), zap.Error(err))
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "file not found"})
 	}
@@ -173,14 +308,25 @@ func (h *fileHandler) GetFile(c echo.Context) error {
 
 	c.Response().Header().Set("Content-Disposition", "attachment; filename="+filename)
 	c.Response().Header().Set("Content-Type", metadata.MimeType)
+	setChecksumHeaders(c, metadata.ChecksumSHA256, metadata.ChecksumCRC32C)
+
+	rng, handled, err := requestedRange(c, metadata.SizeInBytes)
+	if handled {
+		return err
+	}
+	req.Range = rng
+	req.KeepEncoding = keepStoredEncoding(c, metadata.ContentEncoding, metadata.StoredSizeInBytes, rng)
 
-	_, err = h.fileUsecase.GetFile(c.Request().Context(), req, c.Response().Writer)
+	_, err = h.fileUsecase.GetFile(c.Request().Context(), req, contentWriter(c, rng, metadata.SizeInBytes))
 	if err != nil {
 		h.log.Error("failed to download file", zap.Int64("fileID", metadata.ID), zap.Error(err))
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to download file"})
 	}
 
 	c.Response().Status = http.StatusOK
+	if rng != nil {
+		c.Response().Status = http.StatusPartialContent
+	}
 	return nil
 }
 
@@ -190,9 +336,14 @@ func (h *fileHandler) GetFile(c echo.Context) error {
 // @Tags files
 // @Produce application/octet-stream
 // @Param id path int true "ID файла"
+// @Param Range header string false "Диапазон байт, например bytes=0-1023"
 // @Success 200 {file} file
+// @Success 206 {file} file
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 416 {object} map[string]string
 // @Security BearerAuth
 // @Router /files/by-id/{id} [get]
 func (h *fileHandler) GetFileByID(c echo.Context) error {
@@ -211,6 +362,12 @@ func (h *fileHandler) GetFileByID(c echo.Context) error {
 
 	metadata, err := h.fileUsecase.GetFileMetadataByID(c.Request().Context(), req)
 	if err != nil {
+		if errors.Is(err, fileusecase.ErrAccessDenied) || errors.Is(err, fileusecase.ErrFileQuarantined) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, fileusecase.ErrFileExpired) {
+			return c.JSON(http.StatusGone, map[string]string{"error": err.Error()})
+		}
 		h.log.Warn("file not found by ID", zap.Int64("fileID", fileID), zap.Error(err))
 		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
 	}
@@ -219,14 +376,25 @@ func (h *fileHandler) GetFileByID(c echo.Context) error {
 
 	c.Response().Header().Set("Content-Disposition", "attachment; filename="+filename)
 	c.Response().Header().Set("Content-Type", metadata.MimeType)
+	setChecksumHeaders(c, metadata.ChecksumSHA256, metadata.ChecksumCRC32C)
+
+	rng, handled, err := requestedRange(c, metadata.SizeInBytes)
+	if handled {
+		return err
+	}
+	req.Range = rng
+	req.KeepEncoding = keepStoredEncoding(c, metadata.ContentEncoding, metadata.StoredSizeInBytes, rng)
 
-	_, err = h.fileUsecase.GetFileByID(c.Request().Context(), req, c.Response().Writer)
+	_, err = h.fileUsecase.GetFileByID(c.Request().Context(), req, contentWriter(c, rng, metadata.SizeInBytes))
 	if err != nil {
 		h.log.Error("failed to download file by ID", zap.Int64("fileID", fileID), zap.Error(err))
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to download file"})
 	}
 
 	c.Response().Status = http.StatusOK
+	if rng != nil {
+		c.Response().Status = http.StatusPartialContent
+	}
 	return nil
 }
 
@@ -271,6 +439,7 @@ func (h *fileHandler) GetFileInfo(c echo.Context) error {
 // @Param request body RenameFileRequest true "Запрос на переименование"
 // @Success 200 {object} fileusecase.RenameFileDtoOut
 // @Failure 400 {object} map[string]string
+// @Failure 415 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Security BearerAuth
 // @Router /files/rename [put]
@@ -290,6 +459,9 @@ func (h *fileHandler) RenameFile(c echo.Context) error {
 
 	resp, err := h.fileUsecase.RenameFile(c.Request().Context(), dto)
 	if err != nil {
+		if errors.Is(err, fileusecase.ErrTypeNotAllowed) {
+			return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"error": err.Error()})
+		}
 		h.log.Error("failed to rename file", zap.String("oldName", req.OldName), zap.String("newName", req.NewName), zap.Error(err))
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to rename file"})
 	}
@@ -300,7 +472,7 @@ func (h *fileHandler) RenameFile(c echo.Context) error {
 
 // DeleteFile удаляет файл
 // @Summary Удалить файл
-// @Description Удаляет файл по его имени (включая расширение, например: file.txt)
+// @Description Перемещает файл в корзину по его имени (включая расширение, например: file.txt)
 // @Tags files
 // @Produce json
 // @Param name path string true "Имя файла с расширением"
@@ -327,27 +499,47 @@ func (h *fileHandler) DeleteFile(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "file not found"})
 	}
 
-	h.log.Info("file deleted", zap.Int64("fileID", resp.ID), zap.String("originalName", originalName))
+	h.log.Info("file moved to trash", zap.Int64("fileID", resp.ID), zap.String("originalName", originalName))
 	return c.JSON(http.StatusOK, resp)
 }
 
 // GetUserFilesList получает список файлов пользователя
 // @Summary Получить список файлов
-// @Description Возвращает список всех файлов текущего пользователя
+// @Description Возвращает страницу файлов текущего пользователя. Следующая страница запрашивается с курсором next_cursor
 // @Tags files
 // @Produce json
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param limit query int false "Размер страницы (по умолчанию 100, максимум 1000)"
+// @Param sort query string false "Поле сортировки: name, size, created, updated"
+// @Param order query string false "Направление сортировки: asc, desc"
+// @Param mime query string false "Префикс MIME-типа, например image/"
+// @Param status query int false "Статус файла"
+// @Param is_public query bool false "Видимость файла"
+// @Param min_size query int false "Минимальный размер в байтах"
+// @Param max_size query int false "Максимальный размер в байтах"
+// @Param created_after query string false "Создан не раньше (RFC3339)"
+// @Param created_before query string false "Создан раньше (RFC3339)"
+// @Param tag query []string false "Тег; при нескольких значениях файл должен иметь все теги" collectionFormat(multi)
+// @Param meta_key query string false "Ключ пользовательских метаданных, который должен быть у файла"
+// @Param meta_value query string false "Значение ключа meta_key"
 // @Success 200 {object} fileusecase.GetAllUserFilesDtoOut
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Security BearerAuth
 // @Router /files [get]
 func (h *fileHandler) GetUserFilesList(c echo.Context) error {
-	req := &fileusecase.GetAllUserFilesDtoIn{
-		UserID:    getUserID(c),
-		UserEmail: getUserEmail(c),
+	req, err := parseListQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
+	req.UserID = getUserID(c)
+	req.UserEmail = getUserEmail(c)
 
 	resp, err := h.fileUsecase.GetUserFilesList(c.Request().Context(), req)
 	if err != nil {
+		if errors.Is(err, fileusecase.ErrInvalidListQuery) || errors.Is(err, fileusecase.ErrInvalidCursor) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 		h.log.Error("failed to list files", zap.Int64("userID", req.UserID), zap.Error(err))
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to list files"})
 	}
@@ -364,6 +556,7 @@ func (h *fileHandler) GetUserFilesList(c echo.Context) error {
 // @Param request body ChangeVisibilityRequest true "Запрос на изменение приватности"
 // @Success 200 {object} fileusecase.ChangeVisibilityDtoOut
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Security BearerAuth
 // @Router /files/visibility [put]
@@ -383,6 +576,9 @@ func (h *fileHandler) ChangeVisibility(c echo.Context) error {
 
 	resp, err := h.fileUsecase.ChangeVisibility(c.Request().Context(), dto)
 	if err != nil {
+		if errors.Is(err, fileusecase.ErrFileQuarantined) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
 		h.log.Error("failed to change visibility", zap.String("originalName", req.OriginalName), zap.Bool("isPublic", req.IsPublic), zap.Error(err))
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to change visibility"})
 	}
@@ -400,6 +596,7 @@ func (h *fileHandler) ChangeVisibility(c echo.Context) error {
 // @Param request body SetStatusRequest true "Запрос на изменение статуса"
 // @Success 200 {object} fileusecase.SetStatusDtoOut
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Security BearerAuth
 // @Router /files/status [put]
@@ -419,6 +616,12 @@ func (h *fileHandler) SetStatus(c echo.Context) error {
 
 	resp, err := h.fileUsecase.SetStatus(c.Request().Context(), dto)
 	if err != nil {
+		if errors.Is(err, fileusecase.ErrInvalidStatus) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, fileusecase.ErrFileQuarantined) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
 		h.log.Error("failed to set status", zap.String("originalName", req.OriginalName), zap.Int("status", req.Status), zap.Error(err))
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to set status"})
 	}
@@ -433,6 +636,7 @@ func (h *fileHandler) SetStatus(c echo.Context) error {
 // @Tags files
 // @Produce json
 // @Success 200 {object} fileusecase.GetStorageInfoDtoOut
+// @Failure 415 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Security BearerAuth
 // @Router /files/storage [get]
@@ -451,58 +655,76 @@ func (h *fileHandler) GetStorageInfo(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
-func getExtensionFromMimeType(mimeType string) string {
-	if mimeType == "" {
-		return ""
+// InstantUpload создает файл из уже загруженного содержимого
+// @Summary Мгновенная загрузка файла
+// @Description Создает файл без передачи содержимого, если блоб с таким SHA-256 уже хранится (требует включенной дедупликации)
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param request body InstantUploadRequest true "Метаданные файла и SHA-256 содержимого"
+// @Success 201 {object} fileusecase.InstantUploadDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 501 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/instant [post]
+func (h *fileHandler) InstantUpload(c echo.Context) error {
+	var req InstantUploadRequest
+	if err := c.Bind(&req); err != nil {
+		h.log.Warn("invalid JSON in InstantUpload", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
 	}
 
-	lastSlash := -1
-	for i := len(mimeType) - 1; i >= 0; i-- {
-		if mimeType[i] == '/' {
-			lastSlash = i
-			break
-		}
+	sum, err := hex.DecodeString(req.SHA256)
+	if err != nil || len(sum) != sha256.Size {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "sha256 must be a hex-encoded SHA-256 digest"})
 	}
 
-	if lastSlash == -1 || lastSlash == len(mimeType)-1 {
-		return ""
+	dto := &fileusecase.InstantUploadDtoIn{
+		UserID:       getUserID(c),
+		UserEmail:    getUserEmail(c),
+		OriginalName: req.OriginalName,
+		MimeType:     req.MimeType,
+		SizeInBytes:  req.SizeInBytes,
+		IsPublic:     req.IsPublic,
+		SHA256:       hex.EncodeToString(sum),
 	}
 
-	extension := mimeType[lastSlash+1:]
-
-	for i, ch := range extension {
-		if ch == ';' || ch == '+' {
-			extension = extension[:i]
-			break
+	resp, err := h.fileUsecase.InstantUpload(c.Request().Context(), dto)
+	if err != nil {
+		switch {
+		case errors.Is(err, fileusecase.ErrDeduplicationDisabled):
+			return c.JSON(http.StatusNotImplemented, map[string]string{"error": "deduplication is disabled"})
+		case errors.Is(err, fileusecase.ErrBlobNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "content not found, upload required"})
+		case errors.Is(err, fileusecase.ErrInsufficientStorage):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "insufficient storage space"})
+		case errors.Is(err, fileusecase.ErrTypeNotAllowed):
+			return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrScanUnavailable):
+			h.log.Error("antivirus scan unavailable", zap.String("originalName", req.OriginalName), zap.Error(err))
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
 		}
+		h.log.Error("failed to upload file instantly", zap.String("originalName", req.OriginalName), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to upload file"})
 	}
 
-	if extension == "" || extension == "octet-stream" {
-		return ""
-	}
-
-	return "." + extension
+	h.log.Info("file uploaded instantly", zap.Int64("fileID", resp.ID), zap.String("originalName", resp.OriginalName))
+	return c.JSON(http.StatusCreated, resp)
 }
 
+// ensureFileExtension добавляет к имени расширение MIME-типа, если у имени нет известного расширения.
 func ensureFileExtension(filename, mimeType string) string {
-	hasExtension := false
-	for i := len(filename) - 1; i >= 0 && i > len(filename)-6; i-- {
-		if filename[i] == '.' {
-			hasExtension = true
-			break
-		}
-	}
-
-	if hasExtension {
+	if fileservice.MimeTypeByExtension(filename) != "" {
 		return filename
 	}
 
-	ext := getExtensionFromMimeType(mimeType)
-	if ext != "" {
-		return filename + ext
+	ext := fileservice.ExtensionByMimeType(mimeType)
+	if ext == "" || strings.EqualFold(path.Ext(filename), ext) {
+		return filename
 	}
-
-	return filename
+	return filename + ext
 }
 
 func mustParseInt64(s string) int64 {
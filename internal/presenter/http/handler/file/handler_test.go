@@ -0,0 +1,202 @@
+package file
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"meemo/internal/infrastructure/logger"
+	"meemo/internal/infrastructure/storage/s3/file"
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+)
+
+// uploadUsecase отвечает на загрузку содержимого заданной ошибкой.
+type uploadUsecase struct {
+	fileusecase.Usecase
+	err  error
+	size int64
+}
+
+func (u *uploadUsecase) SaveFileContent(_ context.Context, in *fileusecase.SaveFileContentDtoIn, _ io.Reader) (*fileusecase.SaveFileContentDtoOut, error) {
+	u.size = in.SizeInBytes
+	if u.err != nil {
+		return nil, u.err
+	}
+	return &fileusecase.SaveFileContentDtoOut{}, nil
+}
+
+func TestSaveFileContent_UploadErrorStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"size mismatch", fileusecase.ErrSizeMismatch, http.StatusBadRequest},
+		{"file too large", fileusecase.ErrFileTooLarge, http.StatusRequestEntityTooLarge},
+		{"insufficient storage", fileusecase.ErrInsufficientStorage, http.StatusBadRequest},
+		{"ok", nil, http.StatusOK},
+	}
+	log, _ := logger.NewLogger("error")
+	content := []byte("file content")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			mw := multipart.NewWriter(&body)
+			part, err := mw.CreateFormFile("file", "notes.txt")
+			if err != nil {
+				t.Fatalf("Failed to create form file: %v", err)
+			}
+			_, _ = part.Write(content)
+			if err := mw.Close(); err != nil {
+				t.Fatalf("Failed to close multipart writer: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/files/1/content", &body)
+			req.Header.Set(echo.HeaderContentType, mw.FormDataContentType())
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+			c.Set(UserIDKey, int64(1))
+
+			usecase := &uploadUsecase{err: tt.err}
+			h := NewFileHandler(usecase, nil, nil, log)
+			if err := h.SaveFileContent(c); err != nil {
+				t.Fatalf("Handler returned error: %v", err)
+			}
+			if rec.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d: %s", tt.expected, rec.Code, rec.Body.String())
+			}
+			// Заявленный размер берется из части multipart, а не из Content-Length запроса.
+			if usecase.size != int64(len(content)) {
+				t.Errorf("Expected size %d to be passed to usecase, got %d", len(content), usecase.size)
+			}
+		})
+	}
+}
+
+// gzipUsecase отдает файл, который хранится сжатым gzip: как есть при KeepEncoding, иначе распакованным.
+type gzipUsecase struct {
+	fileusecase.Usecase
+	content []byte
+	stored  []byte
+	keep    bool
+}
+
+func (u *gzipUsecase) GetFileMetadataByID(_ context.Context, in *fileusecase.GetFileByIDDtoIn) (*fileusecase.GetFileByIDDtoOut, error) {
+	return &fileusecase.GetFileByIDDtoOut{
+		ID:                in.FileID,
+		OriginalName:      "notes.txt",
+		MimeType:          "text/plain",
+		SizeInBytes:       int64(len(u.content)),
+		ChecksumSHA256:    "00",
+		ContentEncoding:   file.EncodingGzip,
+		StoredSizeInBytes: int64(len(u.stored)),
+	}, nil
+}
+
+func (u *gzipUsecase) GetFileByID(_ context.Context, in *fileusecase.GetFileByIDDtoIn, w io.Writer) (*fileusecase.GetFileByIDDtoOut, error) {
+	u.keep = in.KeepEncoding
+	switch {
+	case in.KeepEncoding:
+		_, _ = w.Write(u.stored)
+	case in.Range != nil:
+		_, _ = w.Write(u.content[in.Range.Offset : in.Range.Offset+in.Range.Length])
+	default:
+		_, _ = w.Write(u.content)
+	}
+	return &fileusecase.GetFileByIDDtoOut{}, nil
+}
+
+func TestGetFileByID_StoredEncoding(t *testing.T) {
+	content := bytes.Repeat([]byte("compressible line\n"), 100)
+	var stored bytes.Buffer
+	gz := gzip.NewWriter(&stored)
+	_, _ = gz.Write(content)
+	_ = gz.Close()
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		rangeHeader    string
+		keep           bool
+	}{
+		{"accepts gzip", "gzip, deflate, br", "", true},
+		{"accepts any", "*", "", true},
+		{"no accept encoding", "", "", false},
+		{"other codec", "br", "", false},
+		{"gzip refused", "gzip;q=0, *", "", false},
+		// Диапазон задается в байтах исходного содержимого, поэтому отдается распакованным.
+		{"range", "gzip", "bytes=0-9", false},
+	}
+	log, _ := logger.NewLogger("error")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/files/by-id/1", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set(echo.HeaderAcceptEncoding, tt.acceptEncoding)
+			}
+			if tt.rangeHeader != "" {
+				req.Header.Set("Range", tt.rangeHeader)
+			}
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+			c.Set(UserIDKey, int64(1))
+
+			usecase := &gzipUsecase{content: content, stored: stored.Bytes()}
+			h := NewFileHandler(usecase, nil, nil, log)
+			if err := h.GetFileByID(c); err != nil {
+				t.Fatalf("Handler returned error: %v", err)
+			}
+			if usecase.keep != tt.keep {
+				t.Errorf("Expected KeepEncoding %v, got %v", tt.keep, usecase.keep)
+			}
+			if vary := rec.Header().Get(echo.HeaderVary); vary != echo.HeaderAcceptEncoding {
+				t.Errorf("Expected Vary: %s, got %q", echo.HeaderAcceptEncoding, vary)
+			}
+
+			encoding := rec.Header().Get(echo.HeaderContentEncoding)
+			switch {
+			case tt.keep:
+				if encoding != file.EncodingGzip {
+					t.Errorf("Expected Content-Encoding gzip, got %q", encoding)
+				}
+				if length := rec.Header().Get(echo.HeaderContentLength); length != strconv.Itoa(stored.Len()) {
+					t.Errorf("Expected Content-Length of the stored object %d, got %s", stored.Len(), length)
+				}
+				if rec.Header().Get(HeaderDigest) != "" {
+					t.Errorf("Expected Digest of the original content to be dropped")
+				}
+				if !bytes.Equal(rec.Body.Bytes(), stored.Bytes()) {
+					t.Errorf("Expected the stored gzip stream to be served as is")
+				}
+			case tt.rangeHeader != "":
+				if encoding != "" || rec.Code != http.StatusPartialContent || !bytes.Equal(rec.Body.Bytes(), content[:10]) {
+					t.Errorf("Expected decompressed partial content, got %d %q %q", rec.Code, encoding, rec.Body.Bytes())
+				}
+			default:
+				if encoding != "" {
+					t.Errorf("Expected no Content-Encoding, got %q", encoding)
+				}
+				if rec.Header().Get(HeaderDigest) == "" {
+					t.Errorf("Expected Digest of the original content")
+				}
+				if !bytes.Equal(rec.Body.Bytes(), content) {
+					t.Errorf("Expected decompressed content")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+)
+
+func parseListQuery(c echo.Context) (*fileusecase.GetAllUserFilesDtoIn, error) {
+	req := &fileusecase.GetAllUserFilesDtoIn{
+		Cursor:      c.QueryParam("cursor"),
+		SortBy:      c.QueryParam("sort"),
+		Order:       c.QueryParam("order"),
+		MimePrefix:  c.QueryParam("mime"),
+		Tags:        c.QueryParams()["tag"],
+		MetadataKey: c.QueryParam("meta_key"),
+	}
+	if c.QueryParams().Has("meta_value") {
+		value := c.QueryParam("meta_value")
+		req.MetadataValue = &value
+	}
+
+	var err error
+	if value := c.QueryParam("limit"); value != "" {
+		if req.Limit, err = strconv.Atoi(value); err != nil {
+			return nil, errors.New("invalid limit")
+		}
+	}
+	if req.Status, err = optionalQuery(c, "status", strconv.Atoi); err != nil {
+		return nil, err
+	}
+	if req.IsPublic, err = optionalQuery(c, "is_public", strconv.ParseBool); err != nil {
+		return nil, err
+	}
+	if req.MinSize, err = optionalQuery(c, "min_size", parseInt64); err != nil {
+		return nil, err
+	}
+	if req.MaxSize, err = optionalQuery(c, "max_size", parseInt64); err != nil {
+		return nil, err
+	}
+	if req.CreatedAfter, err = optionalQuery(c, "created_after", parseTime); err != nil {
+		return nil, err
+	}
+	if req.CreatedBefore, err = optionalQuery(c, "created_before", parseTime); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func optionalQuery[T any](c echo.Context, name string, parse func(string) (T, error)) (*T, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", name)
+	}
+	return &parsed, nil
+}
+
+func parseInt64(value string) (int64, error) {
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func parseTime(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339, value)
+}
@@ -2,6 +2,7 @@ package file
 
 import (
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -49,6 +50,21 @@ func (h *fileHandler) FileMiddleware() echo.MiddlewareFunc {
 	}
 }
 
+// AdminMiddleware пропускает только администраторов из admin_emails. Подключается после FileMiddleware.
+func (h *fileHandler) AdminMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			email := getUserEmail(ctx)
+			if email == "" || !slices.ContainsFunc(h.adminEmails, func(admin string) bool {
+				return strings.EqualFold(admin, email)
+			}) {
+				return ctx.JSON(http.StatusForbidden, map[string]string{"error": "admin access required"})
+			}
+			return next(ctx)
+		}
+	}
+}
+
 func getUserID(ctx echo.Context) int64 {
 	if userID, ok := ctx.Get(UserIDKey).(int64); ok {
 		return userID
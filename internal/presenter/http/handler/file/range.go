@@ -0,0 +1,95 @@
+package file
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+)
+
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// parseRange разбирает заголовок Range для содержимого размером size. Поддерживается один
+// диапазон: bytes=a-b, bytes=a- и bytes=-n. Несколько диапазонов и некорректный заголовок
+// игнорируются, и файл отдается целиком, как допускает RFC 9110.
+func parseRange(header string, size int64) (*fileusecase.ByteRange, error) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return nil, nil
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return nil, nil
+	}
+
+	if first == "" {
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix < 0 {
+			return nil, nil
+		}
+		if suffix == 0 || size == 0 {
+			return nil, errRangeNotSatisfiable
+		}
+		suffix = min(suffix, size)
+		return &fileusecase.ByteRange{Offset: size - suffix, Length: suffix}, nil
+	}
+
+	offset, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || offset < 0 {
+		return nil, nil
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < offset {
+			return nil, nil
+		}
+		end = min(end, size-1)
+	}
+	if offset >= size {
+		return nil, errRangeNotSatisfiable
+	}
+	return &fileusecase.ByteRange{Offset: offset, Length: end - offset + 1}, nil
+}
+
+// requestedRange возвращает запрошенный диапазон. Если диапазон невыполним, ответ 416 уже отправлен
+// и handled равен true.
+func requestedRange(c echo.Context, size int64) (rng *fileusecase.ByteRange, handled bool, err error) {
+	c.Response().Header().Set("Accept-Ranges", "bytes")
+
+	rng, err = parseRange(c.Request().Header.Get("Range"), size)
+	if errors.Is(err, errRangeNotSatisfiable) {
+		c.Response().Header().Set("Content-Range", "bytes */"+strconv.FormatInt(size, 10))
+		return nil, true, c.JSON(http.StatusRequestedRangeNotSatisfiable, map[string]string{"error": "requested range not satisfiable"})
+	}
+	return rng, false, nil
+}
+
+// contentWriter возвращает writer для тела ответа. Для частичного ответа статус 206 и Content-Range
+// отправляются только с первыми байтами, чтобы ошибка чтения до них еще могла вернуть JSON.
+func contentWriter(c echo.Context, rng *fileusecase.ByteRange, size int64) http.ResponseWriter {
+	if rng == nil {
+		return c.Response().Writer
+	}
+	header := c.Response().Header()
+	header.Set("Content-Range", "bytes "+strconv.FormatInt(rng.Offset, 10)+"-"+strconv.FormatInt(rng.Offset+rng.Length-1, 10)+"/"+strconv.FormatInt(size, 10))
+	header.Set("Content-Length", strconv.FormatInt(rng.Length, 10))
+	return &partialContentWriter{ResponseWriter: c.Response().Writer}
+}
+
+type partialContentWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *partialContentWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.ResponseWriter.WriteHeader(http.StatusPartialContent)
+	}
+	return w.ResponseWriter.Write(p)
+}
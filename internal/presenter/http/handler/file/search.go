@@ -0,0 +1,55 @@
+package file
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// SearchFiles ищет файлы
+// @Summary Поиск файлов
+// @Description Ищет файлы по имени и извлеченному тексту с учетом префиксов и опечаток. Результаты отсортированы по релевантности
+// @Tags files
+// @Produce json
+// @Param q query string true "Поисковый запрос"
+// @Param public query bool false "Искать также среди публичных файлов других пользователей"
+// @Param limit query int false "Максимальное число результатов (по умолчанию 50, максимум 200)"
+// @Success 200 {object} fileusecase.SearchFilesDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/search [get]
+func (h *fileHandler) SearchFiles(c echo.Context) error {
+	req := &fileusecase.SearchFilesDtoIn{
+		UserID: getUserID(c),
+		Query:  c.QueryParam("q"),
+	}
+
+	var err error
+	if value := c.QueryParam("public"); value != "" {
+		if req.IncludePublic, err = strconv.ParseBool(value); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid public value"})
+		}
+	}
+	if value := c.QueryParam("limit"); value != "" {
+		if req.Limit, err = strconv.Atoi(value); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+		}
+	}
+
+	resp, err := h.fileUsecase.SearchFiles(c.Request().Context(), req)
+	if err != nil {
+		if errors.Is(err, fileusecase.ErrInvalidSearchQuery) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		h.log.Error("failed to search files", zap.Int64("userID", req.UserID), zap.String("query", req.Query), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to search files"})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
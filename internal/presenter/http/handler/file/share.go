@@ -0,0 +1,223 @@
+package file
+
+import (
+	"errors"
+	"net/http"
+
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ShareFile открывает доступ к файлу другому пользователю
+// @Summary Открыть доступ к файлу
+// @Description Выдает пользователю с указанным email право viewer (чтение) или editor (чтение, загрузка новых версий,
+// @Description переименование и удаление). Повторный вызов меняет право
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Param id path int true "ID файла"
+// @Param request body ShareFileRequest true "Получатель и право"
+// @Success 200 {object} fileusecase.FileShareDto
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id}/shares [post]
+func (h *fileHandler) ShareFile(c echo.Context) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+
+	var req ShareFileRequest
+	if err := c.Bind(&req); err != nil {
+		h.log.Warn("invalid JSON in ShareFile", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+	}
+
+	resp, err := h.fileUsecase.ShareFile(c.Request().Context(), &fileusecase.ShareFileDtoIn{
+		UserID:     getUserID(c),
+		UserEmail:  getUserEmail(c),
+		FileID:     fileID,
+		Email:      req.Email,
+		Permission: req.Permission,
+	})
+	if err != nil {
+		return h.shareError(c, err, "failed to share file")
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ListFileShares возвращает список доступов к файлу
+// @Summary Список доступов к файлу
+// @Description Возвращает пользователей, которым открыт доступ к файлу. Доступно только владельцу
+// @Tags shares
+// @Produce json
+// @Param id path int true "ID файла"
+// @Success 200 {object} fileusecase.ListFileSharesDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id}/shares [get]
+func (h *fileHandler) ListFileShares(c echo.Context) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+
+	resp, err := h.fileUsecase.ListFileShares(c.Request().Context(), &fileusecase.ListFileSharesDtoIn{
+		UserID: getUserID(c),
+		FileID: fileID,
+	})
+	if err != nil {
+		return h.shareError(c, err, "failed to list file shares")
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// RevokeFileShare отзывает доступ к файлу
+// @Summary Отозвать доступ
+// @Description Владелец отзывает доступ пользователя к файлу; получатель может отказаться от своего доступа
+// @Tags shares
+// @Produce json
+// @Param id path int true "ID файла"
+// @Param user_id path int true "ID пользователя, у которого отзывается доступ"
+// @Success 200 {object} fileusecase.RevokeFileShareDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id}/shares/{user_id} [delete]
+func (h *fileHandler) RevokeFileShare(c echo.Context) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+	targetUserID, err := parseIDParam(c, "user_id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+	}
+
+	resp, err := h.fileUsecase.RevokeFileShare(c.Request().Context(), &fileusecase.RevokeFileShareDtoIn{
+		UserID:       getUserID(c),
+		FileID:       fileID,
+		TargetUserID: targetUserID,
+	})
+	if err != nil {
+		return h.shareError(c, err, "failed to revoke file share")
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ListSharedWithMe возвращает файлы, доступные пользователю по ACL
+// @Summary Доступные мне файлы
+// @Description Возвращает файлы других пользователей, к которым текущему пользователю открыт доступ
+// @Tags shares
+// @Produce json
+// @Success 200 {object} fileusecase.ListSharedWithMeDtoOut
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/shared [get]
+func (h *fileHandler) ListSharedWithMe(c echo.Context) error {
+	resp, err := h.fileUsecase.ListSharedWithMe(c.Request().Context(), &fileusecase.ListSharedWithMeDtoIn{UserID: getUserID(c)})
+	if err != nil {
+		return h.shareError(c, err, "failed to list shared files")
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// RenameFileByID переименовывает файл по ID
+// @Summary Переименовать файл по ID
+// @Description Изменяет имя файла. Доступно владельцу и пользователям с правом editor
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param id path int true "ID файла"
+// @Param request body RenameFileByIDRequest true "Новое имя"
+// @Success 200 {object} fileusecase.RenameFileDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 415 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id}/rename [put]
+func (h *fileHandler) RenameFileByID(c echo.Context) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+
+	var req RenameFileByIDRequest
+	if err := c.Bind(&req); err != nil {
+		h.log.Warn("invalid JSON in RenameFileByID", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+	}
+	if req.NewName == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "new name is required"})
+	}
+
+	resp, err := h.fileUsecase.RenameFileByID(c.Request().Context(), &fileusecase.RenameFileByIDDtoIn{
+		UserID:  getUserID(c),
+		FileID:  fileID,
+		NewName: req.NewName,
+	})
+	if err != nil {
+		return h.shareError(c, err, "failed to rename file")
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// DeleteFileByID удаляет файл по ID
+// @Summary Удалить файл по ID
+// @Description Перемещает файл в корзину владельца. Доступно владельцу и пользователям с правом editor
+// @Tags files
+// @Produce json
+// @Param id path int true "ID файла"
+// @Success 200 {object} fileusecase.DeleteFileDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id} [delete]
+func (h *fileHandler) DeleteFileByID(c echo.Context) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+
+	resp, err := h.fileUsecase.DeleteFileByID(c.Request().Context(), &fileusecase.DeleteFileByIDDtoIn{
+		UserID: getUserID(c),
+		FileID: fileID,
+	})
+	if err != nil {
+		return h.shareError(c, err, "failed to delete file")
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (h *fileHandler) shareError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, fileusecase.ErrFileNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "file not found"})
+	case errors.Is(err, fileusecase.ErrUserNotFound), errors.Is(err, fileusecase.ErrShareNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, fileusecase.ErrAccessDenied), errors.Is(err, fileusecase.ErrFileQuarantined):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, fileusecase.ErrInvalidPermission), errors.Is(err, fileusecase.ErrShareWithOwner):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, fileusecase.ErrTypeNotAllowed):
+		return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"error": err.Error()})
+	case isUniqueViolation(err):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "name already exists in the destination folder"})
+	}
+
+	h.log.Error(message, zap.Error(err))
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": message})
+}
@@ -0,0 +1,178 @@
+package file
+
+import (
+	"errors"
+	"net/http"
+
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const HeaderSharePassword = "X-Share-Password"
+
+// CreateShareLink создает публичную ссылку на файл
+// @Summary Создать ссылку на файл
+// @Description Создает ссылку /s/{token}, по которой файл можно скачать без авторизации. Можно задать срок действия,
+// @Description пароль и максимальное число скачиваний. Токен возвращается только в ответе на создание
+// @Tags links
+// @Accept json
+// @Produce json
+// @Param id path int true "ID файла"
+// @Param request body CreateShareLinkRequest true "Параметры ссылки"
+// @Success 201 {object} fileusecase.CreateShareLinkDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id}/links [post]
+func (h *fileHandler) CreateShareLink(c echo.Context) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+
+	var req CreateShareLinkRequest
+	if err := c.Bind(&req); err != nil {
+		h.log.Warn("invalid JSON in CreateShareLink", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+	}
+
+	resp, err := h.fileUsecase.CreateShareLink(c.Request().Context(), &fileusecase.CreateShareLinkDtoIn{
+		UserID:       getUserID(c),
+		FileID:       fileID,
+		ExpiresAt:    req.ExpiresAt,
+		Password:     req.Password,
+		MaxDownloads: req.MaxDownloads,
+	})
+	if err != nil {
+		return h.shareLinkError(c, err, "failed to create share link")
+	}
+
+	resp.URL = c.Scheme() + "://" + c.Request().Host + "/s/" + resp.Token
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// ListShareLinks возвращает ссылки пользователя
+// @Summary Список ссылок
+// @Description Возвращает ссылки текущего пользователя со счетчиками скачиваний
+// @Tags links
+// @Produce json
+// @Param file_id query int false "Только ссылки на этот файл"
+// @Success 200 {object} fileusecase.ListShareLinksDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /links [get]
+func (h *fileHandler) ListShareLinks(c echo.Context) error {
+	fileID, err := optionalQuery(c, "file_id", parseInt64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	resp, err := h.fileUsecase.ListShareLinks(c.Request().Context(), &fileusecase.ListShareLinksDtoIn{
+		UserID: getUserID(c),
+		FileID: fileID,
+	})
+	if err != nil {
+		return h.shareLinkError(c, err, "failed to list share links")
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// RevokeShareLink отзывает ссылку
+// @Summary Отозвать ссылку
+// @Description Отзывает ссылку; она остается в списке вместе со счетчиком скачиваний
+// @Tags links
+// @Produce json
+// @Param id path int true "ID ссылки"
+// @Success 200 {object} fileusecase.ShareLinkDto
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /links/{id} [delete]
+func (h *fileHandler) RevokeShareLink(c echo.Context) error {
+	linkID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid link ID"})
+	}
+
+	resp, err := h.fileUsecase.RevokeShareLink(c.Request().Context(), &fileusecase.RevokeShareLinkDtoIn{
+		UserID: getUserID(c),
+		LinkID: linkID,
+	})
+	if err != nil {
+		return h.shareLinkError(c, err, "failed to revoke share link")
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// OpenShareLink скачивает файл по публичной ссылке
+// @Summary Скачать файл по ссылке
+// @Description Скачивает файл по токену ссылки без авторизации. Пароль передается в заголовке X-Share-Password
+// @Description или в поле формы password (POST)
+// @Tags links
+// @Produce application/octet-stream
+// @Param token path string true "Токен ссылки"
+// @Param X-Share-Password header string false "Пароль ссылки"
+// @Success 200 {file} file
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Router /s/{token} [get]
+// @Router /s/{token} [post]
+func (h *fileHandler) OpenShareLink(c echo.Context) error {
+	req := &fileusecase.OpenShareLinkDtoIn{
+		Token:    c.Param("token"),
+		Password: c.Request().Header.Get(HeaderSharePassword),
+	}
+	if req.Password == "" && c.Request().Method == http.MethodPost {
+		req.Password = c.FormValue("password")
+	}
+
+	metadata, err := h.fileUsecase.OpenShareLink(c.Request().Context(), req)
+	if err != nil {
+		return h.shareLinkError(c, err, "failed to open share link")
+	}
+
+	filename := ensureFileExtension(metadata.OriginalName, metadata.MimeType)
+
+	c.Response().Header().Set("Content-Disposition", "attachment; filename="+filename)
+	c.Response().Header().Set("Content-Type", metadata.MimeType)
+	c.Response().Header().Set("Cache-Control", "no-store")
+	setChecksumHeaders(c, metadata.ChecksumSHA256, metadata.ChecksumCRC32C)
+
+	if err := h.fileUsecase.DownloadShareLink(c.Request().Context(), metadata, c.Response().Writer); err != nil {
+		if !c.Response().Committed {
+			return h.shareLinkError(c, err, "failed to download shared file")
+		}
+		h.log.Error("failed to download shared file", zap.Error(err))
+		return nil
+	}
+
+	c.Response().Status = http.StatusOK
+	return nil
+}
+
+func (h *fileHandler) shareLinkError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, fileusecase.ErrFileNotFound), errors.Is(err, fileusecase.ErrShareLinkNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "share link not found"})
+	case errors.Is(err, fileusecase.ErrShareLinkRevoked),
+		errors.Is(err, fileusecase.ErrShareLinkExpired),
+		errors.Is(err, fileusecase.ErrShareLinkExhausted):
+		return c.JSON(http.StatusGone, map[string]string{"error": err.Error()})
+	case errors.Is(err, fileusecase.ErrShareLinkPasswordRequired), errors.Is(err, fileusecase.ErrShareLinkInvalidPassword):
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	case errors.Is(err, fileusecase.ErrInvalidShareLink):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, fileusecase.ErrFileQuarantined):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
+
+	h.log.Error(message, zap.Error(err))
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": message})
+}
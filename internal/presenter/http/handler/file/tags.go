@@ -0,0 +1,214 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// AddFileTags добавляет теги к файлу
+// @Summary Добавить теги
+// @Description Добавляет теги к файлу. Теги приводятся к нижнему регистру, повторы игнорируются
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "ID файла"
+// @Param request body FileTagsRequest true "Добавляемые теги"
+// @Success 200 {object} fileusecase.FileTagsDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id}/tags [post]
+func (h *fileHandler) AddFileTags(c echo.Context) error {
+	return h.updateFileTags(c, h.fileUsecase.AddFileTags)
+}
+
+// ReplaceFileTags заменяет теги файла
+// @Summary Заменить теги
+// @Description Заменяет все теги файла переданным списком. Пустой список удаляет все теги
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "ID файла"
+// @Param request body FileTagsRequest true "Новый список тегов"
+// @Success 200 {object} fileusecase.FileTagsDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id}/tags [put]
+func (h *fileHandler) ReplaceFileTags(c echo.Context) error {
+	return h.updateFileTags(c, h.fileUsecase.ReplaceFileTags)
+}
+
+// RemoveFileTags удаляет теги файла
+// @Summary Удалить теги
+// @Description Удаляет перечисленные теги файла
+// @Tags tags
+// @Produce json
+// @Param id path int true "ID файла"
+// @Param tag query []string true "Удаляемый тег" collectionFormat(multi)
+// @Success 200 {object} fileusecase.FileTagsDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id}/tags [delete]
+func (h *fileHandler) RemoveFileTags(c echo.Context) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+
+	tags := c.QueryParams()["tag"]
+	if len(tags) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "at least one tag is required"})
+	}
+
+	resp, err := h.fileUsecase.RemoveFileTags(c.Request().Context(), &fileusecase.FileTagsDtoIn{
+		UserID: getUserID(c),
+		FileID: fileID,
+		Tags:   tags,
+	})
+	if err != nil {
+		return h.tagError(c, err, "failed to remove file tags")
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (h *fileHandler) updateFileTags(c echo.Context, update func(context.Context, *fileusecase.FileTagsDtoIn) (*fileusecase.FileTagsDtoOut, error)) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+
+	var req FileTagsRequest
+	if err := c.Bind(&req); err != nil {
+		h.log.Warn("invalid JSON in file tags request", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+	}
+
+	resp, err := update(c.Request().Context(), &fileusecase.FileTagsDtoIn{
+		UserID: getUserID(c),
+		FileID: fileID,
+		Tags:   req.Tags,
+	})
+	if err != nil {
+		return h.tagError(c, err, "failed to update file tags")
+	}
+
+	h.log.Info("file tags updated", zap.Int64("fileID", resp.ID), zap.Strings("tags", resp.Tags))
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ListTags возвращает теги пользователя
+// @Summary Список тегов
+// @Description Возвращает все теги файлов пользователя с количеством файлов по каждому тегу
+// @Tags tags
+// @Produce json
+// @Success 200 {object} fileusecase.ListTagsDtoOut
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /tags [get]
+func (h *fileHandler) ListTags(c echo.Context) error {
+	resp, err := h.fileUsecase.ListTags(c.Request().Context(), &fileusecase.ListTagsDtoIn{UserID: getUserID(c)})
+	if err != nil {
+		h.log.Error("failed to list tags", zap.Int64("userID", getUserID(c)), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to list tags"})
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ReplaceFileMetadata заменяет пользовательские метаданные файла
+// @Summary Заменить метаданные
+// @Description Заменяет все пользовательские метаданные файла (ключ-значение) переданными
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "ID файла"
+// @Param request body ReplaceFileMetadataRequest true "Новые метаданные"
+// @Success 200 {object} fileusecase.FileMetadataDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id}/metadata [put]
+func (h *fileHandler) ReplaceFileMetadata(c echo.Context) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+
+	var req ReplaceFileMetadataRequest
+	if err := c.Bind(&req); err != nil {
+		h.log.Warn("invalid JSON in ReplaceFileMetadata", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+	}
+
+	return h.updateFileMetadata(c, &fileusecase.UpdateFileMetadataDtoIn{
+		UserID:  getUserID(c),
+		FileID:  fileID,
+		Set:     req.Metadata,
+		Replace: true,
+	})
+}
+
+// PatchFileMetadata частично изменяет пользовательские метаданные файла
+// @Summary Изменить метаданные
+// @Description Устанавливает ключи из set и удаляет ключи из unset, остальные метаданные не меняются
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "ID файла"
+// @Param request body PatchFileMetadataRequest true "Изменения метаданных"
+// @Success 200 {object} fileusecase.FileMetadataDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id}/metadata [patch]
+func (h *fileHandler) PatchFileMetadata(c echo.Context) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+
+	var req PatchFileMetadataRequest
+	if err := c.Bind(&req); err != nil {
+		h.log.Warn("invalid JSON in PatchFileMetadata", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+	}
+
+	return h.updateFileMetadata(c, &fileusecase.UpdateFileMetadataDtoIn{
+		UserID: getUserID(c),
+		FileID: fileID,
+		Set:    req.Set,
+		Unset:  req.Unset,
+	})
+}
+
+func (h *fileHandler) updateFileMetadata(c echo.Context, dto *fileusecase.UpdateFileMetadataDtoIn) error {
+	resp, err := h.fileUsecase.UpdateFileMetadata(c.Request().Context(), dto)
+	if err != nil {
+		return h.tagError(c, err, "failed to update file metadata")
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (h *fileHandler) tagError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, fileusecase.ErrFileNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "file not found"})
+	case errors.Is(err, fileusecase.ErrInvalidTags), errors.Is(err, fileusecase.ErrInvalidMetadata):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	h.log.Error(message, zap.Error(err))
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": message})
+}
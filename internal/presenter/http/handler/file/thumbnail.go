@@ -0,0 +1,102 @@
+package file
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// thumbnailRetryAfter — через сколько секунд клиенту стоит повторить запрос, пока миниатюра создается.
+const thumbnailRetryAfter = "5"
+
+// GetThumbnail возвращает миниатюру изображения
+// @Summary Получить миниатюру изображения
+// @Description Возвращает миниатюру PNG, JPEG или GIF, вписанную в квадрат size×size. Миниатюры создаются в фоне после загрузки;
+// @Description пока миниатюра не готова, возвращается заглушка со статусом 202 и заголовком Retry-After.
+// @Tags files
+// @Produce image/jpeg
+// @Produce image/webp
+// @Param name path string true "Имя файла с расширением"
+// @Param size query int false "Размер стороны в пикселях; по умолчанию первый из настроенных"
+// @Param format query string false "Формат: jpeg или webp; по умолчанию первый из настроенных"
+// @Success 200 {file} binary
+// @Success 202 {file} binary "Заглушка, миниатюра еще не готова"
+// @Success 304 "Миниатюра не изменилась"
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Failure 501 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/{name}/thumbnail [get]
+func (h *fileHandler) GetThumbnail(c echo.Context) error {
+	originalName, err := url.PathUnescape(c.Param("name"))
+	if err != nil || originalName == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "file name is required"})
+	}
+
+	req := &fileusecase.GetThumbnailDtoIn{
+		UserID:       getUserID(c),
+		UserEmail:    getUserEmail(c),
+		OriginalName: originalName,
+		Format:       strings.ToLower(c.QueryParam("format")),
+	}
+	if v := c.QueryParam("size"); v != "" {
+		req.Size, err = strconv.Atoi(v)
+		if err != nil || req.Size <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid size"})
+		}
+	}
+
+	resp, err := h.fileUsecase.GetThumbnail(c.Request().Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, fileusecase.ErrThumbnailsDisabled):
+			return c.JSON(http.StatusNotImplemented, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrInvalidThumbnail):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrFileNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "file not found"})
+		case errors.Is(err, fileusecase.ErrThumbnailUnavailable):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrFileQuarantined):
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		case errors.Is(err, fileusecase.ErrFileExpired):
+			return c.JSON(http.StatusGone, map[string]string{"error": err.Error()})
+		}
+		h.log.Error("failed to get thumbnail", zap.String("originalName", originalName), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to get thumbnail"})
+	}
+
+	header := c.Response().Header()
+	if resp.Pending {
+		header.Set("Retry-After", thumbnailRetryAfter)
+		header.Set("Cache-Control", "no-store")
+		return c.Blob(http.StatusAccepted, resp.ContentType, resp.Content)
+	}
+
+	header.Set("ETag", resp.ETag)
+	header.Set("Cache-Control", "private, no-cache")
+	if etagMatches(c.Request().Header.Get("If-None-Match"), resp.ETag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.Blob(http.StatusOK, resp.ContentType, resp.Content)
+}
+
+// etagMatches проверяет заголовок If-None-Match: список ETag через запятую или "*".
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
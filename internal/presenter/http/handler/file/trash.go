@@ -0,0 +1,96 @@
+package file
+
+import (
+	"errors"
+	"net/http"
+
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ListTrash получает содержимое корзины
+// @Summary Получить корзину
+// @Description Возвращает удаленные файлы пользователя, начиная с последних
+// @Tags trash
+// @Produce json
+// @Success 200 {object} fileusecase.ListTrashDtoOut
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /trash [get]
+func (h *fileHandler) ListTrash(c echo.Context) error {
+	dto := &fileusecase.ListTrashDtoIn{
+		UserID: getUserID(c),
+	}
+
+	resp, err := h.fileUsecase.ListTrash(c.Request().Context(), dto)
+	if err != nil {
+		h.log.Error("failed to list trash", zap.Int64("userID", dto.UserID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to list trash"})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// RestoreFromTrash восстанавливает файл из корзины
+// @Summary Восстановить файл из корзины
+// @Description Возвращает файл из корзины на прежнее место
+// @Tags trash
+// @Produce json
+// @Param id path int true "ID файла"
+// @Success 200 {object} fileusecase.RestoreFromTrashDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /trash/{id}/restore [post]
+func (h *fileHandler) RestoreFromTrash(c echo.Context) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+
+	dto := &fileusecase.RestoreFromTrashDtoIn{
+		UserID: getUserID(c),
+		FileID: fileID,
+	}
+
+	resp, err := h.fileUsecase.RestoreFromTrash(c.Request().Context(), dto)
+	if err != nil {
+		if errors.Is(err, fileusecase.ErrFileNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "file not found in trash"})
+		}
+		if isUniqueViolation(err) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "a file with the same name already exists"})
+		}
+		h.log.Error("failed to restore file from trash", zap.Int64("fileID", fileID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to restore file"})
+	}
+
+	h.log.Info("file restored from trash", zap.Int64("fileID", resp.ID))
+	return c.JSON(http.StatusOK, resp)
+}
+
+// EmptyTrash очищает корзину
+// @Summary Очистить корзину
+// @Description Безвозвратно удаляет все файлы из корзины
+// @Tags trash
+// @Produce json
+// @Success 200 {object} fileusecase.EmptyTrashDtoOut
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /trash [delete]
+func (h *fileHandler) EmptyTrash(c echo.Context) error {
+	dto := &fileusecase.EmptyTrashDtoIn{
+		UserID: getUserID(c),
+	}
+
+	resp, err := h.fileUsecase.EmptyTrash(c.Request().Context(), dto)
+	if err != nil {
+		h.log.Error("failed to empty trash", zap.Int64("userID", dto.UserID), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to empty trash"})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,193 @@
+package file
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	fileusecase "meemo/internal/usecase/file"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// ListFileVersions получает список версий файла
+// @Summary Получить версии файла
+// @Description Возвращает все версии файла, начиная с самой новой. История доступна только владельцу файла
+// @Tags versions
+// @Produce json
+// @Param id path int true "ID файла"
+// @Success 200 {object} fileusecase.ListFileVersionsDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id}/versions [get]
+func (h *fileHandler) ListFileVersions(c echo.Context) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+
+	dto := &fileusecase.ListFileVersionsDtoIn{
+		UserID: getUserID(c),
+		FileID: fileID,
+	}
+
+	resp, err := h.fileUsecase.ListFileVersions(c.Request().Context(), dto)
+	if err != nil {
+		return h.versionError(c, err, "failed to list file versions")
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetFileVersion скачивает версию файла
+// @Summary Скачать версию файла
+// @Description Скачивает содержимое указанной версии файла. Старые версии доступны только владельцу файла
+// @Tags versions
+// @Produce application/octet-stream
+// @Param id path int true "ID файла"
+// @Param version path int true "Номер версии"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id}/versions/{version} [get]
+func (h *fileHandler) GetFileVersion(c echo.Context) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+	versionNumber, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid version number"})
+	}
+
+	req := &fileusecase.GetFileVersionDtoIn{
+		UserID:        getUserID(c),
+		FileID:        fileID,
+		VersionNumber: versionNumber,
+	}
+
+	metadata, err := h.fileUsecase.GetFileVersionMetadata(c.Request().Context(), req)
+	if err != nil {
+		return h.versionError(c, err, "failed to get file version")
+	}
+
+	filename := ensureFileExtension(metadata.OriginalName, metadata.MimeType)
+
+	c.Response().Header().Set("Content-Disposition", "attachment; filename="+filename)
+	c.Response().Header().Set("Content-Type", metadata.MimeType)
+	setChecksumHeaders(c, metadata.ChecksumSHA256, metadata.ChecksumCRC32C)
+
+	_, err = h.fileUsecase.GetFileVersion(c.Request().Context(), req, c.Response().Writer)
+	if err != nil {
+		h.log.Error("failed to download file version", zap.Int64("fileID", fileID), zap.Int("version", versionNumber), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "failed to download file version"})
+	}
+
+	c.Response().Status = http.StatusOK
+	return nil
+}
+
+// RestoreFileVersion делает версию текущей
+// @Summary Восстановить версию файла
+// @Description Делает указанную версию текущей. Остальные версии сохраняются
+// @Tags versions
+// @Produce json
+// @Param id path int true "ID файла"
+// @Param version path int true "Номер версии"
+// @Success 200 {object} fileusecase.RestoreFileVersionDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id}/versions/{version}/restore [post]
+func (h *fileHandler) RestoreFileVersion(c echo.Context) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+	versionNumber, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid version number"})
+	}
+
+	dto := &fileusecase.RestoreFileVersionDtoIn{
+		UserID:        getUserID(c),
+		FileID:        fileID,
+		VersionNumber: versionNumber,
+	}
+
+	resp, err := h.fileUsecase.RestoreFileVersion(c.Request().Context(), dto)
+	if err != nil {
+		return h.versionError(c, err, "failed to restore file version")
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// PruneFileVersions удаляет старые версии файла
+// @Summary Удалить старые версии файла
+// @Description Удаляет нетекущие версии, не входящие в keep последних и старше older_than.
+// @Description Если задан только один параметр, используется только он
+// @Tags versions
+// @Produce json
+// @Param id path int true "ID файла"
+// @Param keep query int false "Сколько последних версий оставить"
+// @Param older_than query string false "Минимальный возраст удаляемых версий, например 720h"
+// @Success 200 {object} fileusecase.PruneFileVersionsDtoOut
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/by-id/{id}/versions [delete]
+func (h *fileHandler) PruneFileVersions(c echo.Context) error {
+	fileID, err := parseIDParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+	}
+
+	dto := &fileusecase.PruneFileVersionsDtoIn{
+		UserID: getUserID(c),
+		FileID: fileID,
+	}
+	if keep := c.QueryParam("keep"); keep != "" {
+		if dto.Keep, err = strconv.Atoi(keep); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid keep value"})
+		}
+	}
+	if olderThan := c.QueryParam("older_than"); olderThan != "" {
+		if dto.OlderThan, err = time.ParseDuration(olderThan); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid older_than value"})
+		}
+	}
+
+	resp, err := h.fileUsecase.PruneFileVersions(c.Request().Context(), dto)
+	if err != nil {
+		return h.versionError(c, err, "failed to prune file versions")
+	}
+
+	h.log.Info("file versions pruned", zap.Int64("fileID", resp.FileID), zap.Ints("versions", resp.DeletedVersions))
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (h *fileHandler) versionError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, fileusecase.ErrFileNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "file not found"})
+	case errors.Is(err, fileusecase.ErrAccessDenied), errors.Is(err, fileusecase.ErrFileQuarantined):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, fileusecase.ErrFileExpired):
+		return c.JSON(http.StatusGone, map[string]string{"error": err.Error()})
+	case errors.Is(err, fileusecase.ErrVersionNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "file version not found"})
+	case errors.Is(err, fileusecase.ErrInvalidPruneCriteria):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	h.log.Warn(message, zap.Error(err))
+	return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+}
@@ -14,6 +14,10 @@ func NewRouter(e *echo.Echo, h handler.AppHandler) {
 	e.GET("/ping", Ping)
 	e.GET("/csrf-token", GetCSRFToken)
 
+	// Публичные ссылки открываются без авторизации.
+	e.GET("/s/:token", h.OpenShareLink)
+	e.POST("/s/:token", h.OpenShareLink)
+
 	userRouter := e.Group("/api/v1/users")
 	userRouter.POST("/register", h.CreateUser)
 	userRouter.POST("/login", h.AuthUser)
@@ -26,16 +30,65 @@ func NewRouter(e *echo.Echo, h handler.AppHandler) {
 	fileRouter := e.Group("/api/v1/files", h.FileMiddleware())
 	fileRouter.GET("", h.GetUserFilesList)
 	fileRouter.GET("/storage", h.GetStorageInfo)
+	fileRouter.GET("/search", h.SearchFiles)
+	fileRouter.GET("/shared", h.ListSharedWithMe)
 	fileRouter.POST("/metadata", h.SaveFileMetadata)
+	fileRouter.POST("/instant", h.InstantUpload)
+	fileRouter.POST("/batch", h.BatchFiles)
+	fileRouter.POST("/archive", h.DownloadArchive)
 	fileRouter.POST("/:id/content", h.SaveFileContent)
 	fileRouter.PUT("/rename", h.RenameFile)
 	fileRouter.PUT("/visibility", h.ChangeVisibility)
 	fileRouter.PUT("/status", h.SetStatus)
 
 	fileRouter.GET("/by-id/:id", h.GetFileByID)
+	fileRouter.DELETE("/by-id/:id", h.DeleteFileByID)
+	fileRouter.PUT("/by-id/:id/rename", h.RenameFileByID)
+	fileRouter.GET("/by-id/:id/shares", h.ListFileShares)
+	fileRouter.POST("/by-id/:id/shares", h.ShareFile)
+	fileRouter.DELETE("/by-id/:id/shares/:user_id", h.RevokeFileShare)
+	fileRouter.POST("/by-id/:id/links", h.CreateShareLink)
+	fileRouter.PUT("/by-id/:id/move", h.MoveFile)
+	fileRouter.POST("/by-id/:id/transfer", h.TransferFile)
+	fileRouter.POST("/by-id/:id/tags", h.AddFileTags)
+	fileRouter.PUT("/by-id/:id/tags", h.ReplaceFileTags)
+	fileRouter.DELETE("/by-id/:id/tags", h.RemoveFileTags)
+	fileRouter.PUT("/by-id/:id/metadata", h.ReplaceFileMetadata)
+	fileRouter.PATCH("/by-id/:id/metadata", h.PatchFileMetadata)
+	fileRouter.PUT("/by-id/:id/expiry", h.SetFileExpiry)
+	fileRouter.GET("/by-id/:id/versions", h.ListFileVersions)
+	fileRouter.DELETE("/by-id/:id/versions", h.PruneFileVersions)
+	fileRouter.GET("/by-id/:id/versions/:version", h.GetFileVersion)
+	fileRouter.POST("/by-id/:id/versions/:version/restore", h.RestoreFileVersion)
 	fileRouter.GET("/:name/info", h.GetFileInfo)
+	fileRouter.POST("/:name/copy", h.CopyFile)
+	fileRouter.GET("/:name/thumbnail", h.GetThumbnail)
 	fileRouter.GET("/:name", h.GetFile)
 	fileRouter.DELETE("/:name", h.DeleteFile)
+
+	trashRouter := e.Group("/api/v1/trash", h.FileMiddleware())
+	trashRouter.GET("", h.ListTrash)
+	trashRouter.DELETE("", h.EmptyTrash)
+	trashRouter.POST("/:id/restore", h.RestoreFromTrash)
+
+	linkRouter := e.Group("/api/v1/links", h.FileMiddleware())
+	linkRouter.GET("", h.ListShareLinks)
+	linkRouter.DELETE("/:id", h.RevokeShareLink)
+
+	tagRouter := e.Group("/api/v1/tags", h.FileMiddleware())
+	tagRouter.GET("", h.ListTags)
+
+	folderRouter := e.Group("/api/v1/folders", h.FileMiddleware())
+	folderRouter.GET("", h.ListRootFolder)
+	folderRouter.POST("", h.CreateFolder)
+	folderRouter.GET("/:id/children", h.ListFolderChildren)
+	folderRouter.GET("/:id/size", h.GetFolderSize)
+	folderRouter.PUT("/:id/rename", h.RenameFolder)
+	folderRouter.PUT("/:id/move", h.MoveFolder)
+	folderRouter.DELETE("/:id", h.DeleteFolder)
+
+	adminRouter := e.Group("/api/v1/admin", h.FileMiddleware(), h.AdminMiddleware())
+	adminRouter.POST("/fsck", h.CheckStorage)
 }
 
 // Ping проверяет доступность сервера
@@ -0,0 +1,10 @@
+package encryption
+
+type RewrapKeysDtoIn struct {
+	BatchSize int `json:"batch_size"`
+}
+
+type RewrapKeysDtoOut struct {
+	Rewrapped int `json:"rewrapped"`
+	Failed    int `json:"failed"`
+}
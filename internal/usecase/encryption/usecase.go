@@ -0,0 +1,76 @@
+package encryption
+
+import (
+	"context"
+	"meemo/internal/domain/encryption/repository"
+	"meemo/internal/infrastructure/crypto"
+	"meemo/internal/infrastructure/logger"
+
+	"go.uber.org/zap"
+)
+
+type Usecase interface {
+	RewrapKeys(ctx context.Context, in *RewrapKeysDtoIn) (*RewrapKeysDtoOut, error)
+}
+
+type encryptionUsecase struct {
+	objectKeys repository.ObjectKeyRepository
+	keys       crypto.KeyProvider
+	log        logger.Logger
+}
+
+func NewEncryptionUsecase(objectKeys repository.ObjectKeyRepository, keys crypto.KeyProvider, log logger.Logger) Usecase {
+	return &encryptionUsecase{
+		objectKeys: objectKeys,
+		keys:       keys,
+		log:        log,
+	}
+}
+
+// RewrapKeys переоборачивает текущим мастер-ключом ключи данных, обернутые прежними мастер-ключами.
+// Содержимое объектов при этом не перешифровывается. Когда задание обработает все ключи,
+// старый мастер-ключ можно удалить из keyfile. Ключи, которые не удалось развернуть, отмечаются
+// и в следующих запусках пропускаются: их объекты остаются читаемыми только старым мастер-ключом.
+func (u *encryptionUsecase) RewrapKeys(ctx context.Context, in *RewrapKeysDtoIn) (*RewrapKeysDtoOut, error) {
+	currentKeyID := u.keys.CurrentKeyID()
+	objectKeys, err := u.objectKeys.ListWrappedWithOther(ctx, currentKeyID, in.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &RewrapKeysDtoOut{}
+	for _, objectKey := range objectKeys {
+		if err := ctx.Err(); err != nil {
+			return out, err
+		}
+
+		dataKey, err := u.keys.UnwrapKey(ctx, objectKey.KeyID, objectKey.WrappedKey)
+		if err != nil {
+			u.log.Error("failed to unwrap data key", zap.String("key", objectKey.S3Key), zap.String("keyID", objectKey.KeyID), zap.Error(err))
+			// Отмеченный ключ больше не попадает в выборку, иначе такие ключи заняли бы всю пачку.
+			if err := u.objectKeys.MarkRewrapFailed(ctx, objectKey.S3Key, objectKey.KeyID); err != nil {
+				return out, err
+			}
+			out.Failed++
+			continue
+		}
+		keyID, wrappedKey, err := u.keys.WrapKey(ctx, dataKey)
+		if err != nil {
+			return out, err
+		}
+
+		// Объект мог быть перезаписан или удален, пока ключ переоборачивался: тогда строка не меняется.
+		rewrapped, err := u.objectKeys.Rewrap(ctx, objectKey.S3Key, objectKey.KeyID, keyID, wrappedKey)
+		if err != nil {
+			return out, err
+		}
+		if rewrapped {
+			out.Rewrapped++
+		}
+	}
+
+	if out.Rewrapped > 0 || out.Failed > 0 {
+		u.log.Info("data keys rewrapped", zap.String("keyID", currentKeyID), zap.Int("rewrapped", out.Rewrapped), zap.Int("failed", out.Failed))
+	}
+	return out, nil
+}
@@ -0,0 +1,121 @@
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"meemo/internal/domain/encryption/repository"
+	"meemo/internal/domain/entity"
+	"meemo/internal/infrastructure/crypto"
+	"meemo/internal/infrastructure/logger"
+	"meemo/internal/infrastructure/storage/sqlite"
+	sqliteencryption "meemo/internal/infrastructure/storage/sqlite/encryption"
+)
+
+func TestRewrapKeys_SkipsKeysThatFailToUnwrap(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	db, err := sqlite.NewSQLiteConnection(&sqlite.SQLiteConfig{Path: filepath.Join(dir, "meemo.db")})
+	if err != nil {
+		t.Fatalf("Failed to open sqlite database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	objectKeys := sqliteencryption.NewObjectKeyRepository(db)
+
+	keyfile := filepath.Join(dir, "keys")
+	var lines string
+	for _, keyID := range []string{"old", "new"} {
+		masterKey := make([]byte, crypto.DataKeySize)
+		if _, err := rand.Read(masterKey); err != nil {
+			t.Fatalf("Failed to generate master key: %v", err)
+		}
+		lines += keyID + " " + base64.StdEncoding.EncodeToString(masterKey) + "\n"
+	}
+	if err := os.WriteFile(keyfile, []byte(lines), 0o600); err != nil {
+		t.Fatalf("Failed to write keyfile: %v", err)
+	}
+	oldKeys, err := crypto.NewLocalKeyProvider(keyfile, "old")
+	if err != nil {
+		t.Fatalf("Failed to load keys: %v", err)
+	}
+	newKeys, err := crypto.NewLocalKeyProvider(keyfile, "new")
+	if err != nil {
+		t.Fatalf("Failed to load keys: %v", err)
+	}
+
+	const batchSize = 2
+	const broken = 2*batchSize + 1
+	const valid = 3
+	// Испорченные ключи идут первыми по s3_key и без отметки заняли бы каждую пачку.
+	for i := 0; i < broken; i++ {
+		saveObjectKey(t, objectKeys, fmt.Sprintf("a/broken-%d", i), "old", []byte("not a wrapped key"))
+	}
+	for i := 0; i < valid; i++ {
+		dataKey, err := crypto.NewDataKey()
+		if err != nil {
+			t.Fatalf("Failed to generate data key: %v", err)
+		}
+		keyID, wrapped, err := oldKeys.WrapKey(ctx, dataKey)
+		if err != nil {
+			t.Fatalf("Failed to wrap data key: %v", err)
+		}
+		saveObjectKey(t, objectKeys, fmt.Sprintf("z/valid-%d", i), keyID, wrapped)
+	}
+
+	log, _ := logger.NewLogger("error")
+	u := NewEncryptionUsecase(objectKeys, newKeys, log)
+
+	var rewrapped, failed int
+	for run := 0; run < broken+valid; run++ {
+		out, err := u.RewrapKeys(ctx, &RewrapKeysDtoIn{BatchSize: batchSize})
+		if err != nil {
+			t.Fatalf("Failed to rewrap keys: %v", err)
+		}
+		rewrapped += out.Rewrapped
+		failed += out.Failed
+	}
+	if rewrapped != valid || failed != broken {
+		t.Fatalf("Expected %d rewrapped and %d failed keys, got %d and %d", valid, broken, rewrapped, failed)
+	}
+
+	for i := 0; i < valid; i++ {
+		objectKey, err := objectKeys.Get(ctx, fmt.Sprintf("z/valid-%d", i))
+		if err != nil {
+			t.Fatalf("Failed to get key: %v", err)
+		}
+		if objectKey.KeyID != "new" {
+			t.Errorf("Expected %s to be wrapped with the new master key, got %s", objectKey.S3Key, objectKey.KeyID)
+		}
+		if _, err := newKeys.UnwrapKey(ctx, objectKey.KeyID, objectKey.WrappedKey); err != nil {
+			t.Errorf("Failed to unwrap rewrapped key %s: %v", objectKey.S3Key, err)
+		}
+	}
+
+	remaining, err := objectKeys.ListWrappedWithOther(ctx, "new", broken+valid)
+	if err != nil {
+		t.Fatalf("Failed to list keys: %v", err)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("Expected failed keys to be excluded from rotation, got %d keys", len(remaining))
+	}
+}
+
+func saveObjectKey(t *testing.T, objectKeys repository.ObjectKeyRepository, s3Key, keyID string, wrapped []byte) {
+	t.Helper()
+	_, err := objectKeys.Save(context.Background(), &entity.ObjectKey{
+		S3Key:         s3Key,
+		KeyID:         keyID,
+		WrappedKey:    wrapped,
+		ChunkSize:     65536,
+		PlaintextSize: 10,
+	})
+	if err != nil {
+		t.Fatalf("Failed to save key %s: %v", s3Key, err)
+	}
+}
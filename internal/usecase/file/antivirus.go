@@ -0,0 +1,169 @@
+package file
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	"meemo/internal/domain/entity"
+	"meemo/internal/infrastructure/antivirus"
+	"meemo/internal/infrastructure/notify"
+
+	"go.uber.org/zap"
+)
+
+// AntivirusOptions задает проверку загружаемого содержимого. Без Scanner проверка выключена.
+type AntivirusOptions struct {
+	Scanner antivirus.Scanner
+	// FailOpen принимает содержимое, если сканер недоступен; иначе загрузка отклоняется.
+	FailOpen bool
+	// О заражении сообщается владельцу файла и администраторам AdminEmails.
+	Notifier    notify.Notifier
+	AdminEmails []string
+}
+
+var errScanFinished = errors.New("antivirus scan finished")
+
+// contentScan проверяет содержимое параллельно с загрузкой: загрузка пишет в него копию потока.
+type contentScan struct {
+	pw     *io.PipeWriter
+	done   chan struct{}
+	result *antivirus.Result
+	err    error
+}
+
+// startScan запускает проверку потока или возвращает nil, если проверка выключена.
+func (u *fileUsecase) startScan(ctx context.Context) *contentScan {
+	scanner := u.opts.Antivirus.Scanner
+	if scanner == nil {
+		return nil
+	}
+
+	pr, pw := io.Pipe()
+	s := &contentScan{pw: pw, done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		s.result, s.err = scanner.Scan(ctx, pr)
+		// Сканер мог остановиться раньше конца потока. Остаток дочитывается, чтобы не блокировать загрузку.
+		_, _ = io.Copy(io.Discard, pr)
+	}()
+	return s
+}
+
+func (s *contentScan) Write(p []byte) (int, error) {
+	return s.pw.Write(p)
+}
+
+// wait сообщает сканеру о конце потока и возвращает вердикт.
+func (s *contentScan) wait() (*antivirus.Result, error) {
+	_ = s.pw.Close()
+	<-s.done
+	return s.result, s.err
+}
+
+// abort прерывает проверку, если загрузка не удалась.
+func (s *contentScan) abort(err error) {
+	if s == nil {
+		return
+	}
+	_ = s.pw.CloseWithError(err)
+	<-s.done
+}
+
+// scanObject проверяет содержимое, уже сохраненное в S3.
+func (u *fileUsecase) scanObject(ctx context.Context, key, encoding string) (*antivirus.Result, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		_ = pw.CloseWithError(u.readObject(ctx, key, encoding, pw))
+	}()
+
+	result, err := u.opts.Antivirus.Scanner.Scan(ctx, pr)
+	_ = pr.CloseWithError(errScanFinished)
+	return result, err
+}
+
+// applyScanResult записывает вердикт в версию. Если сканер недоступен и FailOpen выключен,
+// возвращается ErrScanUnavailable.
+func (u *fileUsecase) applyScanResult(version *entity.FileVersion, result *antivirus.Result, scanErr error) error {
+	now := time.Now()
+	version.ScannedAt = &now
+
+	switch {
+	case scanErr != nil:
+		if !u.opts.Antivirus.FailOpen {
+			return fmt.Errorf("%w: %v", ErrScanUnavailable, scanErr)
+		}
+		u.log.Warn("antivirus scan failed, content accepted unscanned", zap.Int64("fileID", version.FileID), zap.Error(scanErr))
+		version.ScanStatus = entity.ScanError
+	case result.Infected:
+		version.ScanStatus = entity.ScanInfected
+		version.ScanSignature = result.Signature
+	default:
+		version.ScanStatus = entity.ScanClean
+	}
+	return nil
+}
+
+// explainNotUpdated уточняет ошибку обновления файла по имени. Запросы не меняют файлы в карантине,
+// и для них вместо sql.ErrNoRows возвращается ErrFileQuarantined.
+func (u *fileUsecase) explainNotUpdated(ctx context.Context, userEmail, originalName string, err error) error {
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	metaFile, getErr := u.fileRepo.GetByOriginalNameAndUserEmail(ctx, userEmail, originalName)
+	if getErr == nil && metaFile.Status == entity.Quarantined {
+		return ErrFileQuarantined
+	}
+	return err
+}
+
+func checkNotQuarantined(metaFile *entity.File) error {
+	if metaFile.Status == entity.Quarantined {
+		return ErrFileQuarantined
+	}
+	return nil
+}
+
+// notifyQuarantined сообщает владельцу и администраторам о помещенном в карантин файле.
+// Ошибка доставки не отменяет загрузку и только попадает в журнал.
+func (u *fileUsecase) notifyQuarantined(ctx context.Context, metaFile *entity.File) {
+	u.log.Warn("infected file quarantined",
+		zap.Int64("fileID", metaFile.ID),
+		zap.Int64("userID", metaFile.UserID),
+		zap.String("signature", metaFile.ScanSignature),
+	)
+
+	notifier := u.opts.Antivirus.Notifier
+	if notifier == nil {
+		return
+	}
+
+	recipients := slices.Clone(u.opts.Antivirus.AdminEmails)
+	ownerEmail, err := u.fileRepo.GetUserEmail(ctx, metaFile.UserID)
+	if err != nil {
+		u.log.Warn("failed to get file owner email", zap.Int64("userID", metaFile.UserID), zap.Error(err))
+	} else {
+		recipients = append([]string{ownerEmail}, recipients...)
+	}
+
+	err = notifier.Notify(ctx, &notify.Notification{
+		Event:      notify.EventFileQuarantined,
+		Recipients: recipients,
+		Subject:    fmt.Sprintf("File %q has been quarantined", metaFile.OriginalName),
+		Data: map[string]string{
+			"file_id":   strconv.FormatInt(metaFile.ID, 10),
+			"file_name": metaFile.OriginalName,
+			"owner_id":  strconv.FormatInt(metaFile.UserID, 10),
+			"signature": metaFile.ScanSignature,
+		},
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		u.log.Warn("failed to send quarantine notification", zap.Int64("fileID", metaFile.ID), zap.Error(err))
+	}
+}
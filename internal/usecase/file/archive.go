@@ -0,0 +1,279 @@
+package file
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"meemo/internal/domain/entity"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	ArchiveFormatZip   = "zip"
+	ArchiveFormatTarGz = "tar.gz"
+
+	MaxArchiveEntries = 10000
+)
+
+// archiveEntry — файл или папка внутри архива. Для папки file равен nil.
+type archiveEntry struct {
+	path     string
+	file     *entity.File
+	modified time.Time
+}
+
+// archiveNames выдает уникальные пути внутри архива: повторяющееся имя получает суффикс " (N)".
+type archiveNames map[string]struct{}
+
+func (n archiveNames) unique(dir, name string) string {
+	name = sanitizeArchiveName(name)
+	candidate := path.Join(dir, name)
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; ; i++ {
+		if _, taken := n[candidate]; !taken {
+			n[candidate] = struct{}{}
+			return candidate
+		}
+		candidate = path.Join(dir, base+" ("+strconv.Itoa(i)+")"+ext)
+	}
+}
+
+// sanitizeArchiveName не дает имени файла выйти за пределы своей папки при распаковке.
+func sanitizeArchiveName(name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(strings.TrimSpace(name))
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
+
+// PrepareArchive проверяет доступ к каждому файлу и составляет список записей архива.
+// Отдельные файлы должны быть доступны пользователю на чтение, папка — принадлежать ему.
+func (u *fileUsecase) PrepareArchive(ctx context.Context, in *PrepareArchiveDtoIn) (*ArchiveDtoOut, error) {
+	out := &ArchiveDtoOut{
+		Format:   in.Format,
+		FileName: "files",
+	}
+	switch in.Format {
+	case "", ArchiveFormatZip:
+		out.Format, out.ContentType = ArchiveFormatZip, "application/zip"
+	case ArchiveFormatTarGz:
+		out.ContentType = "application/gzip"
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidArchive, in.Format)
+	}
+	if len(in.FileIDs) == 0 && in.FolderID == nil {
+		return nil, fmt.Errorf("%w: file_ids or folder_id is required", ErrInvalidArchive)
+	}
+
+	names := archiveNames{}
+	selected := make(map[int64]struct{}, len(in.FileIDs))
+	for _, fileID := range in.FileIDs {
+		if _, ok := selected[fileID]; ok {
+			continue
+		}
+		selected[fileID] = struct{}{}
+
+		metaFile, err := u.getFileMetadataAndCheckAccess(ctx, fileID, in.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if metaFile.Status == entity.Quarantined {
+			return nil, fmt.Errorf("%w: %s", ErrFileQuarantined, metaFile.OriginalName)
+		}
+		if metaFile.Status != entity.Loaded {
+			return nil, fmt.Errorf("%w: %s", ErrFileNotReady, metaFile.OriginalName)
+		}
+		if err := checkNotExpired(metaFile); err != nil {
+			return nil, fmt.Errorf("%w: %s", err, metaFile.OriginalName)
+		}
+		out.entries = append(out.entries, archiveEntry{
+			path:     names.unique("", metaFile.OriginalName),
+			file:     metaFile,
+			modified: metaFile.UpdatedAt,
+		})
+	}
+
+	if in.FolderID != nil {
+		entries, err := u.folderArchiveEntries(ctx, in.UserID, *in.FolderID, names)
+		if err != nil {
+			return nil, err
+		}
+		out.entries = append(out.entries, entries...)
+		if len(in.FileIDs) == 0 {
+			out.FileName = entries[0].path
+		}
+	}
+
+	if len(out.entries) > MaxArchiveEntries {
+		return nil, fmt.Errorf("%w: at most %d entries are allowed", ErrInvalidArchive, MaxArchiveEntries)
+	}
+	out.Entries = len(out.entries)
+	out.FileName += "." + out.Format
+	return out, nil
+}
+
+// folderArchiveEntries возвращает папку, ее подпапки и загруженные файлы поддерева с действующим сроком хранения.
+// Первой записью всегда идет сама папка.
+func (u *fileUsecase) folderArchiveEntries(ctx context.Context, userID, folderID int64, names archiveNames) ([]archiveEntry, error) {
+	if _, err := u.getFolder(ctx, userID, folderID); err != nil {
+		return nil, err
+	}
+
+	folders, err := u.folderRepo.ListTree(ctx, userID, folderID)
+	if err != nil {
+		return nil, err
+	}
+	files, err := u.fileRepo.ListInFolderTree(ctx, userID, folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]archiveEntry, 0, len(folders)+len(files))
+	paths := make(map[int64]string, len(folders))
+	for _, folder := range folders {
+		parent := ""
+		if folder.ID != folderID && folder.ParentID != nil {
+			parent = paths[*folder.ParentID]
+		}
+		paths[folder.ID] = names.unique(parent, folder.Name)
+		entries = append(entries, archiveEntry{path: paths[folder.ID], modified: folder.UpdatedAt})
+	}
+
+	for _, treeFile := range files {
+		if treeFile.DeletedAt != nil || treeFile.Status != entity.Loaded || treeFile.FolderID == nil {
+			continue
+		}
+		// Истекший файл уже считается удаленным, даже если задача до него еще не дошла.
+		if checkNotExpired(treeFile) != nil {
+			continue
+		}
+		entries = append(entries, archiveEntry{
+			path:     names.unique(paths[*treeFile.FolderID], treeFile.OriginalName),
+			file:     treeFile,
+			modified: treeFile.UpdatedAt,
+		})
+	}
+	return entries, nil
+}
+
+// WriteArchive потоково пишет архив в inWriter, читая содержимое файлов из хранилища по одному.
+// ZIP64-записи добавляются автоматически, когда размер или число записей превышают пределы ZIP.
+func (u *fileUsecase) WriteArchive(ctx context.Context, archive *ArchiveDtoOut, inWriter io.Writer) error {
+	if inWriter == nil {
+		return errors.New("output writer is nil")
+	}
+
+	var aw archiveWriter
+	if archive.Format == ArchiveFormatTarGz {
+		aw = newTarGzArchiveWriter(inWriter)
+	} else {
+		aw = &zipArchiveWriter{zw: zip.NewWriter(inWriter)}
+	}
+
+	for _, entry := range archive.entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if entry.file == nil {
+			if err := aw.addDir(entry.path, entry.modified); err != nil {
+				return err
+			}
+			continue
+		}
+
+		w, err := aw.addFile(entry.path, entry.file.SizeInBytes, entry.modified)
+		if err != nil {
+			return err
+		}
+		if err := u.readContent(ctx, entry.file, w); err != nil {
+			return fmt.Errorf("read %s: %w", entry.path, err)
+		}
+	}
+
+	if err := aw.Close(); err != nil {
+		return err
+	}
+
+	u.log.Info("archive streamed", zap.String("format", archive.Format), zap.Int("entries", archive.Entries))
+	return nil
+}
+
+type archiveWriter interface {
+	addDir(name string, modified time.Time) error
+	addFile(name string, size int64, modified time.Time) (io.Writer, error)
+	Close() error
+}
+
+type zipArchiveWriter struct {
+	zw *zip.Writer
+}
+
+func (z *zipArchiveWriter) addDir(name string, modified time.Time) error {
+	_, err := z.zw.CreateHeader(&zip.FileHeader{Name: name + "/", Modified: modified})
+	return err
+}
+
+// Размеры записи ZIP пишутся в дескриптор данных после содержимого, поэтому size не нужен.
+func (z *zipArchiveWriter) addFile(name string, _ int64, modified time.Time) (io.Writer, error) {
+	return z.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+}
+
+func (z *zipArchiveWriter) Close() error {
+	return z.zw.Close()
+}
+
+type tarGzArchiveWriter struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func newTarGzArchiveWriter(w io.Writer) *tarGzArchiveWriter {
+	gz := gzip.NewWriter(w)
+	return &tarGzArchiveWriter{gz: gz, tw: tar.NewWriter(gz)}
+}
+
+func (t *tarGzArchiveWriter) addDir(name string, modified time.Time) error {
+	return t.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     name + "/",
+		Mode:     0o755,
+		ModTime:  modified,
+	})
+}
+
+func (t *tarGzArchiveWriter) addFile(name string, size int64, modified time.Time) (io.Writer, error) {
+	err := t.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o644,
+		Size:     size,
+		ModTime:  modified,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t.tw, nil
+}
+
+func (t *tarGzArchiveWriter) Close() error {
+	if err := t.tw.Close(); err != nil {
+		return err
+	}
+	return t.gz.Close()
+}
@@ -0,0 +1,255 @@
+package file
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"meemo/internal/domain/entity"
+)
+
+func TestArchiveNames_Unique(t *testing.T) {
+	names := archiveNames{}
+	tests := []struct {
+		dir, name, expected string
+	}{
+		{"", "report.pdf", "report.pdf"},
+		{"", "report.pdf", "report (1).pdf"},
+		{"", "report.pdf", "report (2).pdf"},
+		{"docs", "report.pdf", "docs/report.pdf"},
+		{"docs", "report.pdf", "docs/report (1).pdf"},
+		{"", "README", "README"},
+		{"", "README", "README (1)"},
+		{"", "archive.tar.gz", "archive.tar.gz"},
+		{"", "archive.tar.gz", "archive.tar (1).gz"},
+		{"", ".env", ".env"},
+		{"", ".env", " (1).env"},
+		{"", "a/b.txt", "a_b.txt"},
+		{"", "a_b.txt", "a_b (1).txt"},
+	}
+	for _, tt := range tests {
+		if got := names.unique(tt.dir, tt.name); got != tt.expected {
+			t.Errorf("unique(%q, %q) = %q, expected %q", tt.dir, tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestSanitizeArchiveName(t *testing.T) {
+	tests := []struct {
+		name, expected string
+	}{
+		{"report.pdf", "report.pdf"},
+		{"../../etc/passwd", ".._.._etc_passwd"},
+		{"..\\..\\windows\\system.ini", ".._.._windows_system.ini"},
+		{"/abs", "_abs"},
+		{"..", "_"},
+		{".", "_"},
+		{"  ", "_"},
+		{"", "_"},
+		{" spaced.txt ", "spaced.txt"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeArchiveName(tt.name); got != tt.expected {
+			t.Errorf("sanitizeArchiveName(%q) = %q, expected %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestPrepareArchive_RejectsInaccessibleAndUnreadyFiles(t *testing.T) {
+	env := newTestEnv(t, Options{})
+	ctx := context.Background()
+	owner := env.createUser(t, "archiveowner@test.com")
+	other := env.createUser(t, "archiveother@test.com")
+
+	ready := env.uploadFile(t, owner, "ready.txt", []byte("ready"), nil)
+	pending := env.createFile(t, owner, "pending.txt", 5, nil).ID
+	foreign := env.uploadFile(t, other, "foreign.txt", []byte("foreign"), nil)
+	quarantined, err := env.fileRepo.Create(ctx, &entity.File{
+		UserID:       owner.ID,
+		OriginalName: "infected.exe",
+		MimeType:     "application/octet-stream",
+		SizeInBytes:  10,
+		Status:       entity.Quarantined,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create quarantined file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		fileIDs  []int64
+		expected error
+	}{
+		{"foreign file", []int64{ready, foreign}, ErrAccessDenied},
+		{"quarantined file", []int64{ready, quarantined.ID}, ErrFileQuarantined},
+		{"pending file", []int64{ready, pending}, ErrFileNotReady},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := env.PrepareArchive(ctx, &PrepareArchiveDtoIn{UserID: owner.ID, FileIDs: tt.fileIDs})
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	out, err := env.PrepareArchive(ctx, &PrepareArchiveDtoIn{UserID: owner.ID, FileIDs: []int64{ready, ready}})
+	if err != nil {
+		t.Fatalf("Failed to prepare archive of accessible files: %v", err)
+	}
+	if out.Entries != 1 || out.FileName != "files.zip" {
+		t.Errorf("Expected files.zip with 1 entry, got %s with %d entries", out.FileName, out.Entries)
+	}
+}
+
+func TestPrepareArchive_EntryLimit(t *testing.T) {
+	env := newTestEnv(t, Options{})
+	ctx := context.Background()
+	owner := env.createUser(t, "archivelimit@test.com")
+
+	folder, err := env.CreateFolder(ctx, &CreateFolderDtoIn{UserID: owner.ID, Name: "many"})
+	if err != nil {
+		t.Fatalf("Failed to create folder: %v", err)
+	}
+	// Вместе с самой папкой записей на одну больше предела.
+	_, err = env.db.ExecContext(ctx, `
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < ?1)
+INSERT INTO files (user_id, original_name, mime_type, size_in_bytes, s3_bucket, s3_key, status, folder_id)
+SELECT ?2, 'file-' || i || '.txt', 'text/plain', 0, '', '', ?3, ?4 FROM n`,
+		MaxArchiveEntries, owner.ID, entity.Loaded, folder.ID)
+	if err != nil {
+		t.Fatalf("Failed to create files: %v", err)
+	}
+
+	_, err = env.PrepareArchive(ctx, &PrepareArchiveDtoIn{UserID: owner.ID, FolderID: &folder.ID})
+	if !errors.Is(err, ErrInvalidArchive) {
+		t.Fatalf("Expected ErrInvalidArchive over %d entries, got %v", MaxArchiveEntries, err)
+	}
+
+	if _, err := env.db.ExecContext(ctx, `DELETE FROM files WHERE original_name = 'file-1.txt'`); err != nil {
+		t.Fatalf("Failed to delete file: %v", err)
+	}
+	out, err := env.PrepareArchive(ctx, &PrepareArchiveDtoIn{UserID: owner.ID, FolderID: &folder.ID})
+	if err != nil {
+		t.Fatalf("Expected archive of exactly %d entries, got %v", MaxArchiveEntries, err)
+	}
+	if out.Entries != MaxArchiveEntries {
+		t.Errorf("Expected %d entries, got %d", MaxArchiveEntries, out.Entries)
+	}
+}
+
+func TestWriteArchive_ZipAndTarGz(t *testing.T) {
+	env := newTestEnv(t, Options{})
+	ctx := context.Background()
+	owner := env.createUser(t, "archivewrite@test.com")
+
+	root, err := env.CreateFolder(ctx, &CreateFolderDtoIn{UserID: owner.ID, Name: "project"})
+	if err != nil {
+		t.Fatalf("Failed to create folder: %v", err)
+	}
+	sub, err := env.CreateFolder(ctx, &CreateFolderDtoIn{UserID: owner.ID, Name: "src", ParentID: &root.ID})
+	if err != nil {
+		t.Fatalf("Failed to create folder: %v", err)
+	}
+	env.uploadFile(t, owner, "README.md", []byte("# project"), &root.ID)
+	env.uploadFile(t, owner, "main.go", []byte("package main"), &sub.ID)
+	env.createFile(t, owner, "pending.txt", 3, &sub.ID)
+	loose := env.uploadFile(t, owner, "project", []byte("same name as the folder"), nil)
+
+	expected := map[string]string{
+		"project":                 "same name as the folder",
+		"project (1)/":            "",
+		"project (1)/README.md":   "# project",
+		"project (1)/src/":        "",
+		"project (1)/src/main.go": "package main",
+	}
+
+	for _, format := range []string{ArchiveFormatZip, ArchiveFormatTarGz} {
+		t.Run(format, func(t *testing.T) {
+			archive, err := env.PrepareArchive(ctx, &PrepareArchiveDtoIn{
+				UserID:   owner.ID,
+				FileIDs:  []int64{loose},
+				FolderID: &root.ID,
+				Format:   format,
+			})
+			if err != nil {
+				t.Fatalf("Failed to prepare archive: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := env.WriteArchive(ctx, archive, &buf); err != nil {
+				t.Fatalf("Failed to write archive: %v", err)
+			}
+
+			var got map[string]string
+			if format == ArchiveFormatZip {
+				got = readZip(t, buf.Bytes())
+			} else {
+				got = readTarGz(t, buf.Bytes())
+			}
+			if len(got) != len(expected) {
+				t.Errorf("Expected %d entries, got %d: %v", len(expected), len(got), got)
+			}
+			for name, content := range expected {
+				if actual, ok := got[name]; !ok || actual != content {
+					t.Errorf("Expected entry %q with %q, got %q (present: %v)", name, content, actual, ok)
+				}
+			}
+		})
+	}
+}
+
+func readZip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Failed to open zip: %v", err)
+	}
+	entries := make(map[string]string, len(zr.File))
+	for _, zf := range zr.File {
+		rc, err := zf.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", zf.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", zf.Name, err)
+		}
+		entries[zf.Name] = string(content)
+	}
+	return entries
+}
+
+func readTarGz(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to open gzip: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	entries := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read tar: %v", err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", header.Name, err)
+		}
+		entries[header.Name] = string(content)
+	}
+	return entries
+}
@@ -0,0 +1,148 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+
+	"go.uber.org/zap"
+)
+
+const MaxBatchOperations = 1000
+
+const (
+	BatchActionDelete     = "delete"
+	BatchActionVisibility = "visibility"
+	BatchActionMove       = "move"
+	BatchActionTag        = "tag"
+	BatchActionUntag      = "untag"
+)
+
+// toBatchOperation проверяет операцию и переводит ее в операцию репозитория.
+func toBatchOperation(in BatchOperationDto) (repository.BatchOperation, error) {
+	if (in.FileID > 0) == (in.Name != "") {
+		return repository.BatchOperation{}, fmt.Errorf("%w: exactly one of file_id or name is required", ErrInvalidBatchOperation)
+	}
+
+	op := repository.BatchOperation{
+		FileID: in.FileID,
+		Name:   in.Name,
+	}
+	switch in.Action {
+	case BatchActionDelete:
+		op.Action = repository.BatchTrash
+		if in.Permanent {
+			op.Action = repository.BatchPurge
+		}
+	case BatchActionVisibility:
+		if in.IsPublic == nil {
+			return op, fmt.Errorf("%w: is_public is required", ErrInvalidBatchOperation)
+		}
+		op.Action = repository.BatchSetVisibility
+		op.IsPublic = *in.IsPublic
+	case BatchActionMove:
+		op.Action = repository.BatchMove
+		op.FolderID = in.FolderID
+	case BatchActionTag, BatchActionUntag:
+		tags, err := normalizeTags(in.Tags)
+		if err != nil {
+			return op, err
+		}
+		if len(tags) == 0 {
+			return op, fmt.Errorf("%w: at least one tag is required", ErrInvalidBatchOperation)
+		}
+		op.Action = repository.BatchAddTags
+		if in.Action == BatchActionUntag {
+			op.Action = repository.BatchRemoveTags
+		}
+		op.Tags = tags
+	default:
+		return op, fmt.Errorf("%w: unknown action %q", ErrInvalidBatchOperation, in.Action)
+	}
+	return op, nil
+}
+
+// BatchFiles применяет набор операций к файлам пользователя в одной транзакции. Ошибка отдельной
+// операции не отменяет остальные. Содержимое безвозвратно удаленных файлов удаляется из S3 после коммита.
+func (u *fileUsecase) BatchFiles(ctx context.Context, in *BatchFilesDtoIn) (*BatchFilesDtoOut, error) {
+	if len(in.Operations) == 0 {
+		return nil, fmt.Errorf("%w: no operations", ErrInvalidBatch)
+	}
+	if len(in.Operations) > MaxBatchOperations {
+		return nil, fmt.Errorf("%w: at most %d operations are allowed", ErrInvalidBatch, MaxBatchOperations)
+	}
+
+	out := &BatchFilesDtoOut{
+		Results: make([]BatchItemResultDto, len(in.Operations)),
+	}
+
+	folders := make(map[int64]error)
+	ops := make([]repository.BatchOperation, 0, len(in.Operations))
+	indexes := make([]int, 0, len(in.Operations))
+	for i, item := range in.Operations {
+		out.Results[i] = BatchItemResultDto{Index: i, Action: item.Action}
+
+		op, err := toBatchOperation(item)
+		if err == nil && op.FolderID != nil {
+			folderErr, checked := folders[*op.FolderID]
+			if !checked {
+				_, folderErr = u.getFolder(ctx, in.UserID, *op.FolderID)
+				folders[*op.FolderID] = folderErr
+			}
+			err = folderErr
+		}
+		if err != nil {
+			out.Results[i].Err = err
+			continue
+		}
+
+		ops = append(ops, op)
+		indexes = append(indexes, i)
+	}
+
+	if len(ops) > 0 {
+		// Удаление содержимого окончательно удаленных файлов ставится в очередь в транзакции пакета.
+		var results []repository.BatchResult
+		err := u.commitObjectOperations(ctx, func(repo repository.FileRepository) ([]*entity.ObjectOperation, error) {
+			var err error
+			results, err = repo.ApplyBatch(ctx, in.UserID, ops)
+			if err != nil {
+				return nil, err
+			}
+
+			var purgedKeys []string
+			for j, result := range results {
+				if result.Err == nil && ops[j].Action == repository.BatchPurge {
+					purgedKeys = append(purgedKeys, objectKeys(result.File, result.Versions)...)
+				}
+			}
+			return deleteOperations(purgedKeys), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for j, result := range results {
+			item := &out.Results[indexes[j]]
+			if result.Err != nil {
+				item.Err = fileNotFound(result.Err)
+				continue
+			}
+
+			fileItem := toFileListItem(result.File)
+			item.File = &fileItem
+		}
+	}
+
+	for _, item := range out.Results {
+		if item.Err != nil {
+			out.Failed++
+			continue
+		}
+		out.Succeeded++
+	}
+
+	u.log.Info("batch applied", zap.Int64("userID", in.UserID), zap.Int("succeeded", out.Succeeded), zap.Int("failed", out.Failed))
+	return out, nil
+}
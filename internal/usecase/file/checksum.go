@@ -0,0 +1,42 @@
+package file
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"hash/crc32"
+	"strings"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// contentHasher считает SHA-256 и CRC32C за один проход по содержимому файла.
+type contentHasher struct {
+	sha hash.Hash
+	crc hash.Hash32
+}
+
+func newContentHasher() *contentHasher {
+	return &contentHasher{
+		sha: sha256.New(),
+		crc: crc32.New(crc32cTable),
+	}
+}
+
+func (h *contentHasher) Write(p []byte) (int, error) {
+	_, _ = h.sha.Write(p)
+	_, _ = h.crc.Write(p)
+	return len(p), nil
+}
+
+func (h *contentHasher) SHA256() string {
+	return hex.EncodeToString(h.sha.Sum(nil))
+}
+
+func (h *contentHasher) CRC32C() string {
+	return hex.EncodeToString(h.crc.Sum(nil))
+}
+
+func checksumsEqual(expected, actual string) bool {
+	return strings.EqualFold(expected, actual)
+}
@@ -0,0 +1,106 @@
+package file
+
+import (
+	"context"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// objectPath находит на диске файл, в котором хранилище держит объект key.
+func (e *testEnv) objectPath(t *testing.T, key string) string {
+	t.Helper()
+	var found string
+	err := filepath.WalkDir(e.objectsDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && entry.Name() == url.PathEscape(key) {
+			found = path
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if err != nil || found == "" {
+		t.Fatalf("Failed to find object %s on disk: %v", key, err)
+	}
+	return found
+}
+
+func TestScrubChecksums_FlagsCorruptedObjects(t *testing.T) {
+	env := newTestEnv(t, Options{})
+	ctx := context.Background()
+	owner := env.createUser(t, "scrub@test.com")
+
+	healthy := []int64{
+		env.uploadFile(t, owner, "first.txt", []byte("first healthy file"), nil),
+		env.uploadFile(t, owner, "second.txt", []byte("second healthy file"), nil),
+	}
+	flipped := env.uploadFile(t, owner, "flipped.txt", []byte("a bit will rot here"), nil)
+	truncated := env.uploadFile(t, owner, "truncated.txt", []byte("the tail will be lost"), nil)
+
+	corrupt := func(fileID int64, change func([]byte) []byte) {
+		t.Helper()
+		metaFile, err := env.fileRepo.Get(ctx, fileID)
+		if err != nil {
+			t.Fatalf("Failed to get file: %v", err)
+		}
+		path := env.objectPath(t, metaFile.S3Key)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read object: %v", err)
+		}
+		if err := os.WriteFile(path, change(data), 0o600); err != nil {
+			t.Fatalf("Failed to corrupt object: %v", err)
+		}
+	}
+	// Порча без изменения размера: такую не заметит fsck, только проверка контрольной суммы.
+	corrupt(flipped, func(data []byte) []byte {
+		data[3] ^= 0x01
+		return data
+	})
+	corrupt(truncated, func(data []byte) []byte { return data[:len(data)-4] })
+
+	// Содержимое проверяется при загрузке; проверка как будто была давно, и файлам пора на повторную.
+	if _, err := env.db.Exec(`UPDATE files SET checksum_verified_at = ?1`, time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatalf("Failed to backdate verification: %v", err)
+	}
+	out, err := env.ScrubChecksums(ctx, &ScrubChecksumsDtoIn{BatchSize: 10, ReverifyAfter: time.Hour})
+	if err != nil {
+		t.Fatalf("Failed to scrub: %v", err)
+	}
+	if out.Checked != 4 || out.Failed != 2 {
+		t.Fatalf("Expected 4 checked and 2 failed, got %+v", out)
+	}
+
+	for _, fileID := range []int64{flipped, truncated} {
+		metaFile, err := env.fileRepo.Get(ctx, fileID)
+		if err != nil {
+			t.Fatalf("Failed to get file: %v", err)
+		}
+		if !metaFile.ChecksumFailed || metaFile.ChecksumVerifiedAt == nil {
+			t.Errorf("Expected corrupted file %s to be flagged, got failed=%v verified=%v", metaFile.OriginalName, metaFile.ChecksumFailed, metaFile.ChecksumVerifiedAt)
+		}
+	}
+	for _, fileID := range healthy {
+		metaFile, err := env.fileRepo.Get(ctx, fileID)
+		if err != nil {
+			t.Fatalf("Failed to get file: %v", err)
+		}
+		if metaFile.ChecksumFailed || metaFile.ChecksumVerifiedAt == nil {
+			t.Errorf("Expected healthy file %s to be verified and not flagged, got failed=%v verified=%v", metaFile.OriginalName, metaFile.ChecksumFailed, metaFile.ChecksumVerifiedAt)
+		}
+	}
+
+	// Проверенные файлы не перечитываются до истечения ReverifyAfter.
+	out, err = env.ScrubChecksums(ctx, &ScrubChecksumsDtoIn{BatchSize: 10, ReverifyAfter: time.Hour})
+	if err != nil {
+		t.Fatalf("Failed to scrub: %v", err)
+	}
+	if out.Checked != 0 {
+		t.Errorf("Expected nothing to be re-verified, got %+v", out)
+	}
+}
@@ -0,0 +1,104 @@
+package file
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"meemo/internal/infrastructure/storage/s3/file"
+)
+
+func TestCompression_StoredEncodingAndDownload(t *testing.T) {
+	env := newTestEnv(t, Options{Compression: file.CompressionPolicy{
+		MinSize: 64,
+		MimeTypes: map[string]string{
+			"text/*":                   file.EncodingGzip,
+			"application/octet-stream": file.EncodingGzip,
+		},
+	}})
+	ctx := context.Background()
+	owner := env.createUser(t, "compression@test.com")
+
+	noise := make([]byte, 4096)
+	rand.New(rand.NewSource(1)).Read(noise)
+
+	tests := []struct {
+		name     string
+		file     string
+		content  []byte
+		expected string
+	}{
+		{"compressible text", "notes.txt", []byte(strings.Repeat("all work and no play\n", 200)), file.EncodingGzip},
+		// Сжатие случайных байтов только увеличивает объект, поэтому он хранится как есть.
+		{"incompressible", "noise.bin", noise, file.EncodingIdentity},
+		{"below min size", "short.txt", []byte("tiny"), file.EncodingIdentity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileID := env.uploadFile(t, owner, tt.file, tt.content, nil)
+			metaFile, err := env.fileRepo.Get(ctx, fileID)
+			if err != nil {
+				t.Fatalf("Failed to get file: %v", err)
+			}
+			if metaFile.ContentEncoding != tt.expected {
+				t.Fatalf("Expected encoding %q, got %q", tt.expected, metaFile.ContentEncoding)
+			}
+			if metaFile.SizeInBytes != int64(len(tt.content)) {
+				t.Errorf("Expected logical size %d, got %d", len(tt.content), metaFile.SizeInBytes)
+			}
+
+			var stored bytes.Buffer
+			if err := env.s3Client.GetObject(ctx, metaFile.S3Key, &stored); err != nil {
+				t.Fatalf("Failed to read object: %v", err)
+			}
+			if int64(stored.Len()) != metaFile.StoredSizeInBytes {
+				t.Errorf("Expected stored size %d, got %d", metaFile.StoredSizeInBytes, stored.Len())
+			}
+			if tt.expected == file.EncodingIdentity && !bytes.Equal(stored.Bytes(), tt.content) {
+				t.Errorf("Expected object to hold the content as is")
+			}
+			if tt.expected != file.EncodingIdentity && metaFile.StoredSizeInBytes >= metaFile.SizeInBytes {
+				t.Errorf("Expected compressed object, got %d of %d bytes", metaFile.StoredSizeInBytes, metaFile.SizeInBytes)
+			}
+
+			var plain bytes.Buffer
+			if _, err := env.GetFileByID(ctx, &GetFileByIDDtoIn{FileID: fileID, UserID: owner.ID}, &plain); err != nil {
+				t.Fatalf("Failed to download file: %v", err)
+			}
+			if !bytes.Equal(plain.Bytes(), tt.content) {
+				t.Errorf("Expected download to be decompressed")
+			}
+
+			// Клиент, который сам распакует содержимое, получает объект без изменений.
+			var raw bytes.Buffer
+			if _, err := env.GetFileByID(ctx, &GetFileByIDDtoIn{FileID: fileID, UserID: owner.ID, KeepEncoding: true}, &raw); err != nil {
+				t.Fatalf("Failed to download file: %v", err)
+			}
+			if !bytes.Equal(raw.Bytes(), stored.Bytes()) {
+				t.Errorf("Expected stored bytes to be served as is")
+			}
+			if tt.expected == file.EncodingGzip {
+				gz, err := gzip.NewReader(&raw)
+				if err != nil {
+					t.Fatalf("Expected gzip stream: %v", err)
+				}
+				decoded, err := io.ReadAll(gz)
+				if err != nil || !bytes.Equal(decoded, tt.content) {
+					t.Errorf("Expected gzip stream to decode to the content, got %v", err)
+				}
+			}
+
+			var part bytes.Buffer
+			if _, err := env.GetFileByID(ctx, &GetFileByIDDtoIn{FileID: fileID, UserID: owner.ID, Range: &ByteRange{Offset: 1, Length: 3}}, &part); err != nil {
+				t.Fatalf("Failed to download range: %v", err)
+			}
+			if !bytes.Equal(part.Bytes(), tt.content[1:4]) {
+				t.Errorf("Expected range of the original content, got %q", part.Bytes())
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+	"meemo/internal/infrastructure/storage/s3/file"
+
+	"go.uber.org/zap"
+)
+
+// copyName возвращает имя копии по умолчанию: "report.pdf" -> "report (copy).pdf".
+func copyName(name string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + " (copy)" + ext
+}
+
+// CopyFile создает копию текущей версии файла. Содержимое копируется внутри S3 без
+// скачивания, а содержимое с дедупликацией просто получает еще одну ссылку.
+// Копия наследует срок хранения источника; файл с истекшим сроком скопировать нельзя.
+func (u *fileUsecase) CopyFile(ctx context.Context, in *CopyFileDtoIn) (*FileListItemDto, error) {
+	source, err := u.fileRepo.GetByOriginalNameAndUserEmail(ctx, in.UserEmail, in.OriginalName)
+	if err != nil {
+		return nil, fileNotFound(err)
+	}
+	if err := checkNotQuarantined(source); err != nil {
+		return nil, err
+	}
+	if err := checkNotExpired(source); err != nil {
+		return nil, err
+	}
+	if source.Status != entity.Loaded || source.CurrentVersionID == nil {
+		return nil, ErrFileNotReady
+	}
+
+	if in.FolderID != nil {
+		if _, err := u.getFolder(ctx, in.UserID, *in.FolderID); err != nil {
+			return nil, err
+		}
+	}
+	if err := u.checkQuota(ctx, in.UserEmail, source.SizeInBytes); err != nil {
+		return nil, err
+	}
+
+	newName := strings.TrimSpace(in.NewName)
+	if newName == "" {
+		newName = copyName(source.OriginalName)
+	}
+	if err := u.checkFileType(ctx, in.UserID, source.MimeType, newName); err != nil {
+		return nil, err
+	}
+
+	copyEntity := &entity.File{
+		UserID:       in.UserID,
+		OriginalName: newName,
+		MimeType:     source.MimeType,
+		SizeInBytes:  source.SizeInBytes,
+		IsPublic:     source.IsPublic,
+		FolderID:     in.FolderID,
+		Tags:         source.Tags,
+		Metadata:     source.Metadata,
+		// Копия не должна пережить оригинал, иначе через копирование можно обойти срок хранения.
+		ExpiresAt: source.ExpiresAt,
+	}
+	u.fileService.CreateFileMetadata(copyEntity)
+
+	var (
+		copied   *entity.File
+		enqueued []*entity.ObjectOperation
+	)
+	// Строки копии записываются в одной транзакции с копированием содержимого: если процесс
+	// остановится раньше, чем содержимое скопировано, копирование выполнит ProcessObjectOutbox.
+	err = u.fileRepo.InTx(ctx, func(repo repository.FileRepository) error {
+		created, err := repo.Create(ctx, copyEntity)
+		if err != nil {
+			return err
+		}
+
+		version := &entity.FileVersion{
+			FileID:         created.ID,
+			SizeInBytes:    source.SizeInBytes,
+			MimeType:       source.MimeType,
+			ChecksumSHA256: source.ChecksumSHA256,
+			ChecksumCRC32C: source.ChecksumCRC32C,
+			BlobSHA256:     source.BlobSHA256,
+			CreatedBy:      &in.UserID,
+
+			ContentEncoding:   source.ContentEncoding,
+			StoredSizeInBytes: source.StoredSizeInBytes,
+
+			ScanStatus:    source.ScanStatus,
+			ScanSignature: source.ScanSignature,
+			ScannedAt:     source.ScannedAt,
+		}
+		var ops []*entity.ObjectOperation
+		if source.BlobSHA256 == "" {
+			token, err := newObjectToken()
+			if err != nil {
+				return err
+			}
+			version.S3Key = file.VersionKey(created.ID, token)
+			ops = append(ops, &entity.ObjectOperation{
+				Operation: entity.ObjectOperationCopy,
+				SourceKey: source.S3Key,
+				S3Key:     version.S3Key,
+			})
+		}
+
+		if copied, err = repo.AddVersion(ctx, version, nil); err != nil {
+			return err
+		}
+		enqueued, err = u.enqueueObjectOperations(ctx, repo, ops)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.applyObjectOperations(ctx, enqueued); err != nil {
+		u.rollbackCopy(ctx, copied, enqueued)
+		return nil, err
+	}
+
+	u.log.Info("file copied", zap.Int64("sourceID", source.ID), zap.Int64("fileID", copied.ID))
+	item := toFileListItem(copied)
+	return &item, nil
+}
+
+// rollbackCopy удаляет копию, содержимое которой не удалось скопировать, и отменяет отложенное копирование.
+func (u *fileUsecase) rollbackCopy(ctx context.Context, copied *entity.File, pending []*entity.ObjectOperation) {
+	ids := make([]int64, 0, len(pending))
+	for _, op := range pending {
+		ids = append(ids, op.ID)
+	}
+
+	err := u.commitObjectOperations(ctx, func(repo repository.FileRepository) ([]*entity.ObjectOperation, error) {
+		if err := repo.DeleteObjectOperations(ctx, ids); err != nil {
+			return nil, err
+		}
+		deletedFile, err := repo.DeleteByID(ctx, copied.UserID, copied.ID)
+		if err != nil {
+			return nil, err
+		}
+		// Копирование могло выполниться, хотя клиент получил ошибку.
+		return deleteOperations(objectKeys(deletedFile, nil)), nil
+	})
+	if err != nil {
+		u.log.Warn("failed to rollback file copy", zap.Int64("fileID", copied.ID), zap.Error(err))
+	}
+}
+
+// TransferFile передает файл другому пользователю. Квота получателя проверяется
+// в той же транзакции, что и передача.
+func (u *fileUsecase) TransferFile(ctx context.Context, in *TransferFileDtoIn) (*TransferFileDtoOut, error) {
+	email := strings.TrimSpace(in.Email)
+	if strings.EqualFold(email, in.UserEmail) {
+		return nil, ErrTransferToOwner
+	}
+	if _, err := u.getOwnedFile(ctx, in.UserID, in.FileID); err != nil {
+		return nil, err
+	}
+
+	transferred, err := u.fileRepo.TransferOwnership(ctx, in.FileID, in.UserID, email, MaxStorageBytes)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrUserNotFound):
+			return nil, ErrUserNotFound
+		case errors.Is(err, repository.ErrQuotaExceeded):
+			return nil, ErrReceiverQuotaExceeded
+		}
+		return nil, fileNotFound(err)
+	}
+
+	u.log.Info("file ownership transferred", zap.Int64("fileID", transferred.ID), zap.Int64("fromUserID", in.UserID), zap.Int64("toUserID", transferred.UserID))
+	return &TransferFileDtoOut{
+		ID:           transferred.ID,
+		OriginalName: transferred.OriginalName,
+		OwnerEmail:   email,
+		UpdatedAt:    transferred.UpdatedAt,
+	}, nil
+}
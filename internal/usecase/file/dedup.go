@@ -0,0 +1,171 @@
+package file
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+	"meemo/internal/infrastructure/storage/s3/file"
+
+	"go.uber.org/zap"
+)
+
+// storeBlob переносит загруженное содержимое версии в хранилище блобов и возвращает блоб для привязки к ней.
+// Если блоб с таким хешем уже есть, загруженная копия просто удаляется.
+func (u *fileUsecase) storeBlob(ctx context.Context, uploadKey string, version *entity.FileVersion) (*entity.Blob, error) {
+	defer u.deleteObjectQuietly(ctx, uploadKey)
+
+	sha256Sum := version.ChecksumSHA256
+	blobKey := file.BlobKey(sha256Sum)
+	if version.ContentEncoding != file.EncodingIdentity {
+		// Сжатый блоб хранится под своим ключом, чтобы параллельная загрузка того же содержимого
+		// с другим кодеком не перезаписала объект блоба, уже записанного в базу.
+		blobKey += "." + version.ContentEncoding
+	}
+	if _, err := u.fileRepo.GetBlob(ctx, sha256Sum); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		if err := u.s3Client.CopyObject(ctx, uploadKey, blobKey); err != nil {
+			return nil, err
+		}
+	}
+
+	u.log.Debug("uploaded content stored as blob", zap.String("sha256", sha256Sum))
+	return &entity.Blob{
+		SHA256:            sha256Sum,
+		S3Key:             blobKey,
+		SizeInBytes:       version.SizeInBytes,
+		CRC32C:            version.ChecksumCRC32C,
+		ContentEncoding:   version.ContentEncoding,
+		StoredSizeInBytes: version.StoredSizeInBytes,
+	}, nil
+}
+
+func (u *fileUsecase) InstantUpload(ctx context.Context, in *InstantUploadDtoIn) (*InstantUploadDtoOut, error) {
+	if !u.opts.Deduplication {
+		return nil, ErrDeduplicationDisabled
+	}
+
+	blob, err := u.fileRepo.GetBlob(ctx, in.SHA256)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBlobNotFound
+		}
+		return nil, err
+	}
+	if blob.SizeInBytes != in.SizeInBytes {
+		return nil, ErrBlobNotFound
+	}
+
+	if err := u.checkQuota(ctx, in.UserEmail, blob.SizeInBytes); err != nil {
+		return nil, err
+	}
+
+	mimeType := declaredMimeType(in.MimeType, in.OriginalName)
+	if err := u.checkFileType(ctx, in.UserID, mimeType, in.OriginalName); err != nil {
+		return nil, err
+	}
+
+	fileEntity := &entity.File{
+		UserID:       in.UserID,
+		OriginalName: in.OriginalName,
+		IsPublic:     in.IsPublic,
+		SizeInBytes:  blob.SizeInBytes,
+		MimeType:     mimeType,
+	}
+
+	version := &entity.FileVersion{
+		SizeInBytes:    blob.SizeInBytes,
+		MimeType:       mimeType,
+		ChecksumSHA256: blob.SHA256,
+		ChecksumCRC32C: blob.CRC32C,
+		BlobSHA256:     blob.SHA256,
+		CreatedBy:      &in.UserID,
+	}
+	// Содержимое блоба проверяется заново: сигнатуры могли обновиться с момента его загрузки.
+	if u.opts.Antivirus.Scanner != nil {
+		result, scanErr := u.scanObject(ctx, blob.S3Key, blob.ContentEncoding)
+		if err := u.applyScanResult(version, result, scanErr); err != nil {
+			return nil, err
+		}
+	}
+
+	savedFile, created, err := u.findOrCreateFile(ctx, fileEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	version.FileID = savedFile.ID
+	loadedFile, err := u.fileRepo.AddVersion(ctx, version, nil)
+	if err != nil {
+		if created {
+			u.rollbackInstantUpload(ctx, savedFile)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBlobNotFound
+		}
+		return nil, err
+	}
+
+	u.log.Info("file uploaded instantly", zap.Int64("fileID", loadedFile.ID), zap.String("sha256", blob.SHA256))
+	if loadedFile.Status == entity.Quarantined {
+		u.notifyQuarantined(ctx, loadedFile)
+	}
+	return &InstantUploadDtoOut{
+		ID:             loadedFile.ID,
+		OriginalName:   loadedFile.OriginalName,
+		MimeType:       loadedFile.MimeType,
+		SizeInBytes:    loadedFile.SizeInBytes,
+		Status:         loadedFile.Status,
+		CreatedAt:      loadedFile.CreatedAt,
+		IsPublic:       loadedFile.IsPublic,
+		ChecksumSHA256: loadedFile.ChecksumSHA256,
+		ScanStatus:     loadedFile.ScanStatus,
+		ScanSignature:  loadedFile.ScanSignature,
+	}, nil
+}
+
+func (u *fileUsecase) rollbackInstantUpload(ctx context.Context, savedFile *entity.File) {
+	if _, err := u.fileRepo.DeleteByID(ctx, savedFile.UserID, savedFile.ID); err != nil {
+		u.log.Warn("failed to rollback instant upload", zap.Int64("fileID", savedFile.ID), zap.Error(err))
+	}
+}
+
+func (u *fileUsecase) CollectBlobs(ctx context.Context, in *CollectBlobsDtoIn) (*CollectBlobsDtoOut, error) {
+	blobs, err := u.fileRepo.ListUnreferencedBlobs(ctx, time.Now().Add(-in.GracePeriod), in.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &CollectBlobsDtoOut{}
+	for _, blob := range blobs {
+		if err := ctx.Err(); err != nil {
+			return out, err
+		}
+
+		deleted := false
+		err := u.commitObjectOperations(ctx, func(repo repository.FileRepository) ([]*entity.ObjectOperation, error) {
+			var err error
+			deleted, err = repo.DeleteUnreferencedBlob(ctx, blob.SHA256)
+			if err != nil || !deleted {
+				return nil, err
+			}
+			return deleteOperations([]string{blob.S3Key}), nil
+		})
+		if err != nil {
+			return out, err
+		}
+		if deleted {
+			out.Deleted++
+		}
+	}
+
+	if out.Deleted > 0 {
+		u.log.Info("unreferenced blobs collected", zap.Int("deleted", out.Deleted))
+	}
+	return out, nil
+}
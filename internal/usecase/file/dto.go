@@ -3,62 +3,101 @@ package file
 import (
 	"io"
 	"time"
+
+	"meemo/internal/domain/entity"
 )
 
 type SaveFileMetadataDtoIn struct {
-	UserID       int64  `json:"user_id"`
-	UserEmail    string `json:"user_email"`
-	OriginalName string `json:"original_name"`
-	MimeType     string `json:"mime_type"`
-	SizeInBytes  int64  `json:"size_in_bytes"`
-	IsPublic     bool   `json:"is_public"`
+	UserID       int64             `json:"user_id"`
+	UserEmail    string            `json:"user_email"`
+	OriginalName string            `json:"original_name"`
+	MimeType     string            `json:"mime_type"`
+	SizeInBytes  int64             `json:"size_in_bytes"`
+	IsPublic     bool              `json:"is_public"`
+	FolderID     *int64            `json:"folder_id"`
+	Tags         []string          `json:"tags"`
+	Metadata     map[string]string `json:"metadata"`
+	// ExpiresAt — срок хранения: после него файл удаляется. nil — бессрочно.
+	ExpiresAt *time.Time `json:"expires_at"`
 }
 
 type SaveFileMetadataDtoOut struct {
-	ID           int64     `json:"id"`
-	OriginalName string    `json:"original_name"`
-	MimeType     string    `json:"mime_type"`
-	SizeInBytes  int64     `json:"size_in_bytes"`
-	Status       int       `json:"status"`
-	CreatedAt    time.Time `json:"created_at"`
-	IsPublic     bool      `json:"is_public"`
+	ID           int64             `json:"id"`
+	OriginalName string            `json:"original_name"`
+	MimeType     string            `json:"mime_type"`
+	SizeInBytes  int64             `json:"size_in_bytes"`
+	Status       int               `json:"status"`
+	CreatedAt    time.Time         `json:"created_at"`
+	IsPublic     bool              `json:"is_public"`
+	FolderID     *int64            `json:"folder_id"`
+	Tags         []string          `json:"tags"`
+	Metadata     map[string]string `json:"metadata"`
+	ExpiresAt    *time.Time        `json:"expires_at"`
+	NewVersion   bool              `json:"new_version"`
 }
 
 type SaveFileContentDtoIn struct {
-	Email       string `json:"email"`
-	ID          int64  `json:"id"`
-	SizeInBytes int64  `json:"size_in_bytes"`
-	R           io.Reader
+	Email          string `json:"email"`
+	UserID         int64  `json:"user_id"`
+	ID             int64  `json:"id"`
+	SizeInBytes    int64  `json:"size_in_bytes"`
+	ExpectedSHA256 string `json:"expected_sha256"`
+	R              io.Reader
 }
 
 type SaveFileContentDtoOut struct {
-	LoadingResult bool `json:"loading_result"`
+	LoadingResult  bool   `json:"loading_result"`
+	ChecksumSHA256 string `json:"checksum_sha256"`
+	ChecksumCRC32C string `json:"checksum_crc32c"`
+	// Status равен 4 (Quarantined), если антивирус нашел в содержимом сигнатуру ScanSignature.
+	Status        int    `json:"status"`
+	ScanStatus    string `json:"scan_status"`
+	ScanSignature string `json:"scan_signature,omitempty"`
+}
+
+// ByteRange — диапазон содержимого файла для частичного скачивания.
+type ByteRange struct {
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
 }
 
 type GetFileDtoIn struct {
-	UserID       int64  `json:"user_id"`
-	UserEmail    string `json:"user_email"`
-	OriginalName string `json:"original_name"`
+	UserID       int64      `json:"user_id"`
+	UserEmail    string     `json:"user_email"`
+	OriginalName string     `json:"original_name"`
+	Range        *ByteRange `json:"range,omitempty"`
+	// KeepEncoding отдает сжатое содержимое без распаковки; клиент распакует его сам.
+	KeepEncoding bool `json:"keep_encoding"`
 }
 
 type GetFileDtoOut struct {
-	ID           int64  `json:"id"`
-	OriginalName string `json:"original_name"`
-	MimeType     string `json:"mime_type"`
-	SizeInBytes  int64  `json:"size_in_bytes"`
+	ID                int64  `json:"id"`
+	OriginalName      string `json:"original_name"`
+	MimeType          string `json:"mime_type"`
+	SizeInBytes       int64  `json:"size_in_bytes"`
+	ChecksumSHA256    string `json:"checksum_sha256"`
+	ChecksumCRC32C    string `json:"checksum_crc32c"`
+	ContentEncoding   string `json:"content_encoding"`
+	StoredSizeInBytes int64  `json:"stored_size_in_bytes"`
 }
 
 type GetFileByIDDtoIn struct {
-	FileID    int64  `json:"file_id"`
-	UserID    int64  `json:"user_id"`
-	UserEmail string `json:"user_email"`
+	FileID       int64      `json:"file_id"`
+	UserID       int64      `json:"user_id"`
+	UserEmail    string     `json:"user_email"`
+	Range        *ByteRange `json:"range,omitempty"`
+	KeepEncoding bool       `json:"keep_encoding"`
 }
 
 type GetFileByIDDtoOut struct {
-	ID           int64  `json:"id"`
-	OriginalName string `json:"original_name"`
-	MimeType     string `json:"mime_type"`
-	SizeInBytes  int64  `json:"size_in_bytes"`
+	ID                int64  `json:"id"`
+	OriginalName      string `json:"original_name"`
+	MimeType          string `json:"mime_type"`
+	SizeInBytes       int64  `json:"size_in_bytes"`
+	ChecksumSHA256    string `json:"checksum_sha256"`
+	ChecksumCRC32C    string `json:"checksum_crc32c"`
+	ContentEncoding   string `json:"content_encoding"`
+	StoredSizeInBytes int64  `json:"stored_size_in_bytes"`
 }
 
 type GetFileInfoDtoIn struct {
@@ -68,15 +107,27 @@ type GetFileInfoDtoIn struct {
 }
 
 type GetFileInfoDtoOut struct {
-	ID           int64     `json:"id"`
-	UserID       int64     `json:"user_id"`
-	OriginalName string    `json:"original_name"`
-	MimeType     string    `json:"mime_type"`
-	SizeInBytes  int64     `json:"size_in_bytes"`
-	Status       int       `json:"status"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	IsPublic     bool      `json:"is_public"`
+	ID             int64             `json:"id"`
+	UserID         int64             `json:"user_id"`
+	OriginalName   string            `json:"original_name"`
+	MimeType       string            `json:"mime_type"`
+	SizeInBytes    int64             `json:"size_in_bytes"`
+	Status         int               `json:"status"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	IsPublic       bool              `json:"is_public"`
+	ChecksumSHA256 string            `json:"checksum_sha256"`
+	ChecksumCRC32C string            `json:"checksum_crc32c"`
+	Tags           []string          `json:"tags"`
+	Metadata       map[string]string `json:"metadata"`
+	// ContentEncoding и StoredSizeInBytes описывают объект в S3; квота считается по SizeInBytes.
+	ContentEncoding   string `json:"content_encoding"`
+	StoredSizeInBytes int64  `json:"stored_size_in_bytes"`
+	// ScanStatus — результат антивирусной проверки: clean, infected, error или пусто, если проверки не было.
+	ScanStatus    string     `json:"scan_status"`
+	ScanSignature string     `json:"scan_signature,omitempty"`
+	ScannedAt     *time.Time `json:"scanned_at"`
+	ExpiresAt     *time.Time `json:"expires_at"`
 }
 
 type RenameFileDtoIn struct {
@@ -100,27 +151,48 @@ type DeleteFileDtoIn struct {
 }
 
 type DeleteFileDtoOut struct {
-	ID int64 `json:"id"`
+	ID        int64      `json:"id"`
+	DeletedAt *time.Time `json:"deleted_at"`
 }
 
 type GetAllUserFilesDtoIn struct {
-	UserID    int64  `json:"user_id"`
-	UserEmail string `json:"user_email"`
+	UserID        int64      `json:"user_id"`
+	UserEmail     string     `json:"user_email"`
+	Cursor        string     `json:"cursor"`
+	Limit         int        `json:"limit"`
+	SortBy        string     `json:"sort_by"`
+	Order         string     `json:"order"`
+	MimePrefix    string     `json:"mime_prefix"`
+	Status        *int       `json:"status"`
+	IsPublic      *bool      `json:"is_public"`
+	MinSize       *int64     `json:"min_size"`
+	MaxSize       *int64     `json:"max_size"`
+	CreatedAfter  *time.Time `json:"created_after"`
+	CreatedBefore *time.Time `json:"created_before"`
+	Tags          []string   `json:"tags"`
+	MetadataKey   string     `json:"metadata_key"`
+	MetadataValue *string    `json:"metadata_value"`
 }
 
 type GetAllUserFilesDtoOut struct {
-	Files []FileListItemDto `json:"files"`
+	Files      []FileListItemDto `json:"files"`
+	NextCursor string            `json:"next_cursor,omitempty"`
 }
 
 type FileListItemDto struct {
-	ID           int64     `json:"id"`
-	OriginalName string    `json:"original_name"`
-	MimeType     string    `json:"mime_type"`
-	SizeInBytes  int64     `json:"size_in_bytes"`
-	Status       int       `json:"status"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	IsPublic     bool      `json:"is_public"`
+	ID           int64             `json:"id"`
+	OriginalName string            `json:"original_name"`
+	MimeType     string            `json:"mime_type"`
+	SizeInBytes  int64             `json:"size_in_bytes"`
+	Status       int               `json:"status"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	IsPublic     bool              `json:"is_public"`
+	FolderID     *int64            `json:"folder_id"`
+	Tags         []string          `json:"tags"`
+	Metadata     map[string]string `json:"metadata"`
+	DeletedAt    *time.Time        `json:"deleted_at,omitempty"`
+	ExpiresAt    *time.Time        `json:"expires_at"`
 }
 
 type ChangeVisibilityDtoIn struct {
@@ -161,3 +233,582 @@ type GetStorageInfoDtoOut struct {
 	AvailableBytes int64 `json:"available_bytes"`
 	TotalBytes     int64 `json:"total_bytes"`
 }
+
+type ScrubChecksumsDtoIn struct {
+	BatchSize     int           `json:"batch_size"`
+	ReverifyAfter time.Duration `json:"reverify_after"`
+}
+
+type ScrubChecksumsDtoOut struct {
+	Checked int `json:"checked"`
+	Failed  int `json:"failed"`
+}
+
+type InstantUploadDtoIn struct {
+	UserID       int64  `json:"user_id"`
+	UserEmail    string `json:"user_email"`
+	OriginalName string `json:"original_name"`
+	MimeType     string `json:"mime_type"`
+	SizeInBytes  int64  `json:"size_in_bytes"`
+	IsPublic     bool   `json:"is_public"`
+	SHA256       string `json:"sha256"`
+}
+
+type InstantUploadDtoOut struct {
+	ID             int64     `json:"id"`
+	OriginalName   string    `json:"original_name"`
+	MimeType       string    `json:"mime_type"`
+	SizeInBytes    int64     `json:"size_in_bytes"`
+	Status         int       `json:"status"`
+	CreatedAt      time.Time `json:"created_at"`
+	IsPublic       bool      `json:"is_public"`
+	ChecksumSHA256 string    `json:"checksum_sha256"`
+	ScanStatus     string    `json:"scan_status"`
+	ScanSignature  string    `json:"scan_signature,omitempty"`
+}
+
+type CollectBlobsDtoIn struct {
+	BatchSize   int           `json:"batch_size"`
+	GracePeriod time.Duration `json:"grace_period"`
+}
+
+type CollectBlobsDtoOut struct {
+	Deleted int `json:"deleted"`
+}
+
+type FolderDto struct {
+	ID        int64     `json:"id"`
+	ParentID  *int64    `json:"parent_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateFolderDtoIn struct {
+	UserID   int64  `json:"user_id"`
+	ParentID *int64 `json:"parent_id"`
+	Name     string `json:"name"`
+}
+
+type ListFolderChildrenDtoIn struct {
+	UserID   int64  `json:"user_id"`
+	FolderID *int64 `json:"folder_id"`
+}
+
+type ListFolderChildrenDtoOut struct {
+	Folder  *FolderDto        `json:"folder"`
+	Folders []FolderDto       `json:"folders"`
+	Files   []FileListItemDto `json:"files"`
+}
+
+type RenameFolderDtoIn struct {
+	UserID   int64  `json:"user_id"`
+	FolderID int64  `json:"folder_id"`
+	Name     string `json:"name"`
+}
+
+type MoveFolderDtoIn struct {
+	UserID   int64  `json:"user_id"`
+	FolderID int64  `json:"folder_id"`
+	ParentID *int64 `json:"parent_id"`
+}
+
+type DeleteFolderDtoIn struct {
+	UserID   int64 `json:"user_id"`
+	FolderID int64 `json:"folder_id"`
+}
+
+type DeleteFolderDtoOut struct {
+	ID           int64 `json:"id"`
+	DeletedFiles int   `json:"deleted_files"`
+}
+
+type GetFolderSizeDtoIn struct {
+	UserID   int64 `json:"user_id"`
+	FolderID int64 `json:"folder_id"`
+}
+
+type GetFolderSizeDtoOut struct {
+	ID          int64 `json:"id"`
+	SizeInBytes int64 `json:"size_in_bytes"`
+	FileCount   int64 `json:"file_count"`
+	FolderCount int64 `json:"folder_count"`
+}
+
+type MoveFileDtoIn struct {
+	UserID   int64  `json:"user_id"`
+	FileID   int64  `json:"file_id"`
+	FolderID *int64 `json:"folder_id"`
+}
+
+type MoveFileDtoOut struct {
+	ID           int64     `json:"id"`
+	OriginalName string    `json:"original_name"`
+	FolderID     *int64    `json:"folder_id"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type FileVersionDto struct {
+	VersionNumber  int       `json:"version_number"`
+	SizeInBytes    int64     `json:"size_in_bytes"`
+	MimeType       string    `json:"mime_type"`
+	ChecksumSHA256 string    `json:"checksum_sha256"`
+	ChecksumCRC32C string    `json:"checksum_crc32c"`
+	CreatedBy      *int64    `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+	IsCurrent      bool      `json:"is_current"`
+}
+
+type ListFileVersionsDtoIn struct {
+	UserID int64 `json:"user_id"`
+	FileID int64 `json:"file_id"`
+}
+
+type ListFileVersionsDtoOut struct {
+	FileID   int64            `json:"file_id"`
+	Versions []FileVersionDto `json:"versions"`
+}
+
+type GetFileVersionDtoIn struct {
+	UserID        int64 `json:"user_id"`
+	FileID        int64 `json:"file_id"`
+	VersionNumber int   `json:"version_number"`
+}
+
+type GetFileVersionDtoOut struct {
+	FileID         int64  `json:"file_id"`
+	OriginalName   string `json:"original_name"`
+	VersionNumber  int    `json:"version_number"`
+	MimeType       string `json:"mime_type"`
+	SizeInBytes    int64  `json:"size_in_bytes"`
+	ChecksumSHA256 string `json:"checksum_sha256"`
+	ChecksumCRC32C string `json:"checksum_crc32c"`
+}
+
+type RestoreFileVersionDtoIn struct {
+	UserID        int64 `json:"user_id"`
+	FileID        int64 `json:"file_id"`
+	VersionNumber int   `json:"version_number"`
+}
+
+type RestoreFileVersionDtoOut struct {
+	FileID        int64     `json:"file_id"`
+	VersionNumber int       `json:"version_number"`
+	SizeInBytes   int64     `json:"size_in_bytes"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type PruneFileVersionsDtoIn struct {
+	UserID    int64         `json:"user_id"`
+	FileID    int64         `json:"file_id"`
+	Keep      int           `json:"keep"`
+	OlderThan time.Duration `json:"older_than"`
+}
+
+type PruneFileVersionsDtoOut struct {
+	FileID          int64 `json:"file_id"`
+	DeletedVersions []int `json:"deleted_versions"`
+	FreedBytes      int64 `json:"freed_bytes"`
+}
+
+type ListTrashDtoIn struct {
+	UserID int64 `json:"user_id"`
+}
+
+type ListTrashDtoOut struct {
+	Files []FileListItemDto `json:"files"`
+}
+
+type RestoreFromTrashDtoIn struct {
+	UserID int64 `json:"user_id"`
+	FileID int64 `json:"file_id"`
+}
+
+type RestoreFromTrashDtoOut struct {
+	ID           int64     `json:"id"`
+	OriginalName string    `json:"original_name"`
+	FolderID     *int64    `json:"folder_id"`
+	Status       int       `json:"status"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type EmptyTrashDtoIn struct {
+	UserID int64 `json:"user_id"`
+}
+
+type EmptyTrashDtoOut struct {
+	Deleted    int   `json:"deleted"`
+	FreedBytes int64 `json:"freed_bytes"`
+}
+
+type PurgeTrashDtoIn struct {
+	BatchSize int           `json:"batch_size"`
+	Retention time.Duration `json:"retention"`
+}
+
+type PurgeTrashDtoOut struct {
+	Purged int `json:"purged"`
+}
+
+type SetFileExpiryDtoIn struct {
+	UserID    int64      `json:"user_id"`
+	FileID    int64      `json:"file_id"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type FileExpiryDtoOut struct {
+	ID        int64      `json:"id"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type ExpireFilesDtoIn struct {
+	BatchSize int `json:"batch_size"`
+}
+
+type ExpireFilesDtoOut struct {
+	Deleted    int   `json:"deleted"`
+	FreedBytes int64 `json:"freed_bytes"`
+}
+
+type CheckStorageDtoIn struct {
+	// Repair исправляет найденные расхождения; без него проверка ничего не меняет.
+	Repair           bool          `json:"repair"`
+	GracePeriod      time.Duration `json:"grace_period"`
+	PendingOlderThan time.Duration `json:"pending_older_than"`
+	MaxIssues        int           `json:"max_issues"`
+}
+
+// StorageIssueDto — найденное расхождение. Action заполняется, если расхождение исправлено,
+// Error — если исправить не удалось.
+type StorageIssueDto struct {
+	Type         string `json:"type"`
+	S3Key        string `json:"s3_key,omitempty"`
+	Object       string `json:"object,omitempty"`
+	FileID       *int64 `json:"file_id,omitempty"`
+	ExpectedSize *int64 `json:"expected_size,omitempty"`
+	ActualSize   *int64 `json:"actual_size,omitempty"`
+	Action       string `json:"action,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+type CheckStorageDtoOut struct {
+	Repair            bool `json:"repair"`
+	ScannedObjects    int  `json:"scanned_objects"`
+	ReferencedObjects int  `json:"referenced_objects"`
+	// SkippedRecent — объекты без строк в базе, которые моложе grace period и пока не считаются лишними.
+	SkippedRecent   int               `json:"skipped_recent"`
+	OrphanedObjects int               `json:"orphaned_objects"`
+	MissingObjects  int               `json:"missing_objects"`
+	SizeMismatches  int               `json:"size_mismatches"`
+	StalePending    int               `json:"stale_pending"`
+	Repaired        int               `json:"repaired"`
+	Issues          []StorageIssueDto `json:"issues"`
+	IssuesTruncated bool              `json:"issues_truncated"`
+}
+
+// Unresolved возвращает число расхождений, которые остались неисправленными.
+func (o *CheckStorageDtoOut) Unresolved() int {
+	return o.OrphanedObjects + o.MissingObjects + o.SizeMismatches + o.StalePending - o.Repaired
+}
+
+type SearchFilesDtoIn struct {
+	UserID        int64  `json:"user_id"`
+	Query         string `json:"query"`
+	IncludePublic bool   `json:"include_public"`
+	Limit         int    `json:"limit"`
+}
+
+type SearchResultDto struct {
+	FileListItemDto
+	OwnerID int64   `json:"owner_id"`
+	Rank    float64 `json:"rank"`
+}
+
+type SearchFilesDtoOut struct {
+	Results []SearchResultDto `json:"results"`
+}
+
+type FileTagsDtoIn struct {
+	UserID int64    `json:"user_id"`
+	FileID int64    `json:"file_id"`
+	Tags   []string `json:"tags"`
+}
+
+type FileTagsDtoOut struct {
+	ID        int64     `json:"id"`
+	Tags      []string  `json:"tags"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ListTagsDtoIn struct {
+	UserID int64 `json:"user_id"`
+}
+
+type TagCountDto struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+type ListTagsDtoOut struct {
+	Tags []TagCountDto `json:"tags"`
+}
+
+type UpdateFileMetadataDtoIn struct {
+	UserID  int64             `json:"user_id"`
+	FileID  int64             `json:"file_id"`
+	Set     map[string]string `json:"set"`
+	Unset   []string          `json:"unset"`
+	Replace bool              `json:"replace"`
+}
+
+type FileMetadataDtoOut struct {
+	ID        int64             `json:"id"`
+	Metadata  map[string]string `json:"metadata"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+type ShareFileDtoIn struct {
+	UserID     int64  `json:"user_id"`
+	UserEmail  string `json:"user_email"`
+	FileID     int64  `json:"file_id"`
+	Email      string `json:"email"`
+	Permission string `json:"permission"`
+}
+
+type FileShareDto struct {
+	FileID     int64     `json:"file_id"`
+	UserID     int64     `json:"user_id"`
+	Email      string    `json:"email"`
+	Permission string    `json:"permission"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type ListFileSharesDtoIn struct {
+	UserID int64 `json:"user_id"`
+	FileID int64 `json:"file_id"`
+}
+
+type ListFileSharesDtoOut struct {
+	FileID int64          `json:"file_id"`
+	Shares []FileShareDto `json:"shares"`
+}
+
+type RevokeFileShareDtoIn struct {
+	UserID       int64 `json:"user_id"`
+	FileID       int64 `json:"file_id"`
+	TargetUserID int64 `json:"target_user_id"`
+}
+
+type RevokeFileShareDtoOut struct {
+	FileID int64 `json:"file_id"`
+	UserID int64 `json:"user_id"`
+}
+
+type ListSharedWithMeDtoIn struct {
+	UserID int64 `json:"user_id"`
+}
+
+type SharedFileDto struct {
+	FileListItemDto
+	OwnerID    int64  `json:"owner_id"`
+	OwnerEmail string `json:"owner_email"`
+	Permission string `json:"permission"`
+}
+
+type ListSharedWithMeDtoOut struct {
+	Files []SharedFileDto `json:"files"`
+}
+
+type RenameFileByIDDtoIn struct {
+	UserID  int64  `json:"user_id"`
+	FileID  int64  `json:"file_id"`
+	NewName string `json:"new_name"`
+}
+
+type DeleteFileByIDDtoIn struct {
+	UserID int64 `json:"user_id"`
+	FileID int64 `json:"file_id"`
+}
+
+type CreateShareLinkDtoIn struct {
+	UserID       int64      `json:"user_id"`
+	FileID       int64      `json:"file_id"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	Password     string     `json:"-"`
+	MaxDownloads *int       `json:"max_downloads"`
+}
+
+type ShareLinkDto struct {
+	ID            int64      `json:"id"`
+	FileID        int64      `json:"file_id"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	HasPassword   bool       `json:"has_password"`
+	MaxDownloads  *int       `json:"max_downloads"`
+	DownloadCount int        `json:"download_count"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	Active        bool       `json:"active"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// CreateShareLinkDtoOut содержит токен ссылки. Он возвращается только при создании: в базе хранится лишь его хеш.
+type CreateShareLinkDtoOut struct {
+	ShareLinkDto
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+type ListShareLinksDtoIn struct {
+	UserID int64  `json:"user_id"`
+	FileID *int64 `json:"file_id"`
+}
+
+type ListShareLinksDtoOut struct {
+	Links []ShareLinkDto `json:"links"`
+}
+
+type RevokeShareLinkDtoIn struct {
+	UserID int64 `json:"user_id"`
+	LinkID int64 `json:"link_id"`
+}
+
+type OpenShareLinkDtoIn struct {
+	Token    string `json:"-"`
+	Password string `json:"-"`
+}
+
+type OpenShareLinkDtoOut struct {
+	OriginalName   string `json:"original_name"`
+	MimeType       string `json:"mime_type"`
+	SizeInBytes    int64  `json:"size_in_bytes"`
+	ChecksumSHA256 string `json:"checksum_sha256"`
+	ChecksumCRC32C string `json:"checksum_crc32c"`
+	linkID         int64
+	file           *entity.File
+}
+
+type BatchOperationDto struct {
+	Action    string   `json:"action"`
+	FileID    int64    `json:"file_id"`
+	Name      string   `json:"name"`
+	IsPublic  *bool    `json:"is_public"`
+	FolderID  *int64   `json:"folder_id"`
+	Tags      []string `json:"tags"`
+	Permanent bool     `json:"permanent"`
+}
+
+type BatchFilesDtoIn struct {
+	UserID     int64               `json:"user_id"`
+	Operations []BatchOperationDto `json:"operations"`
+}
+
+// BatchItemResultDto описывает итог одной операции; Err заполнен, если операция не применилась.
+type BatchItemResultDto struct {
+	Index  int              `json:"index"`
+	Action string           `json:"action"`
+	File   *FileListItemDto `json:"file,omitempty"`
+	Err    error            `json:"-"`
+}
+
+type BatchFilesDtoOut struct {
+	Results   []BatchItemResultDto `json:"results"`
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+}
+
+type PrepareArchiveDtoIn struct {
+	UserID   int64   `json:"user_id"`
+	FileIDs  []int64 `json:"file_ids"`
+	FolderID *int64  `json:"folder_id"`
+	Format   string  `json:"format"`
+}
+
+// ArchiveDtoOut описывает подготовленный архив. Записи проверены на доступ и передаются
+// в WriteArchive как есть, поэтому наружу не сериализуются.
+type ArchiveDtoOut struct {
+	FileName    string `json:"file_name"`
+	Format      string `json:"format"`
+	ContentType string `json:"content_type"`
+	Entries     int    `json:"entries"`
+	entries     []archiveEntry
+}
+
+type ExtractArchiveDtoIn struct {
+	Email          string `json:"email"`
+	UserID         int64  `json:"user_id"`
+	ID             int64  `json:"id"`
+	SizeInBytes    int64  `json:"size_in_bytes"`
+	ExpectedSHA256 string `json:"expected_sha256"`
+}
+
+type ExtractArchiveDtoOut struct {
+	Format         string            `json:"format"`
+	Files          []FileListItemDto `json:"files"`
+	ExtractedBytes int64             `json:"extracted_bytes"`
+}
+
+type CopyFileDtoIn struct {
+	UserID       int64  `json:"user_id"`
+	UserEmail    string `json:"user_email"`
+	OriginalName string `json:"original_name"`
+	NewName      string `json:"new_name"`
+	FolderID     *int64 `json:"folder_id"`
+}
+
+type TransferFileDtoIn struct {
+	UserID    int64  `json:"user_id"`
+	UserEmail string `json:"user_email"`
+	FileID    int64  `json:"file_id"`
+	Email     string `json:"email"`
+}
+
+type TransferFileDtoOut struct {
+	ID           int64     `json:"id"`
+	OriginalName string    `json:"original_name"`
+	OwnerEmail   string    `json:"owner_email"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type GetThumbnailDtoIn struct {
+	UserID       int64  `json:"user_id"`
+	UserEmail    string `json:"user_email"`
+	OriginalName string `json:"original_name"`
+	// Size — сторона квадрата, в который вписана миниатюра. Ноль выбирает первый настроенный размер.
+	Size int `json:"size"`
+	// Format — jpeg или webp. Пустая строка выбирает первый настроенный формат.
+	Format string `json:"format"`
+}
+
+type GetThumbnailDtoOut struct {
+	FileID      int64  `json:"file_id"`
+	Size        int    `json:"size"`
+	Format      string `json:"format"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	// Pending означает, что миниатюра еще не создана и Content содержит заглушку.
+	Pending bool   `json:"pending"`
+	ETag    string `json:"etag"`
+	Content []byte `json:"-"`
+}
+
+type GenerateThumbnailsDtoIn struct {
+	BatchSize int `json:"batch_size"`
+}
+
+type GenerateThumbnailsDtoOut struct {
+	Generated int `json:"generated"`
+	Failed    int `json:"failed"`
+	Collected int `json:"collected"`
+}
+
+type ProcessObjectOutboxDtoIn struct {
+	BatchSize int `json:"batch_size"`
+}
+
+type ProcessObjectOutboxDtoOut struct {
+	Applied      int `json:"applied"`
+	Retried      int `json:"retried"`
+	DeadLettered int `json:"dead_lettered"`
+}
@@ -3,5 +3,54 @@ package file
 import "errors"
 
 var (
-	ErrInsufficientStorage = errors.New("insufficient storage space")
+	ErrInsufficientStorage       = errors.New("insufficient storage space")
+	ErrChecksumMismatch          = errors.New("checksum mismatch")
+	ErrDeduplicationDisabled     = errors.New("deduplication is disabled")
+	ErrBlobNotFound              = errors.New("blob not found")
+	ErrFolderNotFound            = errors.New("folder not found")
+	ErrInvalidFolderName         = errors.New("invalid folder name")
+	ErrInvalidFolderMove         = errors.New("folder cannot be moved into itself or its descendants")
+	ErrFileNotFound              = errors.New("file not found")
+	ErrVersionNotFound           = errors.New("file version not found")
+	ErrInvalidPruneCriteria      = errors.New("keep or older_than must be set")
+	ErrInvalidListQuery          = errors.New("invalid list query")
+	ErrInvalidCursor             = errors.New("invalid cursor")
+	ErrInvalidSearchQuery        = errors.New("search query is empty")
+	ErrInvalidTags               = errors.New("invalid tags")
+	ErrInvalidMetadata           = errors.New("invalid metadata")
+	ErrAccessDenied              = errors.New("access denied")
+	ErrUserNotFound              = errors.New("user not found")
+	ErrShareNotFound             = errors.New("share not found")
+	ErrInvalidPermission         = errors.New("permission must be viewer or editor")
+	ErrShareWithOwner            = errors.New("file cannot be shared with its owner")
+	ErrInvalidShareLink          = errors.New("invalid share link")
+	ErrShareLinkNotFound         = errors.New("share link not found")
+	ErrShareLinkRevoked          = errors.New("share link has been revoked")
+	ErrShareLinkExpired          = errors.New("share link has expired")
+	ErrShareLinkExhausted        = errors.New("share link download limit reached")
+	ErrShareLinkPasswordRequired = errors.New("share link password required")
+	ErrShareLinkInvalidPassword  = errors.New("invalid share link password")
+	ErrInvalidBatch              = errors.New("invalid batch")
+	ErrInvalidBatchOperation     = errors.New("invalid batch operation")
+	ErrInvalidArchive            = errors.New("invalid archive request")
+	ErrFileNotReady              = errors.New("file content is not uploaded")
+	ErrUnsupportedArchive        = errors.New("unsupported archive format")
+	ErrUnsafeArchive             = errors.New("archive is unsafe to extract")
+	ErrExtractTarget             = errors.New("archives can only be extracted into a new empty file")
+	ErrTransferToOwner           = errors.New("file already belongs to this user")
+	ErrReceiverQuotaExceeded     = errors.New("receiver has insufficient storage space")
+	ErrInvalidRange              = errors.New("requested range is outside of file content")
+	ErrTypeNotAllowed            = errors.New("file type is not allowed")
+	ErrMimeTypeMismatch          = errors.New("file content does not match its mime type")
+	ErrThumbnailsDisabled        = errors.New("thumbnails are disabled")
+	ErrInvalidThumbnail          = errors.New("invalid thumbnail request")
+	ErrThumbnailUnavailable      = errors.New("thumbnail is not available for this file")
+	ErrFileQuarantined           = errors.New("file is quarantined: its content is infected")
+	ErrScanUnavailable           = errors.New("antivirus scan is unavailable")
+	ErrInvalidStatus             = errors.New("invalid file status")
+	ErrInvalidExpiry             = errors.New("expires_at must be in the future")
+	ErrFileExpired               = errors.New("file has expired")
+	ErrInvalidSize               = errors.New("size_in_bytes must not be negative")
+	ErrFileTooLarge              = errors.New("file exceeds the maximum file size")
+	ErrSizeMismatch              = errors.New("uploaded content size does not match the declared size")
 )
@@ -0,0 +1,97 @@
+package file
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+
+	"go.uber.org/zap"
+)
+
+// validateExpiry проверяет срок хранения из запроса: он должен быть в будущем. nil — бессрочно.
+func validateExpiry(expiresAt *time.Time) error {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return ErrInvalidExpiry
+	}
+	return nil
+}
+
+func isExpired(metaFile *entity.File, now time.Time) bool {
+	return metaFile.ExpiresAt != nil && !metaFile.ExpiresAt.After(now)
+}
+
+// checkNotExpired не дает скачать файл, срок которого истек, но который задача еще не удалила.
+func checkNotExpired(metaFile *entity.File) error {
+	if isExpired(metaFile, time.Now()) {
+		return ErrFileExpired
+	}
+	return nil
+}
+
+func (u *fileUsecase) SetFileExpiry(ctx context.Context, in *SetFileExpiryDtoIn) (*FileExpiryDtoOut, error) {
+	if err := validateExpiry(in.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	updatedFile, err := u.fileRepo.SetExpiry(ctx, in.UserID, in.FileID, in.ExpiresAt)
+	if err != nil {
+		return nil, fileNotFound(err)
+	}
+
+	u.log.Info("file expiry updated", zap.Int64("fileID", updatedFile.ID), zap.Timep("expiresAt", updatedFile.ExpiresAt))
+	return &FileExpiryDtoOut{
+		ID:        updatedFile.ID,
+		ExpiresAt: updatedFile.ExpiresAt,
+		UpdatedAt: updatedFile.UpdatedAt,
+	}, nil
+}
+
+// ExpireFiles удаляет из Postgres и S3 файлы с истекшим сроком хранения, в том числе из корзины.
+// Каждое удаление записывается в журнал file_deletions.
+func (u *fileUsecase) ExpireFiles(ctx context.Context, in *ExpireFilesDtoIn) (*ExpireFilesDtoOut, error) {
+	now := time.Now()
+	files, err := u.fileRepo.ListExpired(ctx, now, in.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ExpireFilesDtoOut{}
+	for _, expiredFile := range files {
+		if err := ctx.Err(); err != nil {
+			return out, err
+		}
+
+		var deletedFile *entity.File
+		err := u.commitObjectOperations(ctx, func(repo repository.FileRepository) ([]*entity.ObjectOperation, error) {
+			versions, err := repo.ListVersions(ctx, expiredFile.ID)
+			if err != nil {
+				return nil, err
+			}
+			deletedFile, err = repo.DeleteExpired(ctx, expiredFile.ID, now, entity.DeletionExpired)
+			if err != nil {
+				return nil, err
+			}
+			return deleteOperations(objectKeys(deletedFile, versions)), nil
+		})
+		if err != nil {
+			// Срок продлили или файл удалили, пока шла выборка.
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return out, err
+		}
+
+		u.log.Info("expired file deleted",
+			zap.Int64("fileID", deletedFile.ID),
+			zap.Int64("userID", deletedFile.UserID),
+			zap.Timep("expiresAt", deletedFile.ExpiresAt),
+		)
+		out.Deleted++
+		out.FreedBytes += deletedFile.SizeInBytes
+	}
+	return out, nil
+}
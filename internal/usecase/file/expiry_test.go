@@ -0,0 +1,126 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+	"time"
+
+	"meemo/internal/domain/entity"
+)
+
+// expireFile переносит срок хранения файла в прошлое, как будто задача удаления до него еще не дошла.
+func (e *testEnv) expireFile(t *testing.T, fileID int64) {
+	t.Helper()
+	_, err := e.db.ExecContext(context.Background(), `UPDATE files SET expires_at = ?1 WHERE id = ?2`, time.Now().Add(-time.Minute), fileID)
+	if err != nil {
+		t.Fatalf("Failed to expire file %d: %v", fileID, err)
+	}
+}
+
+func TestExpiredFile_ReadPaths(t *testing.T) {
+	env := newTestEnv(t, Options{Thumbnails: ThumbnailOptions{
+		Enabled: true,
+		Sizes:   []int{64},
+		Formats: []string{"jpeg"},
+	}})
+	ctx := context.Background()
+	owner := env.createUser(t, "expiredreads@test.com")
+
+	var photo bytes.Buffer
+	if err := png.Encode(&photo, image.NewNRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	folder, err := env.CreateFolder(ctx, &CreateFolderDtoIn{UserID: owner.ID, Name: "album"})
+	if err != nil {
+		t.Fatalf("Failed to create folder: %v", err)
+	}
+	expired := env.uploadFile(t, owner, "photo.png", photo.Bytes(), nil)
+	expiredInFolder := env.uploadFile(t, owner, "old.txt", []byte("gone"), &folder.ID)
+	env.uploadFile(t, owner, "notes.txt", []byte("still here"), &folder.ID)
+	env.expireFile(t, expired)
+	env.expireFile(t, expiredInFolder)
+
+	tests := []struct {
+		name string
+		read func() error
+	}{
+		{"archive", func() error {
+			_, err := env.PrepareArchive(ctx, &PrepareArchiveDtoIn{UserID: owner.ID, FileIDs: []int64{expired}})
+			return err
+		}},
+		{"list versions", func() error {
+			_, err := env.ListFileVersions(ctx, &ListFileVersionsDtoIn{UserID: owner.ID, FileID: expired})
+			return err
+		}},
+		{"version metadata", func() error {
+			_, err := env.GetFileVersionMetadata(ctx, &GetFileVersionDtoIn{UserID: owner.ID, FileID: expired, VersionNumber: 1})
+			return err
+		}},
+		{"version content", func() error {
+			_, err := env.GetFileVersion(ctx, &GetFileVersionDtoIn{UserID: owner.ID, FileID: expired, VersionNumber: 1}, io.Discard)
+			return err
+		}},
+		{"thumbnail", func() error {
+			_, err := env.GetThumbnail(ctx, &GetThumbnailDtoIn{UserID: owner.ID, UserEmail: owner.Email, OriginalName: "photo.png"})
+			return err
+		}},
+		{"copy", func() error {
+			_, err := env.CopyFile(ctx, &CopyFileDtoIn{UserID: owner.ID, UserEmail: owner.Email, OriginalName: "photo.png"})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.read(); !errors.Is(err, ErrFileExpired) {
+				t.Errorf("Expected ErrFileExpired, got %v", err)
+			}
+		})
+	}
+
+	t.Run("folder archive", func(t *testing.T) {
+		archive, err := env.PrepareArchive(ctx, &PrepareArchiveDtoIn{UserID: owner.ID, FolderID: &folder.ID})
+		if err != nil {
+			t.Fatalf("Failed to prepare archive: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := env.WriteArchive(ctx, archive, &buf); err != nil {
+			t.Fatalf("Failed to write archive: %v", err)
+		}
+		got := readZip(t, buf.Bytes())
+		if _, ok := got["album/old.txt"]; ok || len(got) != 2 || got["album/notes.txt"] != "still here" {
+			t.Errorf("Expected only the folder and notes.txt in archive, got %v", got)
+		}
+	})
+}
+
+func TestCopyFile_KeepsSourceExpiry(t *testing.T) {
+	env := newTestEnv(t, Options{})
+	ctx := context.Background()
+	owner := env.createUser(t, "copyexpiry@test.com")
+
+	sourceID := env.uploadFile(t, owner, "temp.txt", []byte("temporary"), nil)
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	if _, err := env.SetFileExpiry(ctx, &SetFileExpiryDtoIn{UserID: owner.ID, FileID: sourceID, ExpiresAt: &expiresAt}); err != nil {
+		t.Fatalf("Failed to set expiry: %v", err)
+	}
+
+	copied, err := env.CopyFile(ctx, &CopyFileDtoIn{UserID: owner.ID, UserEmail: owner.Email, OriginalName: "temp.txt"})
+	if err != nil {
+		t.Fatalf("Failed to copy file: %v", err)
+	}
+	saved, err := env.fileRepo.Get(ctx, copied.ID)
+	if err != nil {
+		t.Fatalf("Failed to get copy: %v", err)
+	}
+	if saved.ExpiresAt == nil || !saved.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("Expected copy to expire at %v, got %v", expiresAt, saved.ExpiresAt)
+	}
+	if saved.Status != entity.Loaded {
+		t.Errorf("Expected copy to be loaded, got status %d", saved.Status)
+	}
+}
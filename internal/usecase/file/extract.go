@@ -0,0 +1,453 @@
+package file
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+
+	"go.uber.org/zap"
+)
+
+const (
+	MaxExtractEntries = 10000
+	// MaxExtractedBytes ограничивает суммарный распакованный размер одного архива.
+	MaxExtractedBytes = MaxStorageBytes
+	// Архивы, которые распаковываются больше чем в MaxCompressionRatio раз, считаются zip-бомбами.
+	// Проверка включается начиная с compressionRatioThreshold распакованных байт.
+	MaxCompressionRatio       = 100
+	compressionRatioThreshold = 64 * 1024 * 1024
+
+	archivePathMetadataKey = "archive_path"
+	archiveNameMetadataKey = "archive"
+)
+
+// archiveMember — обычный файл внутри архива. open можно вызывать только внутри обхода.
+type archiveMember struct {
+	path string
+	size int64
+	open func() (io.ReadCloser, error)
+}
+
+// detectArchiveFormat определяет формат по сигнатуре: ZIP, gzip (считается tar.gz) или tar.
+func detectArchiveFormat(r io.ReaderAt) (string, error) {
+	header := make([]byte, 512)
+	n, err := r.ReadAt(header, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return ArchiveFormatZip, nil
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return ArchiveFormatTarGz, nil
+	case len(header) >= 262 && string(header[257:262]) == "ustar":
+		return "tar", nil
+	}
+	return "", ErrUnsupportedArchive
+}
+
+// walkArchive вызывает fn для каждого обычного файла архива в порядке их следования.
+// Папки, ссылки и служебные записи macOS пропускаются. Путь записи проверяется до вызова fn.
+func walkArchive(format string, r io.ReaderAt, size int64, fn func(member archiveMember) error) error {
+	if format == ArchiveFormatZip {
+		zr, err := zip.NewReader(r, size)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUnsupportedArchive, err)
+		}
+		for _, zf := range zr.File {
+			if !zf.Mode().IsRegular() {
+				continue
+			}
+			memberPath, skip, err := cleanMemberPath(zf.Name)
+			if err != nil {
+				return err
+			}
+			if skip {
+				continue
+			}
+			if err := fn(archiveMember{path: memberPath, size: int64(zf.UncompressedSize64), open: zf.Open}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var stream io.Reader = io.NewSectionReader(r, 0, size)
+	if format == ArchiveFormatTarGz {
+		gz, err := gzip.NewReader(stream)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUnsupportedArchive, err)
+		}
+		defer func() { _ = gz.Close() }()
+		stream = gz
+	}
+
+	tr := tar.NewReader(stream)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUnsupportedArchive, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		memberPath, skip, err := cleanMemberPath(header.Name)
+		if err != nil {
+			return err
+		}
+		if skip {
+			continue
+		}
+		open := func() (io.ReadCloser, error) { return io.NopCloser(tr), nil }
+		if err := fn(archiveMember{path: memberPath, size: header.Size, open: open}); err != nil {
+			return err
+		}
+	}
+}
+
+// cleanMemberPath нормализует путь записи. Абсолютные пути, в том числе с буквой диска Windows,
+// и выход за корень архива делают архив небезопасным целиком.
+func cleanMemberPath(name string) (string, bool, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(name, "/") || hasDriveLetter(name) {
+		return "", false, fmt.Errorf("%w: absolute path %q", ErrUnsafeArchive, name)
+	}
+
+	cleaned := path.Clean(name)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", false, fmt.Errorf("%w: path %q escapes the archive", ErrUnsafeArchive, name)
+	}
+	if cleaned == "." || cleaned == "__MACOSX" || strings.HasPrefix(cleaned, "__MACOSX/") || path.Base(cleaned) == ".DS_Store" {
+		return "", true, nil
+	}
+	return cleaned, false, nil
+}
+
+// hasDriveLetter сообщает, начинается ли путь с буквы диска: «C:», «C:/…». Двоеточие
+// в остальных местах — обычный символ имени файла.
+func hasDriveLetter(name string) bool {
+	if len(name) < 2 || name[1] != ':' {
+		return false
+	}
+	letter := name[0] | 0x20
+	return letter >= 'a' && letter <= 'z' && (len(name) == 2 || name[2] == '/')
+}
+
+// scanArchive проверяет архив до распаковки: пути, число записей, суммарный размер и степень сжатия.
+// checkPath дополнительно проверяет путь каждой записи.
+func scanArchive(format string, r io.ReaderAt, size int64, checkPath func(memberPath string) error) (int, int64, error) {
+	var entries int
+	var total int64
+	err := walkArchive(format, r, size, func(member archiveMember) error {
+		if err := checkPath(member.path); err != nil {
+			return err
+		}
+		entries++
+		total += member.size
+		switch {
+		case entries > MaxExtractEntries:
+			return fmt.Errorf("%w: more than %d entries", ErrUnsafeArchive, MaxExtractEntries)
+		case member.size < 0 || total > MaxExtractedBytes:
+			return fmt.Errorf("%w: extracted size exceeds %d bytes", ErrUnsafeArchive, int64(MaxExtractedBytes))
+		case total > compressionRatioThreshold && total/max(size, 1) > MaxCompressionRatio:
+			return fmt.Errorf("%w: compression ratio exceeds %d", ErrUnsafeArchive, MaxCompressionRatio)
+		}
+		return nil
+	})
+	return entries, total, err
+}
+
+// ExtractArchive распаковывает ZIP, tar или tar.gz в отдельные файлы рядом с файлом in.ID.
+// Папки архива воссоздаются как папки хранилища, путь каждой записи сохраняется в метаданных
+// под ключом archive_path. Файл in.ID должен быть новым и пустым: он служит лишь заготовкой.
+// Записи сначала распаковываются в скрытую промежуточную папку и переносятся на место только
+// после того, как распакован весь архив; тогда же удаляется заготовка. Если распаковка
+// прервется, промежуточная папка удаляется, а заготовка и уже лежавшие рядом файлы не меняются.
+func (u *fileUsecase) ExtractArchive(ctx context.Context, in *ExtractArchiveDtoIn, r io.ReaderAt) (*ExtractArchiveDtoOut, error) {
+	if r == nil {
+		return nil, errors.New("input reader is nil")
+	}
+
+	target, err := u.getOwnedFile(ctx, in.UserID, in.ID)
+	if err != nil {
+		return nil, err
+	}
+	if target.Status != entity.Pending || target.CurrentVersionID != nil {
+		return nil, ErrExtractTarget
+	}
+
+	if in.ExpectedSHA256 != "" {
+		hasher := newContentHasher()
+		if _, err := io.Copy(hasher, io.NewSectionReader(r, 0, in.SizeInBytes)); err != nil {
+			return nil, err
+		}
+		if !checksumsEqual(in.ExpectedSHA256, hasher.SHA256()) {
+			return nil, ErrChecksumMismatch
+		}
+	}
+
+	format, err := detectArchiveFormat(r)
+	if err != nil {
+		return nil, err
+	}
+	entries, total, err := scanArchive(format, r, in.SizeInBytes, func(memberPath string) error {
+		name := path.Base(memberPath)
+		return u.checkFileType(ctx, target.UserID, declaredMimeType("", name), name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Заготовка архива уже учтена в занятом месте, но будет удалена.
+	if err := u.checkQuota(ctx, in.Email, total-target.SizeInBytes); err != nil {
+		return nil, err
+	}
+
+	token, err := newObjectToken()
+	if err != nil {
+		return nil, err
+	}
+	staging, err := u.folderRepo.Create(ctx, in.UserID, target.FolderID, fmt.Sprintf(".extract-%d-%s", target.ID, token[:8]))
+	if err != nil {
+		return nil, err
+	}
+	// После переноса промежуточная папка пуста; при ошибке вместе с ней удаляется все распакованное.
+	defer u.removeStagingFolder(context.WithoutCancel(ctx), in.UserID, staging.ID)
+
+	out := &ExtractArchiveDtoOut{Format: format}
+	staged := make([]stagedMember, 0, entries)
+	index := make(map[int64]int, entries)
+	folders := map[string]*int64{"": &staging.ID}
+	err = walkArchive(format, r, in.SizeInBytes, func(member archiveMember) error {
+		folderID, err := u.ensureFolderPath(ctx, in.UserID, path.Dir(member.path), folders)
+		if err != nil {
+			return err
+		}
+
+		extracted, searchText, err := u.extractMember(ctx, target, folderID, member)
+		if err != nil {
+			return fmt.Errorf("extract %s: %w", member.path, err)
+		}
+		out.ExtractedBytes += extracted.SizeInBytes
+		// Запись с уже встречавшимся путем стала новой версией того же файла.
+		i, ok := index[extracted.ID]
+		if !ok {
+			i = len(staged)
+			index[extracted.ID] = i
+			staged = append(staged, stagedMember{fileID: extracted.ID, dir: path.Dir(member.path), name: extracted.OriginalName})
+		}
+		staged[i].searchText = searchText
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out.Files, err = u.swapExtracted(ctx, target, staged)
+	if err != nil {
+		return nil, err
+	}
+
+	u.log.Info("archive extracted", zap.Int64("fileID", target.ID), zap.String("format", format), zap.Int("files", len(out.Files)))
+	return out, nil
+}
+
+// stagedMember — файл, распакованный в промежуточную папку. dir — его папка относительно корня архива.
+type stagedMember struct {
+	fileID     int64
+	dir        string
+	name       string
+	searchText string
+}
+
+// swapExtracted одной транзакцией удаляет заготовку target и переносит распакованные файлы
+// в их папки рядом с ней. Если файл с таким именем уже есть, распакованное содержимое становится
+// его новой версией, как при обычной загрузке.
+func (u *fileUsecase) swapExtracted(ctx context.Context, target *entity.File, staged []stagedMember) ([]FileListItemDto, error) {
+	folders := map[string]*int64{"": target.FolderID}
+	destinations := make([]*int64, len(staged))
+	for i, member := range staged {
+		folderID, err := u.ensureFolderPath(ctx, target.UserID, member.dir, folders)
+		if err != nil {
+			return nil, err
+		}
+		destinations[i] = folderID
+	}
+
+	var fileIDs []int64
+	var versioned []int
+	err := u.fileRepo.InTx(ctx, func(repo repository.FileRepository) error {
+		fileIDs, versioned = make([]int64, len(staged)), nil
+		if _, err := repo.DeleteByID(ctx, target.UserID, target.ID); err != nil {
+			return fileNotFound(err)
+		}
+
+		for i, member := range staged {
+			existing, err := repo.GetByName(ctx, target.UserID, destinations[i], member.name)
+			if errors.Is(err, sql.ErrNoRows) {
+				if _, err := repo.MoveToFolder(ctx, target.UserID, member.fileID, destinations[i]); err != nil {
+					return err
+				}
+				fileIDs[i] = member.fileID
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := moveVersions(ctx, repo, target.UserID, member.fileID, existing.ID); err != nil {
+				return err
+			}
+			fileIDs[i] = existing.ID
+			versioned = append(versioned, i)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]FileListItemDto, 0, len(fileIDs))
+	for _, fileID := range fileIDs {
+		extracted, err := u.fileRepo.Get(ctx, fileID)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, toFileListItem(extracted))
+	}
+	for _, i := range versioned {
+		if err := u.fileRepo.SetSearchText(ctx, fileIDs[i], extractSearchText(files[i].MimeType, []byte(staged[i].searchText))); err != nil {
+			u.log.Warn("failed to update file search text", zap.Int64("fileID", fileIDs[i]), zap.Error(err))
+		}
+	}
+	return files, nil
+}
+
+// moveVersions добавляет версии файла fromID в историю файла toID и удаляет fromID.
+// Объекты версий не копируются: на них начинают ссылаться новые версии.
+func moveVersions(ctx context.Context, repo repository.FileRepository, userID, fromID, toID int64) error {
+	versions, err := repo.ListVersions(ctx, fromID)
+	if err != nil {
+		return err
+	}
+	// ListVersions возвращает новые версии первыми.
+	for i := len(versions) - 1; i >= 0; i-- {
+		version := *versions[i]
+		version.FileID = toID
+		if _, err := repo.AddVersion(ctx, &version, nil); err != nil {
+			return err
+		}
+	}
+	_, err = repo.DeleteByID(ctx, userID, fromID)
+	return err
+}
+
+// removeStagingFolder удаляет промежуточную папку распаковки вместе с оставшимися в ней файлами.
+func (u *fileUsecase) removeStagingFolder(ctx context.Context, userID, folderID int64) {
+	files, err := u.fileRepo.ListInFolderTree(ctx, userID, folderID)
+	if err != nil {
+		u.log.Warn("failed to list staged files", zap.Int64("folderID", folderID), zap.Error(err))
+		return
+	}
+	for _, metaFile := range files {
+		if _, err := u.removeFile(ctx, userID, metaFile.ID); err != nil {
+			u.log.Warn("failed to remove staged file", zap.Int64("fileID", metaFile.ID), zap.Error(err))
+			return
+		}
+	}
+	if err := u.folderRepo.Delete(ctx, userID, folderID); err != nil {
+		u.log.Warn("failed to remove staging folder", zap.Int64("folderID", folderID), zap.Error(err))
+	}
+}
+
+func (u *fileUsecase) extractMember(ctx context.Context, target *entity.File, folderID *int64, member archiveMember) (*entity.File, string, error) {
+	name := path.Base(member.path)
+	mimeType := declaredMimeType("", name)
+
+	memberFile, _, err := u.findOrCreateFile(ctx, &entity.File{
+		UserID:       target.UserID,
+		OriginalName: name,
+		MimeType:     mimeType,
+		SizeInBytes:  member.size,
+		IsPublic:     target.IsPublic,
+		FolderID:     folderID,
+		Tags:         target.Tags,
+		ExpiresAt:    target.ExpiresAt,
+		Metadata: map[string]string{
+			archivePathMetadataKey: member.path,
+			archiveNameMetadataKey: target.OriginalName,
+		},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	content, err := member.open()
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() { _ = content.Close() }()
+
+	// Начало содержимого нужно для поиска, если файл станет версией уже существующего.
+	textPrefix := newPrefixCapture(searchTextLimit)
+	if _, err := u.putContent(ctx, &SaveFileContentDtoIn{
+		UserID:      target.UserID,
+		ID:          memberFile.ID,
+		SizeInBytes: member.size,
+	}, memberFile, io.TeeReader(content, textPrefix)); err != nil {
+		return nil, "", err
+	}
+	extracted, err := u.fileRepo.Get(ctx, memberFile.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	return extracted, string(textPrefix.Bytes()), nil
+}
+
+// ensureFolderPath находит или создает цепочку папок dir относительно корня распаковки.
+func (u *fileUsecase) ensureFolderPath(ctx context.Context, userID int64, dir string, folders map[string]*int64) (*int64, error) {
+	if dir == "." {
+		dir = ""
+	}
+	if folderID, ok := folders[dir]; ok {
+		return folderID, nil
+	}
+
+	parentID, err := u.ensureFolderPath(ctx, userID, path.Dir(dir), folders)
+	if err != nil {
+		return nil, err
+	}
+
+	name := path.Base(dir)
+	children, err := u.folderRepo.ListChildren(ctx, userID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		if child.Name == name {
+			folders[dir] = &child.ID
+			return &child.ID, nil
+		}
+	}
+
+	created, err := u.folderRepo.Create(ctx, userID, parentID, name)
+	if err != nil {
+		return nil, err
+	}
+	folders[dir] = &created.ID
+	return &created.ID, nil
+}
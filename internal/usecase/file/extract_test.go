@@ -0,0 +1,514 @@
+package file
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"meemo/internal/domain/entity"
+)
+
+func TestCleanMemberPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		skip     bool
+		unsafe   bool
+	}{
+		{name: "docs/readme.txt", expected: "docs/readme.txt"},
+		{name: "./docs//readme.txt", expected: "docs/readme.txt"},
+		{name: "docs\\nested\\notes.md", expected: "docs/nested/notes.md"},
+		{name: "a/../b.txt", expected: "b.txt"},
+		{name: "../x", unsafe: true},
+		{name: "..", unsafe: true},
+		{name: "a/../../x", unsafe: true},
+		{name: "..\\x", unsafe: true},
+		{name: "/abs", unsafe: true},
+		{name: "/etc/passwd", unsafe: true},
+		{name: "\\abs", unsafe: true},
+		{name: "C:\\x", unsafe: true},
+		{name: "c:/x", unsafe: true},
+		{name: "C:", unsafe: true},
+		{name: "C:x", expected: "C:x"},
+		{name: "a:b.txt", expected: "a:b.txt"},
+		{name: "docs/a:b", expected: "docs/a:b"},
+		{name: "1:/x", expected: "1:/x"},
+		{name: "__MACOSX/", skip: true},
+		{name: "__MACOSX/docs/._readme.txt", skip: true},
+		{name: ".DS_Store", skip: true},
+		{name: "docs/.DS_Store", skip: true},
+		{name: ".", skip: true},
+		{name: "__MACOSX.txt", expected: "__MACOSX.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, skip, err := cleanMemberPath(tt.name)
+			if tt.unsafe {
+				if !errors.Is(err, ErrUnsafeArchive) {
+					t.Errorf("Expected ErrUnsafeArchive, got %q, skip %v, err %v", got, skip, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if skip != tt.skip || (!tt.skip && got != tt.expected) {
+				t.Errorf("Expected %q (skip %v), got %q (skip %v)", tt.expected, tt.skip, got, skip)
+			}
+		})
+	}
+}
+
+func TestScanArchive_Limits(t *testing.T) {
+	allowAll := func(string) error { return nil }
+
+	manyEntries := buildZip(t, func(zw *zip.Writer) {
+		for i := 0; i <= MaxExtractEntries; i++ {
+			if _, err := zw.Create("file-" + strconv.Itoa(i) + ".txt"); err != nil {
+				t.Fatalf("Failed to add entry: %v", err)
+			}
+		}
+	})
+
+	// Заголовок заявляет размер больше предела: сканирование не читает содержимое, поэтому
+	// архиву не нужно действительно распаковываться в десятки гигабайт.
+	oversized := buildZip(t, func(zw *zip.Writer) {
+		w, err := zw.CreateRaw(&zip.FileHeader{
+			Name:               "huge.bin",
+			Method:             zip.Store,
+			CompressedSize64:   1,
+			UncompressedSize64: uint64(MaxExtractedBytes + 1),
+		})
+		if err != nil {
+			t.Fatalf("Failed to add raw entry: %v", err)
+		}
+		_, _ = w.Write([]byte{0})
+	})
+
+	// Настоящая zip-бомба: нули сжимаются примерно в тысячу раз.
+	bomb := buildZip(t, func(zw *zip.Writer) {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: "zeros.bin", Method: zip.Deflate})
+		if err != nil {
+			t.Fatalf("Failed to add entry: %v", err)
+		}
+		zeros := make([]byte, 1024*1024)
+		for written := 0; written <= compressionRatioThreshold; written += len(zeros) {
+			if _, err := w.Write(zeros); err != nil {
+				t.Fatalf("Failed to write entry: %v", err)
+			}
+		}
+	})
+
+	regular := buildZip(t, func(zw *zip.Writer) {
+		w, err := zw.Create("docs/readme.txt")
+		if err != nil {
+			t.Fatalf("Failed to add entry: %v", err)
+		}
+		_, _ = w.Write([]byte("hello"))
+	})
+
+	tests := []struct {
+		name    string
+		archive []byte
+		message string
+	}{
+		{"entry count", manyEntries, "entries"},
+		{"total size", oversized, "extracted size"},
+		{"compression ratio", bomb, "compression ratio"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := scanArchive(ArchiveFormatZip, bytes.NewReader(tt.archive), int64(len(tt.archive)), allowAll)
+			if !errors.Is(err, ErrUnsafeArchive) || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected ErrUnsafeArchive about %s, got %v", tt.message, err)
+			}
+		})
+	}
+
+	entries, total, err := scanArchive(ArchiveFormatZip, bytes.NewReader(regular), int64(len(regular)), allowAll)
+	if err != nil || entries != 1 || total != 5 {
+		t.Errorf("Expected 1 entry of 5 bytes, got %d entries of %d bytes, err %v", entries, total, err)
+	}
+
+	rejected := errors.New("rejected")
+	_, _, err = scanArchive(ArchiveFormatZip, bytes.NewReader(regular), int64(len(regular)), func(memberPath string) error {
+		if memberPath != "docs/readme.txt" {
+			t.Errorf("Expected cleaned member path, got %q", memberPath)
+		}
+		return rejected
+	})
+	if !errors.Is(err, rejected) {
+		t.Errorf("Expected path check error, got %v", err)
+	}
+}
+
+func TestExtractArchive_RoundTrip(t *testing.T) {
+	members := []struct {
+		name    string
+		content string
+	}{
+		{"top.txt", "top level"},
+		{"docs/readme.txt", "read me"},
+		{"docs/nested/deep/notes.md", "# notes"},
+		{"__MACOSX/docs/._readme.txt", "resource fork"},
+		{"docs/.DS_Store", "finder"},
+	}
+	expected := map[string]string{
+		"top.txt":                   "top level",
+		"docs/readme.txt":           "read me",
+		"docs/nested/deep/notes.md": "# notes",
+	}
+
+	zipArchive := buildZip(t, func(zw *zip.Writer) {
+		if _, err := zw.Create("docs/"); err != nil {
+			t.Fatalf("Failed to add folder: %v", err)
+		}
+		for _, member := range members {
+			w, err := zw.Create(member.name)
+			if err != nil {
+				t.Fatalf("Failed to add entry: %v", err)
+			}
+			_, _ = w.Write([]byte(member.content))
+		}
+	})
+
+	var tarGz bytes.Buffer
+	gz := gzip.NewWriter(&tarGz)
+	tw := tar.NewWriter(gz)
+	_ = tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "docs/", Mode: 0o755})
+	_ = tw.WriteHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: "docs/link", Linkname: "/etc/passwd"})
+	for _, member := range members {
+		_ = tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: member.name, Mode: 0o644, Size: int64(len(member.content))})
+		_, _ = tw.Write([]byte(member.content))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Failed to close tar: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("Failed to close gzip: %v", err)
+	}
+
+	for _, tt := range []struct {
+		format  string
+		archive []byte
+	}{
+		{ArchiveFormatZip, zipArchive},
+		{ArchiveFormatTarGz, tarGz.Bytes()},
+	} {
+		t.Run(tt.format, func(t *testing.T) {
+			env := newTestEnv(t, Options{})
+			ctx := context.Background()
+			owner := env.createUser(t, "extract@test.com")
+
+			parent, err := env.CreateFolder(ctx, &CreateFolderDtoIn{UserID: owner.ID, Name: "uploads"})
+			if err != nil {
+				t.Fatalf("Failed to create folder: %v", err)
+			}
+			target := env.createFile(t, owner, "bundle."+tt.format, int64(len(tt.archive)), &parent.ID)
+
+			out, err := env.ExtractArchive(ctx, &ExtractArchiveDtoIn{
+				Email:       owner.Email,
+				UserID:      owner.ID,
+				ID:          target.ID,
+				SizeInBytes: int64(len(tt.archive)),
+			}, bytes.NewReader(tt.archive))
+			if err != nil {
+				t.Fatalf("Failed to extract archive: %v", err)
+			}
+			if out.Format != tt.format || len(out.Files) != len(expected) {
+				t.Fatalf("Expected %d files from %s, got %d from %s", len(expected), tt.format, len(out.Files), out.Format)
+			}
+
+			if _, err := env.fileRepo.Get(ctx, target.ID); err == nil {
+				t.Errorf("Expected archive placeholder to be deleted")
+			}
+
+			folders := map[int64]string{parent.ID: ""}
+			var walk func(parentID int64, dir string)
+			walk = func(parentID int64, dir string) {
+				children, err := env.folderRepo.ListChildren(ctx, owner.ID, &parentID)
+				if err != nil {
+					t.Fatalf("Failed to list folders: %v", err)
+				}
+				for _, child := range children {
+					folders[child.ID] = dir + child.Name + "/"
+					walk(child.ID, folders[child.ID])
+				}
+			}
+			walk(parent.ID, "")
+			if len(folders) != 4 {
+				t.Errorf("Expected docs, docs/nested and docs/nested/deep under the target folder, got %v", folders)
+			}
+
+			for _, extracted := range out.Files {
+				memberPath := extracted.Metadata[archivePathMetadataKey]
+				content, ok := expected[memberPath]
+				if !ok {
+					t.Errorf("Unexpected extracted file %q", memberPath)
+					continue
+				}
+				if extracted.Metadata[archiveNameMetadataKey] != target.OriginalName {
+					t.Errorf("Expected %s to reference archive %s, got %v", memberPath, target.OriginalName, extracted.Metadata)
+				}
+				if extracted.FolderID == nil || folders[*extracted.FolderID]+extracted.OriginalName != memberPath {
+					t.Errorf("Expected %s in its archive folder, got folder %v", memberPath, extracted.FolderID)
+				}
+				if extracted.Status != entity.Loaded || extracted.SizeInBytes != int64(len(content)) {
+					t.Errorf("Expected %s loaded with %d bytes, got status %d and %d bytes", memberPath, len(content), extracted.Status, extracted.SizeInBytes)
+				}
+
+				var buf bytes.Buffer
+				if _, err := env.GetFileByID(ctx, &GetFileByIDDtoIn{FileID: extracted.ID, UserID: owner.ID}, &buf); err != nil {
+					t.Fatalf("Failed to read %s: %v", memberPath, err)
+				}
+				if buf.String() != content {
+					t.Errorf("Expected %s to contain %q, got %q", memberPath, content, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestExtractArchive_TargetWithContent(t *testing.T) {
+	env := newTestEnv(t, Options{})
+	ctx := context.Background()
+	owner := env.createUser(t, "extracttarget@test.com")
+
+	archive := buildZip(t, func(zw *zip.Writer) {
+		w, _ := zw.Create("a.txt")
+		_, _ = w.Write([]byte("a"))
+	})
+	targetID := env.uploadFile(t, owner, "bundle.zip", archive, nil)
+
+	_, err := env.ExtractArchive(ctx, &ExtractArchiveDtoIn{
+		Email:       owner.Email,
+		UserID:      owner.ID,
+		ID:          targetID,
+		SizeInBytes: int64(len(archive)),
+	}, bytes.NewReader(archive))
+	if !errors.Is(err, ErrExtractTarget) {
+		t.Fatalf("Expected ErrExtractTarget, got %v", err)
+	}
+	if _, err := env.fileRepo.Get(ctx, targetID); err != nil {
+		t.Errorf("Expected target with content to be kept, got %v", err)
+	}
+}
+
+func TestExtractArchive_ExistingFileGetsNewVersion(t *testing.T) {
+	archive := buildZip(t, func(zw *zip.Writer) {
+		w, _ := zw.Create("a.txt")
+		_, _ = w.Write([]byte("fresh words from the archive"))
+		w, _ = zw.Create("b.txt")
+		_, _ = w.Write([]byte("b"))
+	})
+
+	for _, tt := range []struct {
+		name string
+		opts Options
+	}{
+		{"plain objects", Options{}},
+		{"deduplication", Options{Deduplication: true}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, tt.opts)
+			ctx := context.Background()
+			owner := env.createUser(t, "extractversion@test.com")
+
+			parent, err := env.CreateFolder(ctx, &CreateFolderDtoIn{UserID: owner.ID, Name: "uploads"})
+			if err != nil {
+				t.Fatalf("Failed to create folder: %v", err)
+			}
+			existing := env.uploadFile(t, owner, "a.txt", []byte("old"), &parent.ID)
+			target := env.createFile(t, owner, "bundle.zip", int64(len(archive)), &parent.ID)
+
+			out, err := env.ExtractArchive(ctx, &ExtractArchiveDtoIn{
+				Email:       owner.Email,
+				UserID:      owner.ID,
+				ID:          target.ID,
+				SizeInBytes: int64(len(archive)),
+			}, bytes.NewReader(archive))
+			if err != nil {
+				t.Fatalf("Failed to extract archive: %v", err)
+			}
+			if len(out.Files) != 2 || out.Files[0].ID != existing {
+				t.Fatalf("Expected a.txt to be extracted into the existing file %d, got %+v", existing, out.Files)
+			}
+
+			versions, err := env.fileRepo.ListVersions(ctx, existing)
+			if err != nil || len(versions) != 2 {
+				t.Fatalf("Expected the existing file to get a second version, got %d, %v", len(versions), err)
+			}
+			var buf bytes.Buffer
+			if _, err := env.GetFileByID(ctx, &GetFileByIDDtoIn{FileID: existing, UserID: owner.ID}, &buf); err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			if buf.String() != "fresh words from the archive" {
+				t.Errorf("Expected the archive content, got %q", buf.String())
+			}
+			buf.Reset()
+			if _, err := env.GetFileVersion(ctx, &GetFileVersionDtoIn{UserID: owner.ID, FileID: existing, VersionNumber: 1}, &buf); err != nil || buf.String() != "old" {
+				t.Errorf("Expected the previous version to be kept, got %q, %v", buf.String(), err)
+			}
+
+			found, err := env.SearchFiles(ctx, &SearchFilesDtoIn{UserID: owner.ID, Query: "fresh"})
+			if err != nil || len(found.Results) != 1 || found.Results[0].ID != existing {
+				t.Errorf("Expected the new version to be searchable, got %+v, %v", found, err)
+			}
+
+			// Промежуточная папка удалена, а вместе с ней и файл, из которого перенесена версия.
+			if children, err := env.folderRepo.ListChildren(ctx, owner.ID, &parent.ID); err != nil || len(children) != 0 {
+				t.Errorf("Expected no staging folder to remain, got %v, %v", children, err)
+			}
+			files, err := env.fileRepo.ListByFolder(ctx, owner.ID, &parent.ID)
+			if err != nil || len(files) != 2 {
+				t.Errorf("Expected a.txt and b.txt next to the removed placeholder, got %d, %v", len(files), err)
+			}
+		})
+	}
+}
+
+func TestExtractArchive_FailureKeepsExistingContent(t *testing.T) {
+	// Проверка содержимого третьей записи провалится только при ее загрузке: по имени это картинка.
+	archive := buildZip(t, func(zw *zip.Writer) {
+		for _, member := range []struct{ name, content string }{
+			{"a.txt", "replacement"},
+			{"docs/c.txt", "c"},
+			{"photo.jpg", "not an image at all"},
+		} {
+			w, _ := zw.Create(member.name)
+			_, _ = w.Write([]byte(member.content))
+		}
+	})
+
+	env := newTestEnv(t, Options{MimeDetection: MimeDetectionVerify})
+	ctx := context.Background()
+	owner := env.createUser(t, "extractfail@test.com")
+
+	parent, err := env.CreateFolder(ctx, &CreateFolderDtoIn{UserID: owner.ID, Name: "uploads"})
+	if err != nil {
+		t.Fatalf("Failed to create folder: %v", err)
+	}
+	existing := env.uploadFile(t, owner, "a.txt", []byte("original"), &parent.ID)
+	target := env.createFile(t, owner, "bundle.zip", int64(len(archive)), &parent.ID)
+
+	_, err = env.ExtractArchive(ctx, &ExtractArchiveDtoIn{
+		Email:       owner.Email,
+		UserID:      owner.ID,
+		ID:          target.ID,
+		SizeInBytes: int64(len(archive)),
+	}, bytes.NewReader(archive))
+	if !errors.Is(err, ErrMimeTypeMismatch) {
+		t.Fatalf("Expected ErrMimeTypeMismatch, got %v", err)
+	}
+
+	if placeholder, err := env.fileRepo.Get(ctx, target.ID); err != nil || placeholder.Status != entity.Pending {
+		t.Errorf("Expected the placeholder to be kept, got %+v, %v", placeholder, err)
+	}
+	versions, err := env.fileRepo.ListVersions(ctx, existing)
+	if err != nil || len(versions) != 1 {
+		t.Errorf("Expected the existing file to keep a single version, got %d, %v", len(versions), err)
+	}
+	var buf bytes.Buffer
+	if _, err := env.GetFileByID(ctx, &GetFileByIDDtoIn{FileID: existing, UserID: owner.ID}, &buf); err != nil || buf.String() != "original" {
+		t.Errorf("Expected the existing content to be kept, got %q, %v", buf.String(), err)
+	}
+
+	if children, err := env.folderRepo.ListChildren(ctx, owner.ID, &parent.ID); err != nil || len(children) != 0 {
+		t.Errorf("Expected no partial folders, got %v, %v", children, err)
+	}
+	files, err := env.fileRepo.ListByFolder(ctx, owner.ID, &parent.ID)
+	if err != nil || len(files) != 2 {
+		t.Errorf("Expected only a.txt and the placeholder, got %d, %v", len(files), err)
+	}
+	existingFile, err := env.fileRepo.Get(ctx, existing)
+	if err != nil {
+		t.Fatalf("Failed to get file: %v", err)
+	}
+	if keys := env.objectKeys(t); len(keys) != 1 || keys[0] != existingFile.S3Key {
+		t.Errorf("Expected staged objects to be removed, got %v", keys)
+	}
+}
+
+func TestExtractArchive_QuotaExcludesPlaceholder(t *testing.T) {
+	content := strings.Repeat("x", 1000)
+	archive := buildZip(t, func(zw *zip.Writer) {
+		w, _ := zw.Create("big.txt")
+		_, _ = w.Write([]byte(content))
+	})
+	extracted := int64(len(content))
+
+	tests := []struct {
+		name     string
+		used     int64
+		expected error
+	}{
+		// Вместе с распакованным место занято ровно целиком: заготовка не учитывается дважды.
+		{"fits without placeholder", MaxStorageBytes - extracted, nil},
+		{"exceeds by one byte", MaxStorageBytes - extracted + 1, ErrInsufficientStorage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, Options{})
+			ctx := context.Background()
+			owner := env.createUser(t, "extractquota@test.com")
+
+			if _, err := env.fileRepo.Create(ctx, &entity.File{
+				UserID:       owner.ID,
+				OriginalName: "existing.bin",
+				MimeType:     "application/octet-stream",
+				SizeInBytes:  tt.used,
+				Status:       entity.Loaded,
+				CreatedAt:    time.Now(),
+				UpdatedAt:    time.Now(),
+			}); err != nil {
+				t.Fatalf("Failed to create existing file: %v", err)
+			}
+			// Заготовку создает репозиторий: SaveFileMetadata не пустил бы ее сверх квоты.
+			target, err := env.fileRepo.Create(ctx, &entity.File{
+				UserID:       owner.ID,
+				OriginalName: "bundle.zip",
+				MimeType:     "application/zip",
+				SizeInBytes:  int64(len(archive)),
+				Status:       entity.Pending,
+				CreatedAt:    time.Now(),
+				UpdatedAt:    time.Now(),
+			})
+			if err != nil {
+				t.Fatalf("Failed to create placeholder: %v", err)
+			}
+
+			_, err = env.ExtractArchive(ctx, &ExtractArchiveDtoIn{
+				Email:       owner.Email,
+				UserID:      owner.ID,
+				ID:          target.ID,
+				SizeInBytes: int64(len(archive)),
+			}, bytes.NewReader(archive))
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, err)
+			}
+			if tt.expected != nil {
+				if _, err := env.fileRepo.Get(ctx, target.ID); err != nil {
+					t.Errorf("Expected placeholder to be kept after a rejected extraction, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func buildZip(t *testing.T, fill func(zw *zip.Writer)) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	fill(zw)
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close zip: %v", err)
+	}
+	return buf.Bytes()
+}
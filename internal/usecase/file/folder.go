@@ -0,0 +1,199 @@
+package file
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"meemo/internal/domain/entity"
+
+	"go.uber.org/zap"
+)
+
+func (u *fileUsecase) getFolder(ctx context.Context, userID, folderID int64) (*entity.Folder, error) {
+	folder, err := u.folderRepo.Get(ctx, userID, folderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFolderNotFound
+		}
+		return nil, err
+	}
+	return folder, nil
+}
+
+func validateFolderName(name string) error {
+	if strings.TrimSpace(name) == "" || strings.Contains(name, "/") {
+		return ErrInvalidFolderName
+	}
+	return nil
+}
+
+func toFolderDto(folder *entity.Folder) *FolderDto {
+	return &FolderDto{
+		ID:        folder.ID,
+		ParentID:  folder.ParentID,
+		Name:      folder.Name,
+		CreatedAt: folder.CreatedAt,
+		UpdatedAt: folder.UpdatedAt,
+	}
+}
+
+func (u *fileUsecase) CreateFolder(ctx context.Context, in *CreateFolderDtoIn) (*FolderDto, error) {
+	if err := validateFolderName(in.Name); err != nil {
+		return nil, err
+	}
+
+	if in.ParentID != nil {
+		if _, err := u.getFolder(ctx, in.UserID, *in.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	folder, err := u.folderRepo.Create(ctx, in.UserID, in.ParentID, in.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	u.log.Info("folder created", zap.Int64("folderID", folder.ID), zap.String("name", folder.Name))
+	return toFolderDto(folder), nil
+}
+
+func (u *fileUsecase) ListFolderChildren(ctx context.Context, in *ListFolderChildrenDtoIn) (*ListFolderChildrenDtoOut, error) {
+	out := &ListFolderChildrenDtoOut{}
+	if in.FolderID != nil {
+		folder, err := u.getFolder(ctx, in.UserID, *in.FolderID)
+		if err != nil {
+			return nil, err
+		}
+		out.Folder = toFolderDto(folder)
+	}
+
+	folders, err := u.folderRepo.ListChildren(ctx, in.UserID, in.FolderID)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := u.fileRepo.ListByFolder(ctx, in.UserID, in.FolderID)
+	if err != nil {
+		return nil, err
+	}
+
+	out.Folders = make([]FolderDto, 0, len(folders))
+	for _, folder := range folders {
+		out.Folders = append(out.Folders, *toFolderDto(folder))
+	}
+	out.Files = toFileListItems(files)
+
+	return out, nil
+}
+
+func (u *fileUsecase) RenameFolder(ctx context.Context, in *RenameFolderDtoIn) (*FolderDto, error) {
+	if err := validateFolderName(in.Name); err != nil {
+		return nil, err
+	}
+
+	folder, err := u.folderRepo.Rename(ctx, in.UserID, in.FolderID, in.Name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFolderNotFound
+		}
+		return nil, err
+	}
+
+	u.log.Info("folder renamed", zap.Int64("folderID", folder.ID), zap.String("name", folder.Name))
+	return toFolderDto(folder), nil
+}
+
+func (u *fileUsecase) MoveFolder(ctx context.Context, in *MoveFolderDtoIn) (*FolderDto, error) {
+	if _, err := u.getFolder(ctx, in.UserID, in.FolderID); err != nil {
+		return nil, err
+	}
+
+	if in.ParentID != nil {
+		if *in.ParentID == in.FolderID {
+			return nil, ErrInvalidFolderMove
+		}
+		if _, err := u.getFolder(ctx, in.UserID, *in.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	folder, err := u.folderRepo.Move(ctx, in.UserID, in.FolderID, in.ParentID)
+	if err != nil {
+		// Папка существует, значит строку отсекло условие на цикл.
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidFolderMove
+		}
+		return nil, err
+	}
+
+	u.log.Info("folder moved", zap.Int64("folderID", folder.ID))
+	return toFolderDto(folder), nil
+}
+
+func (u *fileUsecase) DeleteFolder(ctx context.Context, in *DeleteFolderDtoIn) (*DeleteFolderDtoOut, error) {
+	if _, err := u.getFolder(ctx, in.UserID, in.FolderID); err != nil {
+		return nil, err
+	}
+
+	files, err := u.fileRepo.ListInFolderTree(ctx, in.UserID, in.FolderID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, metaFile := range files {
+		if _, err := u.removeFile(ctx, in.UserID, metaFile.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.folderRepo.Delete(ctx, in.UserID, in.FolderID); err != nil {
+		return nil, err
+	}
+
+	u.log.Info("folder deleted", zap.Int64("folderID", in.FolderID), zap.Int("deletedFiles", len(files)))
+	return &DeleteFolderDtoOut{
+		ID:           in.FolderID,
+		DeletedFiles: len(files),
+	}, nil
+}
+
+func (u *fileUsecase) GetFolderSize(ctx context.Context, in *GetFolderSizeDtoIn) (*GetFolderSizeDtoOut, error) {
+	if _, err := u.getFolder(ctx, in.UserID, in.FolderID); err != nil {
+		return nil, err
+	}
+
+	size, err := u.folderRepo.GetSize(ctx, in.UserID, in.FolderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetFolderSizeDtoOut{
+		ID:          in.FolderID,
+		SizeInBytes: size.SizeInBytes,
+		FileCount:   size.FileCount,
+		FolderCount: size.FolderCount,
+	}, nil
+}
+
+func (u *fileUsecase) MoveFile(ctx context.Context, in *MoveFileDtoIn) (*MoveFileDtoOut, error) {
+	if in.FolderID != nil {
+		if _, err := u.getFolder(ctx, in.UserID, *in.FolderID); err != nil {
+			return nil, err
+		}
+	}
+
+	movedFile, err := u.fileRepo.MoveToFolder(ctx, in.UserID, in.FileID, in.FolderID)
+	if err != nil {
+		return nil, err
+	}
+
+	u.log.Info("file moved", zap.Int64("fileID", movedFile.ID))
+	return &MoveFileDtoOut{
+		ID:           movedFile.ID,
+		OriginalName: movedFile.OriginalName,
+		FolderID:     movedFile.FolderID,
+		UpdatedAt:    movedFile.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,208 @@
+package file
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"meemo/internal/domain/entity"
+	"meemo/internal/infrastructure/storage/s3/file"
+
+	"go.uber.org/zap"
+)
+
+// Виды расхождений между базой и S3.
+const (
+	IssueOrphanedObject = "orphaned_object"
+	IssueMissingObject  = "missing_object"
+	IssueSizeMismatch   = "size_mismatch"
+	IssueStalePending   = "stale_pending"
+)
+
+// Действия, которыми исправляются расхождения.
+const (
+	RepairDeletedObject    = "deleted_object"
+	RepairFlaggedCorrupted = "flagged_corrupted"
+	RepairDeletedThumbnail = "deleted_thumbnail"
+	RepairDeletedFile      = "deleted_file"
+)
+
+const (
+	DefaultCheckGracePeriod      = 24 * time.Hour
+	DefaultCheckPendingOlderThan = 24 * time.Hour
+	DefaultCheckMaxIssues        = 1000
+)
+
+var errNotRepairable = errors.New("cannot be repaired automatically")
+
+// CheckStorage сверяет объекты бакета со строками базы: ищет объекты, на которые ничего не ссылается,
+// объекты, которых нет в бакете или размер которых не совпадает с записанным, и файлы, застрявшие
+// в статусе Pending. С Repair расхождения исправляются:
+//   - лишние объекты удаляются;
+//   - файл с пропавшим или поврежденным содержимым помечается как не прошедший проверку контрольной суммы;
+//   - миниатюра удаляется, чтобы ее создали заново;
+//   - застрявший файл удаляется.
+//
+// Пропавшие блобы и старые версии только попадают в отчет. Объекты моложе GracePeriod не считаются
+// лишними: они могут принадлежать загрузкам, которые еще не записаны в базу.
+func (u *fileUsecase) CheckStorage(ctx context.Context, in *CheckStorageDtoIn) (*CheckStorageDtoOut, error) {
+	gracePeriod := in.GracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultCheckGracePeriod
+	}
+	pendingOlderThan := in.PendingOlderThan
+	if pendingOlderThan <= 0 {
+		pendingOlderThan = DefaultCheckPendingOlderThan
+	}
+	maxIssues := in.MaxIssues
+	if maxIssues <= 0 {
+		maxIssues = DefaultCheckMaxIssues
+	}
+	startedAt := time.Now()
+
+	// База читается раньше бакета: объект, записанный после этого, моложе GracePeriod.
+	objects, err := u.fileRepo.ListStoredObjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expected := make(map[string]*entity.StoredObject, len(objects))
+	for _, stored := range objects {
+		if _, ok := expected[stored.S3Key]; !ok {
+			expected[stored.S3Key] = stored
+		}
+	}
+
+	out := &CheckStorageDtoOut{
+		Repair:            in.Repair,
+		ReferencedObjects: len(expected),
+		Issues:            []StorageIssueDto{},
+	}
+	report := func(issue StorageIssueDto, repairErr error) {
+		switch {
+		case repairErr != nil:
+			issue.Action = ""
+			issue.Error = repairErr.Error()
+		case issue.Action != "":
+			out.Repaired++
+		}
+		if len(out.Issues) < maxIssues {
+			out.Issues = append(out.Issues, issue)
+		} else {
+			out.IssuesTruncated = true
+		}
+	}
+
+	found := make(map[string]struct{}, len(expected))
+	err = u.s3Client.ListObjects(ctx, "", func(object *file.ObjectInfo) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		out.ScannedObjects++
+
+		stored, ok := expected[object.Key]
+		if !ok {
+			if object.LastModified.After(startedAt.Add(-gracePeriod)) {
+				out.SkippedRecent++
+				return nil
+			}
+			out.OrphanedObjects++
+			issue := StorageIssueDto{Type: IssueOrphanedObject, S3Key: object.Key, ActualSize: &object.SizeInBytes}
+			var repairErr error
+			if in.Repair {
+				issue.Action = RepairDeletedObject
+				repairErr = u.s3Client.DeleteObject(ctx, object.Key)
+			}
+			report(issue, repairErr)
+			return nil
+		}
+
+		found[object.Key] = struct{}{}
+		if object.SizeInBytes != stored.SizeInBytes {
+			out.SizeMismatches++
+			issue := storedObjectIssue(IssueSizeMismatch, stored)
+			issue.ActualSize = &object.SizeInBytes
+			report(issue, u.repairStoredObject(ctx, in.Repair, stored, &issue))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stored := range objects {
+		if _, ok := found[stored.S3Key]; ok {
+			continue
+		}
+		found[stored.S3Key] = struct{}{}
+		out.MissingObjects++
+		issue := storedObjectIssue(IssueMissingObject, stored)
+		report(issue, u.repairStoredObject(ctx, in.Repair, stored, &issue))
+	}
+
+	pending, err := u.fileRepo.ListStalePending(ctx, startedAt.Add(-pendingOlderThan))
+	if err != nil {
+		return nil, err
+	}
+	for _, pendingFile := range pending {
+		out.StalePending++
+		issue := StorageIssueDto{Type: IssueStalePending, FileID: &pendingFile.ID}
+		var repairErr error
+		if in.Repair {
+			issue.Action = RepairDeletedFile
+			if _, err := u.removeFile(ctx, pendingFile.UserID, pendingFile.ID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+				repairErr = err
+			}
+		}
+		report(issue, repairErr)
+	}
+
+	u.log.Info("storage check finished",
+		zap.Bool("repair", in.Repair),
+		zap.Int("scannedObjects", out.ScannedObjects),
+		zap.Int("orphanedObjects", out.OrphanedObjects),
+		zap.Int("missingObjects", out.MissingObjects),
+		zap.Int("sizeMismatches", out.SizeMismatches),
+		zap.Int("stalePending", out.StalePending),
+		zap.Int("repaired", out.Repaired),
+	)
+	return out, nil
+}
+
+func storedObjectIssue(issueType string, stored *entity.StoredObject) StorageIssueDto {
+	return StorageIssueDto{
+		Type:         issueType,
+		S3Key:        stored.S3Key,
+		Object:       stored.Kind,
+		FileID:       stored.FileID,
+		ExpectedSize: &stored.SizeInBytes,
+	}
+}
+
+// repairStoredObject исправляет объект, который пропал или записан не полностью, и заполняет issue.Action.
+func (u *fileUsecase) repairStoredObject(ctx context.Context, repair bool, stored *entity.StoredObject, issue *StorageIssueDto) error {
+	if !repair {
+		return nil
+	}
+
+	switch stored.Kind {
+	case entity.StoredObjectFile:
+		issue.Action = RepairFlaggedCorrupted
+		err := u.fileRepo.MarkChecksumVerified(ctx, *stored.FileID, true)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Файл удалили во время проверки.
+			return nil
+		}
+		return err
+	case entity.StoredObjectThumbnail:
+		issue.Action = RepairDeletedThumbnail
+		if err := u.thumbnailRepo.DeleteByKey(ctx, stored.S3Key); err != nil {
+			return err
+		}
+		if issue.Type == IssueSizeMismatch {
+			u.deleteObjectQuietly(ctx, stored.S3Key)
+		}
+		return nil
+	}
+	return errNotRepairable
+}
@@ -0,0 +1,140 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func issuesOfType(out *CheckStorageDtoOut, issueType string) []StorageIssueDto {
+	var issues []StorageIssueDto
+	for _, issue := range out.Issues {
+		if issue.Type == issueType {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+func TestCheckStorage_ReportAndRepair(t *testing.T) {
+	env := newTestEnv(t, Options{})
+	ctx := context.Background()
+	owner := env.createUser(t, "fsck@test.com")
+
+	healthy := env.uploadFile(t, owner, "healthy.txt", []byte("still here"), nil)
+	lost := env.uploadFile(t, owner, "lost.txt", []byte("gone from the bucket"), nil)
+	stuck := env.createFile(t, owner, "stuck.txt", 10, nil)
+
+	lostFile, err := env.fileRepo.Get(ctx, lost)
+	if err != nil {
+		t.Fatalf("Failed to get file: %v", err)
+	}
+	if err := env.s3Client.DeleteObject(ctx, lostFile.S3Key); err != nil {
+		t.Fatalf("Failed to delete object: %v", err)
+	}
+	const orphanKey = "files/999/orphan"
+	orphan := []byte("nobody references me")
+	if err := env.s3Client.PutObject(ctx, orphanKey, bytes.NewReader(orphan), int64(len(orphan))); err != nil {
+		t.Fatalf("Failed to plant orphan: %v", err)
+	}
+
+	// С обычным grace period только что записанный объект еще может принадлежать загрузке.
+	out, err := env.CheckStorage(ctx, &CheckStorageDtoIn{})
+	if err != nil {
+		t.Fatalf("Failed to check storage: %v", err)
+	}
+	if out.SkippedRecent != 1 || out.OrphanedObjects != 0 || out.StalePending != 0 {
+		t.Errorf("Expected the fresh orphan and pending file to be skipped, got %+v", out)
+	}
+
+	check := &CheckStorageDtoIn{GracePeriod: time.Nanosecond, PendingOlderThan: time.Nanosecond}
+	out, err = env.CheckStorage(ctx, check)
+	if err != nil {
+		t.Fatalf("Failed to check storage: %v", err)
+	}
+	if out.ScannedObjects != 2 || out.ReferencedObjects != 2 {
+		t.Errorf("Expected 2 scanned and 2 referenced objects, got %d and %d", out.ScannedObjects, out.ReferencedObjects)
+	}
+	if out.OrphanedObjects != 1 || out.MissingObjects != 1 || out.SizeMismatches != 0 || out.StalePending != 1 || out.Repaired != 0 || out.Unresolved() != 3 {
+		t.Fatalf("Unexpected dry-run report: %+v", out)
+	}
+	if issues := issuesOfType(out, IssueOrphanedObject); len(issues) != 1 || issues[0].S3Key != orphanKey || *issues[0].ActualSize != int64(len(orphan)) {
+		t.Errorf("Expected orphan %s to be reported, got %+v", orphanKey, issues)
+	}
+	if issues := issuesOfType(out, IssueMissingObject); len(issues) != 1 || issues[0].S3Key != lostFile.S3Key || *issues[0].FileID != lost {
+		t.Errorf("Expected missing object of file %d to be reported, got %+v", lost, issues)
+	}
+	if issues := issuesOfType(out, IssueStalePending); len(issues) != 1 || *issues[0].FileID != stuck.ID {
+		t.Errorf("Expected pending file %d to be reported, got %+v", stuck.ID, issues)
+	}
+	for _, issue := range out.Issues {
+		if issue.Action != "" {
+			t.Errorf("Expected dry run not to repair anything, got %+v", issue)
+		}
+	}
+
+	// Проверка без Repair ничего не меняет.
+	if keys := env.objectKeys(t); len(keys) != 2 {
+		t.Errorf("Expected dry run to keep the orphan, got %v", keys)
+	}
+	if lostFile, err := env.fileRepo.Get(ctx, lost); err != nil || lostFile.ChecksumFailed {
+		t.Errorf("Expected dry run not to flag the file, got %+v, %v", lostFile, err)
+	}
+	if _, err := env.fileRepo.Get(ctx, stuck.ID); err != nil {
+		t.Errorf("Expected dry run to keep the pending file, got %v", err)
+	}
+
+	check.Repair = true
+	out, err = env.CheckStorage(ctx, check)
+	if err != nil {
+		t.Fatalf("Failed to repair storage: %v", err)
+	}
+	if out.Repaired != 3 || out.Unresolved() != 0 {
+		t.Fatalf("Expected all issues to be repaired, got %+v", out)
+	}
+	actions := map[string]string{}
+	for _, issue := range out.Issues {
+		if issue.Error != "" {
+			t.Errorf("Unexpected repair error: %+v", issue)
+		}
+		actions[issue.Type] = issue.Action
+	}
+	expectedActions := map[string]string{
+		IssueOrphanedObject: RepairDeletedObject,
+		IssueMissingObject:  RepairFlaggedCorrupted,
+		IssueStalePending:   RepairDeletedFile,
+	}
+	for issueType, action := range expectedActions {
+		if actions[issueType] != action {
+			t.Errorf("Expected %s to be repaired with %s, got %q", issueType, action, actions[issueType])
+		}
+	}
+
+	healthyFile, err := env.fileRepo.Get(ctx, healthy)
+	if err != nil {
+		t.Fatalf("Failed to get file: %v", err)
+	}
+	if keys := env.objectKeys(t); len(keys) != 1 || keys[0] != healthyFile.S3Key {
+		t.Errorf("Expected only the healthy object to remain, got %v", keys)
+	}
+	if healthyFile.ChecksumFailed {
+		t.Errorf("Expected healthy file not to be flagged")
+	}
+	if lostFile, err := env.fileRepo.Get(ctx, lost); err != nil || !lostFile.ChecksumFailed {
+		t.Errorf("Expected file with missing content to be flagged, got %+v, %v", lostFile, err)
+	}
+	if _, err := env.fileRepo.Get(ctx, stuck.ID); err == nil {
+		t.Errorf("Expected pending file to be deleted")
+	}
+
+	// Пропавшее содержимое не восстановить: файл остается в отчете, но помечен поврежденным.
+	check.Repair = false
+	out, err = env.CheckStorage(ctx, check)
+	if err != nil {
+		t.Fatalf("Failed to check storage: %v", err)
+	}
+	if out.OrphanedObjects != 0 || out.StalePending != 0 || out.MissingObjects != 1 {
+		t.Errorf("Expected only the missing object after repair, got %+v", out)
+	}
+}
@@ -0,0 +1,161 @@
+package file
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+)
+
+const (
+	DefaultListLimit = 100
+	MaxListLimit     = 1000
+)
+
+// listCursor указывает на последний файл страницы. Поле сортировки и направление
+// сохраняются в курсоре, чтобы его нельзя было применить к другому порядку.
+type listCursor struct {
+	SortBy     repository.SortField `json:"s"`
+	Descending bool                 `json:"d"`
+	Value      string               `json:"v"`
+	ID         int64                `json:"id"`
+}
+
+func encodeCursor(cursor listCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(encoded string) (*listCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &listCursor{}
+	if err := json.Unmarshal(raw, cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+func sortValue(file *entity.File, sortBy repository.SortField) string {
+	switch sortBy {
+	case repository.SortByName:
+		return file.OriginalName
+	case repository.SortBySize:
+		return strconv.FormatInt(file.SizeInBytes, 10)
+	case repository.SortByUpdated:
+		return file.UpdatedAt.Format(time.RFC3339Nano)
+	default:
+		return file.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+func listOptionsFromDto(in *GetAllUserFilesDtoIn) (repository.ListOptions, error) {
+	opts := repository.ListOptions{
+		SortBy:        repository.SortByCreated,
+		Descending:    true,
+		Limit:         in.Limit,
+		MimePrefix:    in.MimePrefix,
+		Status:        in.Status,
+		IsPublic:      in.IsPublic,
+		MinSize:       in.MinSize,
+		MaxSize:       in.MaxSize,
+		CreatedAfter:  in.CreatedAfter,
+		CreatedBefore: in.CreatedBefore,
+		MetadataKey:   in.MetadataKey,
+		MetadataValue: in.MetadataValue,
+	}
+
+	switch sortBy := repository.SortField(in.SortBy); sortBy {
+	case "":
+	case repository.SortByName, repository.SortBySize, repository.SortByCreated, repository.SortByUpdated:
+		opts.SortBy = sortBy
+		// По имени естественно листать по возрастанию, по размеру и датам — от больших к меньшим.
+		opts.Descending = sortBy != repository.SortByName
+	default:
+		return opts, fmt.Errorf("%w: unknown sort field %q", ErrInvalidListQuery, in.SortBy)
+	}
+
+	switch in.Order {
+	case "":
+	case "asc":
+		opts.Descending = false
+	case "desc":
+		opts.Descending = true
+	default:
+		return opts, fmt.Errorf("%w: unknown order %q", ErrInvalidListQuery, in.Order)
+	}
+
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultListLimit
+	}
+	if opts.Limit > MaxListLimit {
+		opts.Limit = MaxListLimit
+	}
+
+	if in.MinSize != nil && in.MaxSize != nil && *in.MinSize > *in.MaxSize {
+		return opts, fmt.Errorf("%w: min_size is greater than max_size", ErrInvalidListQuery)
+	}
+
+	if len(in.Tags) > 0 {
+		tags, err := normalizeTags(in.Tags)
+		if err != nil {
+			return opts, fmt.Errorf("%w: %w", ErrInvalidListQuery, err)
+		}
+		opts.Tags = tags
+	}
+	if in.MetadataValue != nil && in.MetadataKey == "" {
+		return opts, fmt.Errorf("%w: metadata value requires a metadata key", ErrInvalidListQuery)
+	}
+
+	if in.Cursor != "" {
+		cursor, err := decodeCursor(in.Cursor)
+		if err != nil {
+			return opts, err
+		}
+		if cursor.SortBy != opts.SortBy || cursor.Descending != opts.Descending {
+			return opts, ErrInvalidCursor
+		}
+		opts.AfterValue = &cursor.Value
+		opts.AfterID = cursor.ID
+	}
+
+	return opts, nil
+}
+
+func (u *fileUsecase) GetUserFilesList(ctx context.Context, in *GetAllUserFilesDtoIn) (*GetAllUserFilesDtoOut, error) {
+	opts, err := listOptionsFromDto(in)
+	if err != nil {
+		return nil, err
+	}
+
+	// Запрашиваем на один файл больше, чтобы понять, есть ли следующая страница.
+	pageSize := opts.Limit
+	opts.Limit++
+
+	files, err := u.fileRepo.ListPage(ctx, in.UserID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &GetAllUserFilesDtoOut{}
+	if len(files) > pageSize {
+		files = files[:pageSize]
+		last := files[len(files)-1]
+		out.NextCursor = encodeCursor(listCursor{
+			SortBy:     opts.SortBy,
+			Descending: opts.Descending,
+			Value:      sortValue(last, opts.SortBy),
+			ID:         last.ID,
+		})
+	}
+	out.Files = toFileListItems(files)
+	return out, nil
+}
@@ -0,0 +1,193 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	DefaultOutboxMaxAttempts = 10
+	DefaultOutboxRetryDelay  = 30 * time.Second
+	DefaultOutboxLease       = 5 * time.Minute
+
+	maxOutboxRetryDelay = time.Hour
+)
+
+// OutboxOptions задает очередь операций с S3, которые записываются вместе с изменением метаданных.
+type OutboxOptions struct {
+	// MaxAttempts — число попыток, после которого операция больше не повторяется.
+	MaxAttempts int
+	// RetryDelay — задержка перед второй попыткой; каждая следующая задержка вдвое больше, но не больше часа.
+	RetryDelay time.Duration
+	// Lease — время, на которое взятая операция скрывается от других исполнителей.
+	Lease time.Duration
+}
+
+func deleteOperations(keys []string) []*entity.ObjectOperation {
+	ops := make([]*entity.ObjectOperation, 0, len(keys))
+	for _, key := range keys {
+		ops = append(ops, &entity.ObjectOperation{Operation: entity.ObjectOperationDelete, S3Key: key})
+	}
+	return ops
+}
+
+// enqueueObjectOperations записывает операции в транзакцию repo. Сразу после фиксации их выполняет
+// applyObjectOperations, поэтому ProcessObjectOutbox берет их только по истечении Lease.
+func (u *fileUsecase) enqueueObjectOperations(ctx context.Context, repo repository.FileRepository, ops []*entity.ObjectOperation) ([]*entity.ObjectOperation, error) {
+	if len(ops) == 0 {
+		return nil, nil
+	}
+	return repo.EnqueueObjectOperations(ctx, ops, time.Now().Add(u.opts.Outbox.Lease))
+}
+
+// commitObjectOperations выполняет fn в транзакции вместе с записью операций с S3, которые она вернула,
+// и после фиксации применяет их. Неудавшиеся операции остаются в очереди.
+func (u *fileUsecase) commitObjectOperations(ctx context.Context, fn func(repo repository.FileRepository) ([]*entity.ObjectOperation, error)) error {
+	var enqueued []*entity.ObjectOperation
+	err := u.fileRepo.InTx(ctx, func(repo repository.FileRepository) error {
+		ops, err := fn(repo)
+		if err != nil {
+			return err
+		}
+		enqueued, err = u.enqueueObjectOperations(ctx, repo, ops)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_ = u.applyObjectOperations(ctx, enqueued)
+	return nil
+}
+
+// applyObjectOperations выполняет записанные операции не более чем в opts.DeleteConcurrency потоков
+// и убирает выполненные из очереди. Возвращается первая ошибка; неудавшиеся операции повторит ProcessObjectOutbox.
+func (u *fileUsecase) applyObjectOperations(ctx context.Context, ops []*entity.ObjectOperation) error {
+	if len(ops) == 0 {
+		return nil
+	}
+
+	var (
+		mu      sync.Mutex
+		applied []int64
+		g       errgroup.Group
+	)
+	g.SetLimit(u.opts.DeleteConcurrency)
+	for _, op := range ops {
+		g.Go(func() error {
+			if err := u.applyObjectOperation(ctx, op); err != nil {
+				u.log.Warn("failed to apply object operation, it will be retried",
+					zap.Int64("operationID", op.ID), zap.String("operation", op.Operation), zap.String("key", op.S3Key), zap.Error(err))
+				return err
+			}
+			mu.Lock()
+			applied = append(applied, op.ID)
+			mu.Unlock()
+			return nil
+		})
+	}
+	applyErr := g.Wait()
+
+	if len(applied) > 0 {
+		if err := u.fileRepo.DeleteObjectOperations(ctx, applied); err != nil {
+			// Операции идемпотентны: в худшем случае их повторит ProcessObjectOutbox.
+			u.log.Warn("failed to remove applied object operations", zap.Int64s("operationIDs", applied), zap.Error(err))
+		}
+	}
+	return applyErr
+}
+
+// applyObjectOperation выполняет операцию. Повтор безопасен: удаление отсутствующего объекта
+// не считается ошибкой, а копирование перезаписывает результат прошлой попытки.
+func (u *fileUsecase) applyObjectOperation(ctx context.Context, op *entity.ObjectOperation) error {
+	switch op.Operation {
+	case entity.ObjectOperationDelete:
+		return u.s3Client.DeleteObject(ctx, op.S3Key)
+	case entity.ObjectOperationCopy:
+		return u.s3Client.CopyObject(ctx, op.SourceKey, op.S3Key)
+	}
+	return fmt.Errorf("unknown object operation %q", op.Operation)
+}
+
+// ProcessObjectOutbox выполняет операции с S3, которые не удалось применить сразу после фиксации:
+// из-за ошибки S3 или потому что процесс остановился раньше. Неудавшаяся операция откладывается
+// с растущей задержкой, а после opts.Outbox.MaxAttempts попыток помечается как мертвая.
+func (u *fileUsecase) ProcessObjectOutbox(ctx context.Context, in *ProcessObjectOutboxDtoIn) (*ProcessObjectOutboxDtoOut, error) {
+	now := time.Now()
+	ops, err := u.fileRepo.ClaimObjectOperations(ctx, now, now.Add(u.opts.Outbox.Lease), in.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu      sync.Mutex
+		out     = &ProcessObjectOutboxDtoOut{}
+		applied []int64
+		g       errgroup.Group
+	)
+	g.SetLimit(u.opts.DeleteConcurrency)
+	for _, op := range ops {
+		g.Go(func() error {
+			applyErr := u.applyObjectOperation(ctx, op)
+			if applyErr == nil {
+				mu.Lock()
+				applied = append(applied, op.ID)
+				out.Applied++
+				mu.Unlock()
+				return nil
+			}
+
+			if op.Attempts >= u.opts.Outbox.MaxAttempts {
+				u.log.Error("object operation dead-lettered",
+					zap.Int64("operationID", op.ID), zap.String("operation", op.Operation), zap.String("key", op.S3Key),
+					zap.Int("attempts", op.Attempts), zap.Error(applyErr))
+				if err := u.fileRepo.DeadLetterObjectOperation(ctx, op.ID, applyErr.Error()); err != nil {
+					return err
+				}
+				mu.Lock()
+				out.DeadLettered++
+				mu.Unlock()
+				return nil
+			}
+
+			u.log.Warn("object operation failed",
+				zap.Int64("operationID", op.ID), zap.String("operation", op.Operation), zap.String("key", op.S3Key),
+				zap.Int("attempts", op.Attempts), zap.Error(applyErr))
+			if err := u.fileRepo.RetryObjectOperation(ctx, op.ID, applyErr.Error(), time.Now().Add(u.outboxRetryDelay(op.Attempts))); err != nil {
+				return err
+			}
+			mu.Lock()
+			out.Retried++
+			mu.Unlock()
+			return nil
+		})
+	}
+	err = g.Wait()
+
+	if len(applied) > 0 {
+		if deleteErr := u.fileRepo.DeleteObjectOperations(ctx, applied); deleteErr != nil && err == nil {
+			err = deleteErr
+		}
+	}
+	if out.Applied > 0 || out.Retried > 0 || out.DeadLettered > 0 {
+		u.log.Info("object outbox processed", zap.Int("applied", out.Applied), zap.Int("retried", out.Retried), zap.Int("deadLettered", out.DeadLettered))
+	}
+	return out, err
+}
+
+// outboxRetryDelay возвращает задержку после attempts неудачных попыток.
+func (u *fileUsecase) outboxRetryDelay(attempts int) time.Duration {
+	delay := u.opts.Outbox.RetryDelay
+	for i := 1; i < attempts && delay < maxOutboxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxOutboxRetryDelay)
+}
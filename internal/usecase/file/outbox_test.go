@@ -0,0 +1,220 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"meemo/internal/domain/file/repository"
+	"meemo/internal/infrastructure/storage/s3/file"
+)
+
+var errObjectStoreDown = errors.New("object store is down")
+
+// flakyObjects — хранилище, в котором удаление и копирование отказывают, пока failing выставлен.
+type flakyObjects struct {
+	file.S3Client
+	failing atomic.Bool
+}
+
+func (s *flakyObjects) DeleteObject(ctx context.Context, key string) error {
+	if s.failing.Load() {
+		return errObjectStoreDown
+	}
+	return s.S3Client.DeleteObject(ctx, key)
+}
+
+func (s *flakyObjects) CopyObject(ctx context.Context, srcKey, dstKey string) error {
+	if s.failing.Load() {
+		return errObjectStoreDown
+	}
+	return s.S3Client.CopyObject(ctx, srcKey, dstKey)
+}
+
+func (e *testEnv) useFlakyObjects() *flakyObjects {
+	flaky := &flakyObjects{S3Client: e.s3Client}
+	e.s3Client = flaky
+	return flaky
+}
+
+type outboxRow struct {
+	ID          int64      `db:"id"`
+	Attempts    int        `db:"attempts"`
+	LastError   string     `db:"last_error"`
+	AvailableAt time.Time  `db:"available_at"`
+	DeadAt      *time.Time `db:"dead_at"`
+}
+
+func (e *testEnv) outboxRows(t *testing.T) []outboxRow {
+	t.Helper()
+	var rows []outboxRow
+	if err := e.db.Select(&rows, `SELECT id, attempts, last_error, available_at, dead_at FROM object_outbox ORDER BY id`); err != nil {
+		t.Fatalf("Failed to read outbox: %v", err)
+	}
+	return rows
+}
+
+// releaseOutbox делает все операции очереди готовыми к выполнению, как будто задержка уже прошла.
+func (e *testEnv) releaseOutbox(t *testing.T) {
+	t.Helper()
+	if _, err := e.db.Exec(`UPDATE object_outbox SET available_at = ?1`, time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("Failed to release outbox: %v", err)
+	}
+}
+
+func TestProcessObjectOutbox_RetriesThenDeadLetters(t *testing.T) {
+	const maxAttempts = 3
+	env := newTestEnv(t, Options{Outbox: OutboxOptions{
+		MaxAttempts: maxAttempts,
+		RetryDelay:  time.Minute,
+		Lease:       time.Minute,
+	}})
+	ctx := context.Background()
+	owner := env.createUser(t, "outboxretry@test.com")
+	flaky := env.useFlakyObjects()
+
+	env.uploadFile(t, owner, "doomed.txt", []byte("delete me"), nil)
+	if _, err := env.DeleteFile(ctx, &DeleteFileDtoIn{UserID: owner.ID, UserEmail: owner.Email, OriginalName: "doomed.txt"}); err != nil {
+		t.Fatalf("Failed to trash file: %v", err)
+	}
+
+	flaky.failing.Store(true)
+	// Метаданные удаляются, даже если S3 недоступен: удаление объекта остается в очереди.
+	if _, err := env.EmptyTrash(ctx, &EmptyTrashDtoIn{UserID: owner.ID}); err != nil {
+		t.Fatalf("Failed to empty trash: %v", err)
+	}
+	if keys := env.objectKeys(t); len(keys) != 1 {
+		t.Fatalf("Expected object to survive the failed delete, got %v", keys)
+	}
+	rows := env.outboxRows(t)
+	if len(rows) != 1 || rows[0].Attempts != 0 {
+		t.Fatalf("Expected one queued operation without attempts, got %+v", rows)
+	}
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		env.releaseOutbox(t)
+		before := time.Now()
+		out, err := env.ProcessObjectOutbox(ctx, &ProcessObjectOutboxDtoIn{BatchSize: 10})
+		if err != nil {
+			t.Fatalf("Attempt %d: failed to process outbox: %v", attempt, err)
+		}
+		after := time.Now()
+
+		rows := env.outboxRows(t)
+		if len(rows) != 1 || rows[0].Attempts != attempt || !strings.Contains(rows[0].LastError, errObjectStoreDown.Error()) {
+			t.Fatalf("Attempt %d: unexpected outbox state %+v", attempt, rows)
+		}
+		if attempt < maxAttempts {
+			delay := time.Minute << (attempt - 1)
+			if out.Retried != 1 || out.DeadLettered != 0 || rows[0].DeadAt != nil {
+				t.Fatalf("Attempt %d: expected a retry, got %+v", attempt, out)
+			}
+			if rows[0].AvailableAt.Before(before.Add(delay).Truncate(time.Microsecond)) || rows[0].AvailableAt.After(after.Add(delay)) {
+				t.Errorf("Attempt %d: expected retry after %v, got available at %v (now %v)", attempt, delay, rows[0].AvailableAt, before)
+			}
+			continue
+		}
+		if out.DeadLettered != 1 || out.Retried != 0 || rows[0].DeadAt == nil {
+			t.Fatalf("Attempt %d: expected the operation to be dead-lettered, got %+v", attempt, out)
+		}
+	}
+
+	// Мертвая операция больше не выполняется, даже когда S3 снова доступен.
+	flaky.failing.Store(false)
+	env.releaseOutbox(t)
+	out, err := env.ProcessObjectOutbox(ctx, &ProcessObjectOutboxDtoIn{BatchSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to process outbox: %v", err)
+	}
+	if out.Applied != 0 || out.Retried != 0 || out.DeadLettered != 0 {
+		t.Errorf("Expected dead operation to be skipped, got %+v", out)
+	}
+}
+
+func TestProcessObjectOutbox_RecoversAfterCrash(t *testing.T) {
+	env := newTestEnv(t, Options{})
+	ctx := context.Background()
+	owner := env.createUser(t, "outboxcrash@test.com")
+
+	fileID := env.uploadFile(t, owner, "report.txt", []byte("quarterly numbers"), nil)
+	// Транзакция зафиксирована, но процесс остановился до того, как применил операции.
+	err := env.fileRepo.InTx(ctx, func(repo repository.FileRepository) error {
+		versions, err := repo.ListVersions(ctx, fileID)
+		if err != nil {
+			return err
+		}
+		deleted, err := repo.DeleteByID(ctx, owner.ID, fileID)
+		if err != nil {
+			return err
+		}
+		_, err = env.enqueueObjectOperations(ctx, repo, deleteOperations(objectKeys(deleted, versions)))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Failed to delete file: %v", err)
+	}
+	if keys := env.objectKeys(t); len(keys) != 1 {
+		t.Fatalf("Expected object to be left behind, got %v", keys)
+	}
+
+	// Пока не истекла аренда, операцию может выполнять сам запрос, поэтому исполнитель ее не берет.
+	out, err := env.ProcessObjectOutbox(ctx, &ProcessObjectOutboxDtoIn{BatchSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to process outbox: %v", err)
+	}
+	if out.Applied != 0 {
+		t.Fatalf("Expected operation to stay leased, got %+v", out)
+	}
+
+	env.releaseOutbox(t)
+	out, err = env.ProcessObjectOutbox(ctx, &ProcessObjectOutboxDtoIn{BatchSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to process outbox: %v", err)
+	}
+	if out.Applied != 1 {
+		t.Fatalf("Expected the delete to be applied, got %+v", out)
+	}
+	if keys := env.objectKeys(t); len(keys) != 0 {
+		t.Errorf("Expected object to be deleted, got %v", keys)
+	}
+	if rows := env.outboxRows(t); len(rows) != 0 {
+		t.Errorf("Expected empty outbox, got %+v", rows)
+	}
+}
+
+func TestCopyFile_RollsBackWhenCopyFails(t *testing.T) {
+	env := newTestEnv(t, Options{})
+	ctx := context.Background()
+	owner := env.createUser(t, "outboxcopy@test.com")
+	flaky := env.useFlakyObjects()
+
+	env.uploadFile(t, owner, "source.txt", []byte("original"), nil)
+	flaky.failing.Store(true)
+	if _, err := env.CopyFile(ctx, &CopyFileDtoIn{UserID: owner.ID, UserEmail: owner.Email, OriginalName: "source.txt"}); !errors.Is(err, errObjectStoreDown) {
+		t.Fatalf("Expected copy to fail with the store error, got %v", err)
+	}
+	flaky.failing.Store(false)
+
+	files, err := env.fileRepo.List(ctx, owner.Email)
+	if err != nil {
+		t.Fatalf("Failed to list files: %v", err)
+	}
+	if len(files) != 1 || files[0].OriginalName != "source.txt" {
+		t.Errorf("Expected only the source file to remain, got %d files", len(files))
+	}
+
+	// Отмененное копирование не выполняется позже, а удаление несостоявшейся копии безвредно.
+	env.releaseOutbox(t)
+	if _, err := env.ProcessObjectOutbox(ctx, &ProcessObjectOutboxDtoIn{BatchSize: 10}); err != nil {
+		t.Fatalf("Failed to process outbox: %v", err)
+	}
+	if keys := env.objectKeys(t); len(keys) != 1 {
+		t.Errorf("Expected only the source object, got %v", keys)
+	}
+	if rows := env.outboxRows(t); len(rows) != 0 {
+		t.Errorf("Expected empty outbox, got %+v", rows)
+	}
+}
@@ -0,0 +1,104 @@
+package file
+
+import (
+	"context"
+	"strings"
+
+	"meemo/internal/domain/file/repository"
+)
+
+const (
+	DefaultSearchLimit = 50
+	MaxSearchLimit     = 200
+
+	// searchTextLimit ограничивает объем текста, извлекаемого из содержимого для поиска.
+	searchTextLimit = 64 * 1024
+)
+
+// prefixCapture сохраняет первые limit байт проходящего через него потока.
+type prefixCapture struct {
+	buf   []byte
+	limit int
+}
+
+func newPrefixCapture(limit int) *prefixCapture {
+	return &prefixCapture{limit: limit}
+}
+
+func (p *prefixCapture) Write(b []byte) (int, error) {
+	if room := p.limit - len(p.buf); room > 0 {
+		if len(b) > room {
+			p.buf = append(p.buf, b[:room]...)
+		} else {
+			p.buf = append(p.buf, b...)
+		}
+	}
+	return len(b), nil
+}
+
+func (p *prefixCapture) Bytes() []byte {
+	return p.buf
+}
+
+func isSearchableMimeType(mimeType string) bool {
+	mimeType = strings.ToLower(strings.TrimSpace(strings.SplitN(mimeType, ";", 2)[0]))
+	switch {
+	case strings.HasPrefix(mimeType, "text/"):
+		return true
+	case strings.HasSuffix(mimeType, "+json"), strings.HasSuffix(mimeType, "+xml"):
+		return true
+	}
+
+	switch mimeType {
+	case "application/json", "application/xml", "application/javascript", "application/x-yaml", "application/yaml":
+		return true
+	}
+	return false
+}
+
+// extractSearchText возвращает текст для индекса или пустую строку, если содержимое не текстовое.
+func extractSearchText(mimeType string, content []byte) string {
+	if !isSearchableMimeType(mimeType) {
+		return ""
+	}
+
+	// Обрезанный на границе лимита символ и NUL-байты Postgres не примет в колонку text.
+	text := strings.ToValidUTF8(string(content), "")
+	return strings.ReplaceAll(text, "\x00", "")
+}
+
+func (u *fileUsecase) SearchFiles(ctx context.Context, in *SearchFilesDtoIn) (*SearchFilesDtoOut, error) {
+	query := strings.TrimSpace(in.Query)
+	if query == "" {
+		return nil, ErrInvalidSearchQuery
+	}
+
+	limit := in.Limit
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	results, err := u.fileRepo.Search(ctx, in.UserID, repository.SearchOptions{
+		Query:         query,
+		IncludePublic: in.IncludePublic,
+		Limit:         limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &SearchFilesDtoOut{
+		Results: make([]SearchResultDto, 0, len(results)),
+	}
+	for _, result := range results {
+		out.Results = append(out.Results, SearchResultDto{
+			FileListItemDto: toFileListItem(result.File),
+			OwnerID:         result.File.UserID,
+			Rank:            result.Rank,
+		})
+	}
+	return out, nil
+}
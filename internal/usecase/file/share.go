@@ -0,0 +1,191 @@
+package file
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"meemo/internal/domain/entity"
+
+	"go.uber.org/zap"
+)
+
+// getFileWithPermission возвращает файл, если у пользователя есть право required. Владелец может все,
+// остальные пользователи — то, что выдано им записью ACL; публичный файл доступен на чтение всем.
+func (u *fileUsecase) getFileWithPermission(ctx context.Context, fileID, userID int64, required entity.SharePermission) (*entity.File, error) {
+	metaFile, err := u.fileRepo.Get(ctx, fileID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFileNotFound
+		}
+		return nil, err
+	}
+
+	if metaFile.UserID == userID {
+		return metaFile, nil
+	}
+	if required == entity.PermissionViewer && metaFile.IsPublic {
+		return metaFile, nil
+	}
+
+	permission, err := u.shareRepo.GetPermission(ctx, fileID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAccessDenied
+		}
+		return nil, err
+	}
+	if !permission.Allows(required) {
+		return nil, ErrAccessDenied
+	}
+	return metaFile, nil
+}
+
+func parsePermission(value string) (entity.SharePermission, error) {
+	switch permission := entity.SharePermission(strings.ToLower(value)); permission {
+	case entity.PermissionViewer, entity.PermissionEditor:
+		return permission, nil
+	default:
+		return "", ErrInvalidPermission
+	}
+}
+
+func toFileShareDto(share *entity.FileShare) FileShareDto {
+	return FileShareDto{
+		FileID:     share.FileID,
+		UserID:     share.UserID,
+		Email:      share.UserEmail,
+		Permission: string(share.Permission),
+		CreatedAt:  share.CreatedAt,
+		UpdatedAt:  share.UpdatedAt,
+	}
+}
+
+func (u *fileUsecase) ShareFile(ctx context.Context, in *ShareFileDtoIn) (*FileShareDto, error) {
+	permission, err := parsePermission(in.Permission)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(strings.TrimSpace(in.Email), in.UserEmail) {
+		return nil, ErrShareWithOwner
+	}
+
+	metaFile, err := u.getOwnedFile(ctx, in.UserID, in.FileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNotQuarantined(metaFile); err != nil {
+		return nil, err
+	}
+
+	share, err := u.shareRepo.Upsert(ctx, in.FileID, strings.TrimSpace(in.Email), permission, in.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	u.log.Info("file shared", zap.Int64("fileID", in.FileID), zap.Int64("userID", share.UserID), zap.String("permission", string(share.Permission)))
+	out := toFileShareDto(share)
+	return &out, nil
+}
+
+func (u *fileUsecase) ListFileShares(ctx context.Context, in *ListFileSharesDtoIn) (*ListFileSharesDtoOut, error) {
+	if _, err := u.getOwnedFile(ctx, in.UserID, in.FileID); err != nil {
+		return nil, err
+	}
+
+	shares, err := u.shareRepo.ListByFile(ctx, in.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ListFileSharesDtoOut{FileID: in.FileID, Shares: make([]FileShareDto, 0, len(shares))}
+	for _, share := range shares {
+		out.Shares = append(out.Shares, toFileShareDto(share))
+	}
+	return out, nil
+}
+
+// RevokeFileShare отзывает доступ. Владелец может отозвать любой доступ к своему файлу,
+// получатель — отказаться от выданного ему.
+func (u *fileUsecase) RevokeFileShare(ctx context.Context, in *RevokeFileShareDtoIn) (*RevokeFileShareDtoOut, error) {
+	if in.TargetUserID != in.UserID {
+		if _, err := u.getOwnedFile(ctx, in.UserID, in.FileID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.shareRepo.Delete(ctx, in.FileID, in.TargetUserID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrShareNotFound
+		}
+		return nil, err
+	}
+
+	u.log.Info("file share revoked", zap.Int64("fileID", in.FileID), zap.Int64("userID", in.TargetUserID))
+	return &RevokeFileShareDtoOut{FileID: in.FileID, UserID: in.TargetUserID}, nil
+}
+
+func (u *fileUsecase) ListSharedWithMe(ctx context.Context, in *ListSharedWithMeDtoIn) (*ListSharedWithMeDtoOut, error) {
+	files, err := u.fileRepo.ListSharedWithUser(ctx, in.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ListSharedWithMeDtoOut{Files: make([]SharedFileDto, 0, len(files))}
+	for _, shared := range files {
+		out.Files = append(out.Files, SharedFileDto{
+			FileListItemDto: toFileListItem(shared.File),
+			OwnerID:         shared.File.UserID,
+			OwnerEmail:      shared.OwnerEmail,
+			Permission:      string(shared.Permission),
+		})
+	}
+	return out, nil
+}
+
+func (u *fileUsecase) RenameFileByID(ctx context.Context, in *RenameFileByIDDtoIn) (*RenameFileDtoOut, error) {
+	metaFile, err := u.getFileWithPermission(ctx, in.FileID, in.UserID, entity.PermissionEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.checkFileType(ctx, metaFile.UserID, metaFile.MimeType, in.NewName); err != nil {
+		return nil, err
+	}
+
+	renamedFile, err := u.fileRepo.RenameByID(ctx, metaFile.ID, in.NewName)
+	if err != nil {
+		return nil, err
+	}
+
+	u.log.Info("file renamed", zap.Int64("fileID", renamedFile.ID), zap.Int64("userID", in.UserID), zap.String("newName", renamedFile.OriginalName))
+	return &RenameFileDtoOut{
+		ID:        renamedFile.ID,
+		OldName:   metaFile.OriginalName,
+		NewName:   renamedFile.OriginalName,
+		UpdatedAt: renamedFile.UpdatedAt,
+	}, nil
+}
+
+// DeleteFileByID перемещает файл в корзину его владельца.
+func (u *fileUsecase) DeleteFileByID(ctx context.Context, in *DeleteFileByIDDtoIn) (*DeleteFileDtoOut, error) {
+	metaFile, err := u.getFileWithPermission(ctx, in.FileID, in.UserID, entity.PermissionEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	trashedFile, err := u.fileRepo.Trash(ctx, metaFile.UserID, metaFile.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	u.log.Info("file moved to trash", zap.Int64("fileID", trashedFile.ID), zap.Int64("userID", in.UserID))
+	return &DeleteFileDtoOut{
+		ID:        trashedFile.ID,
+		DeletedAt: trashedFile.DeletedAt,
+	}, nil
+}
@@ -0,0 +1,230 @@
+package file
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"meemo/internal/domain/entity"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	shareLinkTokenBytes = 32
+	// bcrypt учитывает только первые 72 байта пароля, более длинные пароли отклоняются.
+	MaxShareLinkPasswordLen = 72
+)
+
+func newShareLinkToken() (string, error) {
+	buf := make([]byte, shareLinkTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashShareLinkToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func toShareLinkDto(link *entity.ShareLink) ShareLinkDto {
+	return ShareLinkDto{
+		ID:            link.ID,
+		FileID:        link.FileID,
+		ExpiresAt:     link.ExpiresAt,
+		HasPassword:   link.PasswordHash != "",
+		MaxDownloads:  link.MaxDownloads,
+		DownloadCount: link.DownloadCount,
+		RevokedAt:     link.RevokedAt,
+		Active:        shareLinkState(link, time.Now()) == nil,
+		CreatedAt:     link.CreatedAt,
+	}
+}
+
+// shareLinkState возвращает причину, по которой ссылкой уже нельзя воспользоваться, или nil.
+func shareLinkState(link *entity.ShareLink, now time.Time) error {
+	switch {
+	case link.RevokedAt != nil:
+		return ErrShareLinkRevoked
+	case link.ExpiresAt != nil && !now.Before(*link.ExpiresAt):
+		return ErrShareLinkExpired
+	case link.MaxDownloads != nil && link.DownloadCount >= *link.MaxDownloads:
+		return ErrShareLinkExhausted
+	}
+	return nil
+}
+
+func (u *fileUsecase) CreateShareLink(ctx context.Context, in *CreateShareLinkDtoIn) (*CreateShareLinkDtoOut, error) {
+	if in.ExpiresAt != nil && !in.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidShareLink)
+	}
+	if in.MaxDownloads != nil && *in.MaxDownloads <= 0 {
+		return nil, fmt.Errorf("%w: max_downloads must be positive", ErrInvalidShareLink)
+	}
+	if len(in.Password) > MaxShareLinkPasswordLen {
+		return nil, fmt.Errorf("%w: password is too long", ErrInvalidShareLink)
+	}
+
+	metaFile, err := u.getOwnedFile(ctx, in.UserID, in.FileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNotQuarantined(metaFile); err != nil {
+		return nil, err
+	}
+
+	token, err := newShareLinkToken()
+	if err != nil {
+		return nil, err
+	}
+
+	link := &entity.ShareLink{
+		FileID:       in.FileID,
+		UserID:       in.UserID,
+		TokenHash:    hashShareLinkToken(token),
+		ExpiresAt:    in.ExpiresAt,
+		MaxDownloads: in.MaxDownloads,
+	}
+	if in.Password != "" {
+		passwordHash, err := bcrypt.GenerateFromPassword([]byte(in.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		link.PasswordHash = string(passwordHash)
+	}
+
+	created, err := u.shareLinkRepo.CreateLink(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+
+	u.log.Info("share link created", zap.Int64("fileID", created.FileID), zap.Int64("linkID", created.ID))
+	return &CreateShareLinkDtoOut{
+		ShareLinkDto: toShareLinkDto(created),
+		Token:        token,
+	}, nil
+}
+
+func (u *fileUsecase) ListShareLinks(ctx context.Context, in *ListShareLinksDtoIn) (*ListShareLinksDtoOut, error) {
+	links, err := u.shareLinkRepo.ListLinks(ctx, in.UserID, in.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ListShareLinksDtoOut{Links: make([]ShareLinkDto, 0, len(links))}
+	for _, link := range links {
+		out.Links = append(out.Links, toShareLinkDto(link))
+	}
+	return out, nil
+}
+
+func (u *fileUsecase) RevokeShareLink(ctx context.Context, in *RevokeShareLinkDtoIn) (*ShareLinkDto, error) {
+	link, err := u.shareLinkRepo.RevokeLink(ctx, in.UserID, in.LinkID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrShareLinkNotFound
+		}
+		return nil, err
+	}
+
+	u.log.Info("share link revoked", zap.Int64("linkID", link.ID))
+	out := toShareLinkDto(link)
+	return &out, nil
+}
+
+// resolveShareLink проверяет токен, срок действия, лимит скачиваний и пароль ссылки
+// и возвращает файл, на который она указывает.
+func (u *fileUsecase) resolveShareLink(ctx context.Context, in *OpenShareLinkDtoIn) (*entity.ShareLink, *entity.File, error) {
+	link, err := u.shareLinkRepo.GetLinkByTokenHash(ctx, hashShareLinkToken(in.Token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrShareLinkNotFound
+		}
+		return nil, nil, err
+	}
+
+	if err := shareLinkState(link, time.Now()); err != nil {
+		return nil, nil, err
+	}
+
+	if link.PasswordHash != "" {
+		if in.Password == "" {
+			return nil, nil, ErrShareLinkPasswordRequired
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(in.Password)); err != nil {
+			return nil, nil, ErrShareLinkInvalidPassword
+		}
+	}
+
+	metaFile, err := u.fileRepo.Get(ctx, link.FileID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrShareLinkNotFound
+		}
+		return nil, nil, err
+	}
+	if metaFile.Status != entity.Loaded || isExpired(metaFile, time.Now()) {
+		return nil, nil, ErrShareLinkNotFound
+	}
+	return link, metaFile, nil
+}
+
+func toOpenShareLinkDto(link *entity.ShareLink, metaFile *entity.File) *OpenShareLinkDtoOut {
+	return &OpenShareLinkDtoOut{
+		OriginalName:   metaFile.OriginalName,
+		MimeType:       metaFile.MimeType,
+		SizeInBytes:    metaFile.SizeInBytes,
+		ChecksumSHA256: metaFile.ChecksumSHA256,
+		ChecksumCRC32C: metaFile.ChecksumCRC32C,
+		linkID:         link.ID,
+		file:           metaFile,
+	}
+}
+
+// OpenShareLink проверяет ссылку и пароль без учета скачивания, чтобы обработчик мог выставить
+// заголовки ответа. Результат передается в DownloadShareLink, поэтому пароль проверяется один раз.
+func (u *fileUsecase) OpenShareLink(ctx context.Context, in *OpenShareLinkDtoIn) (*OpenShareLinkDtoOut, error) {
+	link, metaFile, err := u.resolveShareLink(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return toOpenShareLinkDto(link, metaFile), nil
+}
+
+// DownloadShareLink засчитывает скачивание по ссылке, открытой через OpenShareLink, и отдает
+// содержимое файла. Скачивание засчитывается до передачи данных: оборванная загрузка тоже
+// расходует лимит.
+func (u *fileUsecase) DownloadShareLink(ctx context.Context, link *OpenShareLinkDtoOut, inWriter io.Writer) error {
+	if inWriter == nil {
+		return errors.New("output writer is nil")
+	}
+	if link == nil || link.file == nil {
+		return errors.New("share link is not opened")
+	}
+
+	claimed, err := u.shareLinkRepo.ClaimDownload(ctx, link.linkID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Ссылку успели отозвать или исчерпать между проверкой и учетом скачивания.
+			return ErrShareLinkExhausted
+		}
+		return err
+	}
+
+	if err := u.readContent(ctx, link.file, inWriter); err != nil {
+		return err
+	}
+
+	u.log.Info("file downloaded by share link", zap.Int64("fileID", link.file.ID), zap.Int64("linkID", claimed.ID), zap.Int("downloadCount", claimed.DownloadCount))
+	return nil
+}
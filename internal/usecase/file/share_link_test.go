@@ -0,0 +1,241 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// downloadByLink открывает ссылку и скачивает файл, как это делает обработчик.
+func (e *testEnv) downloadByLink(token, password string) (string, error) {
+	ctx := context.Background()
+	opened, err := e.OpenShareLink(ctx, &OpenShareLinkDtoIn{Token: token, Password: password})
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := e.DownloadShareLink(ctx, opened, &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func TestCreateShareLink_Validation(t *testing.T) {
+	env := newTestEnv(t, Options{})
+	ctx := context.Background()
+	owner := env.createUser(t, "linkvalidation@test.com")
+	stranger := env.createUser(t, "linkstranger@test.com")
+	fileID := env.uploadFile(t, owner, "doc.txt", []byte("content"), nil)
+
+	past := time.Now().Add(-time.Minute)
+	zero := 0
+	tests := []struct {
+		name     string
+		in       *CreateShareLinkDtoIn
+		expected error
+	}{
+		{"expiry in the past", &CreateShareLinkDtoIn{UserID: owner.ID, FileID: fileID, ExpiresAt: &past}, ErrInvalidShareLink},
+		{"zero downloads", &CreateShareLinkDtoIn{UserID: owner.ID, FileID: fileID, MaxDownloads: &zero}, ErrInvalidShareLink},
+		{"password too long", &CreateShareLinkDtoIn{UserID: owner.ID, FileID: fileID, Password: strings.Repeat("p", MaxShareLinkPasswordLen+1)}, ErrInvalidShareLink},
+		{"not owner", &CreateShareLinkDtoIn{UserID: stranger.ID, FileID: fileID}, ErrFileNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := env.CreateShareLink(ctx, tt.in); !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestShareLink_Password(t *testing.T) {
+	env := newTestEnv(t, Options{})
+	ctx := context.Background()
+	owner := env.createUser(t, "linkpassword@test.com")
+	fileID := env.uploadFile(t, owner, "doc.txt", []byte("secret content"), nil)
+
+	link, err := env.CreateShareLink(ctx, &CreateShareLinkDtoIn{UserID: owner.ID, FileID: fileID, Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("Failed to create link: %v", err)
+	}
+	if !link.HasPassword {
+		t.Errorf("Expected link to report a password")
+	}
+	var stored string
+	if err := env.db.Get(&stored, `SELECT password_hash FROM share_links WHERE id = ?1`, link.ID); err != nil {
+		t.Fatalf("Failed to read link: %v", err)
+	}
+	if stored == "" || strings.Contains(stored, "correct horse") {
+		t.Errorf("Expected password to be stored as a hash, got %q", stored)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		expected error
+	}{
+		{"missing", "", ErrShareLinkPasswordRequired},
+		{"wrong", "battery staple", ErrShareLinkInvalidPassword},
+		{"wrong case", "Correct Horse", ErrShareLinkInvalidPassword},
+		{"correct", "correct horse", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := env.downloadByLink(link.Token, tt.password)
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, err)
+			}
+			if err == nil && content != "secret content" {
+				t.Errorf("Expected file content, got %q", content)
+			}
+		})
+	}
+
+	// Неудачные попытки не расходуют скачивания.
+	links, err := env.ListShareLinks(ctx, &ListShareLinksDtoIn{UserID: owner.ID})
+	if err != nil {
+		t.Fatalf("Failed to list links: %v", err)
+	}
+	if len(links.Links) != 1 || links.Links[0].DownloadCount != 1 {
+		t.Errorf("Expected exactly one counted download, got %+v", links.Links)
+	}
+
+	if _, err := env.downloadByLink("unknown-token", ""); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("Expected unknown token to be rejected, got %v", err)
+	}
+}
+
+func TestShareLink_Expiry(t *testing.T) {
+	env := newTestEnv(t, Options{})
+	ctx := context.Background()
+	owner := env.createUser(t, "linkexpiry@test.com")
+	fileID := env.uploadFile(t, owner, "doc.txt", []byte("content"), nil)
+
+	expiresAt := time.Now().Add(time.Hour)
+	link, err := env.CreateShareLink(ctx, &CreateShareLinkDtoIn{UserID: owner.ID, FileID: fileID, ExpiresAt: &expiresAt})
+	if err != nil {
+		t.Fatalf("Failed to create link: %v", err)
+	}
+	if _, err := env.downloadByLink(link.Token, ""); err != nil {
+		t.Fatalf("Expected link to work before expiry, got %v", err)
+	}
+
+	// Ссылку открыли, а срок истек до того, как началось скачивание.
+	opened, err := env.OpenShareLink(ctx, &OpenShareLinkDtoIn{Token: link.Token})
+	if err != nil {
+		t.Fatalf("Failed to open link: %v", err)
+	}
+	if _, err := env.db.Exec(`UPDATE share_links SET expires_at = ?1 WHERE id = ?2`, time.Now().Add(-time.Minute), link.ID); err != nil {
+		t.Fatalf("Failed to expire link: %v", err)
+	}
+	if err := env.DownloadShareLink(ctx, opened, &bytes.Buffer{}); !errors.Is(err, ErrShareLinkExhausted) {
+		t.Errorf("Expected download of an expired link to be refused, got %v", err)
+	}
+	if _, err := env.downloadByLink(link.Token, ""); !errors.Is(err, ErrShareLinkExpired) {
+		t.Errorf("Expected %v, got %v", ErrShareLinkExpired, err)
+	}
+
+	// Истекший файл недоступен и по действующей ссылке.
+	other := env.uploadFile(t, owner, "other.txt", []byte("content"), nil)
+	otherLink, err := env.CreateShareLink(ctx, &CreateShareLinkDtoIn{UserID: owner.ID, FileID: other})
+	if err != nil {
+		t.Fatalf("Failed to create link: %v", err)
+	}
+	env.expireFile(t, other)
+	if _, err := env.downloadByLink(otherLink.Token, ""); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Errorf("Expected %v, got %v", ErrShareLinkNotFound, err)
+	}
+}
+
+func TestShareLink_DownloadLimit(t *testing.T) {
+	env := newTestEnv(t, Options{})
+	ctx := context.Background()
+	owner := env.createUser(t, "linklimit@test.com")
+	fileID := env.uploadFile(t, owner, "doc.txt", []byte("content"), nil)
+
+	maxDownloads := 2
+	link, err := env.CreateShareLink(ctx, &CreateShareLinkDtoIn{UserID: owner.ID, FileID: fileID, MaxDownloads: &maxDownloads})
+	if err != nil {
+		t.Fatalf("Failed to create link: %v", err)
+	}
+
+	// Оба запроса открыли ссылку, пока оставалось одно скачивание: засчитать его успеет только один.
+	if _, err := env.downloadByLink(link.Token, ""); err != nil {
+		t.Fatalf("First download failed: %v", err)
+	}
+	first, err := env.OpenShareLink(ctx, &OpenShareLinkDtoIn{Token: link.Token})
+	if err != nil {
+		t.Fatalf("Failed to open link: %v", err)
+	}
+	second, err := env.OpenShareLink(ctx, &OpenShareLinkDtoIn{Token: link.Token})
+	if err != nil {
+		t.Fatalf("Failed to open link: %v", err)
+	}
+	var content bytes.Buffer
+	if err := env.DownloadShareLink(ctx, first, &content); err != nil || content.String() != "content" {
+		t.Fatalf("Expected last download to succeed, got %q, %v", content.String(), err)
+	}
+	content.Reset()
+	if err := env.DownloadShareLink(ctx, second, &content); !errors.Is(err, ErrShareLinkExhausted) {
+		t.Errorf("Expected %v, got %v", ErrShareLinkExhausted, err)
+	}
+	if content.Len() != 0 {
+		t.Errorf("Expected no content after the limit, got %q", content.String())
+	}
+
+	if _, err := env.downloadByLink(link.Token, ""); !errors.Is(err, ErrShareLinkExhausted) {
+		t.Errorf("Expected %v, got %v", ErrShareLinkExhausted, err)
+	}
+	links, err := env.ListShareLinks(ctx, &ListShareLinksDtoIn{UserID: owner.ID, FileID: &fileID})
+	if err != nil {
+		t.Fatalf("Failed to list links: %v", err)
+	}
+	if len(links.Links) != 1 || links.Links[0].DownloadCount != maxDownloads || links.Links[0].Active {
+		t.Errorf("Expected an exhausted link with %d downloads, got %+v", maxDownloads, links.Links)
+	}
+}
+
+func TestShareLink_Revoke(t *testing.T) {
+	env := newTestEnv(t, Options{})
+	ctx := context.Background()
+	owner := env.createUser(t, "linkrevoke@test.com")
+	stranger := env.createUser(t, "linkrevokestranger@test.com")
+	fileID := env.uploadFile(t, owner, "doc.txt", []byte("content"), nil)
+
+	link, err := env.CreateShareLink(ctx, &CreateShareLinkDtoIn{UserID: owner.ID, FileID: fileID})
+	if err != nil {
+		t.Fatalf("Failed to create link: %v", err)
+	}
+	if _, err := env.RevokeShareLink(ctx, &RevokeShareLinkDtoIn{UserID: stranger.ID, LinkID: link.ID}); !errors.Is(err, ErrShareLinkNotFound) {
+		t.Fatalf("Expected stranger to be unable to revoke, got %v", err)
+	}
+	if _, err := env.downloadByLink(link.Token, ""); err != nil {
+		t.Fatalf("Expected link to work before revocation, got %v", err)
+	}
+
+	opened, err := env.OpenShareLink(ctx, &OpenShareLinkDtoIn{Token: link.Token})
+	if err != nil {
+		t.Fatalf("Failed to open link: %v", err)
+	}
+	revoked, err := env.RevokeShareLink(ctx, &RevokeShareLinkDtoIn{UserID: owner.ID, LinkID: link.ID})
+	if err != nil {
+		t.Fatalf("Failed to revoke link: %v", err)
+	}
+	if revoked.RevokedAt == nil || revoked.Active {
+		t.Errorf("Expected link to be inactive after revocation, got %+v", revoked)
+	}
+
+	// Отзыв действует и на запрос, который открыл ссылку раньше.
+	if err := env.DownloadShareLink(ctx, opened, &bytes.Buffer{}); !errors.Is(err, ErrShareLinkExhausted) {
+		t.Errorf("Expected download to be refused after revocation, got %v", err)
+	}
+	if _, err := env.downloadByLink(link.Token, ""); !errors.Is(err, ErrShareLinkRevoked) {
+		t.Errorf("Expected %v, got %v", ErrShareLinkRevoked, err)
+	}
+	if err := env.DownloadShareLink(ctx, &OpenShareLinkDtoOut{}, &bytes.Buffer{}); err == nil {
+		t.Errorf("Expected download without opening the link to fail")
+	}
+}
@@ -0,0 +1,128 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"meemo/internal/domain/entity"
+)
+
+func TestGetFileWithPermission(t *testing.T) {
+	env := newTestEnv(t, Options{})
+	ctx := context.Background()
+	owner := env.createUser(t, "aclowner@test.com")
+	viewer := env.createUser(t, "aclviewer@test.com")
+	editor := env.createUser(t, "acleditor@test.com")
+	revoked := env.createUser(t, "aclrevoked@test.com")
+	neighbour := env.createUser(t, "aclneighbour@test.com")
+
+	folder, err := env.CreateFolder(ctx, &CreateFolderDtoIn{UserID: owner.ID, Name: "projects"})
+	if err != nil {
+		t.Fatalf("Failed to create folder: %v", err)
+	}
+	share := func(t *testing.T, fileID int64, user *entity.User, permission string) {
+		t.Helper()
+		if _, err := env.ShareFile(ctx, &ShareFileDtoIn{UserID: owner.ID, UserEmail: owner.Email, FileID: fileID, Email: user.Email, Permission: permission}); err != nil {
+			t.Fatalf("Failed to share file with %s: %v", user.Email, err)
+		}
+	}
+	// Соседний файл в той же папке открыт neighbour: права выдаются на файл и не распространяются
+	// ни на папку, ни на другие файлы в ней.
+	sibling := env.uploadFile(t, owner, "budget.txt", []byte("budget"), &folder.ID)
+	share(t, sibling, neighbour, "editor")
+
+	sharedFile := func(t *testing.T, name string) int64 {
+		t.Helper()
+		fileID := env.uploadFile(t, owner, name, []byte("plan"), &folder.ID)
+		share(t, fileID, viewer, "viewer")
+		share(t, fileID, editor, "editor")
+		share(t, fileID, revoked, "editor")
+		if _, err := env.RevokeFileShare(ctx, &RevokeFileShareDtoIn{UserID: owner.ID, FileID: fileID, TargetUserID: revoked.ID}); err != nil {
+			t.Fatalf("Failed to revoke share: %v", err)
+		}
+		return fileID
+	}
+
+	users := []struct {
+		name    string
+		userID  int64
+		viewErr error
+		editErr error
+	}{
+		{"owner", owner.ID, nil, nil},
+		{"viewer", viewer.ID, nil, ErrAccessDenied},
+		{"editor", editor.ID, nil, nil},
+		{"revoked share", revoked.ID, ErrAccessDenied, ErrAccessDenied},
+		{"share on sibling", neighbour.ID, ErrAccessDenied, ErrAccessDenied},
+		{"anonymous", 0, ErrAccessDenied, ErrAccessDenied},
+	}
+
+	fileID := sharedFile(t, "plan.txt")
+	for _, user := range users {
+		t.Run("permission/"+user.name, func(t *testing.T) {
+			if _, err := env.getFileWithPermission(ctx, fileID, user.userID, entity.PermissionViewer); !errors.Is(err, user.viewErr) {
+				t.Errorf("viewer: expected %v, got %v", user.viewErr, err)
+			}
+			if _, err := env.getFileWithPermission(ctx, fileID, user.userID, entity.PermissionEditor); !errors.Is(err, user.editErr) {
+				t.Errorf("editor: expected %v, got %v", user.editErr, err)
+			}
+		})
+	}
+	if _, err := env.getFileWithPermission(ctx, fileID+1000, owner.ID, entity.PermissionViewer); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("Expected %v for a missing file, got %v", ErrFileNotFound, err)
+	}
+
+	// Публичный файл доступен всем на чтение, но не на изменение.
+	public := env.uploadFile(t, owner, "public.txt", []byte("public"), nil)
+	if _, err := env.ChangeVisibility(ctx, &ChangeVisibilityDtoIn{UserID: owner.ID, UserEmail: owner.Email, OriginalName: "public.txt", IsPublic: true}); err != nil {
+		t.Fatalf("Failed to make file public: %v", err)
+	}
+	if _, err := env.getFileWithPermission(ctx, public, neighbour.ID, entity.PermissionViewer); err != nil {
+		t.Errorf("Expected public file to be readable, got %v", err)
+	}
+	if _, err := env.getFileWithPermission(ctx, public, neighbour.ID, entity.PermissionEditor); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("Expected %v for editing a public file, got %v", ErrAccessDenied, err)
+	}
+
+	// Каждая операция проверяет нужное ей право. Операции меняют файл, поэтому каждой нужен свой.
+	operations := []struct {
+		name   string
+		edit   bool
+		action func(fileID, userID int64) error
+	}{
+		{"download", false, func(fileID, userID int64) error {
+			_, err := env.GetFileByID(ctx, &GetFileByIDDtoIn{FileID: fileID, UserID: userID}, io.Discard)
+			return err
+		}},
+		{"rename", true, func(fileID, userID int64) error {
+			_, err := env.RenameFileByID(ctx, &RenameFileByIDDtoIn{UserID: userID, FileID: fileID, NewName: fmt.Sprintf("renamed-%d.txt", fileID)})
+			return err
+		}},
+		{"upload", true, func(fileID, userID int64) error {
+			_, err := env.SaveFileContent(ctx, &SaveFileContentDtoIn{UserID: userID, ID: fileID, SizeInBytes: 7}, bytes.NewReader([]byte("updated")))
+			return err
+		}},
+		{"delete", true, func(fileID, userID int64) error {
+			_, err := env.DeleteFileByID(ctx, &DeleteFileByIDDtoIn{UserID: userID, FileID: fileID})
+			return err
+		}},
+	}
+	for _, op := range operations {
+		for _, user := range users {
+			t.Run(op.name+"/"+user.name, func(t *testing.T) {
+				fileID := sharedFile(t, op.name+"-"+user.name+".txt")
+				expected := user.viewErr
+				if op.edit {
+					expected = user.editErr
+				}
+				if err := op.action(fileID, user.userID); !errors.Is(err, expected) {
+					t.Errorf("Expected %v, got %v", expected, err)
+				}
+			})
+		}
+	}
+}
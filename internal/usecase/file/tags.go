@@ -0,0 +1,220 @@
+package file
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"meemo/internal/domain/entity"
+
+	"go.uber.org/zap"
+)
+
+const (
+	MaxTagsPerFile      = 50
+	MaxTagLength        = 64
+	MaxMetadataKeys     = 50
+	MaxMetadataKeyLen   = 128
+	MaxMetadataValueLen = 1024
+)
+
+// normalizeTags приводит теги к нижнему регистру и убирает повторы, чтобы "Work" и "work" были одним тегом.
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || utf8.RuneCountInString(tag) > MaxTagLength || strings.ContainsFunc(tag, unicode.IsControl) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTags, tag)
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > MaxTagsPerFile {
+		return nil, fmt.Errorf("%w: at most %d tags per file", ErrInvalidTags, MaxTagsPerFile)
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+func validateMetadata(metadata map[string]string) error {
+	if len(metadata) > MaxMetadataKeys {
+		return fmt.Errorf("%w: at most %d keys per file", ErrInvalidMetadata, MaxMetadataKeys)
+	}
+	for key, value := range metadata {
+		if strings.TrimSpace(key) == "" || utf8.RuneCountInString(key) > MaxMetadataKeyLen {
+			return fmt.Errorf("%w: invalid key %q", ErrInvalidMetadata, key)
+		}
+		if utf8.RuneCountInString(value) > MaxMetadataValueLen {
+			return fmt.Errorf("%w: value of %q is too long", ErrInvalidMetadata, key)
+		}
+		if strings.ContainsRune(key, 0) || strings.ContainsRune(value, 0) {
+			return fmt.Errorf("%w: NUL characters are not allowed", ErrInvalidMetadata)
+		}
+	}
+	return nil
+}
+
+// mergeMetadata повторяет в памяти слияние, которое выполняет UpdateMetadata, чтобы проверить итоговый размер.
+func mergeMetadata(current, set map[string]string, unset []string) map[string]string {
+	merged := make(map[string]string, len(current)+len(set))
+	for key, value := range current {
+		merged[key] = value
+	}
+	for key, value := range set {
+		merged[key] = value
+	}
+	for _, key := range unset {
+		delete(merged, key)
+	}
+	return merged
+}
+
+func toFileTagsDto(file *entity.File) *FileTagsDtoOut {
+	return &FileTagsDtoOut{
+		ID:        file.ID,
+		Tags:      tagsOrEmpty(file.Tags),
+		UpdatedAt: file.UpdatedAt,
+	}
+}
+
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+func metadataOrEmpty(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return map[string]string{}
+	}
+	return metadata
+}
+
+func fileNotFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrFileNotFound
+	}
+	return err
+}
+
+func (u *fileUsecase) AddFileTags(ctx context.Context, in *FileTagsDtoIn) (*FileTagsDtoOut, error) {
+	tags, err := normalizeTags(in.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	metaFile, err := u.getOwnedFile(ctx, in.UserID, in.FileID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := normalizeTags(append(tags, metaFile.Tags...)); err != nil {
+		return nil, err
+	}
+
+	updatedFile, err := u.fileRepo.AddTags(ctx, in.UserID, in.FileID, tags)
+	if err != nil {
+		return nil, fileNotFound(err)
+	}
+	return toFileTagsDto(updatedFile), nil
+}
+
+func (u *fileUsecase) RemoveFileTags(ctx context.Context, in *FileTagsDtoIn) (*FileTagsDtoOut, error) {
+	tags, err := normalizeTags(in.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedFile, err := u.fileRepo.RemoveTags(ctx, in.UserID, in.FileID, tags)
+	if err != nil {
+		return nil, fileNotFound(err)
+	}
+	return toFileTagsDto(updatedFile), nil
+}
+
+func (u *fileUsecase) ReplaceFileTags(ctx context.Context, in *FileTagsDtoIn) (*FileTagsDtoOut, error) {
+	tags, err := normalizeTags(in.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedFile, err := u.fileRepo.ReplaceTags(ctx, in.UserID, in.FileID, tags)
+	if err != nil {
+		return nil, fileNotFound(err)
+	}
+	return toFileTagsDto(updatedFile), nil
+}
+
+func (u *fileUsecase) ListTags(ctx context.Context, in *ListTagsDtoIn) (*ListTagsDtoOut, error) {
+	tags, err := u.fileRepo.ListTags(ctx, in.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ListTagsDtoOut{Tags: make([]TagCountDto, 0, len(tags))}
+	for _, tag := range tags {
+		out.Tags = append(out.Tags, TagCountDto{Tag: tag.Tag, Count: tag.Count})
+	}
+	return out, nil
+}
+
+func (u *fileUsecase) UpdateFileMetadata(ctx context.Context, in *UpdateFileMetadataDtoIn) (*FileMetadataDtoOut, error) {
+	if err := validateMetadata(in.Set); err != nil {
+		return nil, err
+	}
+
+	if !in.Replace {
+		metaFile, err := u.getOwnedFile(ctx, in.UserID, in.FileID)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateMetadata(mergeMetadata(metaFile.Metadata, in.Set, in.Unset)); err != nil {
+			return nil, err
+		}
+	}
+
+	updatedFile, err := u.fileRepo.UpdateMetadata(ctx, in.UserID, in.FileID, in.Set, in.Unset, in.Replace)
+	if err != nil {
+		return nil, fileNotFound(err)
+	}
+
+	u.log.Info("file metadata updated", zap.Int64("fileID", updatedFile.ID), zap.Int("keys", len(updatedFile.Metadata)))
+	return &FileMetadataDtoOut{
+		ID:        updatedFile.ID,
+		Metadata:  metadataOrEmpty(updatedFile.Metadata),
+		UpdatedAt: updatedFile.UpdatedAt,
+	}, nil
+}
+
+// applyTagsAndMetadata добавляет теги и метаданные из запроса к уже существующему файлу,
+// когда повторная загрузка метаданных создает его новую версию.
+func (u *fileUsecase) applyTagsAndMetadata(ctx context.Context, existing *entity.File, tags []string, metadata map[string]string) (*entity.File, error) {
+	var err error
+	if len(tags) > 0 {
+		if _, err = normalizeTags(append(tags, existing.Tags...)); err != nil {
+			return nil, err
+		}
+		if existing, err = u.fileRepo.AddTags(ctx, existing.UserID, existing.ID, tags); err != nil {
+			return nil, err
+		}
+	}
+	if len(metadata) > 0 {
+		if err = validateMetadata(mergeMetadata(existing.Metadata, metadata, nil)); err != nil {
+			return nil, err
+		}
+		if existing, err = u.fileRepo.UpdateMetadata(ctx, existing.UserID, existing.ID, metadata, nil, false); err != nil {
+			return nil, err
+		}
+	}
+	return existing, nil
+}
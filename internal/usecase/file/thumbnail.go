@@ -0,0 +1,275 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	"slices"
+	"strconv"
+
+	"meemo/internal/domain/entity"
+	"meemo/internal/infrastructure/imaging"
+	"meemo/internal/infrastructure/storage/s3/file"
+
+	"go.uber.org/zap"
+)
+
+// ThumbnailOptions задает миниатюры изображений. Для каждого загруженного PNG, JPEG и GIF
+// фоновая задача создает миниатюры всех размеров Sizes во всех форматах Formats.
+type ThumbnailOptions struct {
+	Enabled     bool
+	Sizes       []int
+	Formats     []string
+	JPEGQuality int
+	// Изображения больше MaxSourceBytes байт или MaxSourcePixels пикселей не обрабатываются.
+	MaxSourceBytes  int64
+	MaxSourcePixels int64
+}
+
+// thumbnailSourceTypes — типы изображений, которые умеет декодировать стандартная библиотека.
+var thumbnailSourceTypes = []string{"image/png", "image/jpeg", "image/gif"}
+
+func (o ThumbnailOptions) supports(mimeType string) bool {
+	return slices.Contains(thumbnailSourceTypes, mimeType)
+}
+
+func thumbnailETag(thumbnail *entity.Thumbnail) string {
+	return strconv.Quote(fmt.Sprintf("%d-%d-%d.%s", thumbnail.FileID, thumbnail.VersionID, thumbnail.Size, thumbnail.Format))
+}
+
+// GetThumbnail возвращает миниатюру файла. Пока фоновая задача ее не создала,
+// возвращается заглушка того же размера и формата с признаком Pending.
+func (u *fileUsecase) GetThumbnail(ctx context.Context, in *GetThumbnailDtoIn) (*GetThumbnailDtoOut, error) {
+	opts := u.opts.Thumbnails
+	if !opts.Enabled {
+		return nil, ErrThumbnailsDisabled
+	}
+
+	size, format := in.Size, in.Format
+	if size == 0 {
+		size = opts.Sizes[0]
+	}
+	if format == "" {
+		format = opts.Formats[0]
+	}
+	if !slices.Contains(opts.Sizes, size) {
+		return nil, fmt.Errorf("%w: size must be one of %v", ErrInvalidThumbnail, opts.Sizes)
+	}
+	if !slices.Contains(opts.Formats, format) {
+		return nil, fmt.Errorf("%w: format must be one of %v", ErrInvalidThumbnail, opts.Formats)
+	}
+
+	metaFile, err := u.fileRepo.GetByOriginalNameAndUserEmail(ctx, in.UserEmail, in.OriginalName)
+	if err != nil {
+		return nil, fileNotFound(err)
+	}
+	if err := checkNotQuarantined(metaFile); err != nil {
+		return nil, err
+	}
+	if err := checkNotExpired(metaFile); err != nil {
+		return nil, err
+	}
+	if !opts.supports(metaFile.MimeType) {
+		return nil, ErrThumbnailUnavailable
+	}
+
+	out := &GetThumbnailDtoOut{
+		FileID:      metaFile.ID,
+		Size:        size,
+		Format:      format,
+		ContentType: imaging.ContentType(format),
+	}
+
+	var thumbnail *entity.Thumbnail
+	if metaFile.Status == entity.Loaded && metaFile.CurrentVersionID != nil {
+		thumbnail, err = u.thumbnailRepo.Get(ctx, metaFile.ID, *metaFile.CurrentVersionID, size, format)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+	}
+	if thumbnail == nil {
+		out.Pending = true
+		out.Width, out.Height = size, size
+		out.Content, err = u.thumbnailPlaceholder(size, format)
+		return out, err
+	}
+	if thumbnail.Status == entity.ThumbnailFailed {
+		return nil, ErrThumbnailUnavailable
+	}
+
+	var content bytes.Buffer
+	content.Grow(int(thumbnail.SizeInBytes))
+	if err := u.s3Client.GetObject(ctx, thumbnail.S3Key, &content); err != nil {
+		return nil, err
+	}
+
+	out.Width, out.Height = thumbnail.Width, thumbnail.Height
+	out.ETag = thumbnailETag(thumbnail)
+	out.Content = content.Bytes()
+	return out, nil
+}
+
+func (u *fileUsecase) thumbnailPlaceholder(size int, format string) ([]byte, error) {
+	key := strconv.Itoa(size) + "." + format
+	if cached, ok := u.placeholders.Load(key); ok {
+		return cached.([]byte), nil
+	}
+
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, imaging.Placeholder(size), format, u.opts.Thumbnails.JPEGQuality); err != nil {
+		return nil, err
+	}
+	u.placeholders.Store(key, buf.Bytes())
+	return buf.Bytes(), nil
+}
+
+// GenerateThumbnails удаляет миниатюры замененных версий и удаленных файлов, затем создает
+// недостающие миниатюры для пачки изображений. Ошибка чтения или записи S3 не мешает обработке
+// остальных файлов: такой файл будет обработан при следующем запуске. Изображение, которое
+// не удалось декодировать, помечается неудачным и больше не обрабатывается.
+func (u *fileUsecase) GenerateThumbnails(ctx context.Context, in *GenerateThumbnailsDtoIn) (*GenerateThumbnailsDtoOut, error) {
+	out := &GenerateThumbnailsDtoOut{}
+	opts := u.opts.Thumbnails
+	if !opts.Enabled {
+		return out, nil
+	}
+
+	collected, err := u.collectStaleThumbnails(ctx, in.BatchSize)
+	out.Collected = collected
+	if err != nil {
+		return out, err
+	}
+
+	files, err := u.fileRepo.ListForThumbnails(ctx, thumbnailSourceTypes, len(opts.Sizes)*len(opts.Formats), in.BatchSize)
+	if err != nil {
+		return out, err
+	}
+	for _, metaFile := range files {
+		if err := ctx.Err(); err != nil {
+			return out, err
+		}
+
+		generated, failed, err := u.generateFileThumbnails(ctx, metaFile)
+		out.Generated += generated
+		out.Failed += failed
+		if err != nil {
+			u.log.Warn("failed to generate thumbnails", zap.Int64("fileID", metaFile.ID), zap.Error(err))
+		}
+	}
+
+	if out.Generated > 0 || out.Failed > 0 || out.Collected > 0 {
+		u.log.Info("thumbnails processed", zap.Int("generated", out.Generated), zap.Int("failed", out.Failed), zap.Int("collected", out.Collected))
+	}
+	return out, nil
+}
+
+func (u *fileUsecase) collectStaleThumbnails(ctx context.Context, limit int) (int, error) {
+	stale, err := u.thumbnailRepo.ListStale(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	collected := 0
+	for _, thumbnail := range stale {
+		if thumbnail.S3Key != "" {
+			if err := u.s3Client.DeleteObject(ctx, thumbnail.S3Key); err != nil {
+				u.log.Warn("failed to delete thumbnail object from S3", zap.String("key", thumbnail.S3Key), zap.Error(err))
+				continue
+			}
+		}
+		if err := u.thumbnailRepo.Delete(ctx, thumbnail); err != nil {
+			return collected, err
+		}
+		collected++
+	}
+	return collected, nil
+}
+
+// generateFileThumbnails создает недостающие миниатюры текущей версии файла.
+func (u *fileUsecase) generateFileThumbnails(ctx context.Context, metaFile *entity.File) (int, int, error) {
+	opts := u.opts.Thumbnails
+	versionID := *metaFile.CurrentVersionID
+
+	existing, err := u.thumbnailRepo.ListByVersion(ctx, metaFile.ID, versionID)
+	if err != nil {
+		return 0, 0, err
+	}
+	done := make(map[string]bool, len(existing))
+	for _, thumbnail := range existing {
+		done[strconv.Itoa(thumbnail.Size)+"."+thumbnail.Format] = true
+	}
+
+	var source *imaging.Source
+	if opts.MaxSourceBytes > 0 && metaFile.SizeInBytes > opts.MaxSourceBytes {
+		err = fmt.Errorf("image is larger than %d bytes", opts.MaxSourceBytes)
+	} else {
+		var content bytes.Buffer
+		content.Grow(int(metaFile.SizeInBytes))
+		if err := u.readContent(ctx, metaFile, &content); err != nil {
+			return 0, 0, err
+		}
+		source, err = imaging.Load(content.Bytes(), opts.MaxSourcePixels)
+	}
+
+	generated, failed := 0, 0
+	for _, size := range opts.Sizes {
+		var img *image.NRGBA
+		for _, format := range opts.Formats {
+			if done[strconv.Itoa(size)+"."+format] {
+				continue
+			}
+
+			thumbnail := &entity.Thumbnail{
+				FileID:    metaFile.ID,
+				VersionID: versionID,
+				Size:      size,
+				Format:    format,
+				Status:    entity.ThumbnailFailed,
+			}
+			if source != nil {
+				if img == nil {
+					img = source.Thumbnail(size)
+				}
+				if err := u.storeThumbnail(ctx, thumbnail, img, format); err != nil {
+					return generated, failed, err
+				}
+			}
+
+			if _, err := u.thumbnailRepo.Save(ctx, thumbnail); err != nil {
+				return generated, failed, err
+			}
+			if thumbnail.Status == entity.ThumbnailReady {
+				generated++
+			} else {
+				failed++
+			}
+		}
+	}
+
+	if source == nil {
+		u.log.Warn("image cannot be thumbnailed", zap.Int64("fileID", metaFile.ID), zap.Error(err))
+	}
+	return generated, failed, nil
+}
+
+// storeThumbnail кодирует миниатюру, загружает ее в S3 и заполняет поля готовой записи.
+func (u *fileUsecase) storeThumbnail(ctx context.Context, thumbnail *entity.Thumbnail, img *image.NRGBA, format string) error {
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, format, u.opts.Thumbnails.JPEGQuality); err != nil {
+		return err
+	}
+
+	key := file.ThumbnailKey(thumbnail.FileID, thumbnail.VersionID, thumbnail.Size, format)
+	if err := u.s3Client.PutObject(ctx, key, bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		return err
+	}
+
+	thumbnail.Status = entity.ThumbnailReady
+	thumbnail.S3Key = key
+	thumbnail.Width, thumbnail.Height = img.Rect.Dx(), img.Rect.Dy()
+	thumbnail.SizeInBytes = int64(buf.Len())
+	return nil
+}
@@ -0,0 +1,89 @@
+package file
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func (u *fileUsecase) ListTrash(ctx context.Context, in *ListTrashDtoIn) (*ListTrashDtoOut, error) {
+	files, err := u.fileRepo.ListTrash(ctx, in.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListTrashDtoOut{
+		Files: toFileListItems(files),
+	}, nil
+}
+
+func (u *fileUsecase) RestoreFromTrash(ctx context.Context, in *RestoreFromTrashDtoIn) (*RestoreFromTrashDtoOut, error) {
+	restoredFile, err := u.fileRepo.RestoreFromTrash(ctx, in.UserID, in.FileID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFileNotFound
+		}
+		return nil, err
+	}
+
+	u.log.Info("file restored from trash", zap.Int64("fileID", restoredFile.ID))
+	return &RestoreFromTrashDtoOut{
+		ID:           restoredFile.ID,
+		OriginalName: restoredFile.OriginalName,
+		FolderID:     restoredFile.FolderID,
+		Status:       restoredFile.Status,
+		UpdatedAt:    restoredFile.UpdatedAt,
+	}, nil
+}
+
+func (u *fileUsecase) EmptyTrash(ctx context.Context, in *EmptyTrashDtoIn) (*EmptyTrashDtoOut, error) {
+	files, err := u.fileRepo.ListTrash(ctx, in.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &EmptyTrashDtoOut{}
+	for _, trashedFile := range files {
+		if _, err := u.removeFile(ctx, in.UserID, trashedFile.ID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return out, err
+		}
+		out.Deleted++
+		out.FreedBytes += trashedFile.SizeInBytes
+	}
+
+	u.log.Info("trash emptied", zap.Int64("userID", in.UserID), zap.Int("deleted", out.Deleted))
+	return out, nil
+}
+
+func (u *fileUsecase) PurgeTrash(ctx context.Context, in *PurgeTrashDtoIn) (*PurgeTrashDtoOut, error) {
+	files, err := u.fileRepo.ListExpiredTrash(ctx, time.Now().Add(-in.Retention), in.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &PurgeTrashDtoOut{}
+	for _, trashedFile := range files {
+		if err := ctx.Err(); err != nil {
+			return out, err
+		}
+
+		if _, err := u.removeFile(ctx, trashedFile.UserID, trashedFile.ID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return out, err
+		}
+		out.Purged++
+	}
+
+	if out.Purged > 0 {
+		u.log.Info("expired trash purged", zap.Int("purged", out.Purged))
+	}
+	return out, nil
+}
@@ -0,0 +1,192 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/service"
+)
+
+// Режимы определения MIME-типа по содержимому загружаемого файла.
+const (
+	// MimeDetectionOff сохраняет тип, указанный клиентом.
+	MimeDetectionOff = "off"
+	// MimeDetectionCorrect заменяет тип клиента определенным по содержимому.
+	MimeDetectionCorrect = "correct"
+	// MimeDetectionVerify отклоняет загрузку, если содержимое не совпадает с типом клиента.
+	MimeDetectionVerify = "verify"
+)
+
+// TypeRules — списки разрешенных и запрещенных типов. Элемент списка — MIME-тип,
+// маска вида image/* или расширение с точкой (.exe). Запрет важнее разрешения,
+// пустой список Allow разрешает все, что не запрещено.
+type TypeRules struct {
+	Allow []string
+	Deny  []string
+}
+
+func (r TypeRules) allows(mimeType, name string) bool {
+	for _, rule := range r.Deny {
+		if matchTypeRule(rule, mimeType, name) {
+			return false
+		}
+	}
+	if len(r.Allow) == 0 {
+		return true
+	}
+	for _, rule := range r.Allow {
+		if matchTypeRule(rule, mimeType, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r TypeRules) empty() bool {
+	return len(r.Allow) == 0 && len(r.Deny) == 0
+}
+
+// TypePolicy ограничивает типы загружаемых файлов для всей инсталляции и отдельно
+// для тарифных планов. Файл должен пройти и общие правила, и правила плана владельца.
+type TypePolicy struct {
+	TypeRules
+	Plans map[string]TypeRules
+}
+
+func (p TypePolicy) empty() bool {
+	if !p.TypeRules.empty() {
+		return false
+	}
+	for _, rules := range p.Plans {
+		if !rules.empty() {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateTypeRule проверяет запись списка типов.
+func ValidateTypeRule(rule string) error {
+	switch {
+	case strings.HasPrefix(rule, "."):
+		if len(rule) < 2 || strings.ContainsAny(rule, "/ ") {
+			return fmt.Errorf("invalid extension rule %q", rule)
+		}
+	case strings.HasSuffix(rule, "/*"):
+		if strings.Count(rule, "/") != 1 || len(rule) < 3 {
+			return fmt.Errorf("invalid mime type mask %q", rule)
+		}
+	case service.MediaType(rule) == "":
+		return fmt.Errorf("invalid mime type %q", rule)
+	}
+	return nil
+}
+
+func matchTypeRule(rule, mimeType, name string) bool {
+	rule = strings.ToLower(strings.TrimSpace(rule))
+	switch {
+	case strings.HasPrefix(rule, "."):
+		return strings.ToLower(path.Ext(name)) == rule
+	case strings.HasSuffix(rule, "/*"):
+		return strings.HasPrefix(service.MediaType(mimeType), strings.TrimSuffix(rule, "*"))
+	}
+	return mimeType != "" && service.SameMimeType(rule, mimeType)
+}
+
+// checkFileType проверяет тип и имя файла по политике типов. План владельца
+// читается из базы только тогда, когда для планов заданы правила.
+func (u *fileUsecase) checkFileType(ctx context.Context, ownerID int64, mimeType, name string) error {
+	policy := u.opts.TypePolicy
+	if !policy.allows(mimeType, name) {
+		return fmt.Errorf("%w: %s", ErrTypeNotAllowed, typeDescription(mimeType, name))
+	}
+	if len(policy.Plans) == 0 {
+		return nil
+	}
+
+	plan, err := u.fileRepo.GetUserPlan(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	rules, ok := policy.Plans[plan]
+	if ok && !rules.empty() && !rules.allows(mimeType, name) {
+		return fmt.Errorf("%w for plan %q: %s", ErrTypeNotAllowed, plan, typeDescription(mimeType, name))
+	}
+	return nil
+}
+
+func typeDescription(mimeType, name string) string {
+	if ext := path.Ext(name); ext != "" {
+		return mimeType + " (" + ext + ")"
+	}
+	return mimeType
+}
+
+// contentMimeType возвращает тип загружаемого содержимого с учетом режима MimeDetection.
+// Возвращенный reader нужно читать вместо r.
+func (u *fileUsecase) contentMimeType(target *entity.File, r io.Reader) (string, io.Reader, error) {
+	mode := u.opts.MimeDetection
+	if mode == "" || mode == MimeDetectionOff {
+		return target.MimeType, r, nil
+	}
+
+	detected, r, err := sniffContent(r, target.OriginalName)
+	if err != nil {
+		return "", nil, err
+	}
+	// Клиент, не знающий тип, ничего не утверждает: ему подходит любой определенный тип.
+	if service.MediaType(target.MimeType) == service.DefaultMimeType {
+		return detected, r, nil
+	}
+	if mimeTypesCompatible(target.MimeType, detected) {
+		return target.MimeType, r, nil
+	}
+	if mode == MimeDetectionVerify {
+		return "", nil, fmt.Errorf("%w: declared %s, detected %s", ErrMimeTypeMismatch, target.MimeType, detected)
+	}
+	return detected, r, nil
+}
+
+// mimeTypesCompatible сообщает, что определенный по содержимому тип не противоречит заявленному.
+// Сигнатуры есть не у всех форматов: неопознанные данные и текст без явного формата
+// совместимы с любым двоичным и любым текстовым типом соответственно.
+func mimeTypesCompatible(declared, detected string) bool {
+	switch {
+	case detected == service.DefaultMimeType:
+		return true
+	case detected == "text/plain":
+		return service.IsTextMimeType(declared)
+	}
+	return service.SameMimeType(declared, detected)
+}
+
+// sniffContent определяет тип содержимого по первым байтам и возвращает reader,
+// который отдает содержимое целиком, включая прочитанные байты.
+func sniffContent(r io.Reader, name string) (string, io.Reader, error) {
+	buffered := bufio.NewReaderSize(r, service.SniffLength)
+	head, err := buffered.Peek(service.SniffLength)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return "", nil, err
+	}
+	return service.DetectMimeType(head, name), buffered, nil
+}
+
+// declaredMimeType возвращает тип, указанный клиентом, или тип по расширению имени.
+func declaredMimeType(mimeType, name string) string {
+	if mediaType := service.MediaType(mimeType); mediaType != "" && mediaType != service.DefaultMimeType {
+		return mimeType
+	}
+	if byExtension := service.MimeTypeByExtension(name); byExtension != "" {
+		return byExtension
+	}
+	if mimeType == "" {
+		return service.DefaultMimeType
+	}
+	return mimeType
+}
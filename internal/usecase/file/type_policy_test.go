@@ -0,0 +1,80 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"testing"
+
+	"meemo/internal/domain/file/service"
+)
+
+func TestContentMimeType_DeclaredAndDetected(t *testing.T) {
+	exe := make([]byte, 1024)
+	copy(exe, "MZ")
+	binary.LittleEndian.PutUint32(exe[0x3C:], 0x80)
+	copy(exe[0x80:], "PE\x00\x00")
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	text := []byte("meeting notes\n")
+
+	tests := []struct {
+		name     string
+		mode     string
+		deny     []string
+		file     string
+		declared string
+		content  []byte
+		expected string
+		err      error
+	}{
+		{"executable as jpg is rejected", MimeDetectionVerify, nil, "photo.jpg", "image/jpeg", exe, "", ErrMimeTypeMismatch},
+		{"executable as jpg by extension is rejected", MimeDetectionVerify, nil, "photo.jpg", "", exe, "", ErrMimeTypeMismatch},
+		{"executable as jpg is corrected", MimeDetectionCorrect, nil, "photo.jpg", "image/jpeg", exe, service.MimeTypePE, nil},
+		{"corrected executable hits deny list", MimeDetectionCorrect, []string{service.MimeTypePE}, "photo.jpg", "image/jpeg", exe, "", ErrTypeNotAllowed},
+		{"detection off trusts the client", MimeDetectionOff, nil, "photo.jpg", "image/jpeg", exe, "image/jpeg", nil},
+		{"empty file keeps declared type", MimeDetectionVerify, nil, "photo.jpg", "image/jpeg", []byte{}, "image/jpeg", nil},
+		{"empty file without extension", MimeDetectionVerify, nil, "blank", "", []byte{}, service.DefaultMimeType, nil},
+		{"text without extension is detected", MimeDetectionVerify, nil, "README", "", text, "text/plain", nil},
+		{"text without extension declared as image", MimeDetectionVerify, nil, "README", "image/png", text, "", ErrMimeTypeMismatch},
+		{"text keeps a more specific text type", MimeDetectionVerify, nil, "notes.md", "text/markdown", text, "text/markdown", nil},
+		{"png declared as jpeg is corrected", MimeDetectionCorrect, nil, "image.jpg", "image/jpeg", png, "image/png", nil},
+		{"png declared as jpeg is rejected", MimeDetectionVerify, nil, "image.jpg", "image/jpeg", png, "", ErrMimeTypeMismatch},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, Options{MimeDetection: tt.mode, TypePolicy: TypePolicy{TypeRules: TypeRules{Deny: tt.deny}}})
+			ctx := context.Background()
+			owner := env.createUser(t, fmt.Sprintf("mime%d@test.com", i))
+
+			created, err := env.SaveFileMetadata(ctx, &SaveFileMetadataDtoIn{
+				UserID:       owner.ID,
+				UserEmail:    owner.Email,
+				OriginalName: tt.file,
+				MimeType:     tt.declared,
+				SizeInBytes:  int64(len(tt.content)),
+			})
+			if err != nil {
+				t.Fatalf("Failed to save metadata: %v", err)
+			}
+			_, err = env.SaveFileContent(ctx, &SaveFileContentDtoIn{UserID: owner.ID, ID: created.ID, SizeInBytes: int64(len(tt.content))}, bytes.NewReader(tt.content))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected %v, got %v", tt.err, err)
+			}
+			if err != nil {
+				if keys := env.objectKeys(t); len(keys) != 0 {
+					t.Errorf("Expected rejected content not to be stored, got %v", keys)
+				}
+				return
+			}
+			metaFile, err := env.fileRepo.Get(ctx, created.ID)
+			if err != nil {
+				t.Fatalf("Failed to get file: %v", err)
+			}
+			if service.MediaType(metaFile.MimeType) != tt.expected {
+				t.Errorf("Expected mime type %q, got %q", tt.expected, metaFile.MimeType)
+			}
+		})
+	}
+}
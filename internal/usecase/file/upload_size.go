@@ -0,0 +1,79 @@
+package file
+
+import (
+	"context"
+	"io"
+
+	"meemo/internal/domain/entity"
+)
+
+// checkDeclaredSize проверяет размер, заявленный в метаданных файла.
+func (u *fileUsecase) checkDeclaredSize(size int64) error {
+	if size < 0 {
+		return ErrInvalidSize
+	}
+	if u.opts.MaxFileSize > 0 && size > u.opts.MaxFileSize {
+		return ErrFileTooLarge
+	}
+	return nil
+}
+
+// checkUploadSize проверяет длину загрузки до записи в S3. Пока файл ожидает первое содержимое,
+// его size_in_bytes — размер из SaveFileMetadata, под который уже проверена квота, и загрузка
+// должна ему соответствовать. Размер новой версии заранее не заявляется, поэтому для нее
+// проверяется квота владельца файла.
+func (u *fileUsecase) checkUploadSize(ctx context.Context, target *entity.File, size int64) error {
+	if err := u.checkDeclaredSize(size); err != nil {
+		return err
+	}
+	if target.Status == entity.Pending && target.CurrentVersionID == nil {
+		if size != target.SizeInBytes {
+			return ErrSizeMismatch
+		}
+		return nil
+	}
+
+	ownerEmail, err := u.fileRepo.GetUserEmail(ctx, target.UserID)
+	if err != nil {
+		return err
+	}
+	return u.checkQuota(ctx, ownerEmail, size)
+}
+
+// uploadCounter считает байты загружаемого потока и прерывает чтение с ErrSizeMismatch,
+// как только поток становится длиннее size.
+type uploadCounter struct {
+	r    io.Reader
+	size int64
+	n    int64
+	eof  bool
+}
+
+func newUploadCounter(r io.Reader, size int64) *uploadCounter {
+	return &uploadCounter{r: r, size: size}
+}
+
+func (c *uploadCounter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if c.n > c.size {
+		return n, ErrSizeMismatch
+	}
+	if err == io.EOF {
+		c.eof = true
+	}
+	return n, err
+}
+
+// mismatch сообщает, что поток оказался длиннее или короче size. Клиент S3 может перестать читать,
+// получив size байт, поэтому после успешной записи проверяется, что поток действительно закончился.
+func (c *uploadCounter) mismatch(written bool) bool {
+	if !written {
+		return c.n > c.size || (c.eof && c.n != c.size)
+	}
+	if !c.eof {
+		var probe [1]byte
+		_, _ = io.ReadFull(c, probe[:])
+	}
+	return c.n != c.size
+}
@@ -0,0 +1,203 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"meemo/internal/domain/entity"
+	"meemo/internal/infrastructure/storage/s3/file"
+)
+
+// lostPuts сохраняет объект, но возвращает ошибку, как S3, ответ которого не дошел до клиента.
+// С err == context.Canceled загрузка еще и отменяет контекст запроса, как оборванное соединение.
+type lostPuts struct {
+	file.S3Client
+	err    error
+	cancel context.CancelFunc
+}
+
+func (s *lostPuts) PutObject(ctx context.Context, key string, reader io.Reader, sizeInBytes int64) error {
+	if err := s.S3Client.PutObject(ctx, key, reader, sizeInBytes); err != nil {
+		return err
+	}
+	if errors.Is(s.err, context.Canceled) {
+		s.cancel()
+	}
+	return s.err
+}
+
+func TestSaveFileContent_FailedFirstUpload(t *testing.T) {
+	const declared = 10
+	tests := []struct {
+		name     string
+		size     int64
+		body     []byte
+		checksum string
+		putErr   error
+		expected error
+	}{
+		// Размер части multipart не совпадает с заявленным в метаданных.
+		{"shorter declared part", 6, bytes.Repeat([]byte("a"), 6), "", nil, ErrSizeMismatch},
+		{"longer declared part", 14, bytes.Repeat([]byte("a"), 14), "", nil, ErrSizeMismatch},
+		// Поток не совпадает с размером, с которым его передали.
+		{"shorter stream", declared, bytes.Repeat([]byte("a"), 6), "", nil, ErrSizeMismatch},
+		{"longer stream", declared, bytes.Repeat([]byte("a"), 14), "", nil, ErrSizeMismatch},
+		{"checksum mismatch", declared, bytes.Repeat([]byte("a"), declared), "00", nil, ErrChecksumMismatch},
+		{"store error", declared, bytes.Repeat([]byte("a"), declared), "", errObjectStoreDown, errObjectStoreDown},
+		{"cancelled upload", declared, bytes.Repeat([]byte("a"), declared), "", context.Canceled, context.Canceled},
+		{"exact", declared, bytes.Repeat([]byte("a"), declared), "", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, Options{})
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			owner := env.createUser(t, "uploadsize@test.com")
+			created := env.createFile(t, owner, "notes.txt", declared, nil)
+			if tt.putErr != nil {
+				env.s3Client = &lostPuts{S3Client: env.s3Client, err: tt.putErr, cancel: cancel}
+			}
+
+			_, err := env.SaveFileContent(ctx, &SaveFileContentDtoIn{
+				UserID:         owner.ID,
+				ID:             created.ID,
+				SizeInBytes:    tt.size,
+				ExpectedSHA256: tt.checksum,
+			}, bytes.NewReader(tt.body))
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, err)
+			}
+
+			saved, err := env.fileRepo.Get(context.Background(), created.ID)
+			if err != nil {
+				t.Fatalf("Failed to get file: %v", err)
+			}
+			keys := env.objectKeys(t)
+			if tt.expected == nil {
+				if saved.Status != entity.Loaded || len(keys) != 1 {
+					t.Errorf("Expected loaded file with one object, got status %d and %v", saved.Status, keys)
+				}
+				return
+			}
+			if saved.Status != entity.Pending || saved.CurrentVersionID != nil {
+				t.Errorf("Expected file to stay pending without a version, got status %d", saved.Status)
+			}
+			if len(keys) != 0 {
+				t.Errorf("Expected failed upload to be deleted, got objects %v", keys)
+			}
+		})
+	}
+}
+
+func TestSaveFileContent_MaxFileSize(t *testing.T) {
+	env := newTestEnv(t, Options{MaxFileSize: 8})
+	ctx := context.Background()
+	owner := env.createUser(t, "uploadlimit@test.com")
+
+	_, err := env.SaveFileMetadata(ctx, &SaveFileMetadataDtoIn{
+		UserID:       owner.ID,
+		UserEmail:    owner.Email,
+		OriginalName: "big.bin",
+		SizeInBytes:  9,
+	})
+	if !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("Expected ErrFileTooLarge for declared size, got %v", err)
+	}
+
+	fileID := env.uploadFile(t, owner, "small.bin", []byte("12345678"), nil)
+	_, err = env.SaveFileContent(ctx, &SaveFileContentDtoIn{
+		UserID:      owner.ID,
+		ID:          fileID,
+		SizeInBytes: 9,
+	}, bytes.NewReader([]byte("123456789")))
+	if !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("Expected ErrFileTooLarge for new version, got %v", err)
+	}
+	if keys := env.objectKeys(t); len(keys) != 1 {
+		t.Errorf("Expected only the first version to be stored, got %v", keys)
+	}
+}
+
+func TestSaveFileContent_NewVersionQuota(t *testing.T) {
+	env := newTestEnv(t, Options{})
+	ctx := context.Background()
+	owner := env.createUser(t, "uploadquota@test.com")
+
+	content := []byte("first version")
+	fileID := env.uploadFile(t, owner, "report.txt", content, nil)
+
+	used, err := env.fileRepo.GetTotalUsedSpace(ctx, owner.Email)
+	if err != nil {
+		t.Fatalf("Failed to get used space: %v", err)
+	}
+	// Остается ровно size байт: новая версия такого размера помещается, на байт больше — нет.
+	const size = 16
+	if _, err := env.fileRepo.Create(ctx, &entity.File{
+		UserID:       owner.ID,
+		OriginalName: "filler.bin",
+		MimeType:     "application/octet-stream",
+		SizeInBytes:  MaxStorageBytes - used - size,
+		Status:       entity.Loaded,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}); err != nil {
+		t.Fatalf("Failed to create filler file: %v", err)
+	}
+
+	_, err = env.SaveFileContent(ctx, &SaveFileContentDtoIn{
+		UserID:      owner.ID,
+		ID:          fileID,
+		SizeInBytes: size + 1,
+	}, bytes.NewReader(bytes.Repeat([]byte("b"), size+1)))
+	if !errors.Is(err, ErrInsufficientStorage) {
+		t.Fatalf("Expected ErrInsufficientStorage, got %v", err)
+	}
+	if keys := env.objectKeys(t); len(keys) != 1 {
+		t.Errorf("Expected rejected version not to be stored, got %v", keys)
+	}
+
+	if _, err := env.SaveFileContent(ctx, &SaveFileContentDtoIn{
+		UserID:      owner.ID,
+		ID:          fileID,
+		SizeInBytes: size,
+	}, bytes.NewReader(bytes.Repeat([]byte("b"), size))); err != nil {
+		t.Fatalf("Expected version that fits the quota to be saved, got %v", err)
+	}
+}
+
+func TestUploadCounter_Mismatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		size     int64
+		read     int
+		written  bool
+		expected bool
+	}{
+		{"exact and written", "12345", 5, 5, true, false},
+		{"longer and written", "1234567", 5, 5, true, true},
+		{"shorter at eof", "123", 5, 10, false, true},
+		{"longer while reading", "1234567", 5, 10, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := newUploadCounter(bytes.NewReader([]byte(tt.body)), tt.size)
+			buf := make([]byte, tt.read)
+			// Как клиент S3, читается не больше read байт, а ошибки чтения учитывает mismatch.
+			for total := 0; total < tt.read; {
+				n, err := counter.Read(buf[total:])
+				total += n
+				if err != nil {
+					break
+				}
+			}
+			if got := counter.mismatch(tt.written); got != tt.expected {
+				t.Errorf("Expected mismatch %v, got %v after %d bytes", tt.expected, got, counter.n)
+			}
+		})
+	}
+}
@@ -2,15 +2,19 @@ package file
 
 import (
 	"context"
+	"database/sql"
 	"errors"
-	"fmt"
 	"io"
 	"meemo/internal/domain/entity"
 	"meemo/internal/domain/file/repository"
 	"meemo/internal/domain/file/service"
+	folderrepository "meemo/internal/domain/folder/repository"
+	sharerepository "meemo/internal/domain/share/repository"
+	thumbnailrepository "meemo/internal/domain/thumbnail/repository"
 	"meemo/internal/infrastructure/logger"
 	"meemo/internal/infrastructure/storage/s3/file"
-	"strconv"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -31,32 +35,161 @@ type Usecase interface {
 	ChangeVisibility(ctx context.Context, in *ChangeVisibilityDtoIn) (*ChangeVisibilityDtoOut, error)
 	SetStatus(ctx context.Context, in *SetStatusDtoIn) (*SetStatusDtoOut, error)
 	GetStorageInfo(ctx context.Context, in *GetStorageInfoDtoIn) (*GetStorageInfoDtoOut, error)
+	ScrubChecksums(ctx context.Context, in *ScrubChecksumsDtoIn) (*ScrubChecksumsDtoOut, error)
+	InstantUpload(ctx context.Context, in *InstantUploadDtoIn) (*InstantUploadDtoOut, error)
+	CollectBlobs(ctx context.Context, in *CollectBlobsDtoIn) (*CollectBlobsDtoOut, error)
+	CreateFolder(ctx context.Context, in *CreateFolderDtoIn) (*FolderDto, error)
+	ListFolderChildren(ctx context.Context, in *ListFolderChildrenDtoIn) (*ListFolderChildrenDtoOut, error)
+	RenameFolder(ctx context.Context, in *RenameFolderDtoIn) (*FolderDto, error)
+	MoveFolder(ctx context.Context, in *MoveFolderDtoIn) (*FolderDto, error)
+	DeleteFolder(ctx context.Context, in *DeleteFolderDtoIn) (*DeleteFolderDtoOut, error)
+	GetFolderSize(ctx context.Context, in *GetFolderSizeDtoIn) (*GetFolderSizeDtoOut, error)
+	MoveFile(ctx context.Context, in *MoveFileDtoIn) (*MoveFileDtoOut, error)
+	ListFileVersions(ctx context.Context, in *ListFileVersionsDtoIn) (*ListFileVersionsDtoOut, error)
+	GetFileVersionMetadata(ctx context.Context, in *GetFileVersionDtoIn) (*GetFileVersionDtoOut, error)
+	GetFileVersion(ctx context.Context, in *GetFileVersionDtoIn, inWriter io.Writer) (*GetFileVersionDtoOut, error)
+	RestoreFileVersion(ctx context.Context, in *RestoreFileVersionDtoIn) (*RestoreFileVersionDtoOut, error)
+	PruneFileVersions(ctx context.Context, in *PruneFileVersionsDtoIn) (*PruneFileVersionsDtoOut, error)
+	ListTrash(ctx context.Context, in *ListTrashDtoIn) (*ListTrashDtoOut, error)
+	RestoreFromTrash(ctx context.Context, in *RestoreFromTrashDtoIn) (*RestoreFromTrashDtoOut, error)
+	EmptyTrash(ctx context.Context, in *EmptyTrashDtoIn) (*EmptyTrashDtoOut, error)
+	PurgeTrash(ctx context.Context, in *PurgeTrashDtoIn) (*PurgeTrashDtoOut, error)
+	SearchFiles(ctx context.Context, in *SearchFilesDtoIn) (*SearchFilesDtoOut, error)
+	AddFileTags(ctx context.Context, in *FileTagsDtoIn) (*FileTagsDtoOut, error)
+	RemoveFileTags(ctx context.Context, in *FileTagsDtoIn) (*FileTagsDtoOut, error)
+	ReplaceFileTags(ctx context.Context, in *FileTagsDtoIn) (*FileTagsDtoOut, error)
+	ListTags(ctx context.Context, in *ListTagsDtoIn) (*ListTagsDtoOut, error)
+	UpdateFileMetadata(ctx context.Context, in *UpdateFileMetadataDtoIn) (*FileMetadataDtoOut, error)
+	ShareFile(ctx context.Context, in *ShareFileDtoIn) (*FileShareDto, error)
+	ListFileShares(ctx context.Context, in *ListFileSharesDtoIn) (*ListFileSharesDtoOut, error)
+	RevokeFileShare(ctx context.Context, in *RevokeFileShareDtoIn) (*RevokeFileShareDtoOut, error)
+	ListSharedWithMe(ctx context.Context, in *ListSharedWithMeDtoIn) (*ListSharedWithMeDtoOut, error)
+	RenameFileByID(ctx context.Context, in *RenameFileByIDDtoIn) (*RenameFileDtoOut, error)
+	DeleteFileByID(ctx context.Context, in *DeleteFileByIDDtoIn) (*DeleteFileDtoOut, error)
+	CreateShareLink(ctx context.Context, in *CreateShareLinkDtoIn) (*CreateShareLinkDtoOut, error)
+	ListShareLinks(ctx context.Context, in *ListShareLinksDtoIn) (*ListShareLinksDtoOut, error)
+	RevokeShareLink(ctx context.Context, in *RevokeShareLinkDtoIn) (*ShareLinkDto, error)
+	OpenShareLink(ctx context.Context, in *OpenShareLinkDtoIn) (*OpenShareLinkDtoOut, error)
+	DownloadShareLink(ctx context.Context, link *OpenShareLinkDtoOut, inWriter io.Writer) error
+	BatchFiles(ctx context.Context, in *BatchFilesDtoIn) (*BatchFilesDtoOut, error)
+	PrepareArchive(ctx context.Context, in *PrepareArchiveDtoIn) (*ArchiveDtoOut, error)
+	WriteArchive(ctx context.Context, archive *ArchiveDtoOut, inWriter io.Writer) error
+	ExtractArchive(ctx context.Context, in *ExtractArchiveDtoIn, r io.ReaderAt) (*ExtractArchiveDtoOut, error)
+	CopyFile(ctx context.Context, in *CopyFileDtoIn) (*FileListItemDto, error)
+	TransferFile(ctx context.Context, in *TransferFileDtoIn) (*TransferFileDtoOut, error)
+	GetThumbnail(ctx context.Context, in *GetThumbnailDtoIn) (*GetThumbnailDtoOut, error)
+	GenerateThumbnails(ctx context.Context, in *GenerateThumbnailsDtoIn) (*GenerateThumbnailsDtoOut, error)
+	SetFileExpiry(ctx context.Context, in *SetFileExpiryDtoIn) (*FileExpiryDtoOut, error)
+	ExpireFiles(ctx context.Context, in *ExpireFilesDtoIn) (*ExpireFilesDtoOut, error)
+	CheckStorage(ctx context.Context, in *CheckStorageDtoIn) (*CheckStorageDtoOut, error)
+	ProcessObjectOutbox(ctx context.Context, in *ProcessObjectOutboxDtoIn) (*ProcessObjectOutboxDtoOut, error)
 }
 
+type Options struct {
+	// Deduplication включает хранение содержимого под его SHA-256 с подсчетом ссылок.
+	Deduplication bool
+	// DeleteConcurrency ограничивает число параллельных удалений объектов из S3.
+	DeleteConcurrency int
+	// Compression выбирает кодек, которым сжимается загружаемое содержимое.
+	Compression file.CompressionPolicy
+	// TypePolicy ограничивает типы и расширения загружаемых файлов.
+	TypePolicy TypePolicy
+	// MimeDetection задает, что делать с типом, определенным по содержимому: MimeDetectionOff,
+	// MimeDetectionCorrect или MimeDetectionVerify. Пустое значение равносильно MimeDetectionOff.
+	MimeDetection string
+	// Thumbnails задает миниатюры изображений.
+	Thumbnails ThumbnailOptions
+	// Antivirus задает проверку загружаемого содержимого на вирусы.
+	Antivirus AntivirusOptions
+	// Outbox задает повторы операций с S3, записанных вместе с изменением метаданных.
+	Outbox OutboxOptions
+	// MaxFileSize ограничивает размер одного файла в байтах; 0 — без ограничения.
+	MaxFileSize int64
+}
+
+const DefaultDeleteConcurrency = 8
+
 type fileUsecase struct {
-	fileRepo    repository.FileRepository
-	s3Client    file.S3Client
-	fileService service.FileService
-	log         logger.Logger
+	fileRepo      repository.FileRepository
+	folderRepo    folderrepository.FolderRepository
+	shareRepo     sharerepository.ShareRepository
+	shareLinkRepo sharerepository.ShareLinkRepository
+	thumbnailRepo thumbnailrepository.ThumbnailRepository
+	s3Client      file.S3Client
+	fileService   service.FileService
+	log           logger.Logger
+	opts          Options
+
+	// placeholders хранит закодированные заглушки миниатюр по размеру и формату.
+	placeholders sync.Map
 }
 
-func NewFileUsecase(fileRepo repository.FileRepository, fileService service.FileService, s3Client file.S3Client, log logger.Logger) Usecase {
+func NewFileUsecase(fileRepo repository.FileRepository, folderRepo folderrepository.FolderRepository, shareRepo sharerepository.ShareRepository, shareLinkRepo sharerepository.ShareLinkRepository, thumbnailRepo thumbnailrepository.ThumbnailRepository, fileService service.FileService, s3Client file.S3Client, log logger.Logger, opts Options) Usecase {
+	if opts.DeleteConcurrency <= 0 {
+		opts.DeleteConcurrency = DefaultDeleteConcurrency
+	}
+	if opts.Outbox.MaxAttempts <= 0 {
+		opts.Outbox.MaxAttempts = DefaultOutboxMaxAttempts
+	}
+	if opts.Outbox.RetryDelay <= 0 {
+		opts.Outbox.RetryDelay = DefaultOutboxRetryDelay
+	}
+	if opts.Outbox.Lease <= 0 {
+		opts.Outbox.Lease = DefaultOutboxLease
+	}
 	return &fileUsecase{
-		fileRepo:    fileRepo,
-		s3Client:    s3Client,
-		fileService: fileService,
-		log:         log,
+		fileRepo:      fileRepo,
+		folderRepo:    folderRepo,
+		shareRepo:     shareRepo,
+		shareLinkRepo: shareLinkRepo,
+		thumbnailRepo: thumbnailRepo,
+		s3Client:      s3Client,
+		fileService:   fileService,
+		log:           log,
+		opts:          opts,
 	}
 }
 
+func (u *fileUsecase) checkQuota(ctx context.Context, userEmail string, sizeInBytes int64) error {
+	usedSpace, err := u.fileRepo.GetTotalUsedSpace(ctx, userEmail)
+	if err != nil {
+		return err
+	}
+
+	if usedSpace+sizeInBytes > MaxStorageBytes {
+		return ErrInsufficientStorage
+	}
+	return nil
+}
+
 func (u *fileUsecase) SaveFileMetadata(ctx context.Context, in *SaveFileMetadataDtoIn) (*SaveFileMetadataDtoOut, error) {
-	usedSpace, err := u.fileRepo.GetTotalUsedSpace(ctx, in.UserEmail)
+	if err := u.checkDeclaredSize(in.SizeInBytes); err != nil {
+		return nil, err
+	}
+	if err := u.checkQuota(ctx, in.UserEmail, in.SizeInBytes); err != nil {
+		return nil, err
+	}
+
+	tags, err := normalizeTags(in.Tags)
 	if err != nil {
 		return nil, err
 	}
+	if err := validateMetadata(in.Metadata); err != nil {
+		return nil, err
+	}
+	if err := validateExpiry(in.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	if in.FolderID != nil {
+		if _, err := u.getFolder(ctx, in.UserID, *in.FolderID); err != nil {
+			return nil, err
+		}
+	}
 
-	if usedSpace+in.SizeInBytes > MaxStorageBytes {
-		return nil, ErrInsufficientStorage
+	mimeType := declaredMimeType(in.MimeType, in.OriginalName)
+	if err := u.checkFileType(ctx, in.UserID, mimeType, in.OriginalName); err != nil {
+		return nil, err
 	}
 
 	fileEntity := &entity.File{
@@ -64,16 +197,28 @@ func (u *fileUsecase) SaveFileMetadata(ctx context.Context, in *SaveFileMetadata
 		OriginalName: in.OriginalName,
 		IsPublic:     in.IsPublic,
 		SizeInBytes:  in.SizeInBytes,
-		MimeType:     in.MimeType,
+		MimeType:     mimeType,
+		FolderID:     in.FolderID,
+		Tags:         tags,
+		Metadata:     in.Metadata,
+		ExpiresAt:    in.ExpiresAt,
 	}
 
-	u.fileService.CreateFileMetadata(fileEntity)
-	fileEntity.S3Key = strconv.FormatInt(in.UserID, 10) + "/" + fileEntity.OriginalName
-
-	savedFile, err := u.fileRepo.Save(ctx, in.UserID, fileEntity.OriginalName, fileEntity.MimeType, fileEntity.S3Bucket, fileEntity.S3Key, fileEntity.SizeInBytes, fileEntity.IsPublic)
+	savedFile, created, err := u.findOrCreateFile(ctx, fileEntity)
 	if err != nil {
 		return nil, err
 	}
+	if !created {
+		if savedFile, err = u.applyTagsAndMetadata(ctx, savedFile, tags, in.Metadata); err != nil {
+			return nil, err
+		}
+		// Новая версия меняет срок хранения, только если он указан в запросе.
+		if in.ExpiresAt != nil {
+			if savedFile, err = u.fileRepo.SetExpiry(ctx, savedFile.UserID, savedFile.ID, in.ExpiresAt); err != nil {
+				return nil, err
+			}
+		}
+	}
 
 	return &SaveFileMetadataDtoOut{
 		ID:           savedFile.ID,
@@ -83,23 +228,171 @@ func (u *fileUsecase) SaveFileMetadata(ctx context.Context, in *SaveFileMetadata
 		Status:       savedFile.Status,
 		CreatedAt:    savedFile.CreatedAt,
 		IsPublic:     savedFile.IsPublic,
+		FolderID:     savedFile.FolderID,
+		Tags:         tagsOrEmpty(savedFile.Tags),
+		Metadata:     metadataOrEmpty(savedFile.Metadata),
+		ExpiresAt:    savedFile.ExpiresAt,
+		NewVersion:   !created,
 	}, nil
 }
 
+// findOrCreateFile возвращает существующий файл с тем же именем в той же папке,
+// чтобы загруженное содержимое стало его новой версией, либо создает новый файл.
+func (u *fileUsecase) findOrCreateFile(ctx context.Context, fileEntity *entity.File) (*entity.File, bool, error) {
+	existing, err := u.fileRepo.GetByName(ctx, fileEntity.UserID, fileEntity.FolderID, fileEntity.OriginalName)
+	if err == nil {
+		return existing, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, err
+	}
+
+	u.fileService.CreateFileMetadata(fileEntity)
+
+	savedFile, err := u.fileRepo.Create(ctx, fileEntity)
+	if err != nil {
+		return nil, false, err
+	}
+	return savedFile, true, nil
+}
+
 func (u *fileUsecase) SaveFileContent(ctx context.Context, in *SaveFileContentDtoIn, inReader io.Reader) (*SaveFileContentDtoOut, error) {
 
 	if inReader == nil {
 		return nil, errors.New("input reader is nil")
 	}
 
-	fmt.Println("fileID: ", in.ID, "sizeInBytes:", in.SizeInBytes)
+	u.log.Debug("saving file content", zap.Int64("fileID", in.ID), zap.Int64("sizeInBytes", in.SizeInBytes))
+
+	target, err := u.getFileWithPermission(ctx, in.ID, in.UserID, entity.PermissionEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.putContent(ctx, in, target, inReader)
+}
+
+// putContent загружает содержимое в S3 и сохраняет его как новую текущую версию файла target.
+// Тип содержимого определяется по первым байтам и проверяется политикой типов владельца файла,
+// затем содержимое сжимается, если этого требует политика сжатия. Доступ к файлу должен быть
+// проверен вызывающим.
+func (u *fileUsecase) putContent(ctx context.Context, in *SaveFileContentDtoIn, target *entity.File, inReader io.Reader) (*SaveFileContentDtoOut, error) {
+	if err := u.checkUploadSize(ctx, target, in.SizeInBytes); err != nil {
+		return nil, err
+	}
+
+	mimeType, inReader, err := u.contentMimeType(target, inReader)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkFileType(ctx, target.UserID, mimeType, target.OriginalName); err != nil {
+		return nil, err
+	}
+
+	token, err := newObjectToken()
+	if err != nil {
+		return nil, err
+	}
+
+	uploadKey := file.VersionKey(in.ID, token)
+	if u.opts.Deduplication {
+		uploadKey = file.UploadKey(in.ID, token)
+	}
 
-	if err := u.s3Client.SaveFile(ctx, in.ID, inReader, in.SizeInBytes); err != nil {
+	// Объект под uploadKey остается, только если на него ссылается сохраненная версия. Контекст
+	// запроса к моменту очистки может быть уже отменен: клиент оборвал загрузку.
+	keepUpload := false
+	defer func() {
+		if !keepUpload {
+			u.deleteObjectQuietly(context.WithoutCancel(ctx), uploadKey)
+		}
+	}()
+
+	hasher := newContentHasher()
+	textPrefix := newPrefixCapture(searchTextLimit)
+	writers := []io.Writer{hasher, textPrefix}
+	scan := u.startScan(ctx)
+	if scan != nil {
+		writers = append(writers, scan)
+	}
+	counter := newUploadCounter(inReader, in.SizeInBytes)
+	content := io.TeeReader(counter, io.MultiWriter(writers...))
+
+	encoding := u.opts.Compression.Encoding(mimeType, in.SizeInBytes)
+	storedSize := in.SizeInBytes
+	if encoding == file.EncodingIdentity {
+		err = u.s3Client.PutObject(ctx, uploadKey, content, in.SizeInBytes)
+	} else {
+		encoding, storedSize, err = file.PutObjectCompressed(ctx, u.s3Client, uploadKey, content, in.SizeInBytes, encoding)
+	}
+	if counter.mismatch(err == nil) {
+		scan.abort(ErrSizeMismatch)
+		u.log.Warn("uploaded content size mismatch", zap.Int64("fileID", in.ID), zap.Int64("declared", in.SizeInBytes), zap.Int64("read", counter.n))
+		return nil, ErrSizeMismatch
+	}
+	if err != nil {
+		scan.abort(err)
 		return nil, err
 	}
 
+	sha256Sum, crc32cSum := hasher.SHA256(), hasher.CRC32C()
+	if in.ExpectedSHA256 != "" && !checksumsEqual(in.ExpectedSHA256, sha256Sum) {
+		scan.abort(ErrChecksumMismatch)
+		u.log.Warn("uploaded content checksum mismatch", zap.Int64("fileID", in.ID), zap.String("expected", in.ExpectedSHA256), zap.String("actual", sha256Sum))
+		return nil, ErrChecksumMismatch
+	}
+
+	version := &entity.FileVersion{
+		FileID:            in.ID,
+		S3Key:             uploadKey,
+		SizeInBytes:       counter.n,
+		MimeType:          mimeType,
+		ChecksumSHA256:    sha256Sum,
+		ChecksumCRC32C:    crc32cSum,
+		ContentEncoding:   encoding,
+		StoredSizeInBytes: storedSize,
+	}
+	if in.UserID != 0 {
+		version.CreatedBy = &in.UserID
+	}
+	if scan != nil {
+		result, scanErr := scan.wait()
+		if err := u.applyScanResult(version, result, scanErr); err != nil {
+			return nil, err
+		}
+	}
+
+	var blob *entity.Blob
+	if u.opts.Deduplication {
+		blob, err = u.storeBlob(ctx, uploadKey, version)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	savedFile, err := u.fileRepo.AddVersion(ctx, version, blob)
+	if err != nil {
+		return nil, err
+	}
+	// Объект теперь принадлежит версии. С дедупликацией его уже удалил storeBlob, скопировав в блоб.
+	keepUpload = true
+
+	u.log.Debug("file version saved", zap.Int64("fileID", savedFile.ID), zap.Int64p("versionID", savedFile.CurrentVersionID))
+	if savedFile.Status == entity.Quarantined {
+		u.notifyQuarantined(ctx, savedFile)
+	}
+
+	if err := u.fileRepo.SetSearchText(ctx, savedFile.ID, extractSearchText(savedFile.MimeType, textPrefix.Bytes())); err != nil {
+		u.log.Warn("failed to update file search text", zap.Int64("fileID", savedFile.ID), zap.Error(err))
+	}
+
 	return &SaveFileContentDtoOut{
-		LoadingResult: true,
+		LoadingResult:  true,
+		ChecksumSHA256: sha256Sum,
+		ChecksumCRC32C: crc32cSum,
+		Status:         savedFile.Status,
+		ScanStatus:     savedFile.ScanStatus,
+		ScanSignature:  savedFile.ScanSignature,
 	}, nil
 }
 
@@ -108,12 +401,22 @@ func (u *fileUsecase) GetFileMetadataByName(ctx context.Context, in *GetFileDtoI
 	if err != nil {
 		return nil, err
 	}
+	if err := checkNotQuarantined(metaFile); err != nil {
+		return nil, err
+	}
+	if err := checkNotExpired(metaFile); err != nil {
+		return nil, err
+	}
 
 	return &GetFileDtoOut{
-		ID:           metaFile.ID,
-		OriginalName: metaFile.OriginalName,
-		MimeType:     metaFile.MimeType,
-		SizeInBytes:  metaFile.SizeInBytes,
+		ID:                metaFile.ID,
+		OriginalName:      metaFile.OriginalName,
+		MimeType:          metaFile.MimeType,
+		SizeInBytes:       metaFile.SizeInBytes,
+		ChecksumSHA256:    metaFile.ChecksumSHA256,
+		ChecksumCRC32C:    metaFile.ChecksumCRC32C,
+		ContentEncoding:   metaFile.ContentEncoding,
+		StoredSizeInBytes: metaFile.StoredSizeInBytes,
 	}, nil
 }
 
@@ -126,30 +429,97 @@ func (u *fileUsecase) GetFile(ctx context.Context, in *GetFileDtoIn, inWriter io
 	if err != nil {
 		return nil, err
 	}
+	if err := checkNotQuarantined(metaFile); err != nil {
+		return nil, err
+	}
+	if err := checkNotExpired(metaFile); err != nil {
+		return nil, err
+	}
 
-	if err := u.s3Client.GetFileByID(ctx, metaFile.ID, inWriter); err != nil {
+	if err := u.readContentRange(ctx, metaFile, in.Range, in.KeepEncoding, inWriter); err != nil {
 		return nil, err
 	}
 
 	return &GetFileDtoOut{
-		ID:           metaFile.ID,
-		OriginalName: metaFile.OriginalName,
-		MimeType:     metaFile.MimeType,
-		SizeInBytes:  metaFile.SizeInBytes,
+		ID:                metaFile.ID,
+		OriginalName:      metaFile.OriginalName,
+		MimeType:          metaFile.MimeType,
+		SizeInBytes:       metaFile.SizeInBytes,
+		ChecksumSHA256:    metaFile.ChecksumSHA256,
+		ChecksumCRC32C:    metaFile.ChecksumCRC32C,
+		ContentEncoding:   metaFile.ContentEncoding,
+		StoredSizeInBytes: metaFile.StoredSizeInBytes,
 	}, nil
 }
 
-func (u *fileUsecase) getFileMetadataAndCheckAccess(ctx context.Context, fileID, userID int64) (*entity.File, error) {
-	metaFile, err := u.fileRepo.Get(ctx, fileID)
-	if err != nil {
-		return nil, err
+// readContent пишет в w распакованное содержимое файла.
+func (u *fileUsecase) readContent(ctx context.Context, metaFile *entity.File, w io.Writer) error {
+	return u.readObject(ctx, metaFile.S3Key, metaFile.ContentEncoding, w)
+}
+
+// readContentRange читает часть содержимого файла или все содержимое, если rng равен nil.
+// При keepEncoding содержимое целиком отдается в том виде, в каком хранится, без распаковки.
+func (u *fileUsecase) readContentRange(ctx context.Context, metaFile *entity.File, rng *ByteRange, keepEncoding bool, w io.Writer) error {
+	if rng == nil {
+		if keepEncoding {
+			return u.s3Client.GetObject(ctx, metaFile.S3Key, w)
+		}
+		return u.readContent(ctx, metaFile, w)
+	}
+	if rng.Offset < 0 || rng.Length < 0 || rng.Offset+rng.Length > metaFile.SizeInBytes {
+		return ErrInvalidRange
+	}
+	if metaFile.ContentEncoding == file.EncodingIdentity {
+		return u.s3Client.GetObjectRange(ctx, metaFile.S3Key, rng.Offset, rng.Length, w)
+	}
+
+	// Сжатый поток нельзя читать с середины: он распаковывается с начала до конца диапазона.
+	err := u.readContent(ctx, metaFile, &rangeWriter{w: w, skip: rng.Offset, remaining: rng.Length})
+	if errors.Is(err, errRangeComplete) {
+		return nil
 	}
+	return err
+}
+
+func (u *fileUsecase) readObject(ctx context.Context, key, encoding string, w io.Writer) error {
+	return file.GetObjectDecoded(ctx, u.s3Client, key, encoding, w)
+}
+
+var errRangeComplete = errors.New("range complete")
+
+// rangeWriter пропускает первые skip байт, пишет в w следующие remaining байт и затем
+// возвращает errRangeComplete, чтобы прервать дальнейшее чтение.
+type rangeWriter struct {
+	w         io.Writer
+	skip      int64
+	remaining int64
+}
+
+func (r *rangeWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	skipped := min(r.skip, int64(len(p)))
+	r.skip -= skipped
+	p = p[skipped:]
 
-	if metaFile.UserID != userID && !metaFile.IsPublic {
-		return nil, errors.New("access denied: file is private")
+	p = p[:min(r.remaining, int64(len(p)))]
+	if _, err := r.w.Write(p); err != nil {
+		return 0, err
+	}
+	r.remaining -= int64(len(p))
+	if r.remaining == 0 {
+		return n, errRangeComplete
 	}
+	return n, nil
+}
 
-	return metaFile, nil
+func (u *fileUsecase) deleteObjectQuietly(ctx context.Context, key string) {
+	if err := u.s3Client.DeleteObject(ctx, key); err != nil {
+		u.log.Warn("failed to delete object from S3", zap.String("key", key), zap.Error(err))
+	}
+}
+
+func (u *fileUsecase) getFileMetadataAndCheckAccess(ctx context.Context, fileID, userID int64) (*entity.File, error) {
+	return u.getFileWithPermission(ctx, fileID, userID, entity.PermissionViewer)
 }
 
 func (u *fileUsecase) GetFileMetadataByID(ctx context.Context, in *GetFileByIDDtoIn) (*GetFileByIDDtoOut, error) {
@@ -157,12 +527,22 @@ func (u *fileUsecase) GetFileMetadataByID(ctx context.Context, in *GetFileByIDDt
 	if err != nil {
 		return nil, err
 	}
+	if err := checkNotQuarantined(metaFile); err != nil {
+		return nil, err
+	}
+	if err := checkNotExpired(metaFile); err != nil {
+		return nil, err
+	}
 
 	return &GetFileByIDDtoOut{
-		ID:           metaFile.ID,
-		OriginalName: metaFile.OriginalName,
-		MimeType:     metaFile.MimeType,
-		SizeInBytes:  metaFile.SizeInBytes,
+		ID:                metaFile.ID,
+		OriginalName:      metaFile.OriginalName,
+		MimeType:          metaFile.MimeType,
+		SizeInBytes:       metaFile.SizeInBytes,
+		ChecksumSHA256:    metaFile.ChecksumSHA256,
+		ChecksumCRC32C:    metaFile.ChecksumCRC32C,
+		ContentEncoding:   metaFile.ContentEncoding,
+		StoredSizeInBytes: metaFile.StoredSizeInBytes,
 	}, nil
 }
 
@@ -175,16 +555,26 @@ func (u *fileUsecase) GetFileByID(ctx context.Context, in *GetFileByIDDtoIn, inW
 	if err != nil {
 		return nil, err
 	}
+	if err := checkNotQuarantined(metaFile); err != nil {
+		return nil, err
+	}
+	if err := checkNotExpired(metaFile); err != nil {
+		return nil, err
+	}
 
-	if err := u.s3Client.GetFileByID(ctx, in.FileID, inWriter); err != nil {
+	if err := u.readContentRange(ctx, metaFile, in.Range, in.KeepEncoding, inWriter); err != nil {
 		return nil, err
 	}
 
 	return &GetFileByIDDtoOut{
-		ID:           metaFile.ID,
-		OriginalName: metaFile.OriginalName,
-		MimeType:     metaFile.MimeType,
-		SizeInBytes:  metaFile.SizeInBytes,
+		ID:                metaFile.ID,
+		OriginalName:      metaFile.OriginalName,
+		MimeType:          metaFile.MimeType,
+		SizeInBytes:       metaFile.SizeInBytes,
+		ChecksumSHA256:    metaFile.ChecksumSHA256,
+		ChecksumCRC32C:    metaFile.ChecksumCRC32C,
+		ContentEncoding:   metaFile.ContentEncoding,
+		StoredSizeInBytes: metaFile.StoredSizeInBytes,
 	}, nil
 }
 
@@ -195,15 +585,28 @@ func (u *fileUsecase) GetFileInfo(ctx context.Context, in *GetFileInfoDtoIn) (*G
 	}
 
 	return &GetFileInfoDtoOut{
-		ID:           metaFile.ID,
-		UserID:       metaFile.UserID,
-		OriginalName: metaFile.OriginalName,
-		MimeType:     metaFile.MimeType,
-		SizeInBytes:  metaFile.SizeInBytes,
-		Status:       metaFile.Status,
-		CreatedAt:    metaFile.CreatedAt,
-		UpdatedAt:    metaFile.UpdatedAt,
-		IsPublic:     metaFile.IsPublic,
+		ID:             metaFile.ID,
+		UserID:         metaFile.UserID,
+		OriginalName:   metaFile.OriginalName,
+		MimeType:       metaFile.MimeType,
+		SizeInBytes:    metaFile.SizeInBytes,
+		Status:         metaFile.Status,
+		CreatedAt:      metaFile.CreatedAt,
+		UpdatedAt:      metaFile.UpdatedAt,
+		IsPublic:       metaFile.IsPublic,
+		ChecksumSHA256: metaFile.ChecksumSHA256,
+		ChecksumCRC32C: metaFile.ChecksumCRC32C,
+		Tags:           tagsOrEmpty(metaFile.Tags),
+		Metadata:       metadataOrEmpty(metaFile.Metadata),
+
+		ContentEncoding:   metaFile.ContentEncoding,
+		StoredSizeInBytes: metaFile.StoredSizeInBytes,
+
+		ScanStatus:    metaFile.ScanStatus,
+		ScanSignature: metaFile.ScanSignature,
+		ScannedAt:     metaFile.ScannedAt,
+
+		ExpiresAt: metaFile.ExpiresAt,
 	}, nil
 }
 
@@ -213,21 +616,74 @@ func (u *fileUsecase) DeleteFile(ctx context.Context, in *DeleteFileDtoIn) (*Del
 		return nil, err
 	}
 
-	if err := u.s3Client.DeleteFile(ctx, metaFile.ID); err != nil {
-		u.log.Warn("failed to delete file from S3", zap.Int64("fileID", metaFile.ID), zap.String("name", in.OriginalName), zap.Error(err))
-	}
-
-	deletedFile, err := u.fileRepo.Delete(ctx, in.UserEmail, in.OriginalName)
+	trashedFile, err := u.fileRepo.Trash(ctx, metaFile.UserID, metaFile.ID)
 	if err != nil {
 		return nil, err
 	}
 
+	u.log.Info("file moved to trash", zap.Int64("fileID", trashedFile.ID))
 	return &DeleteFileDtoOut{
-		ID: deletedFile.ID,
+		ID:        trashedFile.ID,
+		DeletedAt: trashedFile.DeletedAt,
 	}, nil
 }
 
+// removeFile удаляет строку файла вместе со всеми версиями и в той же транзакции ставит
+// в очередь удаление их содержимого из S3.
+func (u *fileUsecase) removeFile(ctx context.Context, userID, fileID int64) (*entity.File, error) {
+	var deletedFile *entity.File
+	err := u.commitObjectOperations(ctx, func(repo repository.FileRepository) ([]*entity.ObjectOperation, error) {
+		versions, err := repo.ListVersions(ctx, fileID)
+		if err != nil {
+			return nil, err
+		}
+
+		deletedFile, err = repo.DeleteByID(ctx, userID, fileID)
+		if err != nil {
+			return nil, err
+		}
+		return deleteOperations(objectKeys(deletedFile, versions)), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deletedFile, nil
+}
+
+// objectKeys возвращает ключи S3, которые принадлежат только удаляемому файлу.
+// Содержимое с дедупликацией освобождается вместе со строкой и удаляется сборщиком блобов.
+func objectKeys(deletedFile *entity.File, versions []*entity.FileVersion) []string {
+	seen := make(map[string]struct{}, len(versions)+1)
+	var keys []string
+	add := func(key, blobSHA256 string) {
+		if key == "" || blobSHA256 != "" {
+			return
+		}
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+
+	add(deletedFile.S3Key, deletedFile.BlobSHA256)
+	for _, version := range versions {
+		add(version.S3Key, version.BlobSHA256)
+	}
+	return keys
+}
+
 func (u *fileUsecase) RenameFile(ctx context.Context, in *RenameFileDtoIn) (*RenameFileDtoOut, error) {
+	if !u.opts.TypePolicy.empty() {
+		metaFile, err := u.fileRepo.GetByOriginalNameAndUserEmail(ctx, in.UserEmail, in.OldName)
+		if err != nil {
+			return nil, err
+		}
+		if err := u.checkFileType(ctx, metaFile.UserID, metaFile.MimeType, in.NewName); err != nil {
+			return nil, err
+		}
+	}
+
 	renamedFile, err := u.fileRepo.Rename(ctx, in.UserEmail, in.OldName, in.NewName)
 	if err != nil {
 		u.log.Error("failed to rename file", zap.String("oldName", in.OldName), zap.String("newName", in.NewName), zap.Error(err))
@@ -243,35 +699,36 @@ func (u *fileUsecase) RenameFile(ctx context.Context, in *RenameFileDtoIn) (*Ren
 	}, nil
 }
 
-func (u *fileUsecase) GetUserFilesList(ctx context.Context, in *GetAllUserFilesDtoIn) (*GetAllUserFilesDtoOut, error) {
-	files, err := u.fileRepo.List(ctx, in.UserEmail)
-	if err != nil {
-		return nil, err
-	}
-
+func toFileListItems(files []*entity.File) []FileListItemDto {
 	fileList := make([]FileListItemDto, 0, len(files))
 	for _, file := range files {
-		fileList = append(fileList, FileListItemDto{
-			ID:           file.ID,
-			OriginalName: file.OriginalName,
-			MimeType:     file.MimeType,
-			SizeInBytes:  file.SizeInBytes,
-			Status:       file.Status,
-			CreatedAt:    file.CreatedAt,
-			UpdatedAt:    file.UpdatedAt,
-			IsPublic:     file.IsPublic,
-		})
-	}
-
-	return &GetAllUserFilesDtoOut{
-		Files: fileList,
-	}, nil
+		fileList = append(fileList, toFileListItem(file))
+	}
+	return fileList
+}
+
+func toFileListItem(file *entity.File) FileListItemDto {
+	return FileListItemDto{
+		ID:           file.ID,
+		OriginalName: file.OriginalName,
+		MimeType:     file.MimeType,
+		SizeInBytes:  file.SizeInBytes,
+		Status:       file.Status,
+		CreatedAt:    file.CreatedAt,
+		UpdatedAt:    file.UpdatedAt,
+		IsPublic:     file.IsPublic,
+		FolderID:     file.FolderID,
+		Tags:         tagsOrEmpty(file.Tags),
+		Metadata:     metadataOrEmpty(file.Metadata),
+		DeletedAt:    file.DeletedAt,
+		ExpiresAt:    file.ExpiresAt,
+	}
 }
 
 func (u *fileUsecase) ChangeVisibility(ctx context.Context, in *ChangeVisibilityDtoIn) (*ChangeVisibilityDtoOut, error) {
 	updatedFile, err := u.fileRepo.ChangeVisibility(ctx, in.UserEmail, in.OriginalName, in.IsPublic)
 	if err != nil {
-		return nil, err
+		return nil, u.explainNotUpdated(ctx, in.UserEmail, in.OriginalName, err)
 	}
 
 	return &ChangeVisibilityDtoOut{
@@ -283,9 +740,14 @@ func (u *fileUsecase) ChangeVisibility(ctx context.Context, in *ChangeVisibility
 }
 
 func (u *fileUsecase) SetStatus(ctx context.Context, in *SetStatusDtoIn) (*SetStatusDtoOut, error) {
+	// Карантин назначается только по результату проверки и снимается загрузкой чистой версии.
+	if in.Status == entity.Quarantined {
+		return nil, ErrInvalidStatus
+	}
+
 	updatedFile, err := u.fileRepo.SetStatus(ctx, in.UserEmail, in.OriginalName, in.Status)
 	if err != nil {
-		return nil, err
+		return nil, u.explainNotUpdated(ctx, in.UserEmail, in.OriginalName, err)
 	}
 
 	return &SetStatusDtoOut{
@@ -313,3 +775,37 @@ func (u *fileUsecase) GetStorageInfo(ctx context.Context, in *GetStorageInfoDtoI
 		TotalBytes:     MaxStorageBytes,
 	}, nil
 }
+
+func (u *fileUsecase) ScrubChecksums(ctx context.Context, in *ScrubChecksumsDtoIn) (*ScrubChecksumsDtoOut, error) {
+	files, err := u.fileRepo.ListForScrub(ctx, time.Now().Add(-in.ReverifyAfter), in.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ScrubChecksumsDtoOut{}
+	for _, metaFile := range files {
+		if err := ctx.Err(); err != nil {
+			return out, err
+		}
+
+		hasher := newContentHasher()
+		if err := u.readContent(ctx, metaFile, hasher); err != nil {
+			u.log.Warn("failed to read file for checksum verification", zap.Int64("fileID", metaFile.ID), zap.Error(err))
+			continue
+		}
+
+		failed := !checksumsEqual(metaFile.ChecksumSHA256, hasher.SHA256())
+		if failed {
+			out.Failed++
+			u.log.Error("stored file checksum mismatch", zap.Int64("fileID", metaFile.ID), zap.String("expected", metaFile.ChecksumSHA256), zap.String("actual", hasher.SHA256()))
+		}
+
+		if err := u.fileRepo.MarkChecksumVerified(ctx, metaFile.ID, failed); err != nil {
+			return out, err
+		}
+		out.Checked++
+	}
+
+	u.log.Info("checksum scrub finished", zap.Int("checked", out.Checked), zap.Int("failed", out.Failed))
+	return out, nil
+}
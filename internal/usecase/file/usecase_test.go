@@ -0,0 +1,113 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/service"
+	"meemo/internal/infrastructure/logger"
+	"meemo/internal/infrastructure/storage/s3/file"
+	"meemo/internal/infrastructure/storage/sqlite"
+	sqlitefile "meemo/internal/infrastructure/storage/sqlite/file"
+	sqlitefolder "meemo/internal/infrastructure/storage/sqlite/folder"
+	sqliteshare "meemo/internal/infrastructure/storage/sqlite/share"
+	sqlitethumbnail "meemo/internal/infrastructure/storage/sqlite/thumbnail"
+	sqliteuser "meemo/internal/infrastructure/storage/sqlite/user"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// testEnv — сценарий usecase на временной базе SQLite и каталоге вместо S3.
+type testEnv struct {
+	*fileUsecase
+	db         *sqlx.DB
+	objectsDir string
+}
+
+func newTestEnv(t *testing.T, opts Options) *testEnv {
+	t.Helper()
+
+	dir := t.TempDir()
+	db, err := sqlite.NewSQLiteConnection(&sqlite.SQLiteConfig{Path: filepath.Join(dir, "meemo.db")})
+	if err != nil {
+		t.Fatalf("Failed to open sqlite database: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	log, _ := logger.NewLogger("error")
+	objectsDir := filepath.Join(dir, "objects")
+	objects, err := file.NewFSClient(file.FSOptions{Root: objectsDir}, log)
+	if err != nil {
+		t.Fatalf("Failed to create filesystem storage: %v", err)
+	}
+
+	u := NewFileUsecase(
+		sqlitefile.NewFileRepository(db),
+		sqlitefolder.NewFolderRepository(db),
+		sqliteshare.NewShareRepository(db),
+		sqliteshare.NewShareLinkRepository(db),
+		sqlitethumbnail.NewThumbnailRepository(db),
+		service.NewFileService(),
+		objects,
+		log,
+		opts,
+	)
+	return &testEnv{fileUsecase: u.(*fileUsecase), db: db, objectsDir: objectsDir}
+}
+
+func (e *testEnv) createUser(t *testing.T, email string) *entity.User {
+	t.Helper()
+	user, err := sqliteuser.NewUserRepository(e.db).Create(context.Background(), "Test", "User", email, "password-hash")
+	if err != nil {
+		t.Fatalf("Failed to create user %s: %v", email, err)
+	}
+	return user
+}
+
+// createFile создает метаданные файла без содержимого.
+func (e *testEnv) createFile(t *testing.T, owner *entity.User, name string, size int64, folderID *int64) *SaveFileMetadataDtoOut {
+	t.Helper()
+	out, err := e.SaveFileMetadata(context.Background(), &SaveFileMetadataDtoIn{
+		UserID:       owner.ID,
+		UserEmail:    owner.Email,
+		OriginalName: name,
+		SizeInBytes:  size,
+		FolderID:     folderID,
+	})
+	if err != nil {
+		t.Fatalf("Failed to save metadata of %s: %v", name, err)
+	}
+	return out
+}
+
+// uploadFile создает файл и загружает его содержимое.
+func (e *testEnv) uploadFile(t *testing.T, owner *entity.User, name string, content []byte, folderID *int64) int64 {
+	t.Helper()
+	created := e.createFile(t, owner, name, int64(len(content)), folderID)
+	_, err := e.SaveFileContent(context.Background(), &SaveFileContentDtoIn{
+		UserID:      owner.ID,
+		ID:          created.ID,
+		SizeInBytes: int64(len(content)),
+	}, bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to upload %s: %v", name, err)
+	}
+	return created.ID
+}
+
+// objectKeys возвращает ключи всех объектов в хранилище.
+func (e *testEnv) objectKeys(t *testing.T) []string {
+	t.Helper()
+	var keys []string
+	err := e.s3Client.ListObjects(context.Background(), "", func(object *file.ObjectInfo) error {
+		keys = append(keys, object.Key)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to list objects: %v", err)
+	}
+	return keys
+}
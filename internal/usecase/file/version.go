@@ -0,0 +1,221 @@
+package file
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"time"
+
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+
+	"go.uber.org/zap"
+)
+
+// newObjectToken возвращает случайный суффикс ключа, чтобы версии одного файла не перезаписывали друг друга.
+func newObjectToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// getOwnedFile возвращает файл, только если он принадлежит пользователю.
+func (u *fileUsecase) getOwnedFile(ctx context.Context, userID, fileID int64) (*entity.File, error) {
+	metaFile, err := u.fileRepo.Get(ctx, fileID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFileNotFound
+		}
+		return nil, err
+	}
+
+	if metaFile.UserID != userID {
+		return nil, ErrFileNotFound
+	}
+	return metaFile, nil
+}
+
+func (u *fileUsecase) getVersion(ctx context.Context, fileID int64, versionNumber int) (*entity.FileVersion, error) {
+	version, err := u.fileRepo.GetVersion(ctx, fileID, versionNumber)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, err
+	}
+	return version, nil
+}
+
+func toFileVersionDto(version *entity.FileVersion, currentVersionID *int64) FileVersionDto {
+	return FileVersionDto{
+		VersionNumber:  version.VersionNumber,
+		SizeInBytes:    version.SizeInBytes,
+		MimeType:       version.MimeType,
+		ChecksumSHA256: version.ChecksumSHA256,
+		ChecksumCRC32C: version.ChecksumCRC32C,
+		CreatedBy:      version.CreatedBy,
+		CreatedAt:      version.CreatedAt,
+		IsCurrent:      currentVersionID != nil && *currentVersionID == version.ID,
+	}
+}
+
+// ListFileVersions возвращает историю версий. История и старые версии доступны только владельцу:
+// ими нельзя делиться, иначе по доступу на чтение можно было бы достать содержимое, которое
+// владелец перезаписал, чтобы убрать.
+func (u *fileUsecase) ListFileVersions(ctx context.Context, in *ListFileVersionsDtoIn) (*ListFileVersionsDtoOut, error) {
+	metaFile, err := u.getOwnedFile(ctx, in.UserID, in.FileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNotExpired(metaFile); err != nil {
+		return nil, err
+	}
+
+	versions, err := u.fileRepo.ListVersions(ctx, metaFile.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ListFileVersionsDtoOut{
+		FileID:   metaFile.ID,
+		Versions: make([]FileVersionDto, 0, len(versions)),
+	}
+	for _, version := range versions {
+		out.Versions = append(out.Versions, toFileVersionDto(version, metaFile.CurrentVersionID))
+	}
+	return out, nil
+}
+
+func (u *fileUsecase) GetFileVersionMetadata(ctx context.Context, in *GetFileVersionDtoIn) (*GetFileVersionDtoOut, error) {
+	metaFile, err := u.getOwnedFile(ctx, in.UserID, in.FileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNotExpired(metaFile); err != nil {
+		return nil, err
+	}
+
+	version, err := u.getVersion(ctx, metaFile.ID, in.VersionNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGetFileVersionDtoOut(metaFile, version), nil
+}
+
+func (u *fileUsecase) GetFileVersion(ctx context.Context, in *GetFileVersionDtoIn, inWriter io.Writer) (*GetFileVersionDtoOut, error) {
+	if inWriter == nil {
+		return nil, errors.New("output writer is nil")
+	}
+
+	metaFile, err := u.getOwnedFile(ctx, in.UserID, in.FileID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNotExpired(metaFile); err != nil {
+		return nil, err
+	}
+
+	version, err := u.getVersion(ctx, metaFile.ID, in.VersionNumber)
+	if err != nil {
+		return nil, err
+	}
+	// Чистые версии файла в карантине остаются доступны владельцу, например чтобы восстановить одну из них.
+	if version.ScanStatus == entity.ScanInfected {
+		return nil, ErrFileQuarantined
+	}
+
+	if err := u.readObject(ctx, version.S3Key, version.ContentEncoding, inWriter); err != nil {
+		return nil, err
+	}
+
+	return toGetFileVersionDtoOut(metaFile, version), nil
+}
+
+func toGetFileVersionDtoOut(metaFile *entity.File, version *entity.FileVersion) *GetFileVersionDtoOut {
+	return &GetFileVersionDtoOut{
+		FileID:         metaFile.ID,
+		OriginalName:   metaFile.OriginalName,
+		VersionNumber:  version.VersionNumber,
+		MimeType:       version.MimeType,
+		SizeInBytes:    version.SizeInBytes,
+		ChecksumSHA256: version.ChecksumSHA256,
+		ChecksumCRC32C: version.ChecksumCRC32C,
+	}
+}
+
+func (u *fileUsecase) RestoreFileVersion(ctx context.Context, in *RestoreFileVersionDtoIn) (*RestoreFileVersionDtoOut, error) {
+	metaFile, err := u.getOwnedFile(ctx, in.UserID, in.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	restoredFile, err := u.fileRepo.RestoreVersion(ctx, metaFile.ID, in.VersionNumber)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, err
+	}
+
+	u.log.Info("file version restored", zap.Int64("fileID", restoredFile.ID), zap.Int("version", in.VersionNumber))
+	return &RestoreFileVersionDtoOut{
+		FileID:        restoredFile.ID,
+		VersionNumber: in.VersionNumber,
+		SizeInBytes:   restoredFile.SizeInBytes,
+		UpdatedAt:     restoredFile.UpdatedAt,
+	}, nil
+}
+
+func (u *fileUsecase) PruneFileVersions(ctx context.Context, in *PruneFileVersionsDtoIn) (*PruneFileVersionsDtoOut, error) {
+	if in.Keep < 0 || in.OlderThan < 0 || (in.Keep == 0 && in.OlderThan == 0) {
+		return nil, ErrInvalidPruneCriteria
+	}
+
+	metaFile, err := u.getOwnedFile(ctx, in.UserID, in.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	var createdBefore time.Time
+	if in.OlderThan > 0 {
+		createdBefore = time.Now().Add(-in.OlderThan)
+	}
+
+	var pruned []*entity.FileVersion
+	err = u.commitObjectOperations(ctx, func(repo repository.FileRepository) ([]*entity.ObjectOperation, error) {
+		var err error
+		pruned, err = repo.PruneVersions(ctx, metaFile.ID, in.Keep, createdBefore)
+		if err != nil {
+			return nil, err
+		}
+
+		var keys []string
+		for _, version := range pruned {
+			if version.BlobSHA256 == "" {
+				keys = append(keys, version.S3Key)
+			}
+		}
+		return deleteOperations(keys), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &PruneFileVersionsDtoOut{
+		FileID:          metaFile.ID,
+		DeletedVersions: make([]int, 0, len(pruned)),
+	}
+	for _, version := range pruned {
+		out.DeletedVersions = append(out.DeletedVersions, version.VersionNumber)
+		out.FreedBytes += version.SizeInBytes
+	}
+
+	u.log.Info("file versions pruned", zap.Int64("fileID", metaFile.ID), zap.Int("deleted", len(pruned)))
+	return out, nil
+}
@@ -0,0 +1,79 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestFileVersions_OnlyOwnerReadsHistory(t *testing.T) {
+	env := newTestEnv(t, Options{})
+	ctx := context.Background()
+	owner := env.createUser(t, "versionowner@test.com")
+	viewer := env.createUser(t, "versionviewer@test.com")
+	editor := env.createUser(t, "versioneditor@test.com")
+
+	fileID := env.uploadFile(t, owner, "secret.txt", []byte("password=hunter2"), nil)
+	// Владелец перезаписал файл, чтобы убрать из него пароль.
+	if _, err := env.SaveFileContent(ctx, &SaveFileContentDtoIn{UserID: owner.ID, ID: fileID, SizeInBytes: 9}, bytes.NewReader([]byte("redacted!"))); err != nil {
+		t.Fatalf("Failed to upload new version: %v", err)
+	}
+	if _, err := env.ChangeVisibility(ctx, &ChangeVisibilityDtoIn{UserID: owner.ID, UserEmail: owner.Email, OriginalName: "secret.txt", IsPublic: true}); err != nil {
+		t.Fatalf("Failed to make file public: %v", err)
+	}
+	for _, share := range []struct {
+		user       string
+		permission string
+	}{{viewer.Email, "viewer"}, {editor.Email, "editor"}} {
+		if _, err := env.ShareFile(ctx, &ShareFileDtoIn{UserID: owner.ID, UserEmail: owner.Email, FileID: fileID, Email: share.user, Permission: share.permission}); err != nil {
+			t.Fatalf("Failed to share file: %v", err)
+		}
+	}
+
+	reads := []struct {
+		name string
+		read func(userID int64) error
+	}{
+		{"list", func(userID int64) error {
+			_, err := env.ListFileVersions(ctx, &ListFileVersionsDtoIn{UserID: userID, FileID: fileID})
+			return err
+		}},
+		{"metadata", func(userID int64) error {
+			_, err := env.GetFileVersionMetadata(ctx, &GetFileVersionDtoIn{UserID: userID, FileID: fileID, VersionNumber: 1})
+			return err
+		}},
+		{"content", func(userID int64) error {
+			_, err := env.GetFileVersion(ctx, &GetFileVersionDtoIn{UserID: userID, FileID: fileID, VersionNumber: 1}, io.Discard)
+			return err
+		}},
+	}
+	users := []struct {
+		name     string
+		userID   int64
+		expected error
+	}{
+		{"owner", owner.ID, nil},
+		{"viewer", viewer.ID, ErrFileNotFound},
+		{"editor", editor.ID, ErrFileNotFound},
+		{"anonymous", 0, ErrFileNotFound},
+	}
+	for _, user := range users {
+		for _, read := range reads {
+			t.Run(user.name+"/"+read.name, func(t *testing.T) {
+				if err := read.read(user.userID); !errors.Is(err, user.expected) {
+					t.Errorf("Expected %v, got %v", user.expected, err)
+				}
+			})
+		}
+	}
+
+	var old bytes.Buffer
+	if _, err := env.GetFileVersion(ctx, &GetFileVersionDtoIn{UserID: owner.ID, FileID: fileID, VersionNumber: 1}, &old); err != nil {
+		t.Fatalf("Failed to read old version: %v", err)
+	}
+	if old.String() != "password=hunter2" {
+		t.Errorf("Expected owner to read the first version, got %q", old.String())
+	}
+}
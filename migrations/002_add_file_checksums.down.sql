@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_files_checksum_verified_at;
+
+ALTER TABLE files
+    DROP COLUMN IF EXISTS checksum_failed,
+    DROP COLUMN IF EXISTS checksum_verified_at,
+    DROP COLUMN IF EXISTS checksum_crc32c,
+    DROP COLUMN IF EXISTS checksum_sha256;
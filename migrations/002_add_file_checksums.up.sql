@@ -0,0 +1,7 @@
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS checksum_sha256      VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS checksum_crc32c      VARCHAR(8)  NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS checksum_verified_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS checksum_failed      BOOLEAN     NOT NULL DEFAULT false;
+
+CREATE INDEX idx_files_checksum_verified_at ON files (checksum_verified_at NULLS FIRST);
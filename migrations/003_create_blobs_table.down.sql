@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_files_blob_sha256;
+
+ALTER TABLE files DROP COLUMN IF EXISTS blob_sha256;
+
+DROP INDEX IF EXISTS idx_blobs_unreferenced;
+
+DROP TABLE IF EXISTS blobs;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS blobs
+(
+    sha256        VARCHAR(64) PRIMARY KEY,
+    s3_key        VARCHAR(500) NOT NULL,
+    size_in_bytes BIGINT       NOT NULL,
+    crc32c        VARCHAR(8)   NOT NULL DEFAULT '',
+    ref_count     BIGINT       NOT NULL DEFAULT 0,
+    created_at    TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_blobs_ref_count CHECK (ref_count >= 0)
+);
+
+CREATE INDEX idx_blobs_unreferenced ON blobs (updated_at) WHERE ref_count = 0;
+
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS blob_sha256 VARCHAR(64)
+        CONSTRAINT fk_files_blob REFERENCES blobs (sha256);
+
+CREATE INDEX idx_files_blob_sha256 ON files (blob_sha256);
+
+-- До появления дедупликации содержимое всегда хранилось под ключом, равным ID файла.
+UPDATE files SET s3_key = id::text;
@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS idx_files_folder_id;
+DROP INDEX IF EXISTS idx_files_unique_name;
+
+ALTER TABLE files DROP COLUMN IF EXISTS folder_id;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'unique_user_filename') THEN
+        ALTER TABLE files ADD CONSTRAINT unique_user_filename UNIQUE (user_id, original_name);
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_folders_parent_id;
+DROP INDEX IF EXISTS idx_folders_unique_name;
+
+DROP TABLE IF EXISTS folders;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS folders
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT       NOT NULL,
+    parent_id  BIGINT,
+    name       VARCHAR(500) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_folders_user
+        FOREIGN KEY (user_id)
+            REFERENCES users (id)
+            ON DELETE CASCADE,
+
+    CONSTRAINT fk_folders_parent
+        FOREIGN KEY (parent_id)
+            REFERENCES folders (id)
+            ON DELETE CASCADE,
+
+    CONSTRAINT chk_folders_name CHECK (name <> '' AND position('/' in name) = 0)
+);
+
+CREATE UNIQUE INDEX idx_folders_unique_name ON folders (user_id, COALESCE(parent_id, 0), name);
+CREATE INDEX idx_folders_parent_id ON folders (parent_id);
+
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS folder_id BIGINT
+        CONSTRAINT fk_files_folder REFERENCES folders (id);
+
+-- Уникальность имени файла теперь ограничена родительской папкой.
+ALTER TABLE files DROP CONSTRAINT IF EXISTS unique_user_filename;
+CREATE UNIQUE INDEX idx_files_unique_name ON files (user_id, COALESCE(folder_id, 0), original_name);
+CREATE INDEX idx_files_folder_id ON files (folder_id);
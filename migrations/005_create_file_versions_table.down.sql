@@ -0,0 +1,5 @@
+ALTER TABLE files DROP COLUMN IF EXISTS current_version_id;
+
+DROP INDEX IF EXISTS idx_file_versions_blob_sha256;
+
+DROP TABLE IF EXISTS file_versions;
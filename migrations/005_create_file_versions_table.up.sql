@@ -0,0 +1,52 @@
+CREATE TABLE IF NOT EXISTS file_versions
+(
+    id              BIGSERIAL PRIMARY KEY,
+    file_id         BIGINT       NOT NULL,
+    version_number  INTEGER      NOT NULL,
+    s3_key          VARCHAR(500) NOT NULL,
+    size_in_bytes   BIGINT       NOT NULL,
+    mime_type       VARCHAR(255) NOT NULL,
+    checksum_sha256 VARCHAR(64)  NOT NULL DEFAULT '',
+    checksum_crc32c VARCHAR(8)   NOT NULL DEFAULT '',
+    blob_sha256     VARCHAR(64),
+    created_by      BIGINT,
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_file_versions_file
+        FOREIGN KEY (file_id)
+            REFERENCES files (id)
+            ON DELETE CASCADE,
+
+    CONSTRAINT fk_file_versions_blob
+        FOREIGN KEY (blob_sha256)
+            REFERENCES blobs (sha256),
+
+    CONSTRAINT fk_file_versions_created_by
+        FOREIGN KEY (created_by)
+            REFERENCES users (id)
+            ON DELETE SET NULL,
+
+    CONSTRAINT unique_file_version UNIQUE (file_id, version_number)
+);
+
+CREATE INDEX idx_file_versions_blob_sha256 ON file_versions (blob_sha256);
+
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS current_version_id BIGINT
+        CONSTRAINT fk_files_current_version REFERENCES file_versions (id) ON DELETE SET NULL;
+
+-- Загруженное содержимое существующих файлов становится их первой версией.
+-- Ссылка на блоб переходит от строки файла к версии, поэтому ref_count не меняется.
+INSERT INTO file_versions (file_id, version_number, s3_key, size_in_bytes, mime_type,
+                           checksum_sha256, checksum_crc32c, blob_sha256, created_by, created_at)
+SELECT f.id, 1, f.s3_key, f.size_in_bytes, f.mime_type,
+       f.checksum_sha256, f.checksum_crc32c, f.blob_sha256, f.user_id, f.updated_at
+FROM files f
+WHERE (f.status = 2 OR f.blob_sha256 IS NOT NULL)
+  AND NOT EXISTS (SELECT 1 FROM file_versions v WHERE v.file_id = f.id);
+
+UPDATE files f
+SET current_version_id = v.id
+FROM file_versions v
+WHERE v.file_id = f.id
+  AND f.current_version_id IS NULL;
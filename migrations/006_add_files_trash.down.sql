@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_files_deleted_at;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'files' AND column_name = 'deleted_at') THEN
+        -- Без корзины файлы в ней считаются удаленными, иначе они нарушат уникальность имен.
+        DELETE FROM files WHERE deleted_at IS NOT NULL;
+        DROP INDEX IF EXISTS idx_files_unique_name;
+        CREATE UNIQUE INDEX idx_files_unique_name ON files (user_id, COALESCE(folder_id, 0), original_name);
+    END IF;
+END $$;
+
+ALTER TABLE files DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,8 @@
+ALTER TABLE files ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_files_deleted_at ON files (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Файлы в корзине не занимают имя: на их место можно загрузить новый файл.
+DROP INDEX IF EXISTS idx_files_unique_name;
+CREATE UNIQUE INDEX idx_files_unique_name ON files (user_id, COALESCE(folder_id, 0), original_name)
+    WHERE deleted_at IS NULL;
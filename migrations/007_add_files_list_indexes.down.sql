@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_files_list_mime;
+DROP INDEX IF EXISTS idx_files_list_updated;
+DROP INDEX IF EXISTS idx_files_list_created;
+DROP INDEX IF EXISTS idx_files_list_size;
+DROP INDEX IF EXISTS idx_files_list_name;
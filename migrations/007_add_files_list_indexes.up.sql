@@ -0,0 +1,6 @@
+-- Индексы под постраничный список файлов: сортировка по ключу (колонка, id) внутри пользователя.
+CREATE INDEX IF NOT EXISTS idx_files_list_name ON files (user_id, original_name, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_files_list_size ON files (user_id, size_in_bytes, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_files_list_created ON files (user_id, created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_files_list_updated ON files (user_id, updated_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_files_list_mime ON files (user_id, mime_type text_pattern_ops) WHERE deleted_at IS NULL;
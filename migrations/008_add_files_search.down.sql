@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_files_name_trgm;
+DROP INDEX IF EXISTS idx_files_search_vector;
+
+DROP TRIGGER IF EXISTS trg_files_search_vector ON files;
+
+DROP FUNCTION IF EXISTS files_search_vector_update();
+DROP FUNCTION IF EXISTS files_search_vector(TEXT, TEXT);
+
+ALTER TABLE files DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE files DROP COLUMN IF EXISTS search_text;
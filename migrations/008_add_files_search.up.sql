@@ -0,0 +1,29 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE files ADD COLUMN IF NOT EXISTS search_text TEXT NOT NULL DEFAULT '';
+ALTER TABLE files ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+-- Имя индексируется целиком и по словам, разделенным любыми не буквенно-цифровыми символами,
+-- чтобы "quarterly_report-2024.pdf" находился и по "report", и по "2024".
+CREATE OR REPLACE FUNCTION files_search_vector(name TEXT, body TEXT) RETURNS TSVECTOR AS $$
+SELECT setweight(to_tsvector('simple', coalesce(name, '')), 'A')
+    || setweight(to_tsvector('simple', regexp_replace(coalesce(name, ''), '[^[:alnum:]]+', ' ', 'g')), 'A')
+    || setweight(to_tsvector('simple', coalesce(body, '')), 'C');
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION files_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := files_search_vector(NEW.original_name, NEW.search_text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_files_search_vector ON files;
+CREATE TRIGGER trg_files_search_vector
+    BEFORE INSERT OR UPDATE OF original_name, search_text ON files
+    FOR EACH ROW EXECUTE FUNCTION files_search_vector_update();
+
+UPDATE files SET search_vector = files_search_vector(original_name, search_text) WHERE search_vector IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_files_search_vector ON files USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_files_name_trgm ON files USING GIN (original_name gin_trgm_ops);
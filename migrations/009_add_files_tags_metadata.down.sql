@@ -0,0 +1,28 @@
+CREATE OR REPLACE FUNCTION files_search_vector(name TEXT, body TEXT) RETURNS TSVECTOR AS $$
+SELECT setweight(to_tsvector('simple', coalesce(name, '')), 'A')
+    || setweight(to_tsvector('simple', regexp_replace(coalesce(name, ''), '[^[:alnum:]]+', ' ', 'g')), 'A')
+    || setweight(to_tsvector('simple', coalesce(body, '')), 'C');
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION files_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := files_search_vector(NEW.original_name, NEW.search_text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_files_search_vector ON files;
+CREATE TRIGGER trg_files_search_vector
+    BEFORE INSERT OR UPDATE OF original_name, search_text ON files
+    FOR EACH ROW EXECUTE FUNCTION files_search_vector_update();
+
+DROP FUNCTION IF EXISTS files_search_vector(TEXT, TEXT[], TEXT);
+
+-- Убираем из индекса лексемы тегов, которые остались от удаляемой колонки.
+UPDATE files SET search_vector = files_search_vector(original_name, search_text);
+
+DROP INDEX IF EXISTS idx_files_metadata;
+DROP INDEX IF EXISTS idx_files_tags;
+
+ALTER TABLE files DROP COLUMN IF EXISTS metadata;
+ALTER TABLE files DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,27 @@
+ALTER TABLE files ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE files ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_files_tags ON files USING GIN (tags) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_files_metadata ON files USING GIN (metadata) WHERE deleted_at IS NULL;
+
+-- Теги участвуют в поиске с весом B: ниже имени, но выше извлеченного текста.
+CREATE OR REPLACE FUNCTION files_search_vector(name TEXT, tags TEXT[], body TEXT) RETURNS TSVECTOR AS $$
+SELECT setweight(to_tsvector('simple', coalesce(name, '')), 'A')
+    || setweight(to_tsvector('simple', regexp_replace(coalesce(name, ''), '[^[:alnum:]]+', ' ', 'g')), 'A')
+    || setweight(to_tsvector('simple', array_to_string(coalesce(tags, '{}'), ' ')), 'B')
+    || setweight(to_tsvector('simple', coalesce(body, '')), 'C');
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION files_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := files_search_vector(NEW.original_name, NEW.tags, NEW.search_text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_files_search_vector ON files;
+CREATE TRIGGER trg_files_search_vector
+    BEFORE INSERT OR UPDATE OF original_name, tags, search_text ON files
+    FOR EACH ROW EXECUTE FUNCTION files_search_vector_update();
+
+DROP FUNCTION IF EXISTS files_search_vector(TEXT, TEXT);
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS file_shares
+(
+    id         BIGSERIAL PRIMARY KEY,
+    file_id    BIGINT      NOT NULL,
+    user_id    BIGINT      NOT NULL,
+    permission VARCHAR(16) NOT NULL,
+    created_by BIGINT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_file_shares_file
+        FOREIGN KEY (file_id)
+            REFERENCES files (id)
+            ON DELETE CASCADE,
+
+    CONSTRAINT fk_file_shares_user
+        FOREIGN KEY (user_id)
+            REFERENCES users (id)
+            ON DELETE CASCADE,
+
+    CONSTRAINT fk_file_shares_created_by
+        FOREIGN KEY (created_by)
+            REFERENCES users (id)
+            ON DELETE SET NULL,
+
+    CONSTRAINT unique_file_share UNIQUE (file_id, user_id),
+    CONSTRAINT chk_file_shares_permission CHECK (permission IN ('viewer', 'editor'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_shares_user_id ON file_shares (user_id);
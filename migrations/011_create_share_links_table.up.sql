@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS share_links
+(
+    id             BIGSERIAL PRIMARY KEY,
+    file_id        BIGINT   NOT NULL,
+    user_id        BIGINT   NOT NULL,
+    token_hash     CHAR(64) NOT NULL,
+    password_hash  VARCHAR(255),
+    expires_at     TIMESTAMP WITH TIME ZONE,
+    max_downloads  INTEGER,
+    download_count INTEGER  NOT NULL DEFAULT 0,
+    revoked_at     TIMESTAMP WITH TIME ZONE,
+    created_at     TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_share_links_file
+        FOREIGN KEY (file_id)
+            REFERENCES files (id)
+            ON DELETE CASCADE,
+
+    CONSTRAINT fk_share_links_user
+        FOREIGN KEY (user_id)
+            REFERENCES users (id)
+            ON DELETE CASCADE,
+
+    CONSTRAINT unique_share_link_token UNIQUE (token_hash),
+    CONSTRAINT chk_share_links_max_downloads CHECK (max_downloads IS NULL OR max_downloads > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_links_user_id ON share_links (user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_share_links_file_id ON share_links (file_id);
@@ -0,0 +1,16 @@
+-- Ключи данных зашифрованных объектов S3. Объект без строки в этой таблице хранится открытым текстом.
+CREATE TABLE IF NOT EXISTS object_keys
+(
+    s3_key         VARCHAR(500) PRIMARY KEY,
+    key_id         VARCHAR(255) NOT NULL,
+    wrapped_key    BYTEA        NOT NULL,
+    chunk_size     INTEGER      NOT NULL,
+    plaintext_size BIGINT       NOT NULL,
+    created_at     TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_object_keys_chunk_size CHECK (chunk_size > 0),
+    CONSTRAINT chk_object_keys_plaintext_size CHECK (plaintext_size >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_object_keys_key_id ON object_keys (key_id);
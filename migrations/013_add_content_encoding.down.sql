@@ -0,0 +1,11 @@
+ALTER TABLE blobs
+    DROP COLUMN IF EXISTS stored_size_in_bytes,
+    DROP COLUMN IF EXISTS content_encoding;
+
+ALTER TABLE file_versions
+    DROP COLUMN IF EXISTS stored_size_in_bytes,
+    DROP COLUMN IF EXISTS content_encoding;
+
+ALTER TABLE files
+    DROP COLUMN IF EXISTS stored_size_in_bytes,
+    DROP COLUMN IF EXISTS content_encoding;
@@ -0,0 +1,17 @@
+-- Кодек сжатия содержимого в S3 и размер сохраненного объекта. size_in_bytes остается логическим размером:
+-- по нему считается квота. Пустой content_encoding означает, что объект не сжат.
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS content_encoding     VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS stored_size_in_bytes BIGINT      NOT NULL DEFAULT 0;
+
+ALTER TABLE file_versions
+    ADD COLUMN IF NOT EXISTS content_encoding     VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS stored_size_in_bytes BIGINT      NOT NULL DEFAULT 0;
+
+ALTER TABLE blobs
+    ADD COLUMN IF NOT EXISTS content_encoding     VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS stored_size_in_bytes BIGINT      NOT NULL DEFAULT 0;
+
+UPDATE files SET stored_size_in_bytes = size_in_bytes;
+UPDATE file_versions SET stored_size_in_bytes = size_in_bytes;
+UPDATE blobs SET stored_size_in_bytes = size_in_bytes;
@@ -0,0 +1,3 @@
+-- Тарифный план пользователя. Пустая строка — план по умолчанию без собственных ограничений.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS plan VARCHAR(32) NOT NULL DEFAULT '';
@@ -0,0 +1,19 @@
+-- Миниатюры изображений. Строка относится к версии файла version_id: когда версия перестает быть
+-- текущей или файл удаляется, миниатюры собирает фоновая задача. Внешнего ключа на files нет,
+-- чтобы после удаления файла строки оставались до тех пор, пока не удалены их объекты в S3.
+CREATE TABLE IF NOT EXISTS thumbnails
+(
+    file_id       BIGINT       NOT NULL,
+    version_id    BIGINT       NOT NULL,
+    size          INTEGER      NOT NULL,
+    format        VARCHAR(8)   NOT NULL,
+    status        SMALLINT     NOT NULL,
+    s3_key        VARCHAR(500) NOT NULL DEFAULT '',
+    width         INTEGER      NOT NULL DEFAULT 0,
+    height        INTEGER      NOT NULL DEFAULT 0,
+    size_in_bytes BIGINT       NOT NULL DEFAULT 0,
+    created_at    TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (file_id, version_id, size, format),
+    CONSTRAINT chk_thumbnails_size CHECK (size > 0)
+);
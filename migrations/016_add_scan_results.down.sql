@@ -0,0 +1,12 @@
+-- Статуса Quarantined до миграции не было: такие файлы остаются недоступными как удаленные.
+UPDATE files SET status = 3 WHERE status = 4;
+
+ALTER TABLE files
+    DROP COLUMN IF EXISTS scanned_at,
+    DROP COLUMN IF EXISTS scan_signature,
+    DROP COLUMN IF EXISTS scan_status;
+
+ALTER TABLE file_versions
+    DROP COLUMN IF EXISTS scanned_at,
+    DROP COLUMN IF EXISTS scan_signature,
+    DROP COLUMN IF EXISTS scan_status;
@@ -0,0 +1,11 @@
+-- Результат антивирусной проверки содержимого. Пустой scan_status — версия не проверялась
+-- (загружена до включения проверки). Файл с зараженной текущей версией получает статус 4 (Quarantined).
+ALTER TABLE file_versions
+    ADD COLUMN IF NOT EXISTS scan_status    VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS scan_signature TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS scanned_at     TIMESTAMPTZ;
+
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS scan_status    VARCHAR(16) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS scan_signature TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS scanned_at     TIMESTAMPTZ;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS file_deletions;
+
+DROP INDEX IF EXISTS idx_files_expires_at;
+
+ALTER TABLE files DROP COLUMN IF EXISTS expires_at;
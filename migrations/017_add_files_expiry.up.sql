@@ -0,0 +1,18 @@
+ALTER TABLE files ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_files_expires_at ON files (expires_at) WHERE expires_at IS NOT NULL;
+
+-- Журнал удалений по истечении срока. Внешних ключей нет: записи переживают и файл, и пользователя.
+CREATE TABLE IF NOT EXISTS file_deletions
+(
+    id            BIGSERIAL PRIMARY KEY,
+    file_id       BIGINT       NOT NULL,
+    user_id       BIGINT       NOT NULL,
+    original_name VARCHAR(500) NOT NULL,
+    size_in_bytes BIGINT       NOT NULL,
+    reason        VARCHAR(32)  NOT NULL,
+    expires_at    TIMESTAMP WITH TIME ZONE,
+    deleted_at    TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_file_deletions_user_id ON file_deletions (user_id, deleted_at);
@@ -0,0 +1,16 @@
+-- Изменения в S3, записанные вместе с изменением метаданных. Строка удаляется, когда операция применена;
+-- dead_at заполняется, когда попытки исчерпаны.
+CREATE TABLE IF NOT EXISTS object_outbox
+(
+    id           BIGSERIAL PRIMARY KEY,
+    operation    VARCHAR(16) NOT NULL,
+    s3_key       TEXT        NOT NULL,
+    source_key   TEXT        NOT NULL DEFAULT '',
+    attempts     INTEGER     NOT NULL DEFAULT 0,
+    last_error   TEXT        NOT NULL DEFAULT '',
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    dead_at      TIMESTAMP WITH TIME ZONE,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_object_outbox_available_at ON object_outbox (available_at, id) WHERE dead_at IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE object_keys DROP COLUMN IF EXISTS rewrap_failed_at;
@@ -0,0 +1,3 @@
+-- Время неудачной попытки развернуть ключ данных при ротации мастер-ключа. Такие ключи
+-- пропускаются заданием ротации, пока объект не будет перезаписан.
+ALTER TABLE object_keys ADD COLUMN IF NOT EXISTS rewrap_failed_at TIMESTAMP WITH TIME ZONE;
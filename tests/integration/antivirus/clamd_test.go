@@ -0,0 +1,184 @@
+package antivirus
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"meemo/internal/infrastructure/antivirus"
+	"meemo/internal/infrastructure/antivirus/clamdtest"
+)
+
+func setupScanner(t *testing.T, chunkSize int) (*clamdtest.Server, antivirus.Scanner) {
+	t.Helper()
+
+	server, err := clamdtest.NewServer()
+	if err != nil {
+		t.Fatalf("Failed to start fake clamd: %v", err)
+	}
+	t.Cleanup(func() { _ = server.Close() })
+
+	scanner, err := antivirus.NewClamdScanner(antivirus.ClamdOptions{
+		Address:   server.Address(),
+		ChunkSize: chunkSize,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create scanner: %v", err)
+	}
+	return server, scanner
+}
+
+func TestClamdScanner_Ping(t *testing.T) {
+	_, scanner := setupScanner(t, 0)
+
+	if err := scanner.Ping(t.Context()); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+}
+
+func TestClamdScanner_Scan(t *testing.T) {
+	server, scanner := setupScanner(t, 16)
+
+	t.Run("Clean", func(t *testing.T) {
+		result, err := scanner.Scan(t.Context(), strings.NewReader("just a harmless text file"))
+		if err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		if result.Infected {
+			t.Errorf("Expected clean result, got signature %q", result.Signature)
+		}
+	})
+
+	t.Run("EICAR", func(t *testing.T) {
+		// Строка попадает на границу порций и должна быть найдена в собранном потоке.
+		content := strings.Repeat("a", 10) + clamdtest.EICAR + strings.Repeat("b", 100)
+		result, err := scanner.Scan(t.Context(), strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		if !result.Infected {
+			t.Fatal("Expected infected result")
+		}
+		if result.Signature != clamdtest.EICARSignature {
+			t.Errorf("Expected signature %q, got %q", clamdtest.EICARSignature, result.Signature)
+		}
+	})
+
+	t.Run("CustomSignature", func(t *testing.T) {
+		server.AddSignature("Test.Custom", []byte("malicious payload"))
+		result, err := scanner.Scan(t.Context(), strings.NewReader("header malicious payload footer"))
+		if err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		if !result.Infected || result.Signature != "Test.Custom" {
+			t.Errorf("Expected Test.Custom, got %+v", result)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		result, err := scanner.Scan(t.Context(), bytes.NewReader(nil))
+		if err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		if result.Infected {
+			t.Error("Expected empty content to be clean")
+		}
+	})
+
+	if server.Scans() != 4 {
+		t.Errorf("Expected 4 scans, got %d", server.Scans())
+	}
+}
+
+func TestClamdScanner_Errors(t *testing.T) {
+	t.Run("ServerError", func(t *testing.T) {
+		server, scanner := setupScanner(t, 0)
+		server.Fail.Store(true)
+
+		_, err := scanner.Scan(t.Context(), strings.NewReader("content"))
+		if !errors.Is(err, antivirus.ErrScanFailed) {
+			t.Errorf("Expected ErrScanFailed, got %v", err)
+		}
+	})
+
+	t.Run("SizeLimitExceeded", func(t *testing.T) {
+		server, scanner := setupScanner(t, 1024)
+		server.MaxStreamSize = 4096
+
+		_, err := scanner.Scan(t.Context(), bytes.NewReader(make([]byte, 1<<20)))
+		if !errors.Is(err, antivirus.ErrScanFailed) {
+			t.Errorf("Expected ErrScanFailed, got %v", err)
+		}
+	})
+
+	t.Run("Unavailable", func(t *testing.T) {
+		server, scanner := setupScanner(t, 0)
+		_ = server.Close()
+
+		if err := scanner.Ping(t.Context()); !errors.Is(err, antivirus.ErrScanFailed) {
+			t.Errorf("Expected ErrScanFailed from Ping, got %v", err)
+		}
+		if _, err := scanner.Scan(t.Context(), strings.NewReader("content")); !errors.Is(err, antivirus.ErrScanFailed) {
+			t.Errorf("Expected ErrScanFailed from Scan, got %v", err)
+		}
+	})
+
+	t.Run("ReadError", func(t *testing.T) {
+		_, scanner := setupScanner(t, 0)
+		readErr := errors.New("source is broken")
+
+		_, err := scanner.Scan(t.Context(), &failingReader{err: readErr})
+		if !errors.Is(err, readErr) {
+			t.Errorf("Expected source read error, got %v", err)
+		}
+		if errors.Is(err, antivirus.ErrScanFailed) {
+			t.Error("Source read error must not be reported as ErrScanFailed")
+		}
+	})
+}
+
+func TestParseClamdAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		network string
+		addr    string
+		wantErr bool
+	}{
+		{address: "tcp://localhost:3310", network: "tcp", addr: "localhost:3310"},
+		{address: "clamav:3310", network: "tcp", addr: "clamav:3310"},
+		{address: "unix:///var/run/clamav/clamd.sock", network: "unix", addr: "/var/run/clamav/clamd.sock"},
+		{address: "unix://", wantErr: true},
+		{address: "localhost", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		network, addr, err := antivirus.ParseClamdAddress(tt.address)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: expected error", tt.address)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.address, err)
+			continue
+		}
+		if network != tt.network || addr != tt.addr {
+			t.Errorf("%q: expected %s %s, got %s %s", tt.address, tt.network, tt.addr, network, addr)
+		}
+	}
+}
+
+type failingReader struct {
+	err  error
+	read bool
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if !r.read {
+		r.read = true
+		return copy(p, "partial"), nil
+	}
+	return 0, r.err
+}
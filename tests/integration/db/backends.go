@@ -0,0 +1,123 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	encryptionrepo "meemo/internal/domain/encryption/repository"
+	filerepo "meemo/internal/domain/file/repository"
+	folderrepo "meemo/internal/domain/folder/repository"
+	sharerepo "meemo/internal/domain/share/repository"
+	thumbnailrepo "meemo/internal/domain/thumbnail/repository"
+	userrepo "meemo/internal/domain/user/repository"
+	pgencryption "meemo/internal/infrastructure/storage/pg/encryption"
+	pgfile "meemo/internal/infrastructure/storage/pg/file"
+	pgfolder "meemo/internal/infrastructure/storage/pg/folder"
+	pgshare "meemo/internal/infrastructure/storage/pg/share"
+	pgthumbnail "meemo/internal/infrastructure/storage/pg/thumbnail"
+	pguser "meemo/internal/infrastructure/storage/pg/user"
+	"meemo/internal/infrastructure/storage/sqlite"
+	sqliteencryption "meemo/internal/infrastructure/storage/sqlite/encryption"
+	sqlitefile "meemo/internal/infrastructure/storage/sqlite/file"
+	sqlitefolder "meemo/internal/infrastructure/storage/sqlite/folder"
+	sqliteshare "meemo/internal/infrastructure/storage/sqlite/share"
+	sqlitethumbnail "meemo/internal/infrastructure/storage/sqlite/thumbnail"
+	sqliteuser "meemo/internal/infrastructure/storage/sqlite/user"
+)
+
+// Backend — реализация хранилища метаданных, на которой прогоняются общие тесты репозиториев.
+type Backend struct {
+	Name  string
+	Setup func(t *testing.T) (*sqlx.DB, func())
+
+	Files      func(conn *sqlx.DB) filerepo.FileRepository
+	Users      func(conn *sqlx.DB) userrepo.UserRepository
+	Folders    func(conn *sqlx.DB) folderrepo.FolderRepository
+	Shares     func(conn *sqlx.DB) sharerepo.ShareRepository
+	ShareLinks func(conn *sqlx.DB) sharerepo.ShareLinkRepository
+	Thumbnails func(conn *sqlx.DB) thumbnailrepo.ThumbnailRepository
+	ObjectKeys func(conn *sqlx.DB) encryptionrepo.ObjectKeyRepository
+}
+
+func Backends() []Backend {
+	return []Backend{
+		{
+			Name:       "Postgres",
+			Setup:      setupPostgresBackend,
+			Files:      pgfile.NewFileRepository,
+			Users:      pguser.NewUserRepository,
+			Folders:    pgfolder.NewFolderRepository,
+			Shares:     pgshare.NewShareRepository,
+			ShareLinks: pgshare.NewShareLinkRepository,
+			Thumbnails: pgthumbnail.NewThumbnailRepository,
+			ObjectKeys: pgencryption.NewObjectKeyRepository,
+		},
+		{
+			Name:       "SQLite",
+			Setup:      setupSQLiteBackend,
+			Files:      sqlitefile.NewFileRepository,
+			Users:      sqliteuser.NewUserRepository,
+			Folders:    sqlitefolder.NewFolderRepository,
+			Shares:     sqliteshare.NewShareRepository,
+			ShareLinks: sqliteshare.NewShareLinkRepository,
+			Thumbnails: sqlitethumbnail.NewThumbnailRepository,
+			ObjectKeys: sqliteencryption.NewObjectKeyRepository,
+		},
+	}
+}
+
+// Store — пустая база одной реализации и конструкторы ее репозиториев.
+type Store struct {
+	DB      *sqlx.DB
+	backend Backend
+}
+
+func (s *Store) NewFileRepository() filerepo.FileRepository { return s.backend.Files(s.DB) }
+
+func (s *Store) NewUserRepository() userrepo.UserRepository { return s.backend.Users(s.DB) }
+
+func (s *Store) NewFolderRepository() folderrepo.FolderRepository { return s.backend.Folders(s.DB) }
+
+func (s *Store) NewShareRepository() sharerepo.ShareRepository { return s.backend.Shares(s.DB) }
+
+func (s *Store) NewShareLinkRepository() sharerepo.ShareLinkRepository {
+	return s.backend.ShareLinks(s.DB)
+}
+
+func (s *Store) NewThumbnailRepository() thumbnailrepo.ThumbnailRepository {
+	return s.backend.Thumbnails(s.DB)
+}
+
+func (s *Store) NewObjectKeyRepository() encryptionrepo.ObjectKeyRepository {
+	return s.backend.ObjectKeys(s.DB)
+}
+
+// ForEachBackend запускает fn подтестом для каждой реализации с отдельной пустой базой.
+func ForEachBackend(t *testing.T, fn func(t *testing.T, store *Store)) {
+	for _, backend := range Backends() {
+		t.Run(backend.Name, func(t *testing.T) {
+			db, cleanup := backend.Setup(t)
+			t.Cleanup(cleanup)
+			fn(t, &Store{DB: db, backend: backend})
+		})
+	}
+}
+
+func setupPostgresBackend(t *testing.T) (*sqlx.DB, func()) {
+	tc, cleanup := SetupPostgresContainer(t)
+	return tc.DB, cleanup
+}
+
+func setupSQLiteBackend(t *testing.T) (*sqlx.DB, func()) {
+	db, err := sqlite.NewSQLiteConnection(&sqlite.SQLiteConfig{Path: filepath.Join(t.TempDir(), "meemo.db")})
+	if err != nil {
+		t.Fatalf("Failed to open sqlite database: %v", err)
+	}
+	return db, func() {
+		if err := db.Close(); err != nil {
+			t.Logf("Warning: failed to close db: %v", err)
+		}
+	}
+}
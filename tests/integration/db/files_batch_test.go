@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+	"testing"
+)
+
+func TestFileRepository_ApplyBatch(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "batch@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+		folders := store.NewFolderRepository()
+
+		archive, err := folders.Create(context.Background(), testUser.ID, nil, "archive")
+		if err != nil {
+			t.Fatalf("Failed to create folder: %v", err)
+		}
+
+		files := make(map[string]*entity.File)
+		for _, name := range []string{"a.txt", "b.txt", "c.txt", "d.txt"} {
+			saved, err := fr.Save(context.Background(), testUser.ID, name, "text/plain", "test-bucket", "", 10, false)
+			if err != nil {
+				t.Fatalf("Failed to save file %s: %v", name, err)
+			}
+			files[name] = saved
+		}
+		// Файл с тем же именем уже лежит в целевой папке, поэтому перенос b.txt должен упасть.
+		if _, err := fr.Create(context.Background(), &entity.File{
+			UserID:       testUser.ID,
+			OriginalName: "b.txt",
+			MimeType:     "text/plain",
+			S3Bucket:     "test-bucket",
+			FolderID:     &archive.ID,
+		}); err != nil {
+			t.Fatalf("Failed to save file in folder: %v", err)
+		}
+		if _, err := fr.AddVersion(context.Background(), &entity.FileVersion{
+			FileID:      files["d.txt"].ID,
+			S3Key:       "versions/d",
+			SizeInBytes: 10,
+		}, nil); err != nil {
+			t.Fatalf("Failed to add version: %v", err)
+		}
+
+		results, err := fr.ApplyBatch(context.Background(), testUser.ID, []repository.BatchOperation{
+			{Action: repository.BatchSetVisibility, Name: "a.txt", IsPublic: true},
+			{Action: repository.BatchMove, FileID: files["b.txt"].ID, FolderID: &archive.ID},
+			{Action: repository.BatchAddTags, FileID: files["c.txt"].ID, Tags: []string{"work"}},
+			{Action: repository.BatchTrash, Name: "missing.txt"},
+			{Action: repository.BatchPurge, FileID: files["d.txt"].ID},
+			{Action: repository.BatchTrash, FileID: files["c.txt"].ID},
+		})
+		if err != nil {
+			t.Fatalf("Failed to apply batch: %v", err)
+		}
+		if len(results) != 6 {
+			t.Fatalf("Expected 6 results, got %d", len(results))
+		}
+
+		if results[0].Err != nil || !results[0].File.IsPublic {
+			t.Errorf("Expected a.txt to become public, got %+v", results[0])
+		}
+		if results[1].Err == nil {
+			t.Error("Expected move into a folder with the same file name to fail")
+		}
+		if results[2].Err != nil || len(results[2].File.Tags) != 1 {
+			t.Errorf("Expected c.txt to be tagged, got %+v", results[2])
+		}
+		if !errors.Is(results[3].Err, sql.ErrNoRows) {
+			t.Errorf("Expected missing file to fail with no rows, got %v", results[3].Err)
+		}
+		if results[4].Err != nil || len(results[4].Versions) != 1 || results[4].Versions[0].S3Key != "versions/d" {
+			t.Errorf("Expected purge to return the deleted version, got %+v", results[4])
+		}
+		if results[5].Err != nil || results[5].File.DeletedAt == nil {
+			t.Errorf("Expected c.txt to be trashed after the failed operations, got %+v", results[5])
+		}
+
+		moved, err := fr.Get(context.Background(), files["b.txt"].ID)
+		if err != nil {
+			t.Fatalf("Failed to get file: %v", err)
+		}
+		if moved.FolderID != nil {
+			t.Error("Expected failed move to be rolled back")
+		}
+		if _, err := fr.Get(context.Background(), files["d.txt"].ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected purged file to be deleted, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+	"testing"
+	"time"
+)
+
+func TestAttachBlob_RefCounting(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "blob@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		first, err := fr.Save(context.Background(), testUser.ID, "first.iso", "application/octet-stream", "test-bucket", "", 4, false)
+		if err != nil {
+			t.Fatalf("Failed to save first file: %v", err)
+		}
+		second, err := fr.Save(context.Background(), testUser.ID, "second.iso", "application/octet-stream", "test-bucket", "", 4, false)
+		if err != nil {
+			t.Fatalf("Failed to save second file: %v", err)
+		}
+
+		blob := &entity.Blob{SHA256: "deadbeef", S3Key: "blobs/deadbeef", SizeInBytes: 4, CRC32C: "00000000"}
+		attached, err := fr.AddVersion(context.Background(), &entity.FileVersion{FileID: first.ID, SizeInBytes: 4, ChecksumSHA256: blob.SHA256}, blob)
+		if err != nil {
+			t.Fatalf("Failed to attach blob: %v", err)
+		}
+		if attached.BlobSHA256 != blob.SHA256 || attached.S3Key != blob.S3Key {
+			t.Errorf("Expected file to point at blob %s, got %s (%s)", blob.SHA256, attached.BlobSHA256, attached.S3Key)
+		}
+		assertBlobRefCount(t, fr, blob.SHA256, 1)
+
+		if _, err := fr.AddVersion(context.Background(), &entity.FileVersion{FileID: second.ID, SizeInBytes: 4, BlobSHA256: blob.SHA256}, nil); err != nil {
+			t.Fatalf("Failed to reference blob: %v", err)
+		}
+		assertBlobRefCount(t, fr, blob.SHA256, 2)
+
+		if _, err := fr.Delete(context.Background(), testUser.Email, "first.iso"); err != nil {
+			t.Fatalf("Failed to delete first file: %v", err)
+		}
+		if _, err := fr.Delete(context.Background(), testUser.Email, "second.iso"); err != nil {
+			t.Fatalf("Failed to delete second file: %v", err)
+		}
+
+		found, err := fr.GetBlob(context.Background(), blob.SHA256)
+		if err != nil {
+			t.Fatalf("Failed to get blob: %v", err)
+		}
+		if found.RefCount != 0 {
+			t.Errorf("Expected ref count 0 after deletes, got %d", found.RefCount)
+		}
+
+		unreferenced, err := fr.ListUnreferencedBlobs(context.Background(), time.Now().Add(time.Minute), 10)
+		if err != nil {
+			t.Fatalf("Failed to list unreferenced blobs: %v", err)
+		}
+		if len(unreferenced) != 1 {
+			t.Fatalf("Expected 1 unreferenced blob, got %d", len(unreferenced))
+		}
+
+		deleted, err := fr.DeleteUnreferencedBlob(context.Background(), blob.SHA256)
+		if err != nil {
+			t.Fatalf("Failed to delete blob: %v", err)
+		}
+		if !deleted {
+			t.Error("Expected blob to be deleted")
+		}
+	})
+}
+
+func TestAddVersion_UnknownBlob(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "noblob@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		saved, err := fr.Save(context.Background(), testUser.ID, "missing.bin", "application/octet-stream", "test-bucket", "", 4, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+
+		if _, err := fr.AddVersion(context.Background(), &entity.FileVersion{FileID: saved.ID, SizeInBytes: 4, BlobSHA256: "unknown"}, nil); err == nil {
+			t.Error("Expected error for unknown blob, got nil")
+		}
+	})
+}
+
+func assertBlobRefCount(t *testing.T, fr repository.FileRepository, sha256 string, expected int64) {
+	t.Helper()
+
+	blob, err := fr.GetBlob(context.Background(), sha256)
+	if err != nil {
+		t.Fatalf("Failed to get blob: %v", err)
+	}
+	if blob.RefCount != expected {
+		t.Errorf("Expected ref count %d, got %d", expected, blob.RefCount)
+	}
+}
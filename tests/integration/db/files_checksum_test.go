@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+	"testing"
+	"time"
+)
+
+func TestSetChecksums_Success(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "checksum@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		savedFile, err := fr.Save(context.Background(), testUser.ID, "checksum.txt", "text/plain", "test-bucket", "files/checksum.txt", 5, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+
+		sha := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+		updated, err := fr.AddVersion(context.Background(), &entity.FileVersion{
+			FileID:         savedFile.ID,
+			S3Key:          "checksum-key",
+			SizeInBytes:    5,
+			ChecksumSHA256: sha,
+			ChecksumCRC32C: "9a71bb4c",
+		}, nil)
+		if err != nil {
+			t.Fatalf("Failed to set checksums: %v", err)
+		}
+
+		if updated.ChecksumSHA256 != sha {
+			t.Errorf("Expected sha256 %s, got %s", sha, updated.ChecksumSHA256)
+		}
+		if updated.Status != entity.Loaded {
+			t.Errorf("Expected status %d, got %d", entity.Loaded, updated.Status)
+		}
+		if updated.ChecksumVerifiedAt == nil {
+			t.Error("Expected checksum_verified_at to be set")
+		}
+	})
+}
+
+func TestListForScrub(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "scrub@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		withSum, err := fr.Save(context.Background(), testUser.ID, "with_sum.txt", "text/plain", "test-bucket", "files/with_sum.txt", 5, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		if _, err := fr.Save(context.Background(), testUser.ID, "without_sum.txt", "text/plain", "test-bucket", "files/without_sum.txt", 5, false); err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		if _, err := fr.AddVersion(context.Background(), &entity.FileVersion{FileID: withSum.ID, S3Key: "checksum-key", SizeInBytes: 5, ChecksumSHA256: "abc", ChecksumCRC32C: "def"}, nil); err != nil {
+			t.Fatalf("Failed to set checksums: %v", err)
+		}
+
+		files, err := fr.ListForScrub(context.Background(), time.Now().Add(time.Hour), 10)
+		if err != nil {
+			t.Fatalf("Failed to list files for scrub: %v", err)
+		}
+		if len(files) != 1 || files[0].ID != withSum.ID {
+			t.Fatalf("Expected only file %d to be scrubbed, got %d files", withSum.ID, len(files))
+		}
+
+		if err := fr.MarkChecksumVerified(context.Background(), withSum.ID, true); err != nil {
+			t.Fatalf("Failed to mark checksum verified: %v", err)
+		}
+
+		files, err = fr.ListForScrub(context.Background(), time.Now().Add(-time.Hour), 10)
+		if err != nil {
+			t.Fatalf("Failed to list files for scrub: %v", err)
+		}
+		if len(files) != 0 {
+			t.Errorf("Expected recently verified file to be skipped, got %d files", len(files))
+		}
+
+		found, err := fr.Get(context.Background(), withSum.ID)
+		if err != nil {
+			t.Fatalf("Failed to get file: %v", err)
+		}
+		if !found.ChecksumFailed {
+			t.Error("Expected checksum_failed to be set")
+		}
+	})
+}
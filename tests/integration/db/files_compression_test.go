@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+	"testing"
+)
+
+func TestAddVersion_ContentEncoding(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "compression@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		notes, err := fr.Save(context.Background(), testUser.ID, "notes.txt", "text/plain", "test-bucket", "", 1000, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+
+		loaded, err := fr.AddVersion(context.Background(), &entity.FileVersion{
+			FileID:            notes.ID,
+			S3Key:             "versions/notes/1",
+			SizeInBytes:       1000,
+			ContentEncoding:   "zstd",
+			StoredSizeInBytes: 120,
+		}, nil)
+		if err != nil {
+			t.Fatalf("Failed to add version: %v", err)
+		}
+		if loaded.ContentEncoding != "zstd" || loaded.StoredSizeInBytes != 120 || loaded.SizeInBytes != 1000 {
+			t.Errorf("Expected zstd content of 120 stored bytes and 1000 logical, got %q %d %d", loaded.ContentEncoding, loaded.StoredSizeInBytes, loaded.SizeInBytes)
+		}
+
+		used, err := fr.GetTotalUsedSpace(context.Background(), testUser.Email)
+		if err != nil {
+			t.Fatalf("Failed to get used space: %v", err)
+		}
+		if used != 1000 {
+			t.Errorf("Expected quota to count logical size 1000, got %d", used)
+		}
+
+		// Блоб уже сохранен со своим кодеком: новая версия должна ссылаться на него, а не на свой.
+		blob := &entity.Blob{SHA256: "cafe", S3Key: "blobs/cafe.gzip", SizeInBytes: 1000, ContentEncoding: "gzip", StoredSizeInBytes: 150}
+		if _, err := fr.AddVersion(context.Background(), &entity.FileVersion{FileID: notes.ID, SizeInBytes: 1000, ChecksumSHA256: blob.SHA256}, blob); err != nil {
+			t.Fatalf("Failed to attach blob: %v", err)
+		}
+		other, err := fr.Save(context.Background(), testUser.ID, "other.txt", "text/plain", "test-bucket", "", 1000, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		duplicate := &entity.Blob{SHA256: "cafe", S3Key: "blobs/cafe.zstd", SizeInBytes: 1000, ContentEncoding: "zstd", StoredSizeInBytes: 110}
+		deduplicated, err := fr.AddVersion(context.Background(), &entity.FileVersion{FileID: other.ID, SizeInBytes: 1000, ChecksumSHA256: duplicate.SHA256, ContentEncoding: "zstd", StoredSizeInBytes: 110}, duplicate)
+		if err != nil {
+			t.Fatalf("Failed to attach duplicate blob: %v", err)
+		}
+		if deduplicated.S3Key != blob.S3Key || deduplicated.ContentEncoding != "gzip" || deduplicated.StoredSizeInBytes != 150 {
+			t.Errorf("Expected existing gzip blob, got %s %q %d", deduplicated.S3Key, deduplicated.ContentEncoding, deduplicated.StoredSizeInBytes)
+		}
+
+		restored, err := fr.RestoreVersion(context.Background(), notes.ID, 1)
+		if err != nil {
+			t.Fatalf("Failed to restore version: %v", err)
+		}
+		if restored.ContentEncoding != "zstd" || restored.StoredSizeInBytes != 120 {
+			t.Errorf("Expected restored version to bring its encoding back, got %q %d", restored.ContentEncoding, restored.StoredSizeInBytes)
+		}
+	})
+}
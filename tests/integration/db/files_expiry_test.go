@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"meemo/internal/domain/entity"
+	"testing"
+	"time"
+)
+
+func TestFileRepository_Expiry(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ctx := context.Background()
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(ctx, "Test", "User", "expiry@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		now := time.Now()
+		inHour := now.Add(time.Hour)
+		handoff, err := fr.Create(ctx, &entity.File{
+			UserID:       testUser.ID,
+			OriginalName: "handoff.zip",
+			MimeType:     "application/zip",
+			SizeInBytes:  100,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			ExpiresAt:    &inHour,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		if _, err := fr.Save(ctx, testUser.ID, "keep.txt", "text/plain", "test-bucket", "", 10, false); err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+
+		stored, err := fr.Get(ctx, handoff.ID)
+		if err != nil {
+			t.Fatalf("Failed to get file: %v", err)
+		}
+		if stored.ExpiresAt == nil || !stored.ExpiresAt.Equal(inHour.Truncate(time.Microsecond)) {
+			t.Errorf("Expected expires_at %v, got %v", inHour, stored.ExpiresAt)
+		}
+
+		expired, err := fr.ListExpired(ctx, now, 10)
+		if err != nil {
+			t.Fatalf("Failed to list expired files: %v", err)
+		}
+		if len(expired) != 0 {
+			t.Errorf("Expected no expired files yet, got %d", len(expired))
+		}
+
+		past := now.Add(-time.Minute)
+		if _, err := fr.SetExpiry(ctx, testUser.ID, handoff.ID, &past); err != nil {
+			t.Fatalf("Failed to set expiry: %v", err)
+		}
+		expired, err = fr.ListExpired(ctx, now, 10)
+		if err != nil {
+			t.Fatalf("Failed to list expired files: %v", err)
+		}
+		if len(expired) != 1 || expired[0].ID != handoff.ID {
+			t.Fatalf("Expected only handoff.zip to be expired, got %d files", len(expired))
+		}
+
+		// Срок сняли после выборки: файл не удаляется.
+		cleared, err := fr.SetExpiry(ctx, testUser.ID, handoff.ID, nil)
+		if err != nil {
+			t.Fatalf("Failed to clear expiry: %v", err)
+		}
+		if cleared.ExpiresAt != nil {
+			t.Errorf("Expected expiry to be cleared, got %v", cleared.ExpiresAt)
+		}
+		if _, err := fr.DeleteExpired(ctx, handoff.ID, now, entity.DeletionExpired); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected sql.ErrNoRows for a file without expiry, got %v", err)
+		}
+
+		if _, err := fr.SetExpiry(ctx, testUser.ID, handoff.ID, &past); err != nil {
+			t.Fatalf("Failed to set expiry: %v", err)
+		}
+		deleted, err := fr.DeleteExpired(ctx, handoff.ID, now, entity.DeletionExpired)
+		if err != nil {
+			t.Fatalf("Failed to delete expired file: %v", err)
+		}
+		if deleted.ID != handoff.ID {
+			t.Errorf("Expected deleted file %d, got %d", handoff.ID, deleted.ID)
+		}
+		if _, err := fr.Get(ctx, handoff.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected expired file to be gone, got %v", err)
+		}
+
+		var reason, originalName string
+		err = store.DB.QueryRowContext(ctx, "SELECT reason, original_name FROM file_deletions WHERE file_id = $1", handoff.ID).Scan(&reason, &originalName)
+		if err != nil {
+			t.Fatalf("Failed to read deletion record: %v", err)
+		}
+		if reason != entity.DeletionExpired || originalName != "handoff.zip" {
+			t.Errorf("Expected deletion record for handoff.zip with reason %q, got %q %q", entity.DeletionExpired, originalName, reason)
+		}
+	})
+}
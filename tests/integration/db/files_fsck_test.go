@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+	"testing"
+	"time"
+)
+
+func TestFileRepository_StorageCheck(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ctx := context.Background()
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(ctx, "Test", "User", "fsck@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		report, err := fr.Save(ctx, testUser.ID, "report.txt", "text/plain", "test-bucket", "", 20, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		for i, key := range []string{"versions/report/1", "versions/report/2"} {
+			if _, err := fr.AddVersion(ctx, &entity.FileVersion{
+				FileID:            report.ID,
+				S3Key:             key,
+				SizeInBytes:       int64(10 * (i + 1)),
+				StoredSizeInBytes: int64(10 * (i + 1)),
+			}, nil); err != nil {
+				t.Fatalf("Failed to add version: %v", err)
+			}
+		}
+
+		deduplicated, err := fr.Save(ctx, testUser.ID, "copy.iso", "application/octet-stream", "test-bucket", "", 4, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		blob := &entity.Blob{SHA256: "cafebabe", S3Key: "blobs/cafebabe", SizeInBytes: 4, CRC32C: "00000000", StoredSizeInBytes: 4}
+		if _, err := fr.AddVersion(ctx, &entity.FileVersion{FileID: deduplicated.ID, SizeInBytes: 4, ChecksumSHA256: blob.SHA256}, blob); err != nil {
+			t.Fatalf("Failed to attach blob: %v", err)
+		}
+
+		now := time.Now()
+		pending, err := fr.Create(ctx, &entity.File{
+			UserID:       testUser.ID,
+			OriginalName: "pending.txt",
+			MimeType:     "text/plain",
+			S3Bucket:     "test-bucket",
+			SizeInBytes:  5,
+			Status:       entity.Pending,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		})
+		if err != nil {
+			t.Fatalf("Failed to save pending file: %v", err)
+		}
+
+		objects, err := fr.ListStoredObjects(ctx)
+		if err != nil {
+			t.Fatalf("Failed to list stored objects: %v", err)
+		}
+		byKey := make(map[string]*entity.StoredObject, len(objects))
+		for _, object := range objects {
+			byKey[object.S3Key] = object
+		}
+		if len(byKey) != 3 {
+			t.Fatalf("Expected 3 stored objects, got %d: %v", len(byKey), byKey)
+		}
+		expected := map[string]struct {
+			kind string
+			size int64
+		}{
+			"versions/report/1": {entity.StoredObjectVersion, 10},
+			"versions/report/2": {entity.StoredObjectFile, 20},
+			"blobs/cafebabe":    {entity.StoredObjectBlob, 4},
+		}
+		for key, want := range expected {
+			object, ok := byKey[key]
+			if !ok {
+				t.Errorf("Expected stored object %s", key)
+				continue
+			}
+			if object.Kind != want.kind || object.SizeInBytes != want.size {
+				t.Errorf("Expected %s to be %s of %d bytes, got %s of %d bytes", key, want.kind, want.size, object.Kind, object.SizeInBytes)
+			}
+		}
+		if object := byKey["versions/report/2"]; object != nil && (object.FileID == nil || *object.FileID != report.ID) {
+			t.Errorf("Expected current version to reference file %d, got %v", report.ID, object.FileID)
+		}
+		if object := byKey["blobs/cafebabe"]; object != nil && object.FileID != nil {
+			t.Errorf("Expected blob to reference no file, got %d", *object.FileID)
+		}
+
+		stale, err := fr.ListStalePending(ctx, time.Now().Add(-time.Hour))
+		if err != nil {
+			t.Fatalf("Failed to list stale pending files: %v", err)
+		}
+		if len(stale) != 0 {
+			t.Errorf("Expected no stale pending files yet, got %d", len(stale))
+		}
+
+		stale, err = fr.ListStalePending(ctx, time.Now().Add(time.Minute))
+		if err != nil {
+			t.Fatalf("Failed to list stale pending files: %v", err)
+		}
+		if len(stale) != 1 || stale[0].ID != pending.ID {
+			t.Fatalf("Expected only pending.txt to be stale, got %d files", len(stale))
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"meemo/internal/domain/file/repository"
+	"strconv"
+	"testing"
+)
+
+func TestListPage_KeysetPagination(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "listpage@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		names := []string{"a.txt", "b.png", "c.txt", "d.png", "e.txt"}
+		for i, name := range names {
+			mimeType := "text/plain"
+			if i%2 == 1 {
+				mimeType = "image/png"
+			}
+			if _, err := fr.Save(context.Background(), testUser.ID, name, mimeType, "test-bucket", "", int64(100*(i+1)), i == 0); err != nil {
+				t.Fatalf("Failed to save file: %v", err)
+			}
+		}
+
+		opts := repository.ListOptions{SortBy: repository.SortByName, Limit: 2}
+		var collected []string
+		for {
+			page, err := fr.ListPage(context.Background(), testUser.ID, opts)
+			if err != nil {
+				t.Fatalf("Failed to list page: %v", err)
+			}
+			for _, f := range page {
+				collected = append(collected, f.OriginalName)
+			}
+			if len(page) < opts.Limit {
+				break
+			}
+			last := page[len(page)-1]
+			opts.AfterValue = &last.OriginalName
+			opts.AfterID = last.ID
+		}
+
+		if len(collected) != len(names) {
+			t.Fatalf("Expected %d files across pages, got %d", len(names), len(collected))
+		}
+		for i, name := range names {
+			if collected[i] != name {
+				t.Errorf("Expected %s at position %d, got %s", name, i, collected[i])
+			}
+		}
+
+		bySize, err := fr.ListPage(context.Background(), testUser.ID, repository.ListOptions{SortBy: repository.SortBySize, Descending: true, Limit: 10})
+		if err != nil {
+			t.Fatalf("Failed to list by size: %v", err)
+		}
+		if len(bySize) != 5 || bySize[0].SizeInBytes != 500 {
+			t.Errorf("Expected largest file first, got %d files", len(bySize))
+		}
+
+		after := strconv.FormatInt(bySize[1].SizeInBytes, 10)
+		rest, err := fr.ListPage(context.Background(), testUser.ID, repository.ListOptions{SortBy: repository.SortBySize, Descending: true, Limit: 10, AfterValue: &after, AfterID: bySize[1].ID})
+		if err != nil {
+			t.Fatalf("Failed to list by size after cursor: %v", err)
+		}
+		if len(rest) != 3 {
+			t.Errorf("Expected 3 files after cursor, got %d", len(rest))
+		}
+	})
+}
+
+func TestListPage_Filters(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "listfilter@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		if _, err := fr.Save(context.Background(), testUser.ID, "photo.png", "image/png", "test-bucket", "", 2048, true); err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		if _, err := fr.Save(context.Background(), testUser.ID, "icon.svg", "image/svg+xml", "test-bucket", "", 10, false); err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		if _, err := fr.Save(context.Background(), testUser.ID, "notes.txt", "text/plain", "test-bucket", "", 4096, true); err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+
+		images, err := fr.ListPage(context.Background(), testUser.ID, repository.ListOptions{SortBy: repository.SortByName, Limit: 10, MimePrefix: "image/"})
+		if err != nil {
+			t.Fatalf("Failed to filter by mime: %v", err)
+		}
+		if len(images) != 2 {
+			t.Errorf("Expected 2 images, got %d", len(images))
+		}
+
+		isPublic := true
+		minSize := int64(1000)
+		maxSize := int64(3000)
+		filtered, err := fr.ListPage(context.Background(), testUser.ID, repository.ListOptions{
+			SortBy:   repository.SortByName,
+			Limit:    10,
+			IsPublic: &isPublic,
+			MinSize:  &minSize,
+			MaxSize:  &maxSize,
+		})
+		if err != nil {
+			t.Fatalf("Failed to filter by visibility and size: %v", err)
+		}
+		if len(filtered) != 1 || filtered[0].OriginalName != "photo.png" {
+			t.Errorf("Expected only photo.png, got %d files", len(filtered))
+		}
+	})
+}
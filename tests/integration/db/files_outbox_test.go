@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+	"testing"
+	"time"
+)
+
+func TestFileRepository_InTx(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ctx := context.Background()
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(ctx, "Test", "User", "outbox@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		kept, err := fr.Save(ctx, testUser.ID, "kept.txt", "text/plain", "test-bucket", "", 10, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		if _, err := fr.AddVersion(ctx, &entity.FileVersion{FileID: kept.ID, S3Key: "versions/kept", SizeInBytes: 10}, nil); err != nil {
+			t.Fatalf("Failed to add version: %v", err)
+		}
+
+		availableAt := time.Now().Add(-time.Second)
+		errAbort := errors.New("abort")
+		err = fr.InTx(ctx, func(repo repository.FileRepository) error {
+			if _, err := repo.DeleteByID(ctx, testUser.ID, kept.ID); err != nil {
+				return err
+			}
+			ops := []*entity.ObjectOperation{{Operation: entity.ObjectOperationDelete, S3Key: "versions/kept"}}
+			if _, err := repo.EnqueueObjectOperations(ctx, ops, availableAt); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("Expected transaction to be aborted, got %v", err)
+		}
+		if _, err := fr.Get(ctx, kept.ID); err != nil {
+			t.Errorf("Expected file to survive rolled back transaction: %v", err)
+		}
+		claimed, err := fr.ClaimObjectOperations(ctx, time.Now(), time.Now().Add(time.Minute), 10)
+		if err != nil {
+			t.Fatalf("Failed to claim object operations: %v", err)
+		}
+		if len(claimed) != 0 {
+			t.Errorf("Expected rolled back operations to be discarded, got %d", len(claimed))
+		}
+
+		// AddVersion внутри InTx работает в точке сохранения и фиксируется вместе с транзакцией.
+		var enqueued []*entity.ObjectOperation
+		err = fr.InTx(ctx, func(repo repository.FileRepository) error {
+			copied, err := repo.Save(ctx, testUser.ID, "copy.txt", "text/plain", "test-bucket", "", 10, false)
+			if err != nil {
+				return err
+			}
+			if _, err := repo.AddVersion(ctx, &entity.FileVersion{FileID: copied.ID, S3Key: "versions/copy", SizeInBytes: 10}, nil); err != nil {
+				return err
+			}
+			ops := []*entity.ObjectOperation{{Operation: entity.ObjectOperationCopy, SourceKey: "versions/kept", S3Key: "versions/copy"}}
+			enqueued, err = repo.EnqueueObjectOperations(ctx, ops, availableAt)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Failed to commit transaction: %v", err)
+		}
+		copied, err := fr.GetByName(ctx, testUser.ID, nil, "copy.txt")
+		if err != nil {
+			t.Fatalf("Failed to get copied file: %v", err)
+		}
+		if copied.Status != entity.Loaded || copied.S3Key != "versions/copy" {
+			t.Errorf("Expected committed copy to be loaded from versions/copy, got status %d, key %s", copied.Status, copied.S3Key)
+		}
+		if len(enqueued) != 1 || enqueued[0].ID == 0 || enqueued[0].SourceKey != "versions/kept" {
+			t.Fatalf("Expected copy operation to be enqueued, got %+v", enqueued)
+		}
+	})
+}
+
+func TestFileRepository_ObjectOutbox(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ctx := context.Background()
+		fr := store.NewFileRepository()
+
+		now := time.Now()
+		enqueued, err := fr.EnqueueObjectOperations(ctx, []*entity.ObjectOperation{
+			{Operation: entity.ObjectOperationDelete, S3Key: "versions/1/a"},
+			{Operation: entity.ObjectOperationDelete, S3Key: "versions/1/b"},
+		}, now.Add(time.Minute))
+		if err != nil {
+			t.Fatalf("Failed to enqueue object operations: %v", err)
+		}
+		if len(enqueued) != 2 {
+			t.Fatalf("Expected 2 operations, got %d", len(enqueued))
+		}
+
+		claimed, err := fr.ClaimObjectOperations(ctx, now, now.Add(time.Minute), 10)
+		if err != nil {
+			t.Fatalf("Failed to claim object operations: %v", err)
+		}
+		if len(claimed) != 0 {
+			t.Errorf("Expected operations to be hidden until available, got %d", len(claimed))
+		}
+
+		later := now.Add(2 * time.Minute)
+		claimed, err = fr.ClaimObjectOperations(ctx, later, later.Add(time.Minute), 10)
+		if err != nil {
+			t.Fatalf("Failed to claim object operations: %v", err)
+		}
+		if len(claimed) != 2 {
+			t.Fatalf("Expected 2 claimed operations, got %d", len(claimed))
+		}
+		for _, op := range claimed {
+			if op.Attempts != 1 {
+				t.Errorf("Expected operation %d to have 1 attempt, got %d", op.ID, op.Attempts)
+			}
+		}
+
+		// Взятые операции не выдаются повторно, пока не истечет аренда.
+		claimed, err = fr.ClaimObjectOperations(ctx, later, later.Add(time.Minute), 10)
+		if err != nil {
+			t.Fatalf("Failed to claim object operations: %v", err)
+		}
+		if len(claimed) != 0 {
+			t.Errorf("Expected leased operations to be hidden, got %d", len(claimed))
+		}
+
+		if err := fr.DeleteObjectOperations(ctx, []int64{enqueued[0].ID}); err != nil {
+			t.Fatalf("Failed to delete object operation: %v", err)
+		}
+		if err := fr.RetryObjectOperation(ctx, enqueued[1].ID, "connection reset", later); err != nil {
+			t.Fatalf("Failed to retry object operation: %v", err)
+		}
+		claimed, err = fr.ClaimObjectOperations(ctx, later, later.Add(time.Minute), 10)
+		if err != nil {
+			t.Fatalf("Failed to claim object operations: %v", err)
+		}
+		if len(claimed) != 1 || claimed[0].ID != enqueued[1].ID {
+			t.Fatalf("Expected only the retried operation, got %d operations", len(claimed))
+		}
+		if claimed[0].Attempts != 2 || claimed[0].LastError != "connection reset" {
+			t.Errorf("Expected 2 attempts with last error, got %d attempts, error %q", claimed[0].Attempts, claimed[0].LastError)
+		}
+
+		if err := fr.DeadLetterObjectOperation(ctx, enqueued[1].ID, "access denied"); err != nil {
+			t.Fatalf("Failed to dead-letter object operation: %v", err)
+		}
+		claimed, err = fr.ClaimObjectOperations(ctx, later.Add(time.Hour), later.Add(2*time.Hour), 10)
+		if err != nil {
+			t.Fatalf("Failed to claim object operations: %v", err)
+		}
+		if len(claimed) != 0 {
+			t.Errorf("Expected dead operations not to be claimed, got %d", len(claimed))
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+	"testing"
+)
+
+func TestAddVersion_Quarantine(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ctx := context.Background()
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(ctx, "Test", "User", "scan@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		saved, err := fr.Save(ctx, testUser.ID, "report.pdf", "application/pdf", "test-bucket", "", 10, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		if _, err := fr.AddVersion(ctx, &entity.FileVersion{
+			FileID:      saved.ID,
+			S3Key:       "versions/report/1",
+			SizeInBytes: 10,
+			ScanStatus:  entity.ScanClean,
+		}, nil); err != nil {
+			t.Fatalf("Failed to add clean version: %v", err)
+		}
+
+		infected, err := fr.AddVersion(ctx, &entity.FileVersion{
+			FileID:        saved.ID,
+			S3Key:         "versions/report/2",
+			SizeInBytes:   20,
+			ScanStatus:    entity.ScanInfected,
+			ScanSignature: "Eicar-Test-Signature",
+		}, nil)
+		if err != nil {
+			t.Fatalf("Failed to add infected version: %v", err)
+		}
+		if infected.Status != entity.Quarantined {
+			t.Fatalf("Expected infected file to be quarantined, got status %d", infected.Status)
+		}
+		if infected.ScanStatus != entity.ScanInfected || infected.ScanSignature != "Eicar-Test-Signature" {
+			t.Errorf("Expected scan result to be copied to the file, got %q %q", infected.ScanStatus, infected.ScanSignature)
+		}
+
+		if _, err := fr.SetStatus(ctx, testUser.Email, "report.pdf", entity.Loaded); err == nil {
+			t.Error("Expected status of a quarantined file to stay unchanged")
+		}
+		if _, err := fr.ChangeVisibility(ctx, testUser.Email, "report.pdf", true); err == nil {
+			t.Error("Expected a quarantined file not to become public")
+		}
+
+		restored, err := fr.RestoreVersion(ctx, saved.ID, 1)
+		if err != nil {
+			t.Fatalf("Failed to restore version: %v", err)
+		}
+		if restored.Status != entity.Loaded || restored.ScanStatus != entity.ScanClean || restored.ScanSignature != "" {
+			t.Errorf("Expected restoring a clean version to lift quarantine, got status %d scan %q %q", restored.Status, restored.ScanStatus, restored.ScanSignature)
+		}
+
+		email, err := fr.GetUserEmail(ctx, testUser.ID)
+		if err != nil {
+			t.Fatalf("Failed to get user email: %v", err)
+		}
+		if email != testUser.Email {
+			t.Errorf("Expected email %s, got %s", testUser.Email, email)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+	"testing"
+	"time"
+)
+
+func TestSearch_PrefixFuzzyAndText(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		owner, err := ur.Create(context.Background(), "Test", "User", "search@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		report, err := fr.Save(context.Background(), owner.ID, "quarterly_report-2024.pdf", "application/pdf", "test-bucket", "", 10, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		notes, err := fr.Save(context.Background(), owner.ID, "notes.txt", "text/plain", "test-bucket", "", 10, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		if err := fr.SetSearchText(context.Background(), notes.ID, "meeting about the migration plan"); err != nil {
+			t.Fatalf("Failed to set search text: %v", err)
+		}
+
+		results, err := fr.Search(context.Background(), owner.ID, repository.SearchOptions{Query: "quart", Limit: 10})
+		if err != nil {
+			t.Fatalf("Failed to search by prefix: %v", err)
+		}
+		if len(results) != 1 || results[0].File.ID != report.ID {
+			t.Errorf("Expected prefix match on report, got %d results", len(results))
+		}
+
+		results, err = fr.Search(context.Background(), owner.ID, repository.SearchOptions{Query: "quartely", Limit: 10})
+		if err != nil {
+			t.Fatalf("Failed to search with typo: %v", err)
+		}
+		if len(results) == 0 || results[0].File.ID != report.ID {
+			t.Errorf("Expected fuzzy match on report, got %d results", len(results))
+		}
+
+		results, err = fr.Search(context.Background(), owner.ID, repository.SearchOptions{Query: "migration", Limit: 10})
+		if err != nil {
+			t.Fatalf("Failed to search by content: %v", err)
+		}
+		if len(results) != 1 || results[0].File.ID != notes.ID {
+			t.Errorf("Expected content match on notes, got %d results", len(results))
+		}
+
+		if _, err := fr.Rename(context.Background(), owner.Email, "notes.txt", "minutes.txt"); err != nil {
+			t.Fatalf("Failed to rename file: %v", err)
+		}
+		results, err = fr.Search(context.Background(), owner.ID, repository.SearchOptions{Query: "minutes", Limit: 10})
+		if err != nil {
+			t.Fatalf("Failed to search renamed file: %v", err)
+		}
+		if len(results) != 1 || results[0].File.ID != notes.ID {
+			t.Errorf("Expected renamed file to be found by new name, got %d results", len(results))
+		}
+	})
+}
+
+func TestSearch_PublicFilesOfOtherUsers(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		owner, err := ur.Create(context.Background(), "Test", "User", "searchowner@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+		other, err := ur.Create(context.Background(), "Other", "User", "searchother@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		if _, err := fr.Save(context.Background(), owner.ID, "shared-budget.xlsx", "application/vnd.ms-excel", "test-bucket", "", 10, true); err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		if _, err := fr.Save(context.Background(), owner.ID, "private-budget.xlsx", "application/vnd.ms-excel", "test-bucket", "", 10, false); err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+
+		results, err := fr.Search(context.Background(), other.ID, repository.SearchOptions{Query: "budget", Limit: 10})
+		if err != nil {
+			t.Fatalf("Failed to search: %v", err)
+		}
+		if len(results) != 0 {
+			t.Errorf("Expected no results without public option, got %d", len(results))
+		}
+
+		results, err = fr.Search(context.Background(), other.ID, repository.SearchOptions{Query: "budget", IncludePublic: true, Limit: 10})
+		if err != nil {
+			t.Fatalf("Failed to search public files: %v", err)
+		}
+		if len(results) != 1 || results[0].File.OriginalName != "shared-budget.xlsx" {
+			t.Errorf("Expected only the public file, got %d results", len(results))
+		}
+	})
+}
+
+func TestSearch_PublicFilesHideQuarantinedAndExpired(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ctx := context.Background()
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		owner, err := ur.Create(ctx, "Test", "User", "searchhidden@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+		other, err := ur.Create(ctx, "Other", "User", "searchviewer@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		now := time.Now()
+		expired := now.Add(-time.Hour)
+		later := now.Add(time.Hour)
+		files := []*entity.File{
+			{OriginalName: "clean-invoice.pdf", Status: entity.Loaded},
+			{OriginalName: "infected-invoice.pdf", Status: entity.Quarantined},
+			{OriginalName: "expired-invoice.pdf", Status: entity.Loaded, ExpiresAt: &expired},
+			{OriginalName: "expiring-invoice.pdf", Status: entity.Loaded, ExpiresAt: &later},
+		}
+		for _, file := range files {
+			file.UserID = owner.ID
+			file.MimeType = "application/pdf"
+			file.S3Bucket = "test-bucket"
+			file.SizeInBytes = 10
+			file.IsPublic = true
+			file.CreatedAt = now
+			file.UpdatedAt = now
+			if _, err := fr.Create(ctx, file); err != nil {
+				t.Fatalf("Failed to create %s: %v", file.OriginalName, err)
+			}
+		}
+
+		results, err := fr.Search(ctx, other.ID, repository.SearchOptions{Query: "invoice", IncludePublic: true, Limit: 10})
+		if err != nil {
+			t.Fatalf("Failed to search public files: %v", err)
+		}
+		names := make(map[string]bool, len(results))
+		for _, result := range results {
+			names[result.File.OriginalName] = true
+		}
+		if len(results) != 2 || !names["clean-invoice.pdf"] || !names["expiring-invoice.pdf"] {
+			t.Errorf("Expected only clean and not yet expired public files, got %v", names)
+		}
+
+		results, err = fr.Search(ctx, owner.ID, repository.SearchOptions{Query: "invoice", Limit: 10})
+		if err != nil {
+			t.Fatalf("Failed to search own files: %v", err)
+		}
+		if len(results) != 4 {
+			t.Errorf("Expected owner to find all 4 files, got %d", len(results))
+		}
+	})
+}
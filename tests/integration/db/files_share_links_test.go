@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"meemo/internal/domain/entity"
+	"testing"
+	"time"
+)
+
+func TestShareLinks_DownloadLimitAndRevoke(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		owner, err := ur.Create(context.Background(), "Test", "User", "links@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+		lr := store.NewShareLinkRepository()
+
+		sharedFile, err := fr.Save(context.Background(), owner.ID, "report.pdf", "application/pdf", "test-bucket", "", 10, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+
+		maxDownloads := 2
+		link, err := lr.CreateLink(context.Background(), &entity.ShareLink{
+			FileID:       sharedFile.ID,
+			UserID:       owner.ID,
+			TokenHash:    "a000000000000000000000000000000000000000000000000000000000000001",
+			PasswordHash: "bcrypt-hash",
+			MaxDownloads: &maxDownloads,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create share link: %v", err)
+		}
+		if link.DownloadCount != 0 || link.PasswordHash != "bcrypt-hash" || link.ExpiresAt != nil {
+			t.Errorf("Unexpected share link: %+v", link)
+		}
+
+		found, err := lr.GetLinkByTokenHash(context.Background(), link.TokenHash)
+		if err != nil {
+			t.Fatalf("Failed to get share link by token hash: %v", err)
+		}
+		if found.ID != link.ID {
+			t.Errorf("Expected link %d, got %d", link.ID, found.ID)
+		}
+
+		for i := 1; i <= maxDownloads; i++ {
+			claimed, err := lr.ClaimDownload(context.Background(), link.ID)
+			if err != nil {
+				t.Fatalf("Failed to claim download %d: %v", i, err)
+			}
+			if claimed.DownloadCount != i {
+				t.Errorf("Expected download count %d, got %d", i, claimed.DownloadCount)
+			}
+		}
+		if _, err := lr.ClaimDownload(context.Background(), link.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected download limit to be enforced, got %v", err)
+		}
+
+		unlimited, err := lr.CreateLink(context.Background(), &entity.ShareLink{
+			FileID:    sharedFile.ID,
+			UserID:    owner.ID,
+			TokenHash: "a000000000000000000000000000000000000000000000000000000000000002",
+		})
+		if err != nil {
+			t.Fatalf("Failed to create share link: %v", err)
+		}
+		revoked, err := lr.RevokeLink(context.Background(), owner.ID, unlimited.ID)
+		if err != nil {
+			t.Fatalf("Failed to revoke share link: %v", err)
+		}
+		if revoked.RevokedAt == nil {
+			t.Error("Expected revoked_at to be set")
+		}
+		if _, err := lr.ClaimDownload(context.Background(), unlimited.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected revoked link to reject downloads, got %v", err)
+		}
+
+		links, err := lr.ListLinks(context.Background(), owner.ID, &sharedFile.ID)
+		if err != nil {
+			t.Fatalf("Failed to list share links: %v", err)
+		}
+		if len(links) != 2 {
+			t.Errorf("Expected 2 links including the revoked one, got %d", len(links))
+		}
+	})
+}
+
+func TestShareLinks_ExpiredLinkRejectsDownloads(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		owner, err := ur.Create(context.Background(), "Test", "User", "expiredlinks@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+		lr := store.NewShareLinkRepository()
+
+		sharedFile, err := fr.Save(context.Background(), owner.ID, "old.txt", "text/plain", "test-bucket", "", 10, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+
+		expiresAt := time.Now().Add(-time.Minute)
+		link, err := lr.CreateLink(context.Background(), &entity.ShareLink{
+			FileID:    sharedFile.ID,
+			UserID:    owner.ID,
+			TokenHash: "b000000000000000000000000000000000000000000000000000000000000001",
+			ExpiresAt: &expiresAt,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create share link: %v", err)
+		}
+
+		if _, err := lr.ClaimDownload(context.Background(), link.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected expired link to reject downloads, got %v", err)
+		}
+
+		other, err := ur.Create(context.Background(), "Other", "User", "otherlinks@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+		if _, err := lr.RevokeLink(context.Background(), other.ID, link.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected other users to be unable to revoke the link, got %v", err)
+		}
+	})
+}
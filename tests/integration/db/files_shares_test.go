@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"meemo/internal/domain/entity"
+	"testing"
+)
+
+func TestShares_UpsertListAndRevoke(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		owner, err := ur.Create(context.Background(), "Owner", "User", "shareowner@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+		viewer, err := ur.Create(context.Background(), "Viewer", "User", "shareviewer@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+		sr := store.NewShareRepository()
+
+		sharedFile, err := fr.Save(context.Background(), owner.ID, "plan.txt", "text/plain", "test-bucket", "", 10, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+
+		created, err := sr.Upsert(context.Background(), sharedFile.ID, viewer.Email, entity.PermissionViewer, owner.ID)
+		if err != nil {
+			t.Fatalf("Failed to share file: %v", err)
+		}
+		if created.UserID != viewer.ID || created.UserEmail != viewer.Email || created.Permission != entity.PermissionViewer {
+			t.Errorf("Unexpected share: %+v", created)
+		}
+
+		updated, err := sr.Upsert(context.Background(), sharedFile.ID, viewer.Email, entity.PermissionEditor, owner.ID)
+		if err != nil {
+			t.Fatalf("Failed to update share: %v", err)
+		}
+		if updated.ID != created.ID || updated.Permission != entity.PermissionEditor {
+			t.Errorf("Expected the same share upgraded to editor, got %+v", updated)
+		}
+
+		permission, err := sr.GetPermission(context.Background(), sharedFile.ID, viewer.ID)
+		if err != nil {
+			t.Fatalf("Failed to get permission: %v", err)
+		}
+		if permission != entity.PermissionEditor {
+			t.Errorf("Expected editor permission, got %s", permission)
+		}
+
+		if _, err := sr.Upsert(context.Background(), sharedFile.ID, owner.Email, entity.PermissionViewer, owner.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected sharing with the owner to be rejected, got %v", err)
+		}
+		if _, err := sr.Upsert(context.Background(), sharedFile.ID, "nobody@test.com", entity.PermissionViewer, owner.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected sharing with an unknown email to be rejected, got %v", err)
+		}
+
+		shares, err := sr.ListByFile(context.Background(), sharedFile.ID)
+		if err != nil {
+			t.Fatalf("Failed to list shares: %v", err)
+		}
+		if len(shares) != 1 {
+			t.Errorf("Expected 1 share, got %d", len(shares))
+		}
+
+		sharedWithViewer, err := fr.ListSharedWithUser(context.Background(), viewer.ID)
+		if err != nil {
+			t.Fatalf("Failed to list shared files: %v", err)
+		}
+		if len(sharedWithViewer) != 1 || sharedWithViewer[0].File.ID != sharedFile.ID || sharedWithViewer[0].OwnerEmail != owner.Email {
+			t.Errorf("Expected the shared file with owner email, got %d files", len(sharedWithViewer))
+		}
+
+		if err := sr.Delete(context.Background(), sharedFile.ID, viewer.ID); err != nil {
+			t.Fatalf("Failed to revoke share: %v", err)
+		}
+		if _, err := sr.GetPermission(context.Background(), sharedFile.ID, viewer.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected no permission after revoke, got %v", err)
+		}
+		if err := sr.Delete(context.Background(), sharedFile.ID, viewer.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected sql.ErrNoRows for a missing share, got %v", err)
+		}
+	})
+}
+
+func TestShares_TrashedFilesAreHiddenAndDeletedFilesCascade(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		owner, err := ur.Create(context.Background(), "Owner", "User", "sharetrash@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+		recipient, err := ur.Create(context.Background(), "Recipient", "User", "sharerecipient@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+		sr := store.NewShareRepository()
+
+		sharedFile, err := fr.Save(context.Background(), owner.ID, "draft.txt", "text/plain", "test-bucket", "", 10, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		if _, err := sr.Upsert(context.Background(), sharedFile.ID, recipient.Email, entity.PermissionViewer, owner.ID); err != nil {
+			t.Fatalf("Failed to share file: %v", err)
+		}
+
+		if _, err := fr.Trash(context.Background(), owner.ID, sharedFile.ID); err != nil {
+			t.Fatalf("Failed to trash file: %v", err)
+		}
+		files, err := fr.ListSharedWithUser(context.Background(), recipient.ID)
+		if err != nil {
+			t.Fatalf("Failed to list shared files: %v", err)
+		}
+		if len(files) != 0 {
+			t.Errorf("Expected trashed file to be hidden, got %d files", len(files))
+		}
+
+		if _, err := fr.DeleteByID(context.Background(), owner.ID, sharedFile.ID); err != nil {
+			t.Fatalf("Failed to delete file: %v", err)
+		}
+		shares, err := sr.ListByFile(context.Background(), sharedFile.ID)
+		if err != nil {
+			t.Fatalf("Failed to list shares: %v", err)
+		}
+		if len(shares) != 0 {
+			t.Errorf("Expected shares to be removed with the file, got %d", len(shares))
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+	"reflect"
+	"testing"
+)
+
+func TestTags_AddRemoveReplaceAndCount(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "tags@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		created, err := fr.Create(context.Background(), &entity.File{
+			UserID:       testUser.ID,
+			OriginalName: "invoice.pdf",
+			MimeType:     "application/pdf",
+			S3Bucket:     "test-bucket",
+			Tags:         []string{"finance"},
+			Metadata:     map[string]string{"project": "apollo"},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+
+		other, err := fr.Save(context.Background(), testUser.ID, "photo.png", "image/png", "test-bucket", "", 10, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+
+		updated, err := fr.AddTags(context.Background(), testUser.ID, created.ID, []string{"work", "finance"})
+		if err != nil {
+			t.Fatalf("Failed to add tags: %v", err)
+		}
+		if !reflect.DeepEqual(updated.Tags, []string{"finance", "work"}) {
+			t.Errorf("Expected tags [finance work], got %v", updated.Tags)
+		}
+		if updated.Metadata["project"] != "apollo" {
+			t.Errorf("Expected metadata to be kept, got %v", updated.Metadata)
+		}
+
+		if _, err := fr.ReplaceTags(context.Background(), testUser.ID, other.ID, []string{"work", "travel"}); err != nil {
+			t.Fatalf("Failed to replace tags: %v", err)
+		}
+
+		updated, err = fr.RemoveTags(context.Background(), testUser.ID, created.ID, []string{"finance"})
+		if err != nil {
+			t.Fatalf("Failed to remove tags: %v", err)
+		}
+		if !reflect.DeepEqual(updated.Tags, []string{"work"}) {
+			t.Errorf("Expected tags [work], got %v", updated.Tags)
+		}
+
+		tags, err := fr.ListTags(context.Background(), testUser.ID)
+		if err != nil {
+			t.Fatalf("Failed to list tags: %v", err)
+		}
+		counts := map[string]int64{}
+		for _, tag := range tags {
+			counts[tag.Tag] = tag.Count
+		}
+		if counts["work"] != 2 || counts["travel"] != 1 || len(counts) != 2 {
+			t.Errorf("Unexpected tag counts: %v", counts)
+		}
+		if tags[0].Tag != "work" {
+			t.Errorf("Expected most used tag first, got %s", tags[0].Tag)
+		}
+
+		results, err := fr.Search(context.Background(), testUser.ID, repository.SearchOptions{Query: "travel", Limit: 10})
+		if err != nil {
+			t.Fatalf("Failed to search by tag: %v", err)
+		}
+		if len(results) != 1 || results[0].File.ID != other.ID {
+			t.Errorf("Expected tagged file in search results, got %d results", len(results))
+		}
+	})
+}
+
+func TestMetadata_UpdateAndFilter(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "metadata@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		first, err := fr.Save(context.Background(), testUser.ID, "a.txt", "text/plain", "test-bucket", "", 10, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		second, err := fr.Save(context.Background(), testUser.ID, "b.txt", "text/plain", "test-bucket", "", 10, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+
+		updated, err := fr.UpdateMetadata(context.Background(), testUser.ID, first.ID, map[string]string{"project": "apollo", "owner": "ann"}, nil, false)
+		if err != nil {
+			t.Fatalf("Failed to set metadata: %v", err)
+		}
+		updated, err = fr.UpdateMetadata(context.Background(), testUser.ID, first.ID, map[string]string{"stage": "draft"}, []string{"owner"}, false)
+		if err != nil {
+			t.Fatalf("Failed to merge metadata: %v", err)
+		}
+		expected := map[string]string{"project": "apollo", "stage": "draft"}
+		if !reflect.DeepEqual(updated.Metadata, expected) {
+			t.Errorf("Expected metadata %v, got %v", expected, updated.Metadata)
+		}
+
+		if _, err := fr.UpdateMetadata(context.Background(), testUser.ID, second.ID, map[string]string{"project": "gemini"}, nil, true); err != nil {
+			t.Fatalf("Failed to replace metadata: %v", err)
+		}
+		if _, err := fr.AddTags(context.Background(), testUser.ID, second.ID, []string{"work"}); err != nil {
+			t.Fatalf("Failed to add tags: %v", err)
+		}
+
+		page, err := fr.ListPage(context.Background(), testUser.ID, repository.ListOptions{SortBy: repository.SortByName, Limit: 10, MetadataKey: "project"})
+		if err != nil {
+			t.Fatalf("Failed to list by metadata key: %v", err)
+		}
+		if len(page) != 2 {
+			t.Errorf("Expected 2 files with project key, got %d", len(page))
+		}
+
+		value := "gemini"
+		page, err = fr.ListPage(context.Background(), testUser.ID, repository.ListOptions{SortBy: repository.SortByName, Limit: 10, MetadataKey: "project", MetadataValue: &value})
+		if err != nil {
+			t.Fatalf("Failed to list by metadata value: %v", err)
+		}
+		if len(page) != 1 || page[0].ID != second.ID {
+			t.Errorf("Expected only the gemini file, got %d files", len(page))
+		}
+
+		page, err = fr.ListPage(context.Background(), testUser.ID, repository.ListOptions{SortBy: repository.SortByName, Limit: 10, Tags: []string{"work"}})
+		if err != nil {
+			t.Fatalf("Failed to list by tag: %v", err)
+		}
+		if len(page) != 1 || page[0].ID != second.ID {
+			t.Errorf("Expected only the tagged file, got %d files", len(page))
+		}
+	})
+}
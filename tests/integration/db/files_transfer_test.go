@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"meemo/internal/domain/entity"
+	"meemo/internal/domain/file/repository"
+	"testing"
+)
+
+func TestFileRepository_TransferOwnership(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		owner, err := ur.Create(context.Background(), "Test", "Owner", "transfer-owner@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create owner: %v", err)
+		}
+		receiver, err := ur.Create(context.Background(), "Test", "Receiver", "transfer-receiver@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create receiver: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+		sr := store.NewShareRepository()
+
+		docs, err := store.NewFolderRepository().Create(context.Background(), owner.ID, nil, "docs")
+		if err != nil {
+			t.Fatalf("Failed to create folder: %v", err)
+		}
+		saved, err := fr.Create(context.Background(), &entity.File{
+			UserID:       owner.ID,
+			OriginalName: "report.pdf",
+			MimeType:     "application/pdf",
+			S3Bucket:     "test-bucket",
+			FolderID:     &docs.ID,
+		})
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		for _, size := range []int64{30, 70} {
+			if _, err := fr.AddVersion(context.Background(), &entity.FileVersion{FileID: saved.ID, S3Key: "versions/report", SizeInBytes: size}, nil); err != nil {
+				t.Fatalf("Failed to add version: %v", err)
+			}
+		}
+		if _, err := sr.Upsert(context.Background(), saved.ID, receiver.Email, entity.PermissionViewer, owner.ID); err != nil {
+			t.Fatalf("Failed to share file: %v", err)
+		}
+
+		// Файл занимает 100 байт вместе со старой версией.
+		if _, err := fr.TransferOwnership(context.Background(), saved.ID, owner.ID, receiver.Email, 99); !errors.Is(err, repository.ErrQuotaExceeded) {
+			t.Errorf("Expected quota to be exceeded, got %v", err)
+		}
+		if _, err := fr.TransferOwnership(context.Background(), saved.ID, owner.ID, "nobody@test.com", 1000); !errors.Is(err, repository.ErrUserNotFound) {
+			t.Errorf("Expected unknown receiver to be rejected, got %v", err)
+		}
+
+		transferred, err := fr.TransferOwnership(context.Background(), saved.ID, owner.ID, receiver.Email, 100)
+		if err != nil {
+			t.Fatalf("Failed to transfer file: %v", err)
+		}
+		if transferred.UserID != receiver.ID || transferred.FolderID != nil {
+			t.Errorf("Expected file in the receiver's root, got user %d folder %v", transferred.UserID, transferred.FolderID)
+		}
+
+		shares, err := sr.ListByFile(context.Background(), saved.ID)
+		if err != nil {
+			t.Fatalf("Failed to list shares: %v", err)
+		}
+		if len(shares) != 0 {
+			t.Errorf("Expected shares to be revoked, got %d", len(shares))
+		}
+
+		if _, err := fr.TransferOwnership(context.Background(), saved.ID, owner.ID, receiver.Email, 1000); err == nil {
+			t.Error("Expected previous owner to be unable to transfer the file again")
+		}
+	})
+}
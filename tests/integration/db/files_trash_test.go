@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+	"testing"
+	"time"
+)
+
+func TestTrash_SoftDeleteAndRestore(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "trash@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		saved, err := fr.Save(context.Background(), testUser.ID, "notes.txt", "text/plain", "test-bucket", "", 100, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+
+		trashed, err := fr.Trash(context.Background(), testUser.ID, saved.ID)
+		if err != nil {
+			t.Fatalf("Failed to trash file: %v", err)
+		}
+		if trashed.Status != entity.Removed || trashed.DeletedAt == nil {
+			t.Errorf("Expected trashed file to be Removed with deleted_at, got status %d", trashed.Status)
+		}
+
+		if _, err := fr.Get(context.Background(), saved.ID); err == nil {
+			t.Error("Expected trashed file to be hidden from Get")
+		}
+
+		files, err := fr.List(context.Background(), testUser.Email)
+		if err != nil {
+			t.Fatalf("Failed to list files: %v", err)
+		}
+		if len(files) != 0 {
+			t.Errorf("Expected trashed file to be excluded from list, got %d files", len(files))
+		}
+
+		used, err := fr.GetTotalUsedSpace(context.Background(), testUser.Email)
+		if err != nil {
+			t.Fatalf("Failed to get used space: %v", err)
+		}
+		if used != 100 {
+			t.Errorf("Expected trashed file to count towards quota, got %d", used)
+		}
+
+		trash, err := fr.ListTrash(context.Background(), testUser.ID)
+		if err != nil {
+			t.Fatalf("Failed to list trash: %v", err)
+		}
+		if len(trash) != 1 || trash[0].ID != saved.ID {
+			t.Fatalf("Expected trashed file in trash, got %d files", len(trash))
+		}
+
+		restored, err := fr.RestoreFromTrash(context.Background(), testUser.ID, saved.ID)
+		if err != nil {
+			t.Fatalf("Failed to restore file: %v", err)
+		}
+		if restored.DeletedAt != nil || restored.Status != entity.Pending {
+			t.Errorf("Expected restored file without content to be Pending, got status %d", restored.Status)
+		}
+	})
+}
+
+func TestTrash_NameReuseAndExpiry(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "trashexpiry@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		first, err := fr.Save(context.Background(), testUser.ID, "report.pdf", "application/pdf", "test-bucket", "", 10, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		if _, err := fr.Trash(context.Background(), testUser.ID, first.ID); err != nil {
+			t.Fatalf("Failed to trash file: %v", err)
+		}
+
+		if _, err := fr.Save(context.Background(), testUser.ID, "report.pdf", "application/pdf", "test-bucket", "", 10, false); err != nil {
+			t.Fatalf("Expected name of trashed file to be reusable: %v", err)
+		}
+
+		if _, err := fr.RestoreFromTrash(context.Background(), testUser.ID, first.ID); err == nil {
+			t.Error("Expected restore to fail while the name is taken")
+		}
+
+		expired, err := fr.ListExpiredTrash(context.Background(), time.Now().Add(time.Minute), 10)
+		if err != nil {
+			t.Fatalf("Failed to list expired trash: %v", err)
+		}
+		if len(expired) != 1 || expired[0].ID != first.ID {
+			t.Fatalf("Expected trashed file to be expired, got %d files", len(expired))
+		}
+
+		expired, err = fr.ListExpiredTrash(context.Background(), time.Now().Add(-time.Hour), 10)
+		if err != nil {
+			t.Fatalf("Failed to list expired trash: %v", err)
+		}
+		if len(expired) != 0 {
+			t.Errorf("Expected recently trashed file to be retained, got %d files", len(expired))
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+	"testing"
+)
+
+func TestGetUserPlan(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "plan@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		plan, err := fr.GetUserPlan(context.Background(), testUser.ID)
+		if err != nil {
+			t.Fatalf("Failed to get user plan: %v", err)
+		}
+		if plan != "" {
+			t.Errorf("Expected default plan to be empty, got %q", plan)
+		}
+
+		if _, err := store.DB.ExecContext(context.Background(), "UPDATE users SET plan = 'free' WHERE id = $1", testUser.ID); err != nil {
+			t.Fatalf("Failed to set user plan: %v", err)
+		}
+		plan, err = fr.GetUserPlan(context.Background(), testUser.ID)
+		if err != nil {
+			t.Fatalf("Failed to get user plan: %v", err)
+		}
+		if plan != "free" {
+			t.Errorf("Expected plan free, got %q", plan)
+		}
+	})
+}
+
+func TestAddVersion_CorrectsMimeType(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "sniff@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		saved, err := fr.Save(context.Background(), testUser.ID, "photo", "application/octet-stream", "test-bucket", "", 100, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+
+		loaded, err := fr.AddVersion(context.Background(), &entity.FileVersion{
+			FileID:      saved.ID,
+			S3Key:       "versions/photo/1",
+			SizeInBytes: 100,
+			MimeType:    "image/png",
+		}, nil)
+		if err != nil {
+			t.Fatalf("Failed to add version: %v", err)
+		}
+		if loaded.MimeType != "image/png" {
+			t.Errorf("Expected detected mime type image/png, got %q", loaded.MimeType)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+	"testing"
+	"time"
+)
+
+func TestFileVersions_AddRestorePrune(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "versions@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		saved, err := fr.Save(context.Background(), testUser.ID, "report.txt", "text/plain", "test-bucket", "", 10, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+
+		for i, size := range []int64{10, 20, 30} {
+			updated, err := fr.AddVersion(context.Background(), &entity.FileVersion{
+				FileID:         saved.ID,
+				S3Key:          "versions/report-" + string(rune('a'+i)),
+				SizeInBytes:    size,
+				ChecksumSHA256: "sum",
+				CreatedBy:      &testUser.ID,
+			}, nil)
+			if err != nil {
+				t.Fatalf("Failed to add version %d: %v", i+1, err)
+			}
+			if updated.SizeInBytes != size {
+				t.Errorf("Expected current size %d, got %d", size, updated.SizeInBytes)
+			}
+		}
+
+		versions, err := fr.ListVersions(context.Background(), saved.ID)
+		if err != nil {
+			t.Fatalf("Failed to list versions: %v", err)
+		}
+		if len(versions) != 3 || versions[0].VersionNumber != 3 {
+			t.Fatalf("Expected 3 versions newest first, got %d", len(versions))
+		}
+
+		used, err := fr.GetTotalUsedSpace(context.Background(), testUser.Email)
+		if err != nil {
+			t.Fatalf("Failed to get used space: %v", err)
+		}
+		if used != 60 {
+			t.Errorf("Expected all versions to count towards used space (60), got %d", used)
+		}
+
+		restored, err := fr.RestoreVersion(context.Background(), saved.ID, 1)
+		if err != nil {
+			t.Fatalf("Failed to restore version: %v", err)
+		}
+		if restored.SizeInBytes != 10 || restored.S3Key != "versions/report-a" {
+			t.Errorf("Expected version 1 to become current, got size %d key %s", restored.SizeInBytes, restored.S3Key)
+		}
+
+		pruned, err := fr.PruneVersions(context.Background(), saved.ID, 1, time.Time{})
+		if err != nil {
+			t.Fatalf("Failed to prune versions: %v", err)
+		}
+		if len(pruned) != 1 || pruned[0].VersionNumber != 2 {
+			t.Fatalf("Expected only version 2 to be pruned (3 is newest, 1 is current), got %d", len(pruned))
+		}
+
+		if _, err := fr.GetVersion(context.Background(), saved.ID, 2); err == nil {
+			t.Error("Expected pruned version to be gone")
+		}
+	})
+}
+
+func TestFileVersions_DeleteReleasesBlobs(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "versionblobs@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+
+		saved, err := fr.Save(context.Background(), testUser.ID, "data.bin", "application/octet-stream", "test-bucket", "", 4, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+
+		blob := &entity.Blob{SHA256: "cafebabe", S3Key: "blobs/cafebabe", SizeInBytes: 4}
+		for i := 0; i < 2; i++ {
+			if _, err := fr.AddVersion(context.Background(), &entity.FileVersion{FileID: saved.ID, SizeInBytes: 4}, blob); err != nil {
+				t.Fatalf("Failed to add version: %v", err)
+			}
+		}
+		assertBlobRefCount(t, fr, blob.SHA256, 2)
+
+		if _, err := fr.DeleteByID(context.Background(), testUser.ID, saved.ID); err != nil {
+			t.Fatalf("Failed to delete file: %v", err)
+		}
+		assertBlobRefCount(t, fr, blob.SHA256, 0)
+	})
+}
@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFolderRepository_Hierarchy(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "folders@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFolderRepository()
+
+		docs, err := fr.Create(context.Background(), testUser.ID, nil, "docs")
+		if err != nil {
+			t.Fatalf("Failed to create root folder: %v", err)
+		}
+		reports, err := fr.Create(context.Background(), testUser.ID, &docs.ID, "reports")
+		if err != nil {
+			t.Fatalf("Failed to create nested folder: %v", err)
+		}
+
+		if _, err := fr.Create(context.Background(), testUser.ID, nil, "docs"); err == nil {
+			t.Error("Expected error when creating duplicate folder name in the same parent")
+		}
+
+		if _, err := fr.Move(context.Background(), testUser.ID, docs.ID, &reports.ID); err == nil {
+			t.Error("Expected error when moving folder into its own descendant")
+		}
+
+		children, err := fr.ListChildren(context.Background(), testUser.ID, nil)
+		if err != nil {
+			t.Fatalf("Failed to list root folders: %v", err)
+		}
+		if len(children) != 1 || children[0].ID != docs.ID {
+			t.Errorf("Expected only docs folder at root, got %d folders", len(children))
+		}
+
+		moved, err := fr.Move(context.Background(), testUser.ID, reports.ID, nil)
+		if err != nil {
+			t.Fatalf("Failed to move folder to root: %v", err)
+		}
+		if moved.ParentID != nil {
+			t.Error("Expected moved folder to have no parent")
+		}
+	})
+}
+
+func TestFolderRepository_SizeAndFiles(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "foldersize@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		folders := store.NewFolderRepository()
+		files := store.NewFileRepository()
+
+		parent, err := folders.Create(context.Background(), testUser.ID, nil, "parent")
+		if err != nil {
+			t.Fatalf("Failed to create folder: %v", err)
+		}
+		child, err := folders.Create(context.Background(), testUser.ID, &parent.ID, "child")
+		if err != nil {
+			t.Fatalf("Failed to create folder: %v", err)
+		}
+
+		rootFile, err := files.Save(context.Background(), testUser.ID, "report.pdf", "application/pdf", "test-bucket", "", 100, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		nestedFile, err := files.Save(context.Background(), testUser.ID, "other.pdf", "application/pdf", "test-bucket", "", 50, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+
+		if _, err := files.MoveToFolder(context.Background(), testUser.ID, nestedFile.ID, &child.ID); err != nil {
+			t.Fatalf("Failed to move file: %v", err)
+		}
+		if _, err := files.MoveToFolder(context.Background(), testUser.ID, rootFile.ID, &parent.ID); err != nil {
+			t.Fatalf("Failed to move file: %v", err)
+		}
+
+		// Файл с тем же именем может лежать в другой папке
+		if _, err := files.Save(context.Background(), testUser.ID, "report.pdf", "application/pdf", "test-bucket", "", 10, false); err != nil {
+			t.Fatalf("Expected same file name to be allowed in another folder: %v", err)
+		}
+
+		size, err := folders.GetSize(context.Background(), testUser.ID, parent.ID)
+		if err != nil {
+			t.Fatalf("Failed to get folder size: %v", err)
+		}
+		if size.SizeInBytes != 150 {
+			t.Errorf("Expected size 150, got %d", size.SizeInBytes)
+		}
+		if size.FileCount != 2 {
+			t.Errorf("Expected 2 files, got %d", size.FileCount)
+		}
+		if size.FolderCount != 1 {
+			t.Errorf("Expected 1 nested folder, got %d", size.FolderCount)
+		}
+
+		tree, err := files.ListInFolderTree(context.Background(), testUser.ID, parent.ID)
+		if err != nil {
+			t.Fatalf("Failed to list folder tree: %v", err)
+		}
+		if len(tree) != 2 {
+			t.Errorf("Expected 2 files in folder tree, got %d", len(tree))
+		}
+	})
+}
+
+func TestFolderRepository_ListTree(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(context.Background(), "Test", "User", "foldertree@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFolderRepository()
+
+		docs, err := fr.Create(context.Background(), testUser.ID, nil, "docs")
+		if err != nil {
+			t.Fatalf("Failed to create root folder: %v", err)
+		}
+		reports, err := fr.Create(context.Background(), testUser.ID, &docs.ID, "reports")
+		if err != nil {
+			t.Fatalf("Failed to create nested folder: %v", err)
+		}
+		if _, err := fr.Create(context.Background(), testUser.ID, &reports.ID, "2024"); err != nil {
+			t.Fatalf("Failed to create nested folder: %v", err)
+		}
+		if _, err := fr.Create(context.Background(), testUser.ID, nil, "other"); err != nil {
+			t.Fatalf("Failed to create root folder: %v", err)
+		}
+
+		tree, err := fr.ListTree(context.Background(), testUser.ID, docs.ID)
+		if err != nil {
+			t.Fatalf("Failed to list folder tree: %v", err)
+		}
+		if len(tree) != 3 {
+			t.Fatalf("Expected 3 folders in the tree, got %d", len(tree))
+		}
+		if tree[0].ID != docs.ID || tree[1].ID != reports.ID || tree[2].Name != "2024" {
+			t.Errorf("Expected folders ordered from root to leaves, got %s, %s, %s", tree[0].Name, tree[1].Name, tree[2].Name)
+		}
+
+		other, err := ur.Create(context.Background(), "Other", "User", "foldertree-other@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+		tree, err = fr.ListTree(context.Background(), other.ID, docs.ID)
+		if err != nil {
+			t.Fatalf("Failed to list folder tree: %v", err)
+		}
+		if len(tree) != 0 {
+			t.Errorf("Expected no folders for another user, got %d", len(tree))
+		}
+	})
+}
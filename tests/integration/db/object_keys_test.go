@@ -0,0 +1,146 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"meemo/internal/domain/entity"
+	"testing"
+)
+
+func TestObjectKeys_CopyAndRewrap(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ctx := context.Background()
+		kr := store.NewObjectKeyRepository()
+
+		saved, err := kr.Save(ctx, &entity.ObjectKey{
+			S3Key:         "uploads/1/a",
+			KeyID:         "old",
+			WrappedKey:    []byte("wrapped-old"),
+			ChunkSize:     65536,
+			PlaintextSize: 100,
+		})
+		if err != nil {
+			t.Fatalf("Failed to save object key: %v", err)
+		}
+		if saved.KeyID != "old" || saved.PlaintextSize != 100 {
+			t.Errorf("Unexpected saved key: %+v", saved)
+		}
+
+		if err := kr.Copy(ctx, "uploads/1/a", "blobs/abc"); err != nil {
+			t.Fatalf("Failed to copy object key: %v", err)
+		}
+		copied, err := kr.Get(ctx, "blobs/abc")
+		if err != nil {
+			t.Fatalf("Failed to get copied key: %v", err)
+		}
+		if !bytes.Equal(copied.WrappedKey, saved.WrappedKey) || copied.ChunkSize != saved.ChunkSize {
+			t.Errorf("Copied key differs from source: %+v", copied)
+		}
+
+		// Копия открытого объекта не должна наследовать старый ключ получателя.
+		if err := kr.Copy(ctx, "plain", "blobs/abc"); err != nil {
+			t.Fatalf("Failed to copy missing key: %v", err)
+		}
+		if _, err := kr.Get(ctx, "blobs/abc"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected destination key to be removed, got %v", err)
+		}
+
+		stale, err := kr.ListWrappedWithOther(ctx, "new", 10)
+		if err != nil {
+			t.Fatalf("Failed to list keys: %v", err)
+		}
+		if len(stale) != 1 || stale[0].S3Key != "uploads/1/a" {
+			t.Fatalf("Expected one key wrapped with the old master key, got %d", len(stale))
+		}
+
+		rewrapped, err := kr.Rewrap(ctx, "uploads/1/a", "old", "new", []byte("wrapped-new"))
+		if err != nil || !rewrapped {
+			t.Fatalf("Failed to rewrap key: %v", err)
+		}
+		rewrapped, err = kr.Rewrap(ctx, "uploads/1/a", "old", "new", []byte("wrapped-again"))
+		if err != nil || rewrapped {
+			t.Errorf("Rewrap with a stale key id should be a no-op, got %v %v", rewrapped, err)
+		}
+
+		current, err := kr.Get(ctx, "uploads/1/a")
+		if err != nil {
+			t.Fatalf("Failed to get key: %v", err)
+		}
+		if current.KeyID != "new" || !bytes.Equal(current.WrappedKey, []byte("wrapped-new")) {
+			t.Errorf("Unexpected key after rewrap: %+v", current)
+		}
+
+		if err := kr.Delete(ctx, "uploads/1/a"); err != nil {
+			t.Fatalf("Failed to delete key: %v", err)
+		}
+		if _, err := kr.Get(ctx, "uploads/1/a"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("Expected key to be deleted, got %v", err)
+		}
+	})
+}
+
+func TestObjectKeys_MarkRewrapFailed(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ctx := context.Background()
+		kr := store.NewObjectKeyRepository()
+
+		for _, s3Key := range []string{"uploads/1/a", "uploads/1/b"} {
+			if _, err := kr.Save(ctx, &entity.ObjectKey{
+				S3Key:         s3Key,
+				KeyID:         "old",
+				WrappedKey:    []byte("wrapped-old"),
+				ChunkSize:     65536,
+				PlaintextSize: 100,
+			}); err != nil {
+				t.Fatalf("Failed to save object key: %v", err)
+			}
+		}
+
+		// Отметка с устаревшим ID мастер-ключа не применяется.
+		if err := kr.MarkRewrapFailed(ctx, "uploads/1/b", "other"); err != nil {
+			t.Fatalf("Failed to mark key: %v", err)
+		}
+		if err := kr.MarkRewrapFailed(ctx, "uploads/1/a", "old"); err != nil {
+			t.Fatalf("Failed to mark key: %v", err)
+		}
+		stale, err := kr.ListWrappedWithOther(ctx, "new", 10)
+		if err != nil {
+			t.Fatalf("Failed to list keys: %v", err)
+		}
+		if len(stale) != 1 || stale[0].S3Key != "uploads/1/b" {
+			t.Fatalf("Expected only the unmarked key, got %d keys", len(stale))
+		}
+
+		// Копия того же шифртекста наследует отметку.
+		if err := kr.Copy(ctx, "uploads/1/a", "blobs/abc"); err != nil {
+			t.Fatalf("Failed to copy object key: %v", err)
+		}
+		stale, err = kr.ListWrappedWithOther(ctx, "new", 10)
+		if err != nil {
+			t.Fatalf("Failed to list keys: %v", err)
+		}
+		if len(stale) != 1 {
+			t.Fatalf("Expected copied key to stay marked, got %d keys", len(stale))
+		}
+
+		// Перезапись объекта сохраняет новый ключ данных и снимает отметку.
+		if _, err := kr.Save(ctx, &entity.ObjectKey{
+			S3Key:         "uploads/1/a",
+			KeyID:         "old",
+			WrappedKey:    []byte("wrapped-again"),
+			ChunkSize:     65536,
+			PlaintextSize: 50,
+		}); err != nil {
+			t.Fatalf("Failed to overwrite object key: %v", err)
+		}
+		stale, err = kr.ListWrappedWithOther(ctx, "new", 10)
+		if err != nil {
+			t.Fatalf("Failed to list keys: %v", err)
+		}
+		if len(stale) != 2 || stale[0].S3Key != "uploads/1/a" {
+			t.Errorf("Expected overwritten key to be listed again, got %d keys", len(stale))
+		}
+	})
+}
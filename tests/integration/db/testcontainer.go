@@ -1,4 +1,4 @@
-package db_postgres
+package db
 
 import (
 	"context"
@@ -119,7 +119,7 @@ func cleanupTables(db *sqlx.DB) error {
 	// TODO: Добавить обработку ошибки
 	defer func() { _ = tx.Rollback() }()
 
-	tables := []string{"files", "users"}
+	tables := []string{"object_outbox", "file_deletions", "thumbnails", "object_keys", "share_links", "file_shares", "file_versions", "files", "folders", "blobs", "users"}
 	for _, table := range tables {
 		if _, err := tx.Exec("TRUNCATE TABLE " + table + " CASCADE"); err != nil {
 			return fmt.Errorf("failed to truncate table %s: %w", table, err)
@@ -128,8 +128,3 @@ func cleanupTables(db *sqlx.DB) error {
 
 	return tx.Commit()
 }
-
-func initTestDB(t *testing.T) (*sqlx.DB, func()) {
-	tc, cleanup := SetupPostgresContainer(t)
-	return tc.DB, cleanup
-}
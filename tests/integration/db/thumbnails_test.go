@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"meemo/internal/domain/entity"
+	"testing"
+)
+
+func TestThumbnailRepository(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, store *Store) {
+		ctx := context.Background()
+		ur := store.NewUserRepository()
+		passwordHash := hashPassword(t, "password")
+		testUser, err := ur.Create(ctx, "Test", "User", "thumbs@test.com", passwordHash)
+		if err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+
+		fr := store.NewFileRepository()
+		tr := store.NewThumbnailRepository()
+
+		saved, err := fr.Save(ctx, testUser.ID, "photo.png", "image/png", "test-bucket", "", 100, false)
+		if err != nil {
+			t.Fatalf("Failed to save file: %v", err)
+		}
+		loaded, err := fr.AddVersion(ctx, &entity.FileVersion{
+			FileID:      saved.ID,
+			S3Key:       "versions/photo/1",
+			SizeInBytes: 100,
+			MimeType:    "image/png",
+		}, nil)
+		if err != nil {
+			t.Fatalf("Failed to add version: %v", err)
+		}
+		if loaded.CurrentVersionID == nil {
+			t.Fatalf("Expected current version to be set")
+		}
+		versionID := *loaded.CurrentVersionID
+
+		pending, err := fr.ListForThumbnails(ctx, []string{"image/png"}, 2, 10)
+		if err != nil {
+			t.Fatalf("Failed to list files for thumbnails: %v", err)
+		}
+		if len(pending) != 1 || pending[0].ID != saved.ID {
+			t.Fatalf("Expected file %d to need thumbnails, got %d files", saved.ID, len(pending))
+		}
+
+		for _, format := range []string{"jpeg", "webp"} {
+			_, err := tr.Save(ctx, &entity.Thumbnail{
+				FileID:      saved.ID,
+				VersionID:   versionID,
+				Size:        128,
+				Format:      format,
+				Status:      entity.ThumbnailReady,
+				S3Key:       "thumbnails/photo/" + format,
+				Width:       128,
+				Height:      96,
+				SizeInBytes: 10,
+			})
+			if err != nil {
+				t.Fatalf("Failed to save thumbnail: %v", err)
+			}
+		}
+
+		got, err := tr.Get(ctx, saved.ID, versionID, 128, "webp")
+		if err != nil {
+			t.Fatalf("Failed to get thumbnail: %v", err)
+		}
+		if got.Status != entity.ThumbnailReady || got.Width != 128 || got.Height != 96 || got.S3Key != "thumbnails/photo/webp" {
+			t.Errorf("Unexpected thumbnail: %+v", got)
+		}
+
+		pending, err = fr.ListForThumbnails(ctx, []string{"image/png"}, 2, 10)
+		if err != nil {
+			t.Fatalf("Failed to list files for thumbnails: %v", err)
+		}
+		if len(pending) != 0 {
+			t.Errorf("Expected no files to need thumbnails, got %d", len(pending))
+		}
+
+		stale, err := tr.ListStale(ctx, 10)
+		if err != nil {
+			t.Fatalf("Failed to list stale thumbnails: %v", err)
+		}
+		if len(stale) != 0 {
+			t.Errorf("Expected no stale thumbnails, got %d", len(stale))
+		}
+
+		if _, err := fr.AddVersion(ctx, &entity.FileVersion{
+			FileID:      saved.ID,
+			S3Key:       "versions/photo/2",
+			SizeInBytes: 120,
+			MimeType:    "image/png",
+		}, nil); err != nil {
+			t.Fatalf("Failed to add version: %v", err)
+		}
+
+		stale, err = tr.ListStale(ctx, 10)
+		if err != nil {
+			t.Fatalf("Failed to list stale thumbnails: %v", err)
+		}
+		if len(stale) != 2 {
+			t.Fatalf("Expected 2 stale thumbnails after new version, got %d", len(stale))
+		}
+		for _, thumb := range stale {
+			if err := tr.Delete(ctx, thumb); err != nil {
+				t.Fatalf("Failed to delete thumbnail: %v", err)
+			}
+		}
+
+		thumbs, err := tr.ListByVersion(ctx, saved.ID, versionID)
+		if err != nil {
+			t.Fatalf("Failed to list thumbnails: %v", err)
+		}
+		if len(thumbs) != 0 {
+			t.Errorf("Expected thumbnails to be deleted, got %d", len(thumbs))
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package s3
+
+import (
+	"testing"
+
+	"meemo/internal/infrastructure/logger"
+	"meemo/internal/infrastructure/storage/s3/file"
+)
+
+// Backend — реализация file.S3Client, на которой прогоняются общие тесты клиента.
+type Backend struct {
+	Name  string
+	Setup func(t *testing.T) (file.S3Client, func())
+}
+
+func Backends() []Backend {
+	return []Backend{
+		{Name: "S3", Setup: setupS3Backend},
+		{Name: "Filesystem", Setup: setupFilesystemBackend},
+	}
+}
+
+// ForEachBackend запускает fn подтестом для каждой реализации с отдельным пустым хранилищем.
+func ForEachBackend(t *testing.T, fn func(t *testing.T, client file.S3Client)) {
+	for _, backend := range Backends() {
+		t.Run(backend.Name, func(t *testing.T) {
+			client, cleanup := backend.Setup(t)
+			// Cleanup, а не defer: параллельные подтесты fn завершаются после возврата из нее.
+			t.Cleanup(cleanup)
+			fn(t, client)
+		})
+	}
+}
+
+func setupS3Backend(t *testing.T) (file.S3Client, func()) {
+	s3Client, testBucket, cleanup := SetupS3ClientForTest(t)
+	log, _ := logger.NewLogger("error")
+	return file.NewS3Client(s3Client, testBucket, log), cleanup
+}
+
+func setupFilesystemBackend(t *testing.T) (file.S3Client, func()) {
+	log, _ := logger.NewLogger("error")
+	client, err := file.NewFSClient(file.FSOptions{Root: t.TempDir(), Fsync: true}, log)
+	if err != nil {
+		t.Fatalf("Failed to create filesystem storage: %v", err)
+	}
+	return client, func() {}
+}
@@ -0,0 +1,197 @@
+package s3
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"meemo/internal/infrastructure/logger"
+	"meemo/internal/infrastructure/storage/s3/file"
+)
+
+func TestFSClient_Layout(t *testing.T) {
+	log, _ := logger.NewLogger("error")
+	root := t.TempDir()
+	client, err := file.NewFSClient(file.FSOptions{Root: root, ShardDepth: 3}, log)
+	if err != nil {
+		t.Fatalf("Failed to create filesystem storage: %v", err)
+	}
+
+	keys := []string{
+		file.ThumbnailKey(1, 2, 256, "webp"),
+		"../escape",
+		".hidden",
+		"user@example.com/dir/name with spaces.txt",
+	}
+	for _, key := range keys {
+		if err := client.PutObject(t.Context(), key, strings.NewReader(key), int64(len(key))); err != nil {
+			t.Fatalf("Failed to put %q: %v", key, err)
+		}
+	}
+
+	t.Run("Layout_StaysInRoot", func(t *testing.T) {
+		var files []string
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			rel, _ := filepath.Rel(root, path)
+			if strings.HasPrefix(rel, ".tmp") {
+				t.Errorf("Temp file left behind: %s", rel)
+			}
+			if depth := strings.Count(rel, string(filepath.Separator)); depth != 3 {
+				t.Errorf("Expected %s to be 3 directories deep, got %d", rel, depth)
+			}
+			files = append(files, rel)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to walk root: %v", err)
+		}
+		if len(files) != len(keys) {
+			t.Errorf("Expected %d files, got %v", len(keys), files)
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(root), "escape")); err == nil {
+			t.Error("Object key escaped the storage root")
+		}
+	})
+
+	t.Run("Layout_ListRestoresKeys", func(t *testing.T) {
+		var listed []string
+		err := client.ListObjects(t.Context(), "", func(object *file.ObjectInfo) error {
+			listed = append(listed, object.Key)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to list objects: %v", err)
+		}
+		if len(listed) != len(keys) {
+			t.Fatalf("Expected %d objects, got %v", len(keys), listed)
+		}
+
+		// Объекты перечисляются по ходу обхода каталогов, то есть в порядке путей на диске.
+		var onDisk []string
+		err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() && entry.Name() == ".tmp" {
+				return filepath.SkipDir
+			}
+			if !entry.IsDir() {
+				key, err := url.PathUnescape(entry.Name())
+				if err != nil {
+					return err
+				}
+				onDisk = append(onDisk, key)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to walk root: %v", err)
+		}
+		if !slices.Equal(listed, onDisk) {
+			t.Errorf("Expected objects in path order %v, got %v", onDisk, listed)
+		}
+		for _, key := range keys {
+			if !slices.Contains(listed, key) {
+				t.Errorf("Expected %q to be listed, got %v", key, listed)
+			}
+		}
+	})
+
+	t.Run("Layout_ListStopsOnError", func(t *testing.T) {
+		stop := errors.New("stop")
+		calls := 0
+		err := client.ListObjects(t.Context(), "", func(*file.ObjectInfo) error {
+			calls++
+			return stop
+		})
+		if !errors.Is(err, stop) || calls != 1 {
+			t.Errorf("Expected listing to stop after the first object, got %d calls and %v", calls, err)
+		}
+	})
+
+	t.Run("Layout_DeleteWhileListing", func(t *testing.T) {
+		var deleted []string
+		err := client.ListObjects(t.Context(), "", func(object *file.ObjectInfo) error {
+			deleted = append(deleted, object.Key)
+			return client.DeleteObject(t.Context(), object.Key)
+		})
+		if err != nil {
+			t.Fatalf("Failed to delete objects while listing: %v", err)
+		}
+		if len(deleted) != len(keys) {
+			t.Errorf("Expected all %d objects to be visited, got %v", len(keys), deleted)
+		}
+		err = client.ListObjects(t.Context(), "", func(object *file.ObjectInfo) error {
+			t.Errorf("Expected no objects left, got %q", object.Key)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to list objects: %v", err)
+		}
+	})
+}
+
+func TestFSClient_PutObject(t *testing.T) {
+	log, _ := logger.NewLogger("error")
+	root := t.TempDir()
+	client, err := file.NewFSClient(file.FSOptions{Root: root}, log)
+	if err != nil {
+		t.Fatalf("Failed to create filesystem storage: %v", err)
+	}
+
+	key := file.FileKey(42)
+	original := "original content"
+	if err := client.PutObject(t.Context(), key, strings.NewReader(original), int64(len(original))); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	t.Run("PutObject_ShortReader", func(t *testing.T) {
+		err := client.PutObject(t.Context(), key, strings.NewReader("short"), 100)
+		if err == nil {
+			t.Fatal("Expected error for a reader shorter than the declared size, got nil")
+		}
+
+		var buf bytes.Buffer
+		if err := client.GetObject(t.Context(), key, &buf); err != nil {
+			t.Fatalf("Failed to get object: %v", err)
+		}
+		if buf.String() != original {
+			t.Errorf("Failed write replaced the object. Expected: %s, Got: %s", original, buf.String())
+		}
+
+		entries, err := os.ReadDir(filepath.Join(root, ".tmp"))
+		if err != nil {
+			t.Fatalf("Failed to read temp directory: %v", err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("Expected no temp files, got %d", len(entries))
+		}
+	})
+
+	t.Run("PutObject_Overwrite", func(t *testing.T) {
+		updated := "updated"
+		if err := client.PutObject(t.Context(), key, strings.NewReader(updated), int64(len(updated))); err != nil {
+			t.Fatalf("Failed to overwrite object: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := client.GetObject(t.Context(), key, &buf); err != nil {
+			t.Fatalf("Failed to get object: %v", err)
+		}
+		if buf.String() != updated {
+			t.Errorf("Object content mismatch. Expected: %s, Got: %s", updated, buf.String())
+		}
+	})
+}
@@ -5,269 +5,344 @@ import (
 	"strings"
 	"testing"
 
-	"meemo/internal/infrastructure/logger"
 	"meemo/internal/infrastructure/storage/s3/file"
-
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
 func TestS3Client_SaveAndGetFile(t *testing.T) {
-	s3Client, testBucket, cleanup := SetupS3ClientForTest(t)
-	defer cleanup()
-
-	log, _ := logger.NewLogger("error")
-	client := file.NewS3Client(s3Client, testBucket, log)
+	ForEachBackend(t, func(t *testing.T, client file.S3Client) {
+		testContent := "Hello, this is test file content!"
+		fileID := int64(12345)
+
+		t.Run("SaveFile_Success", func(t *testing.T) {
+			reader := strings.NewReader(testContent)
+			err := client.SaveFile(t.Context(), fileID, reader, int64(len(testContent)))
+			if err != nil {
+				t.Fatalf("Failed to save file: %v", err)
+			}
+		})
+
+		t.Run("GetFile_Success", func(t *testing.T) {
+			var buf bytes.Buffer
+			err := client.GetFileByID(t.Context(), fileID, &buf)
+			if err != nil {
+				t.Fatalf("Failed to get file: %v", err)
+			}
+
+			if buf.String() != testContent {
+				t.Errorf("File content mismatch. Expected: %s, Got: %s", testContent, buf.String())
+			}
+		})
+	})
+}
 
-	testContent := "Hello, this is test file content!"
-	fileID := int64(12345)
+func TestS3Client_GetFileByOriginalName(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, client file.S3Client) {
+		userEmail := "test@example.com"
+		originalName := "testfile.txt"
+		testContent := "File content by name"
 
-	t.Run("SaveFile_Success", func(t *testing.T) {
-		reader := strings.NewReader(testContent)
-		err := client.SaveFile(t.Context(), fileID, reader, int64(len(testContent)))
+		key := userEmail + originalName
+		err := client.PutObject(t.Context(), key, strings.NewReader(testContent), int64(len(testContent)))
 		if err != nil {
 			t.Fatalf("Failed to save file: %v", err)
 		}
-	})
 
-	t.Run("GetFile_Success", func(t *testing.T) {
-		var buf bytes.Buffer
-		err := client.GetFileByID(t.Context(), fileID, &buf)
-		if err != nil {
-			t.Fatalf("Failed to get file: %v", err)
-		}
-
-		if buf.String() != testContent {
-			t.Errorf("File content mismatch. Expected: %s, Got: %s", testContent, buf.String())
-		}
+		t.Run("GetFileByOriginalName_Success", func(t *testing.T) {
+			var buf bytes.Buffer
+			err := client.GetFileByOriginalName(t.Context(), userEmail, originalName, &buf)
+			if err != nil {
+				t.Fatalf("Failed to get file by original name: %v", err)
+			}
+
+			if buf.String() != testContent {
+				t.Errorf("File content mismatch. Expected: %s, Got: %s", testContent, buf.String())
+			}
+		})
 	})
 }
 
-func TestS3Client_GetFileByOriginalName(t *testing.T) {
-	s3Client, testBucket, cleanup := SetupS3ClientForTest(t)
-	defer cleanup()
-
-	log, _ := logger.NewLogger("error")
-	client := file.NewS3Client(s3Client, testBucket, log)
+func TestS3Client_DeleteFile(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, client file.S3Client) {
+		fileID := int64(99999)
+		testContent := "content to delete"
 
-	userEmail := "test@example.com"
-	originalName := "testfile.txt"
-	testContent := "File content by name"
+		reader := strings.NewReader(testContent)
+		err := client.SaveFile(t.Context(), fileID, reader, int64(len(testContent)))
+		if err != nil {
+			t.Fatalf("Setup failed: %v", err)
+		}
 
-	key := userEmail + originalName
-	_, err := s3Client.PutObject(t.Context(), &s3.PutObjectInput{
-		Bucket: aws.String(testBucket),
-		Key:    aws.String(key),
-		Body:   strings.NewReader(testContent),
+		t.Run("DeleteFile_Success", func(t *testing.T) {
+			err := client.DeleteFile(t.Context(), fileID)
+			if err != nil {
+				t.Fatalf("Failed to delete file: %v", err)
+			}
+
+			var buf bytes.Buffer
+			err = client.GetFileByID(t.Context(), fileID, &buf)
+			if err == nil {
+				t.Error("Expected error when getting deleted file, got nil")
+			}
+		})
 	})
-	if err != nil {
-		t.Fatalf("Failed to save file: %v", err)
-	}
+}
 
-	t.Run("GetFileByOriginalName_Success", func(t *testing.T) {
-		var buf bytes.Buffer
-		err := client.GetFileByOriginalName(t.Context(), userEmail, originalName, &buf)
+func TestS3Client_RenameFile(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, client file.S3Client) {
+		userEmail := "rename@example.com"
+		originalName := "old-name.txt"
+		newName := "new-name.txt"
+		originalContent := "original content"
+
+		key := userEmail + originalName
+		err := client.PutObject(t.Context(), key, strings.NewReader(originalContent), int64(len(originalContent)))
 		if err != nil {
-			t.Fatalf("Failed to get file by original name: %v", err)
+			t.Fatalf("Setup failed: %v", err)
 		}
 
-		if buf.String() != testContent {
-			t.Errorf("File content mismatch. Expected: %s, Got: %s", testContent, buf.String())
-		}
+		t.Run("RenameFile_Success", func(t *testing.T) {
+			err := client.RenameFile(t.Context(), userEmail, originalName, newName)
+			if err != nil {
+				t.Fatalf("Failed to rename file: %v", err)
+			}
+
+			var buf bytes.Buffer
+			err = client.GetFileByOriginalName(t.Context(), userEmail, newName, &buf)
+			if err != nil {
+				t.Fatalf("Failed to get renamed file: %v", err)
+			}
+
+			if buf.String() != originalContent {
+				t.Errorf("File content mismatch. Expected: %s, Got: %s", originalContent, buf.String())
+			}
+
+			var buf2 bytes.Buffer
+			err = client.GetFileByOriginalName(t.Context(), userEmail, originalName, &buf2)
+			if err == nil {
+				t.Error("Expected error when getting file by old name, got nil")
+			}
+		})
 	})
 }
 
-func TestS3Client_DeleteFile(t *testing.T) {
-	s3Client, testBucket, cleanup := SetupS3ClientForTest(t)
-	defer cleanup()
-
-	log, _ := logger.NewLogger("error")
-	client := file.NewS3Client(s3Client, testBucket, log)
-
-	fileID := int64(99999)
-	testContent := "content to delete"
-
-	reader := strings.NewReader(testContent)
-	err := client.SaveFile(t.Context(), fileID, reader, int64(len(testContent)))
-	if err != nil {
-		t.Fatalf("Setup failed: %v", err)
-	}
-
-	t.Run("DeleteFile_Success", func(t *testing.T) {
-		err := client.DeleteFile(t.Context(), fileID)
-		if err != nil {
-			t.Fatalf("Failed to delete file: %v", err)
+func TestS3Client_MultipleFiles(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, client file.S3Client) {
+		fileIDs := []int64{1001, 1002, 1003}
+		testContent := "test content"
+
+		for _, fileID := range fileIDs {
+			reader := strings.NewReader(testContent)
+			err := client.SaveFile(t.Context(), fileID, reader, int64(len(testContent)))
+			if err != nil {
+				t.Fatalf("Failed to create test file %d: %v", fileID, err)
+			}
 		}
 
-		var buf bytes.Buffer
-		err = client.GetFileByID(t.Context(), fileID, &buf)
-		if err == nil {
-			t.Error("Expected error when getting deleted file, got nil")
+		for _, fileID := range fileIDs {
+			var buf bytes.Buffer
+			err := client.GetFileByID(t.Context(), fileID, &buf)
+			if err != nil {
+				t.Errorf("Failed to get file %d: %v", fileID, err)
+			}
 		}
 	})
 }
 
-func TestS3Client_RenameFile(t *testing.T) {
-	s3Client, testBucket, cleanup := SetupS3ClientForTest(t)
-	defer cleanup()
-
-	log, _ := logger.NewLogger("error")
-	client := file.NewS3Client(s3Client, testBucket, log)
-
-	userEmail := "rename@example.com"
-	originalName := "old-name.txt"
-	newName := "new-name.txt"
-	originalContent := "original content"
-
-	key := userEmail + originalName
-	_, err := s3Client.PutObject(t.Context(), &s3.PutObjectInput{
-		Bucket: aws.String(testBucket),
-		Key:    aws.String(key),
-		Body:   strings.NewReader(originalContent),
+func TestS3Client_Parallel(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, client file.S3Client) {
+		t.Run("ParallelOperations", func(t *testing.T) {
+			t.Parallel()
+
+			testContent := "parallel content"
+			fileID := int64(2001)
+			reader := strings.NewReader(testContent)
+
+			err := client.SaveFile(t.Context(), fileID, reader, int64(len(testContent)))
+			if err != nil {
+				t.Errorf("Parallel save failed: %v", err)
+			}
+		})
+
+		t.Run("AnotherParallel", func(t *testing.T) {
+			t.Parallel()
+
+			testContent := "another content"
+			fileID := int64(2002)
+			reader := strings.NewReader(testContent)
+
+			err := client.SaveFile(t.Context(), fileID, reader, int64(len(testContent)))
+			if err != nil {
+				t.Errorf("Another parallel save failed: %v", err)
+			}
+		})
 	})
-	if err != nil {
-		t.Fatalf("Setup failed: %v", err)
-	}
+}
 
-	t.Run("RenameFile_Success", func(t *testing.T) {
-		err := client.RenameFile(t.Context(), userEmail, originalName, newName)
+func TestS3Client_DifferentUsers(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, client file.S3Client) {
+		user1Email := "user1@example.com"
+		user2Email := "user2@example.com"
+		fileName := "shared-name.txt"
+		user1Content := "user1 content"
+		user2Content := "user2 content"
+
+		key1 := user1Email + fileName
+		err := client.PutObject(t.Context(), key1, strings.NewReader(user1Content), int64(len(user1Content)))
 		if err != nil {
-			t.Fatalf("Failed to rename file: %v", err)
+			t.Fatalf("Failed to save file for user1: %v", err)
 		}
 
-		var buf bytes.Buffer
-		err = client.GetFileByOriginalName(t.Context(), userEmail, newName, &buf)
+		key2 := user2Email + fileName
+		err = client.PutObject(t.Context(), key2, strings.NewReader(user2Content), int64(len(user2Content)))
 		if err != nil {
-			t.Fatalf("Failed to get renamed file: %v", err)
+			t.Fatalf("Failed to save file for user2: %v", err)
 		}
 
-		if buf.String() != originalContent {
-			t.Errorf("File content mismatch. Expected: %s, Got: %s", originalContent, buf.String())
+		var buf1 bytes.Buffer
+		err = client.GetFileByOriginalName(t.Context(), user1Email, fileName, &buf1)
+		if err != nil {
+			t.Fatalf("Failed to get file for user1: %v", err)
+		}
+		if buf1.String() != user1Content {
+			t.Errorf("User1 got wrong content: %s", buf1.String())
 		}
 
 		var buf2 bytes.Buffer
-		err = client.GetFileByOriginalName(t.Context(), userEmail, originalName, &buf2)
-		if err == nil {
-			t.Error("Expected error when getting file by old name, got nil")
+		err = client.GetFileByOriginalName(t.Context(), user2Email, fileName, &buf2)
+		if err != nil {
+			t.Fatalf("Failed to get file for user2: %v", err)
+		}
+		if buf2.String() != user2Content {
+			t.Errorf("User2 got wrong content: %s", buf2.String())
 		}
 	})
 }
 
-func TestS3Client_MultipleFiles(t *testing.T) {
-	s3Client, testBucket, cleanup := SetupS3ClientForTest(t)
-	defer cleanup()
-
-	log, _ := logger.NewLogger("error")
-	client := file.NewS3Client(s3Client, testBucket, log)
+func TestS3Client_CopyObject(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, client file.S3Client) {
+		testContent := "content addressed"
+		uploadKey := file.UploadKey(777, "copy-test")
+		blobKey := file.BlobKey("0123456789abcdef")
 
-	fileIDs := []int64{1001, 1002, 1003}
-	testContent := "test content"
-
-	for _, fileID := range fileIDs {
-		reader := strings.NewReader(testContent)
-		err := client.SaveFile(t.Context(), fileID, reader, int64(len(testContent)))
+		err := client.PutObject(t.Context(), uploadKey, strings.NewReader(testContent), int64(len(testContent)))
 		if err != nil {
-			t.Fatalf("Failed to create test file %d: %v", fileID, err)
+			t.Fatalf("Setup failed: %v", err)
 		}
-	}
 
-	for _, fileID := range fileIDs {
-		var buf bytes.Buffer
-		err := client.GetFileByID(t.Context(), fileID, &buf)
-		if err != nil {
-			t.Errorf("Failed to get file %d: %v", fileID, err)
-		}
-	}
+		t.Run("CopyObject_Success", func(t *testing.T) {
+			if err := client.CopyObject(t.Context(), uploadKey, blobKey); err != nil {
+				t.Fatalf("Failed to copy object: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := client.GetObject(t.Context(), blobKey, &buf); err != nil {
+				t.Fatalf("Failed to get copied object: %v", err)
+			}
+			if buf.String() != testContent {
+				t.Errorf("Object content mismatch. Expected: %s, Got: %s", testContent, buf.String())
+			}
+		})
+
+		t.Run("DeleteObject_Success", func(t *testing.T) {
+			if err := client.DeleteObject(t.Context(), uploadKey); err != nil {
+				t.Fatalf("Failed to delete object: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := client.GetObject(t.Context(), uploadKey, &buf); err == nil {
+				t.Error("Expected error when getting deleted object, got nil")
+			}
+		})
+	})
 }
 
-func TestS3Client_Parallel(t *testing.T) {
-	t.Run("ParallelOperations", func(t *testing.T) {
-		t.Parallel()
-
-		s3Client, testBucket, cleanup := SetupS3ClientForTest(t)
-		defer cleanup()
-
-		log, _ := logger.NewLogger("error")
-		client := file.NewS3Client(s3Client, testBucket, log)
+func TestS3Client_ListObjects(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, client file.S3Client) {
+		objects := map[string]string{
+			file.UploadKey(1, "a"):           "first",
+			file.UploadKey(1, "b"):           "second object",
+			file.BlobKey("0123456789abcdef"): "blob",
+		}
+		for key, content := range objects {
+			if err := client.PutObject(t.Context(), key, strings.NewReader(content), int64(len(content))); err != nil {
+				t.Fatalf("Setup failed: %v", err)
+			}
+		}
 
-		testContent := "parallel content"
-		fileID := int64(2001)
-		reader := strings.NewReader(testContent)
+		t.Run("ListObjects_All", func(t *testing.T) {
+			listed := make(map[string]int64)
+			err := client.ListObjects(t.Context(), "", func(object *file.ObjectInfo) error {
+				listed[object.Key] = object.SizeInBytes
+				if object.LastModified.IsZero() {
+					t.Errorf("Expected last modified time for %s", object.Key)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Failed to list objects: %v", err)
+			}
+			if len(listed) != len(objects) {
+				t.Fatalf("Expected %d objects, got %d", len(objects), len(listed))
+			}
+			for key, content := range objects {
+				if listed[key] != int64(len(content)) {
+					t.Errorf("Expected %s to be %d bytes, got %d", key, len(content), listed[key])
+				}
+			}
+		})
+
+		t.Run("ListObjects_Prefix", func(t *testing.T) {
+			var keys []string
+			err := client.ListObjects(t.Context(), "uploads/", func(object *file.ObjectInfo) error {
+				keys = append(keys, object.Key)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Failed to list objects: %v", err)
+			}
+			if len(keys) != 2 {
+				t.Errorf("Expected 2 uploads, got %v", keys)
+			}
+		})
+	})
+}
 
-		err := client.SaveFile(t.Context(), fileID, reader, int64(len(testContent)))
-		if err != nil {
-			t.Errorf("Parallel save failed: %v", err)
+func TestS3Client_DeleteMissingObject(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, client file.S3Client) {
+		if err := client.DeleteObject(t.Context(), file.UploadKey(404, "missing")); err != nil {
+			t.Fatalf("Expected deleting a missing object to succeed, got: %v", err)
 		}
 	})
+}
 
-	t.Run("AnotherParallel", func(t *testing.T) {
-		t.Parallel()
-
-		s3Client, testBucket, cleanup := SetupS3ClientForTest(t)
-		defer cleanup()
-
-		log, _ := logger.NewLogger("error")
-		client := file.NewS3Client(s3Client, testBucket, log)
-
-		testContent := "another content"
-		fileID := int64(2002)
-		reader := strings.NewReader(testContent)
+func TestS3Client_GetObjectRange(t *testing.T) {
+	ForEachBackend(t, func(t *testing.T, client file.S3Client) {
+		testContent := "0123456789"
+		key := file.FileKey(3003)
 
-		err := client.SaveFile(t.Context(), fileID, reader, int64(len(testContent)))
+		err := client.PutObject(t.Context(), key, strings.NewReader(testContent), int64(len(testContent)))
 		if err != nil {
-			t.Errorf("Another parallel save failed: %v", err)
+			t.Fatalf("Setup failed: %v", err)
 		}
-	})
-}
 
-func TestS3Client_DifferentUsers(t *testing.T) {
-	s3Client, testBucket, cleanup := SetupS3ClientForTest(t)
-	defer cleanup()
-
-	log, _ := logger.NewLogger("error")
-	client := file.NewS3Client(s3Client, testBucket, log)
-
-	user1Email := "user1@example.com"
-	user2Email := "user2@example.com"
-	fileName := "shared-name.txt"
-	user1Content := "user1 content"
-	user2Content := "user2 content"
-
-	key1 := user1Email + fileName
-	_, err := s3Client.PutObject(t.Context(), &s3.PutObjectInput{
-		Bucket: aws.String(testBucket),
-		Key:    aws.String(key1),
-		Body:   strings.NewReader(user1Content),
-	})
-	if err != nil {
-		t.Fatalf("Failed to save file for user1: %v", err)
-	}
-
-	key2 := user2Email + fileName
-	_, err = s3Client.PutObject(t.Context(), &s3.PutObjectInput{
-		Bucket: aws.String(testBucket),
-		Key:    aws.String(key2),
-		Body:   strings.NewReader(user2Content),
+		t.Run("GetObjectRange_Middle", func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := client.GetObjectRange(t.Context(), key, 2, 5, &buf); err != nil {
+				t.Fatalf("Failed to get object range: %v", err)
+			}
+			if buf.String() != "23456" {
+				t.Errorf("Range content mismatch. Expected: 23456, Got: %s", buf.String())
+			}
+		})
+
+		t.Run("GetObjectRange_PastEnd", func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := client.GetObjectRange(t.Context(), key, 8, 10, &buf); err != nil {
+				t.Fatalf("Failed to get object range: %v", err)
+			}
+			if buf.String() != "89" {
+				t.Errorf("Range content mismatch. Expected: 89, Got: %s", buf.String())
+			}
+		})
 	})
-	if err != nil {
-		t.Fatalf("Failed to save file for user2: %v", err)
-	}
-
-	var buf1 bytes.Buffer
-	err = client.GetFileByOriginalName(t.Context(), user1Email, fileName, &buf1)
-	if err != nil {
-		t.Fatalf("Failed to get file for user1: %v", err)
-	}
-	if buf1.String() != user1Content {
-		t.Errorf("User1 got wrong content: %s", buf1.String())
-	}
-
-	var buf2 bytes.Buffer
-	err = client.GetFileByOriginalName(t.Context(), user2Email, fileName, &buf2)
-	if err != nil {
-		t.Fatalf("Failed to get file for user2: %v", err)
-	}
-	if buf2.String() != user2Content {
-		t.Errorf("User2 got wrong content: %s", buf2.String())
-	}
 }